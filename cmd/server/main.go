@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -14,7 +17,10 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/melihkorkmaz/gtd/internal/config"
 	"github.com/melihkorkmaz/gtd/internal/handlers"
+	"github.com/melihkorkmaz/gtd/internal/mailcapture"
 	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/reminders"
+	"github.com/melihkorkmaz/gtd/internal/trash"
 	"github.com/melihkorkmaz/gtd/internal/views/pages"
 )
 
@@ -26,6 +32,16 @@ func main() {
 		log.Println("Loaded configuration from .env file")
 	}
 
+	// Run a CLI subcommand instead of the server if one was given
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		runRestoreCommand(os.Args[2:])
+		return
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "3000"
@@ -33,6 +49,18 @@ func main() {
 
 	// Initialize the task store and user store (PostgreSQL or in-memory)
 	var taskStore models.TaskStore
+	var projectStore models.ProjectStore
+	var reviewStore models.ReviewStore
+	var staleThresholdStore models.StaleThresholdStore
+	var reminderStore models.ReminderStore
+	var notificationStore models.NotificationStore
+	var captureTokenStore models.CaptureTokenStore
+	var attachmentStore models.AttachmentStore
+	var calendarTokenStore models.CalendarTokenStore
+	var caldavTokenStore models.CalDAVTokenStore
+	var perspectiveStore models.PerspectiveStore
+	var contextStore models.ContextStore
+	var undoStore models.UndoStore
 	var userStore models.UserStore
 	var err error
 
@@ -41,15 +69,7 @@ func main() {
 
 	if useDB {
 		// Use PostgreSQL store with configuration
-		dbConfig := config.NewDatabaseConfigFromEnv()
-		dbConnString := os.Getenv("DATABASE_URL")
-
-		// Allow override via DATABASE_URL if set
-		if dbConnString == "" {
-			dbConnString = dbConfig.ConnectionString()
-		}
-
-		log.Printf("Connecting to PostgreSQL database: %s/%s", dbConfig.Host, dbConfig.DBName)
+		dbConnString := databaseConnString()
 
 		// Initialize task store
 		pgTaskStore, err := models.NewPgTaskStore(dbConnString)
@@ -59,6 +79,20 @@ func main() {
 		defer pgTaskStore.Close()
 		taskStore = pgTaskStore
 
+		// Projects, reviews and settings share the task store's connection pool
+		projectStore = models.NewPgProjectStore(pgTaskStore.Pool())
+		reviewStore = models.NewPgReviewStore(pgTaskStore.Pool())
+		staleThresholdStore = models.NewPgStaleThresholdStore(pgTaskStore.Pool())
+		reminderStore = models.NewPgReminderStore(pgTaskStore.Pool())
+		notificationStore = models.NewPgNotificationStore(pgTaskStore.Pool())
+		captureTokenStore = models.NewPgCaptureTokenStore(pgTaskStore.Pool())
+		attachmentStore = models.NewPgAttachmentStore(pgTaskStore.Pool())
+		calendarTokenStore = models.NewPgCalendarTokenStore(pgTaskStore.Pool())
+		caldavTokenStore = models.NewPgCalDAVTokenStore(pgTaskStore.Pool())
+		perspectiveStore = models.NewPgPerspectiveStore(pgTaskStore.Pool())
+		contextStore = models.NewPgContextStore(pgTaskStore.Pool())
+		undoStore = models.NewPgUndoStore(pgTaskStore.Pool())
+
 		// Initialize user store
 		pgUserStore, err := models.NewPgUserStore(dbConnString)
 		if err != nil {
@@ -66,15 +100,27 @@ func main() {
 		}
 		userStore = pgUserStore
 
-		log.Println("Using PostgreSQL database for task and user storage")
+		log.Println("Using PostgreSQL database for task, project and user storage")
 	} else {
 		// Use in-memory store
 		taskStore = models.NewMemoryTaskStore()
+		projectStore = models.NewMemoryProjectStore()
+		reviewStore = models.NewMemoryReviewStore()
+		staleThresholdStore = models.NewMemoryStaleThresholdStore()
+		reminderStore = models.NewMemoryReminderStore()
+		notificationStore = models.NewMemoryNotificationStore()
+		captureTokenStore = models.NewMemoryCaptureTokenStore()
+		attachmentStore = models.NewMemoryAttachmentStore()
+		calendarTokenStore = models.NewMemoryCalendarTokenStore()
+		caldavTokenStore = models.NewMemoryCalDAVTokenStore()
+		perspectiveStore = models.NewMemoryPerspectiveStore()
+		contextStore = models.NewMemoryContextStore()
+		undoStore = models.NewMemoryUndoStore()
 		userStore = models.NewMemoryUserStore()
 		log.Println("Using in-memory storage (data will be lost when server stops)")
 
 		// Create some sample tasks for testing (only for in-memory store)
-		createSampleTasks(taskStore)
+		createSampleTasks(taskStore, projectStore)
 	}
 
 	// Set up router
@@ -101,20 +147,96 @@ func main() {
 	// Templates directory
 	templatesDir := filepath.Join(workDir, "internal/templates")
 
+	// Recent task changes can be undone for a while
+	undoConfig := config.NewUndoConfigFromEnv()
+	undoStack := models.NewUndoStack(undoStore, undoConfig.Window, undoConfig.Depth)
+
 	// Initialize task handler
-	taskHandler, err := handlers.NewTaskHandler(taskStore, templatesDir)
+	taskHandler, err := handlers.NewTaskHandler(taskStore, projectStore, contextStore, undoStack, templatesDir)
 	if err != nil {
 		log.Fatalf("Failed to create task handler: %v", err)
 	}
 
 	// Initialize project handler
-	projectHandler, err := handlers.NewProjectHandler(taskStore, templatesDir)
+	projectHandler, err := handlers.NewProjectHandler(taskStore, projectStore, contextStore, undoStack, templatesDir)
 	if err != nil {
 		log.Fatalf("Failed to create project handler: %v", err)
 	}
 
+	// Initialize weekly review handler
+	reviewHandler, err := handlers.NewReviewHandler(taskStore, projectStore, reviewStore, staleThresholdStore, templatesDir)
+	if err != nil {
+		log.Fatalf("Failed to create review handler: %v", err)
+	}
+
+	// Initialize insights handler
+	insightsHandler, err := handlers.NewInsightsHandler(taskStore, projectStore, staleThresholdStore, templatesDir)
+	if err != nil {
+		log.Fatalf("Failed to create insights handler: %v", err)
+	}
+
+	// Initialize reminder handler
+	reminderHandler, err := handlers.NewReminderHandler(reminderStore, notificationStore, templatesDir)
+	if err != nil {
+		log.Fatalf("Failed to create reminder handler: %v", err)
+	}
+
+	// Initialize capture handler; capture addresses are only shown when the SMTP listener runs
+	mailCaptureConfig := config.NewMailCaptureConfigFromEnv()
+	captureDomain := ""
+	if mailCaptureConfig.Enabled() {
+		captureDomain = mailCaptureConfig.Domain
+	}
+	captureHandler, err := handlers.NewCaptureHandler(taskStore, captureTokenStore, attachmentStore, captureDomain, templatesDir)
+	if err != nil {
+		log.Fatalf("Failed to create capture handler: %v", err)
+	}
+
+	// Initialize calendar feed and import handler
+	calendarHandler := handlers.NewCalendarHandler(taskStore, calendarTokenStore, contextStore)
+
+	// Initialize CalDAV sync handler
+	caldavHandler := handlers.NewCalDAVHandler(taskStore, caldavTokenStore, contextStore, undoStack)
+
+	// Initialize import handler
+	importHandler, err := handlers.NewImportHandler(taskStore, projectStore, contextStore, templatesDir)
+	if err != nil {
+		log.Fatalf("Failed to create import handler: %v", err)
+	}
+
+	// Initialize backup handler
+	backupHandler, err := handlers.NewBackupHandler(taskStore, projectStore, reviewStore, contextStore, perspectiveStore, staleThresholdStore, reminderStore, templatesDir)
+	if err != nil {
+		log.Fatalf("Failed to create backup handler: %v", err)
+	}
+
+	// Initialize trash handler
+	trashConfig := config.NewTrashConfigFromEnv()
+	trashHandler, err := handlers.NewTrashHandler(taskStore, projectStore, trashConfig.RetentionDays, templatesDir)
+	if err != nil {
+		log.Fatalf("Failed to create trash handler: %v", err)
+	}
+
+	// Initialize undo handler
+	undoHandler := handlers.NewUndoHandler(taskStore, undoStack)
+
+	// Initialize perspective handler
+	perspectiveHandler, err := handlers.NewPerspectiveHandler(taskStore, projectStore, perspectiveStore, templatesDir)
+	if err != nil {
+		log.Fatalf("Failed to create perspective handler: %v", err)
+	}
+
+	// Initialize engage handler
+	engageHandler, err := handlers.NewEngageHandler(taskStore, projectStore, templatesDir)
+	if err != nil {
+		log.Fatalf("Failed to create engage handler: %v", err)
+	}
+
+	// Initialize context handler
+	contextHandler := handlers.NewContextHandler(taskStore, projectStore, contextStore)
+
 	// Initialize index handler
-	indexHandler, err := handlers.NewIndexHandler(taskStore, templatesDir)
+	indexHandler, err := handlers.NewIndexHandler(taskStore, projectStore, staleThresholdStore, templatesDir)
 	if err != nil {
 		log.Fatalf("Failed to create index handler: %v", err)
 	}
@@ -133,6 +255,12 @@ func main() {
 		pages.Register().Render(r.Context(), w)
 	})
 	
+	// Calendar feed (protected by the token in its URL)
+	calendarHandler.RegisterPublicRoutes(r)
+	
+	// CalDAV server (protected by HTTP Basic authentication with CalDAV credentials)
+	caldavHandler.RegisterPublicRoutes(r)
+	
 	// Public API endpoints
 	r.Route("/api", func(r chi.Router) {
 		r.Get("/hello", handlers.HelloHandler)
@@ -146,9 +274,6 @@ func main() {
 		// Home page
 		r.Get("/", indexHandler.HomePage)
 		
-		// Weekly review page
-		r.Get("/weekly-review", indexHandler.WeeklyReviewPage)
-		
 		// Profile page
 		r.Get("/profile", authHandler.ProfilePage)
 		
@@ -158,6 +283,45 @@ func main() {
 		
 		// Register project routes (all project routes require authentication)
 		projectHandler.RegisterRoutes(r)
+		
+		// Register weekly review routes
+		reviewHandler.RegisterRoutes(r)
+		
+		// Register insights routes
+		insightsHandler.RegisterRoutes(r)
+		
+		// Register reminder settings and notification routes
+		reminderHandler.RegisterRoutes(r)
+		
+		// Register capture address and attachment routes
+		captureHandler.RegisterRoutes(r)
+		
+		// Register calendar feed and import routes
+		calendarHandler.RegisterRoutes(r)
+		
+		// Register CalDAV credential routes
+		caldavHandler.RegisterRoutes(r)
+		
+		// Register import routes
+		importHandler.RegisterRoutes(r)
+		
+		// Register export and restore routes
+		backupHandler.RegisterRoutes(r)
+		
+		// Register trash routes
+		trashHandler.RegisterRoutes(r)
+		
+		// Register undo routes
+		undoHandler.RegisterRoutes(r)
+		
+		// Register perspective routes
+		perspectiveHandler.RegisterRoutes(r)
+		
+		// Register engage routes
+		engageHandler.RegisterRoutes(r)
+		
+		// Register context routes
+		contextHandler.RegisterRoutes(r)
 	})
 
 	// Start server
@@ -168,15 +332,105 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 	}
 
+	// Start the reminder scheduler
+	reminderConfig := config.NewReminderConfigFromEnv()
+	notifiers := []reminders.Notifier{
+		reminders.NewInAppNotifier(notificationStore),
+		reminders.NewWebhookNotifier(reminderConfig.WebhookSecret),
+	}
+	if reminderConfig.SMTP.Enabled() {
+		notifiers = append(notifiers, reminders.NewSMTPNotifier(reminderConfig.SMTP))
+	} else {
+		log.Println("SMTP_HOST not set, email reminders are disabled")
+	}
+	scheduler := reminders.NewScheduler(taskStore, reminderStore,
+		reminderConfig.Interval, reminderConfig.Lookback, reminderConfig.MaxAttempts, notifiers...)
+	scheduler.Start()
+
+	// Start the trash retention job if deleted items expire
+	var retention *trash.Retention
+	if trashConfig.Enabled() {
+		retention = trash.NewRetention(taskStore, projectStore, trashConfig.Retention(), trashConfig.PurgeInterval)
+		retention.Start()
+	} else {
+		log.Println("TRASH_RETENTION_DAYS is 0, deleted items are kept forever")
+	}
+
+	// Start the email capture listener if configured
+	var mailServer *mailcapture.Server
+	if mailCaptureConfig.Enabled() {
+		capturer := mailcapture.NewCapturer(taskStore, captureTokenStore, attachmentStore, contextStore, mailCaptureConfig.Domain)
+		mailServer = mailcapture.NewServer(mailCaptureConfig.Addr, mailCaptureConfig.Domain, mailCaptureConfig.MaxMessageBytes, capturer)
+		go func() {
+			if err := mailServer.ListenAndServe(); err != nil && err != mailcapture.ErrServerClosed {
+				log.Printf("Mail capture server: %v", err)
+			}
+		}()
+		log.Printf("Mail capture listening on %s for inbox+<token>@%s", mailCaptureConfig.Addr, mailCaptureConfig.Domain)
+	}
+
 	fmt.Printf("Server starting on port %s...\n", port)
 	fmt.Printf("Visit http://localhost:%s to get started\n", port)
 	fmt.Printf("Task list available at http://localhost:%s/tasks\n", port)
 	fmt.Printf("Inbox available at http://localhost:%s/tasks?status=inbox\n", port)
-	log.Fatal(server.ListenAndServe())
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// Wait for an interrupt, then stop accepting requests and let the scheduler finish its run
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	var listenErr error
+	select {
+	case listenErr = <-serverErr:
+	case sig := <-stop:
+		log.Printf("Received %s, shutting down", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown: %v", err)
+	}
+	if err := scheduler.Shutdown(ctx); err != nil {
+		log.Printf("Reminder scheduler shutdown: %v", err)
+	}
+	if retention != nil {
+		if err := retention.Shutdown(ctx); err != nil {
+			log.Printf("Trash retention shutdown: %v", err)
+		}
+	}
+	if mailServer != nil {
+		if err := mailServer.Shutdown(ctx); err != nil {
+			log.Printf("Mail capture shutdown: %v", err)
+		}
+	}
+
+	if listenErr != nil && listenErr != http.ErrServerClosed {
+		log.Fatal(listenErr)
+	}
+}
+
+// databaseConnString returns the PostgreSQL connection string from the environment
+func databaseConnString() string {
+	dbConfig := config.NewDatabaseConfigFromEnv()
+	dbConnString := os.Getenv("DATABASE_URL")
+
+	// Allow override via DATABASE_URL if set
+	if dbConnString == "" {
+		dbConnString = dbConfig.ConnectionString()
+	}
+
+	log.Printf("Connecting to PostgreSQL database: %s/%s", dbConfig.Host, dbConfig.DBName)
+	return dbConnString
 }
 
-// createSampleTasks creates a few sample tasks for testing
-func createSampleTasks(store models.TaskStore) {
+// createSampleTasks creates a few sample tasks and a project for testing
+func createSampleTasks(store models.TaskStore, projects models.ProjectStore) {
 	// Create a sample user ID for demonstration purposes
 	sampleUserID := "sample-user-123"
 	
@@ -200,9 +454,9 @@ func createSampleTasks(store models.TaskStore) {
 	store.Save(task4)
 
 	// Project
-	task5 := models.NewTask("Redesign personal website", "Project to update and refresh my personal website", sampleUserID)
-	task5.MarkAsProject()
-	store.Save(task5)
+	project := models.NewProject("Redesign personal website", "Project to update and refresh my personal website", sampleUserID)
+	project.Outcome = "A refreshed personal website is live"
+	projects.Save(project)
 }
 
 // fileServer sets up a http.FileServer handler to serve
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/melihkorkmaz/gtd/internal/backup"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// runRestoreCommand handles `server restore [-user ID] [-dry-run] archive`, which replays an
+// export into PostgreSQL when USE_POSTGRES=true. Without a database it restores into in-memory
+// stores that are discarded on exit, which only checks the archive.
+func runRestoreCommand(args []string) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	userID := flags.String("user", "", "restore into this user instead of the archive's owner")
+	dryRun := flags.Bool("dry-run", false, "check the archive without writing anything")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: server restore [-user ID] [-dry-run] archive")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to read archive: %v", err)
+	}
+	archive, err := backup.Read(data)
+	if err != nil {
+		log.Fatalf("Failed to read archive: %v", err)
+	}
+
+	owner := archive.UserID
+	if *userID != "" {
+		owner = *userID
+	}
+	if owner == "" {
+		log.Fatal("The archive has no user ID; pass one with -user")
+	}
+
+	var stores backup.Stores
+	if os.Getenv("USE_POSTGRES") == "true" {
+		pgTaskStore, err := models.NewPgTaskStore(databaseConnString())
+		if err != nil {
+			log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+		}
+		defer pgTaskStore.Close()
+
+		stores = backup.Stores{
+			Tasks:           pgTaskStore,
+			Projects:        models.NewPgProjectStore(pgTaskStore.Pool()),
+			Reviews:         models.NewPgReviewStore(pgTaskStore.Pool()),
+			Contexts:        models.NewPgContextStore(pgTaskStore.Pool()),
+			Perspectives:    models.NewPgPerspectiveStore(pgTaskStore.Pool()),
+			StaleThresholds: models.NewPgStaleThresholdStore(pgTaskStore.Pool()),
+			Reminders:       models.NewPgReminderStore(pgTaskStore.Pool()),
+		}
+	} else {
+		log.Println("USE_POSTGRES is not set; restoring into in-memory stores, which are discarded on exit")
+		stores = backup.Stores{
+			Tasks:           models.NewMemoryTaskStore(),
+			Projects:        models.NewMemoryProjectStore(),
+			Reviews:         models.NewMemoryReviewStore(),
+			Contexts:        models.NewMemoryContextStore(),
+			Perspectives:    models.NewMemoryPerspectiveStore(),
+			StaleThresholds: models.NewMemoryStaleThresholdStore(),
+			Reminders:       models.NewMemoryReminderStore(),
+		}
+	}
+
+	report, err := backup.Restore(context.Background(), stores, archive, owner, *dryRun)
+	if err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+
+	verb := "Restored"
+	if report.DryRun {
+		verb = "Would restore"
+	}
+	fmt.Printf("%s %d tasks, %d projects, %d review sessions, %d contexts, %d perspectives and %d settings for user %s\n",
+		verb, report.Tasks, report.Projects, report.Reviews, report.Contexts, report.Perspectives, report.Settings, owner)
+	for _, message := range report.Errors {
+		fmt.Printf("Skipped %s\n", message)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,215 @@
+// Package backup exports everything a user keeps in the app into a versioned archive and
+// restores such an archive into any set of stores. An archive is a zip file holding the data
+// as JSON next to a Markdown outline for people to read.
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// FormatVersion is the version of the archive format written by Export
+const FormatVersion = 1
+
+// Files inside an archive
+const (
+	DataFile    = "gtd.json"
+	OutlineFile = "outline.md"
+)
+
+// MaxDataSize caps the uncompressed size of an archive's data file, so a small upload can't
+// expand into more than the server is willing to hold in memory
+const MaxDataSize = 200 << 20
+
+// ErrInvalidArchive is returned when data is neither an archive nor its JSON file
+var ErrInvalidArchive = errors.New("not a GTD export")
+
+// Settings are the user's preferences
+type Settings struct {
+	StaleThresholds *models.StaleThresholds  `json:"staleThresholds,omitempty"`
+	Reminders       *models.ReminderSettings `json:"reminders,omitempty"`
+}
+
+// Archive is a complete export of one user's data
+type Archive struct {
+	Version      int                         `json:"version"`
+	ExportedAt   time.Time                   `json:"exportedAt"`
+	UserID       string                      `json:"userId"`
+	Tasks        []*models.Task              `json:"tasks"` // Includes soft-deleted tasks
+	Projects     []*models.Project           `json:"projects"`
+	Reviews      []*models.ReviewSession     `json:"reviews"`
+	Contexts     []*models.ContextDefinition `json:"contexts"`
+	Perspectives []*models.Perspective       `json:"perspectives"`
+	Settings     Settings                    `json:"settings"`
+}
+
+// Stores are the stores an archive is exported from or restored into.
+// Data of a nil store is left out of exports and skipped on restore.
+type Stores struct {
+	Tasks           models.TaskStore
+	Projects        models.ProjectStore
+	Reviews         models.ReviewStore
+	Contexts        models.ContextStore
+	Perspectives    models.PerspectiveStore
+	StaleThresholds models.StaleThresholdStore
+	Reminders       models.ReminderStore
+}
+
+// Export collects all of a user's data, including soft-deleted tasks and projects
+func Export(ctx context.Context, stores Stores, userID string, now time.Time) (*Archive, error) {
+	archive := &Archive{
+		Version:      FormatVersion,
+		ExportedAt:   now,
+		UserID:       userID,
+		Tasks:        []*models.Task{},
+		Projects:     []*models.Project{},
+		Reviews:      []*models.ReviewSession{},
+		Contexts:     []*models.ContextDefinition{},
+		Perspectives: []*models.Perspective{},
+	}
+
+	if stores.Tasks != nil {
+		tasks, err := models.QueryAll(ctx, stores.Tasks, models.TaskQuery{
+			Filter: models.TaskFilter{UserID: userID, IncludeDeleted: true},
+			Sort:   models.SortCreatedAsc,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("exporting tasks: %w", err)
+		}
+		if tasks != nil {
+			archive.Tasks = tasks
+		}
+	}
+
+	if stores.Projects != nil {
+		projects, err := stores.Projects.List(ctx, models.ProjectFilter{UserID: userID, IncludeDeleted: true}, models.ProjectSortCreatedAsc)
+		if err != nil {
+			return nil, fmt.Errorf("exporting projects: %w", err)
+		}
+		archive.Projects = projects
+	}
+
+	if stores.Reviews != nil {
+		reviews, err := stores.Reviews.ListByUser(ctx, userID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("exporting review sessions: %w", err)
+		}
+		archive.Reviews = reviews
+	}
+
+	if stores.Contexts != nil {
+		contexts, err := stores.Contexts.List(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("exporting contexts: %w", err)
+		}
+		archive.Contexts = contexts
+	}
+
+	if stores.Perspectives != nil {
+		perspectives, err := stores.Perspectives.List(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("exporting perspectives: %w", err)
+		}
+		archive.Perspectives = perspectives
+	}
+
+	if stores.StaleThresholds != nil {
+		thresholds, err := stores.StaleThresholds.Get(userID)
+		if err != nil {
+			return nil, fmt.Errorf("exporting stale thresholds: %w", err)
+		}
+		archive.Settings.StaleThresholds = &thresholds
+	}
+
+	if stores.Reminders != nil {
+		settings, err := stores.Reminders.GetSettings(userID)
+		if err != nil {
+			return nil, fmt.Errorf("exporting reminder settings: %w", err)
+		}
+		archive.Settings.Reminders = &settings
+	}
+
+	return archive, nil
+}
+
+// WriteJSON writes the archive's data file
+func (a *Archive) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(a)
+}
+
+// Write writes the archive as a zip file holding the data file and the outline
+func (a *Archive) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	header := &zip.FileHeader{Name: DataFile, Method: zip.Deflate, Modified: a.ExportedAt}
+	data, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	if err := a.WriteJSON(data); err != nil {
+		return err
+	}
+
+	header = &zip.FileHeader{Name: OutlineFile, Method: zip.Deflate, Modified: a.ExportedAt}
+	outline, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(outline, Outline(a)); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// Read decodes an archive written by Write, or just its data file
+func Read(data []byte) (*Archive, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		file, err := zr.Open(DataFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: archive has no %s", ErrInvalidArchive, DataFile)
+		}
+		defer file.Close()
+
+		// The size in the header can't be trusted, so the read is capped as well
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > MaxDataSize {
+			return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalidArchive, DataFile, MaxDataSize)
+		}
+		if data, err = io.ReadAll(io.LimitReader(file, MaxDataSize+1)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if len(data) > MaxDataSize {
+			return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalidArchive, DataFile, MaxDataSize)
+		}
+	}
+
+	var archive Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if archive.Version < 1 {
+		return nil, fmt.Errorf("%w: missing format version", ErrInvalidArchive)
+	}
+	if archive.Version > FormatVersion {
+		return nil, fmt.Errorf("%w: format version %d is newer than the supported version %d", ErrInvalidArchive, archive.Version, FormatVersion)
+	}
+	return &archive, nil
+}
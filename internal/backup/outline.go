@@ -0,0 +1,196 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// outlineLists are the lists of the outline, in order, with their headings
+var outlineLists = []struct {
+	status  models.TaskStatus
+	heading string
+}{
+	{models.StatusInbox, "Inbox"},
+	{models.StatusNext, "Next Actions"},
+	{models.StatusWaiting, "Waiting For"},
+	{models.StatusScheduled, "Scheduled"},
+	{models.StatusSomeday, "Someday/Maybe"},
+	{models.StatusReference, "Reference"},
+	{models.StatusProject, "Project Tasks"},
+	{models.StatusDone, "Completed"},
+}
+
+// projectStateLabels are the display names of project states
+var projectStateLabels = map[models.ProjectState]string{
+	models.ProjectActive:    "Active",
+	models.ProjectOnHold:    "On hold",
+	models.ProjectCompleted: "Completed",
+	models.ProjectArchived:  "Archived",
+	models.ProjectDropped:   "Dropped",
+}
+
+// Outline renders the archive as a Markdown outline: projects with their tasks, the remaining
+// tasks by list, deleted items, the weekly review history, and the user's contexts and perspectives
+func Outline(a *Archive) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# GTD Export\n\nExported %s (format version %d)\n", a.ExportedAt.Format("2006-01-02 15:04 MST"), a.Version)
+
+	projects := make(map[string]bool)
+	for _, project := range a.Projects {
+		if !project.IsDeleted() {
+			projects[project.ID] = true
+		}
+	}
+
+	// Tasks of live projects are listed under the project, the others under their list
+	byProject := make(map[string][]*models.Task)
+	byStatus := make(map[models.TaskStatus][]*models.Task)
+	var deletedTasks []*models.Task
+	for _, task := range a.Tasks {
+		switch {
+		case task.IsDeleted():
+			deletedTasks = append(deletedTasks, task)
+		case projects[task.ProjectID]:
+			byProject[task.ProjectID] = append(byProject[task.ProjectID], task)
+		default:
+			byStatus[task.Status] = append(byStatus[task.Status], task)
+		}
+	}
+
+	var deletedProjects []*models.Project
+	if len(projects) > 0 {
+		b.WriteString("\n## Projects\n")
+	}
+	for _, project := range a.Projects {
+		if project.IsDeleted() {
+			deletedProjects = append(deletedProjects, project)
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", project.Title)
+		details := []string{projectStateLabels[project.State]}
+		if project.DueDate != nil {
+			details = append(details, "due "+project.DueDate.Format("2006-01-02"))
+		}
+		fmt.Fprintf(&b, "*%s*\n", strings.Join(details, ", "))
+		if project.Outcome != "" {
+			fmt.Fprintf(&b, "\nOutcome: %s\n", project.Outcome)
+		}
+		if project.Description != "" {
+			fmt.Fprintf(&b, "\n%s\n", project.Description)
+		}
+		if tasks := byProject[project.ID]; len(tasks) > 0 {
+			b.WriteString("\n")
+			for _, task := range tasks {
+				writeOutlineTask(&b, task)
+			}
+		}
+	}
+
+	for _, list := range outlineLists {
+		tasks := byStatus[list.status]
+		if len(tasks) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", list.heading)
+		for _, task := range tasks {
+			writeOutlineTask(&b, task)
+		}
+	}
+
+	if len(deletedProjects) > 0 || len(deletedTasks) > 0 {
+		b.WriteString("\n## Deleted\n\n")
+		for _, project := range deletedProjects {
+			fmt.Fprintf(&b, "- Project: %s (deleted %s)\n", project.Title, project.DeletedAt.Format("2006-01-02"))
+		}
+		for _, task := range deletedTasks {
+			fmt.Fprintf(&b, "- %s (deleted %s)\n", task.Title, task.DeletedAt.Format("2006-01-02"))
+		}
+	}
+
+	if len(a.Reviews) > 0 {
+		b.WriteString("\n## Weekly Reviews\n\n")
+		for _, session := range a.Reviews {
+			state := "in progress"
+			if session.IsFinished() {
+				state = "finished " + session.CompletedAt.Format("2006-01-02")
+			}
+			fmt.Fprintf(&b, "- %s: %s, %d%% of steps done\n", session.StartedAt.Format("2006-01-02"), state, session.Progress())
+			if notes := strings.TrimSpace(session.Notes); notes != "" {
+				writeOutlineNotes(&b, notes)
+			}
+		}
+	}
+
+	if len(a.Contexts) > 0 {
+		b.WriteString("\n## Contexts\n\n")
+		for _, def := range a.Contexts {
+			fmt.Fprintf(&b, "- @%s", def.Name)
+			if def.Location != "" {
+				fmt.Fprintf(&b, " (%s)", def.Location)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(a.Perspectives) > 0 {
+		b.WriteString("\n## Perspectives\n\n")
+		for _, perspective := range a.Perspectives {
+			fmt.Fprintf(&b, "- %s: `%s`\n", perspective.Name, perspective.Query)
+		}
+	}
+
+	return b.String()
+}
+
+// writeOutlineTask writes a task as a checklist item followed by its description
+func writeOutlineTask(b *strings.Builder, task *models.Task) {
+	check := " "
+	if task.Status == models.StatusDone {
+		check = "x"
+	}
+
+	var details []string
+	if task.DueDate != nil {
+		details = append(details, "due "+task.DueDate.Format("2006-01-02"))
+	}
+	if task.ScheduledDate != nil {
+		details = append(details, "scheduled "+task.ScheduledDate.Format("2006-01-02 15:04"))
+	}
+	if task.CompletedAt != nil {
+		details = append(details, "completed "+task.CompletedAt.Format("2006-01-02"))
+	}
+	if task.Priority > 0 {
+		details = append(details, fmt.Sprintf("priority %d", task.Priority))
+	}
+	if task.TimeEstimate > 0 {
+		details = append(details, fmt.Sprintf("%d min", task.TimeEstimate))
+	}
+	if task.RecurringRule != "" {
+		details = append(details, "repeats "+task.RecurringRule)
+	}
+	for _, context := range task.Contexts {
+		details = append(details, "@"+string(context))
+	}
+	for _, tag := range task.Tags {
+		details = append(details, "#"+tag)
+	}
+
+	fmt.Fprintf(b, "- [%s] %s", check, task.Title)
+	if len(details) > 0 {
+		fmt.Fprintf(b, " (%s)", strings.Join(details, ", "))
+	}
+	b.WriteString("\n")
+
+	if description := strings.TrimSpace(task.Description); description != "" {
+		writeOutlineNotes(b, description)
+	}
+}
+
+// writeOutlineNotes indents text under the list item above it
+func writeOutlineNotes(b *strings.Builder, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(b, "  %s\n", strings.TrimRight(line, " \t\r"))
+	}
+}
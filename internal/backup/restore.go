@@ -0,0 +1,218 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// errOtherOwner is reported for records whose ID is already used by another user
+var errOtherOwner = errors.New("ID belongs to another user")
+
+// RestoreReport summarizes what a restore wrote, or would write on a dry run
+type RestoreReport struct {
+	DryRun       bool     `json:"dryRun"`
+	Tasks        int      `json:"tasks"`
+	Projects     int      `json:"projects"`
+	Reviews      int      `json:"reviews"`
+	Contexts     int      `json:"contexts"`
+	Perspectives int      `json:"perspectives"`
+	Settings     int      `json:"settings"`
+	Failed       int      `json:"failed"`
+	Errors       []string `json:"errors,omitempty"`
+}
+
+// fail records a record that could not be restored
+func (r *RestoreReport) fail(kind, id string, err error) {
+	r.Failed++
+	r.Errors = append(r.Errors, fmt.Sprintf("%s %s: %v", kind, id, err))
+}
+
+// Restore replays an archive into the stores on behalf of a user. Records keep their IDs, so
+// restoring the same archive again overwrites rather than duplicates, and every record is handed
+// to userID, which lets an archive move between accounts. Records that are invalid or whose ID
+// belongs to another user are reported and skipped; other store errors abort the restore.
+// With dryRun the archive is only checked and nothing is written.
+func Restore(ctx context.Context, stores Stores, archive *Archive, userID string, dryRun bool) (*RestoreReport, error) {
+	report := &RestoreReport{DryRun: dryRun}
+
+	// Contexts go first so tasks never use a context the user hasn't set up yet
+	if stores.Contexts != nil {
+		for _, original := range archive.Contexts {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			def := *original
+			def.UserID = userID
+			if def.ID == "" {
+				report.fail("context", fmt.Sprintf("%q", def.Name), errors.New("missing ID"))
+				continue
+			}
+			if err := def.Validate(); err != nil {
+				report.fail("context", def.ID, err)
+				continue
+			}
+			if !dryRun {
+				err := stores.Contexts.SaveForUser(&def, userID)
+				if errors.Is(err, models.ErrContextNotFound) {
+					report.fail("context", def.ID, errOtherOwner)
+					continue
+				}
+				if errors.Is(err, models.ErrContextExists) {
+					report.fail("context", def.ID, err)
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("restoring context %s: %w", def.ID, err)
+				}
+			}
+			report.Contexts++
+		}
+	}
+
+	// Projects go before tasks so tasks never point at a project that isn't there yet
+	if stores.Projects != nil {
+		for _, original := range archive.Projects {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			project := *original
+			project.UserID = userID
+			if project.ID == "" {
+				report.fail("project", fmt.Sprintf("%q", project.Title), errors.New("missing ID"))
+				continue
+			}
+			if err := project.Validate(); err != nil {
+				report.fail("project", project.ID, err)
+				continue
+			}
+			if !dryRun {
+				err := stores.Projects.SaveForUser(&project, userID)
+				if errors.Is(err, models.ErrProjectNotFound) {
+					report.fail("project", project.ID, errOtherOwner)
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("restoring project %s: %w", project.ID, err)
+				}
+			}
+			report.Projects++
+		}
+	}
+
+	if stores.Tasks != nil {
+		for _, original := range archive.Tasks {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			task := *original
+			task.UserID = userID
+			if task.ID == "" {
+				report.fail("task", fmt.Sprintf("%q", task.Title), errors.New("missing ID"))
+				continue
+			}
+			if err := task.Validate(); err != nil {
+				report.fail("task", task.ID, err)
+				continue
+			}
+			if !dryRun {
+				// The archived copy replaces whatever is stored
+				err := stores.Tasks.ReplaceForUser(&task, userID)
+				if errors.Is(err, models.ErrTaskNotFound) {
+					report.fail("task", task.ID, errOtherOwner)
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("restoring task %s: %w", task.ID, err)
+				}
+			}
+			report.Tasks++
+		}
+	}
+
+	if stores.Reviews != nil {
+		for _, original := range archive.Reviews {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			session := *original
+			session.UserID = userID
+			if session.ID == "" {
+				report.fail("review session", session.StartedAt.Format("2006-01-02"), errors.New("missing ID"))
+				continue
+			}
+			if !dryRun {
+				err := stores.Reviews.Save(&session)
+				if errors.Is(err, models.ErrReviewNotFound) {
+					report.fail("review session", session.ID, errOtherOwner)
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("restoring review session %s: %w", session.ID, err)
+				}
+			}
+			report.Reviews++
+		}
+	}
+
+	if stores.Perspectives != nil {
+		for _, original := range archive.Perspectives {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			perspective := *original
+			perspective.UserID = userID
+			if perspective.ID == "" {
+				report.fail("perspective", fmt.Sprintf("%q", perspective.Name), errors.New("missing ID"))
+				continue
+			}
+			if err := perspective.Validate(); err != nil {
+				report.fail("perspective", perspective.ID, err)
+				continue
+			}
+			if !dryRun {
+				err := stores.Perspectives.SaveForUser(&perspective, userID)
+				if errors.Is(err, models.ErrPerspectiveNotFound) {
+					report.fail("perspective", perspective.ID, errOtherOwner)
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("restoring perspective %s: %w", perspective.ID, err)
+				}
+			}
+			report.Perspectives++
+		}
+	}
+
+	if thresholds := archive.Settings.StaleThresholds; thresholds != nil && stores.StaleThresholds != nil {
+		if err := thresholds.Validate(); err != nil {
+			report.fail("setting", "staleThresholds", err)
+		} else {
+			if !dryRun {
+				if err := stores.StaleThresholds.Save(userID, *thresholds); err != nil {
+					return nil, fmt.Errorf("restoring stale thresholds: %w", err)
+				}
+			}
+			report.Settings++
+		}
+	}
+
+	if reminders := archive.Settings.Reminders; reminders != nil && stores.Reminders != nil {
+		settings := *reminders
+		settings.UserID = userID
+		if err := settings.Validate(); err != nil {
+			report.fail("setting", "reminders", err)
+		} else {
+			if !dryRun {
+				if err := stores.Reminders.SaveSettings(settings); err != nil {
+					return nil, fmt.Errorf("restoring reminder settings: %w", err)
+				}
+			}
+			report.Settings++
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,22 @@
+package config
+
+// MailCaptureConfig represents the configuration of the email-to-inbox SMTP listener
+type MailCaptureConfig struct {
+	Addr            string // Listen address such as ":2525"; capture is disabled when empty
+	Domain          string // Domain of the inbox+<token>@domain capture addresses
+	MaxMessageBytes int64
+}
+
+// NewMailCaptureConfigFromEnv creates a new MailCaptureConfig from environment variables
+func NewMailCaptureConfigFromEnv() MailCaptureConfig {
+	return MailCaptureConfig{
+		Addr:            getEnvOrDefault("MAIL_CAPTURE_ADDR", ""),
+		Domain:          getEnvOrDefault("MAIL_CAPTURE_DOMAIN", "localhost"),
+		MaxMessageBytes: int64(parsePositiveIntEnv("MAIL_CAPTURE_MAX_BYTES", 10<<20)),
+	}
+}
+
+// Enabled reports whether the SMTP listener should run
+func (c MailCaptureConfig) Enabled() bool {
+	return c.Addr != ""
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"log"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig represents the configuration of the SMTP server used for email reminders
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Enabled reports whether an SMTP server has been configured
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+// Addr returns the host:port address of the SMTP server
+func (c SMTPConfig) Addr() string {
+	return c.Host + ":" + c.Port
+}
+
+// ReminderConfig represents the configuration of the reminder scheduler
+type ReminderConfig struct {
+	Interval      time.Duration // How often the scheduler looks for due reminders
+	Lookback      time.Duration // How late a reminder may still be sent, e.g. after a restart
+	MaxAttempts   int           // Delivery attempts before a reminder is given up
+	WebhookSecret string        // Signs webhook payloads when set
+	SMTP          SMTPConfig
+}
+
+// NewReminderConfigFromEnv creates a new ReminderConfig from environment variables
+func NewReminderConfigFromEnv() ReminderConfig {
+	return ReminderConfig{
+		Interval:      parseDurationEnv("REMINDER_INTERVAL", time.Minute),
+		Lookback:      parseDurationEnv("REMINDER_LOOKBACK", 24*time.Hour),
+		MaxAttempts:   parsePositiveIntEnv("REMINDER_MAX_ATTEMPTS", 5),
+		WebhookSecret: getEnvOrDefault("REMINDER_WEBHOOK_SECRET", ""),
+		SMTP: SMTPConfig{
+			Host:     getEnvOrDefault("SMTP_HOST", ""),
+			Port:     getEnvOrDefault("SMTP_PORT", "587"),
+			Username: getEnvOrDefault("SMTP_USERNAME", ""),
+			Password: getEnvOrDefault("SMTP_PASSWORD", ""),
+			From:     getEnvOrDefault("SMTP_FROM", "gtd@localhost"),
+		},
+	}
+}
+
+// parseDurationEnv parses a duration such as "30s" from the environment, or returns a default value
+func parseDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := getEnvOrDefault(key, "")
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+// parsePositiveIntEnv parses a positive integer from the environment, or returns a default value
+func parsePositiveIntEnv(key string, defaultValue int) int {
+	value := getEnvOrDefault(key, "")
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid %s %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"log"
+	"strconv"
+	"time"
+)
+
+// TrashConfig represents the retention policy for deleted tasks and projects
+type TrashConfig struct {
+	RetentionDays int           // Deleted items are purged after this many days; 0 keeps them forever
+	PurgeInterval time.Duration // How often expired items are purged
+}
+
+// NewTrashConfigFromEnv creates a new TrashConfig from environment variables
+func NewTrashConfigFromEnv() TrashConfig {
+	return TrashConfig{
+		RetentionDays: parseNonNegativeIntEnv("TRASH_RETENTION_DAYS", 30),
+		PurgeInterval: parseDurationEnv("TRASH_PURGE_INTERVAL", time.Hour),
+	}
+}
+
+// Enabled reports whether deleted items should be purged at all
+func (c TrashConfig) Enabled() bool {
+	return c.RetentionDays > 0
+}
+
+// Retention returns how long deleted items are kept
+func (c TrashConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// parseNonNegativeIntEnv parses a non-negative integer from the environment, or returns a default value
+func parseNonNegativeIntEnv(key string, defaultValue int) int {
+	value := getEnvOrDefault(key, "")
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("Invalid %s %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
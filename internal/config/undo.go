@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// UndoConfig represents how long and how many user actions can be undone
+type UndoConfig struct {
+	Window time.Duration // How long an action can be undone after it was made
+	Depth  int           // How many recent actions are kept per user
+}
+
+// NewUndoConfigFromEnv creates a new UndoConfig from environment variables
+func NewUndoConfigFromEnv() UndoConfig {
+	return UndoConfig{
+		Window: parseDurationEnv("UNDO_WINDOW", 10*time.Minute),
+		Depth:  parsePositiveIntEnv("UNDO_DEPTH", 20),
+	}
+}
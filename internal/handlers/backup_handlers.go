@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/backup"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// maxRestoreBytes limits the size of an uploaded archive
+const maxRestoreBytes = 50 << 20
+
+// BackupHandler exports a user's data and restores it from an export
+type BackupHandler struct {
+	stores backup.Stores
+}
+
+// NewBackupHandler creates a new backup handler
+func NewBackupHandler(store models.TaskStore, projects models.ProjectStore, reviews models.ReviewStore, contexts models.ContextStore, perspectives models.PerspectiveStore, thresholds models.StaleThresholdStore, reminders models.ReminderStore, templatesDir string) (*BackupHandler, error) {
+	return &BackupHandler{
+		stores: backup.Stores{
+			Tasks:           store,
+			Projects:        projects,
+			Reviews:         reviews,
+			Contexts:        contexts,
+			Perspectives:    perspectives,
+			StaleThresholds: thresholds,
+			Reminders:       reminders,
+		},
+	}, nil
+}
+
+// RegisterRoutes registers the export and restore routes
+func (h *BackupHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/export", h.ExportAPI)
+	r.Post("/api/restore", h.RestoreAPI)
+}
+
+// ExportAPI downloads all of the user's data. The default is a zip archive with the data as JSON
+// and a Markdown outline; format=json or format=markdown returns just one of them.
+func (h *BackupHandler) ExportAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "zip" && format != "json" && format != "markdown" {
+		http.Error(w, "Invalid format: expected zip, json or markdown", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	archive, err := backup.Export(r.Context(), h.stores, user.ID, now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := "gtd-export-" + now.Format("20060102")
+	switch format {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".json"))
+		archive.WriteJSON(w)
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".md"))
+		io.WriteString(w, backup.Outline(archive))
+	default:
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+		archive.Write(w)
+	}
+}
+
+// RestoreAPI replays an export into the current user's account and returns a report.
+// The archive, or its JSON file, is sent as the "file" field of a multipart form or as the raw
+// request body; dryRun=true checks it without writing anything.
+func (h *BackupHandler) RestoreAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRestoreBytes)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Missing archive: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, "Could not read archive: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	archive, err := backup.Read(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := backup.Restore(r.Context(), h.stores, archive, user.ID, r.FormValue("dryRun") == "true")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/ical"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// caldavRoot is where the CalDAV server is mounted. It is both the user's principal
+// and their calendar home; each GTD list below it is a VTODO collection.
+const caldavRoot = "/caldav/"
+
+// maxCalDAVBodyBytes limits request bodies of CalDAV clients
+const maxCalDAVBodyBytes = 1 << 20
+
+// caldavList is a GTD list exposed as a calendar collection
+type caldavList struct {
+	Name        string // Path segment
+	DisplayName string
+	Status      models.TaskStatus
+}
+
+// caldavLists are the lists clients can sync, in the order they are listed
+var caldavLists = []caldavList{
+	{Name: "next", DisplayName: "Next Actions", Status: models.StatusNext},
+	{Name: "waiting", DisplayName: "Waiting For", Status: models.StatusWaiting},
+	{Name: "scheduled", DisplayName: "Scheduled", Status: models.StatusScheduled},
+}
+
+// findCalDAVList returns the list with the given path segment
+func findCalDAVList(name string) (caldavList, bool) {
+	for _, list := range caldavLists {
+		if list.Name == name {
+			return list, true
+		}
+	}
+	return caldavList{}, false
+}
+
+// caldavResourceName matches task resource names; clients choose them for new tasks
+// and they become the task ID, so they are restricted to URL- and ID-safe characters
+var caldavResourceName = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_.-]{0,127})\.ics$`)
+
+// caldavUserKey is the context key of the user ID authenticated by caldavAuth
+type caldavUserKey struct{}
+
+// CalDAVHandler serves the next, waiting and scheduled lists to CalDAV (RFC 4791) clients
+// as VTODO collections, so tasks can be edited in native reminder and calendar apps
+type CalDAVHandler struct {
+	store    models.TaskStore
+	tokens   models.CalDAVTokenStore
+	contexts models.ContextStore
+	undo     *models.UndoStack
+}
+
+// NewCalDAVHandler creates a new CalDAV handler
+func NewCalDAVHandler(store models.TaskStore, tokens models.CalDAVTokenStore, contexts models.ContextStore, undo *models.UndoStack) *CalDAVHandler {
+	return &CalDAVHandler{
+		store:    store,
+		tokens:   tokens,
+		contexts: contexts,
+		undo:     undo,
+	}
+}
+
+// RegisterPublicRoutes registers the CalDAV server. Clients authenticate with HTTP Basic
+// authentication and their CalDAV password rather than with a session.
+func (h *CalDAVHandler) RegisterPublicRoutes(r chi.Router) {
+	for _, method := range []string{"PROPFIND", "REPORT"} {
+		chi.RegisterMethod(method)
+	}
+
+	// Service discovery (RFC 6764)
+	r.HandleFunc("/.well-known/caldav", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, caldavRoot, http.StatusMovedPermanently)
+	})
+
+	r.Route("/caldav", func(r chi.Router) {
+		r.Use(h.caldavAuth)
+
+		for _, pattern := range []string{"/", "/{list}", "/{list}/"} {
+			r.Options(pattern, h.Options)
+			r.MethodFunc("PROPFIND", pattern, h.Propfind)
+			r.MethodFunc("REPORT", pattern, h.Report)
+		}
+
+		r.Options("/{list}/{name}", h.Options)
+		r.MethodFunc("PROPFIND", "/{list}/{name}", h.Propfind)
+		r.Get("/{list}/{name}", h.GetTodo)
+		r.Head("/{list}/{name}", h.GetTodo)
+		r.Put("/{list}/{name}", h.PutTodo)
+		r.Delete("/{list}/{name}", h.DeleteTodo)
+	})
+}
+
+// RegisterRoutes registers the routes for managing CalDAV credentials
+func (h *CalDAVHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/caldav/credentials", func(r chi.Router) {
+		r.Get("/", h.GetCredentialsAPI)
+		r.Post("/regenerate", h.RegenerateCredentialsAPI)
+	})
+}
+
+// caldavAuth authenticates CalDAV requests with HTTP Basic authentication.
+// The user name is the user's ID and the password their CalDAV token.
+func (h *CalDAVHandler) caldavAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if ok {
+			userID, err := h.tokens.GetUserID(password)
+			if err == nil && userID == username {
+				ctx := context.WithValue(r.Context(), caldavUserKey{}, userID)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			if err != nil && err != models.ErrCalDAVTokenNotFound {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("WWW-Authenticate", `Basic realm="GTD CalDAV", charset="UTF-8"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// caldavUserID returns the user authenticated by caldavAuth
+func caldavUserID(r *http.Request) string {
+	userID, _ := r.Context().Value(caldavUserKey{}).(string)
+	return userID
+}
+
+// listCTag changes whenever a task in the list is added, changed or removed
+func listCTag(tasks []*models.Task) string {
+	hash := fnv.New64a()
+	for _, task := range tasks {
+		hash.Write([]byte(task.ID + taskETag(task)))
+	}
+	return fmt.Sprintf(`"%x-%d"`, hash.Sum64(), len(tasks))
+}
+
+// listTasks returns the tasks of a list
+func (h *CalDAVHandler) listTasks(list caldavList, userID string) ([]*models.Task, error) {
+	return h.store.GetByStatusAndUserID(list.Status, userID)
+}
+
+// taskHref returns the URL path of a task resource
+func taskHref(list caldavList, task *models.Task) string {
+	return caldavRoot + list.Name + "/" + task.ID + ".ics"
+}
+
+// todoCalendar wraps a task in a VCALENDAR holding a single VTODO
+func todoCalendar(task *models.Task) []byte {
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", "-//GTD App//Tasks//EN")
+	cal.Components = append(cal.Components, ical.Todo(task, task.ID, time.Now()))
+
+	var buf bytes.Buffer
+	ical.Encode(&buf, cal)
+	return buf.Bytes()
+}
+
+// Options advertises CalDAV support
+func (h *CalDAVHandler) Options(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+	w.WriteHeader(http.StatusOK)
+}
+
+// loadCalDAVTask resolves the list and task of a resource URL. A missing task is returned
+// as nil without an error, as PUT creates it; on other failures the response is written.
+func (h *CalDAVHandler) loadCalDAVTask(w http.ResponseWriter, r *http.Request) (caldavList, string, *models.Task, bool) {
+	list, ok := findCalDAVList(chi.URLParam(r, "list"))
+	if !ok {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return list, "", nil, false
+	}
+
+	match := caldavResourceName.FindStringSubmatch(chi.URLParam(r, "name"))
+	if match == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return list, "", nil, false
+	}
+	id := match[1]
+
+	task, err := h.store.GetForUser(id, caldavUserID(r))
+	if err != nil {
+		if err == models.ErrTaskNotFound {
+			return list, id, nil, true
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return list, id, nil, false
+	}
+
+	// A task that moved to another list is no longer part of this collection
+	if task.Status != list.Status {
+		return list, id, nil, true
+	}
+	return list, id, task, true
+}
+
+// checkPreconditions applies If-Match and If-None-Match to the current version of a resource
+func checkPreconditions(r *http.Request, task *models.Task) bool {
+	if match := r.Header.Get("If-Match"); match != "" {
+		if task == nil || (match != "*" && !strings.Contains(match, taskETag(task))) {
+			return false
+		}
+	}
+	if noneMatch := r.Header.Get("If-None-Match"); noneMatch != "" {
+		if task != nil && (noneMatch == "*" || strings.Contains(noneMatch, taskETag(task))) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetTodo returns a task as an iCalendar VTODO
+func (h *CalDAVHandler) GetTodo(w http.ResponseWriter, r *http.Request) {
+	_, _, task, ok := h.loadCalDAVTask(w, r)
+	if !ok {
+		return
+	}
+	if task == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", taskETag(task))
+	w.Header().Set("Last-Modified", task.UpdatedAt.UTC().Format(http.TimeFormat))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(todoCalendar(task))
+}
+
+// PutTodo creates or replaces a task from a client's VTODO. The collection decides the task's
+// list; a completed to-do completes the task, creating the next occurrence of recurring tasks.
+// Categories naming contexts the user hasn't set up are kept as tags.
+func (h *CalDAVHandler) PutTodo(w http.ResponseWriter, r *http.Request) {
+	list, id, task, ok := h.loadCalDAVTask(w, r)
+	if !ok {
+		return
+	}
+	if !checkPreconditions(r, task) {
+		http.Error(w, "Precondition Failed", http.StatusPreconditionFailed)
+		return
+	}
+
+	calendars, err := ical.Decode(http.MaxBytesReader(w, r.Body, maxCalDAVBodyBytes))
+	if err != nil {
+		http.Error(w, "Invalid calendar: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var todo *ical.Component
+	for _, cal := range calendars {
+		for _, c := range cal.Components {
+			if c.Name == "VTODO" && todo == nil {
+				todo = c
+			}
+		}
+	}
+	if todo == nil {
+		http.Error(w, "Only VTODO resources are supported", http.StatusForbidden)
+		return
+	}
+
+	userID := caldavUserID(r)
+	created := task == nil
+	isNew := false // No task of any list has the ID yet
+	if created {
+		// The task may exist in another list; moving it keeps its other fields
+		existing, err := h.store.GetForUser(id, userID)
+		switch {
+		case err == nil:
+			task = existing
+		case err == models.ErrTaskNotFound:
+			task = models.NewTask("", "", userID)
+			task.ID = id
+			isNew = true
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	label := "Task updated"
+	if created {
+		label = "Task created"
+	}
+	action := h.undo.Begin(userID, label)
+	if isNew {
+		action.RecordCreated(task)
+	} else {
+		action.Record(task)
+	}
+
+	completed := ical.ApplyTodo(todo, task, time.Local)
+	task.UpdatedAt = time.Now()
+	if err := models.ContextsToTags(r.Context(), h.contexts, userID, task); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if completed {
+		_, _, err = completeTask(r.Context(), h.store, task, userID, action)
+	} else {
+		task.Status = list.Status
+		task.CompletedAt = nil
+		err = h.store.SaveForUser(task, userID)
+	}
+	if err != nil {
+		if err == models.ErrTaskNotFound {
+			// The ID belongs to another user's task, which stays hidden like on every other route
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if err == models.ErrTaskConflict {
+			// Another client changed the task while this one was being applied
+			http.Error(w, "Precondition Failed", http.StatusPreconditionFailed)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pushUndo(w, h.undo, action)
+
+	// Completed tasks leave the list, so clients must not cache them under this URL
+	if !completed {
+		w.Header().Set("ETag", taskETag(task))
+	}
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteTodo moves a task and its sub-tasks to the trash
+func (h *CalDAVHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
+	_, _, task, ok := h.loadCalDAVTask(w, r)
+	if !ok {
+		return
+	}
+	if task == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	if !checkPreconditions(r, task) {
+		http.Error(w, "Precondition Failed", http.StatusPreconditionFailed)
+		return
+	}
+
+	userID := caldavUserID(r)
+	action := h.undo.Begin(userID, "Task deleted")
+	if _, err := deleteTaskTree(r.Context(), h.store, task, userID, action); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pushUndo(w, h.undo, action)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CalDAVCredentialsResponse holds what a CalDAV client needs to connect
+type CalDAVCredentialsResponse struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// caldavURL returns the absolute server URL, based on the host the request was made to
+func caldavURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + caldavRoot
+}
+
+// GetCredentialsAPI returns the user's CalDAV server URL and credentials, creating them on first use
+func (h *CalDAVHandler) GetCredentialsAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := h.tokens.GetOrCreate(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CalDAVCredentialsResponse{URL: caldavURL(r), Username: user.ID, Password: token})
+}
+
+// RegenerateCredentialsAPI replaces the user's CalDAV password, signing out all clients
+func (h *CalDAVHandler) RegenerateCredentialsAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := h.tokens.Regenerate(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CalDAVCredentialsResponse{URL: caldavURL(r), Username: user.ID, Password: token})
+}
@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// caldavClient talks to a CalDAV server running on a local port as one user
+type caldavClient struct {
+	t        *testing.T
+	url      string
+	userID   string
+	password string
+	tasks    *models.MemoryTaskStore
+	undo     *models.UndoStack
+}
+
+func startCalDAVServer(t *testing.T) *caldavClient {
+	t.Helper()
+
+	tasks := models.NewMemoryTaskStore()
+	tokens := models.NewMemoryCalDAVTokenStore()
+	undo := models.NewUndoStack(models.NewMemoryUndoStore(), time.Hour, 10)
+	h := NewCalDAVHandler(tasks, tokens, models.NewMemoryContextStore(), undo)
+
+	r := chi.NewRouter()
+	h.RegisterPublicRoutes(r)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	password, err := tokens.GetOrCreate("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &caldavClient{t: t, url: server.URL, userID: "alice", password: password, tasks: tasks, undo: undo}
+}
+
+// do sends a request; headers are given as name, value pairs
+func (c *caldavClient) do(method, path, body string, headers ...string) (*http.Response, string) {
+	c.t.Helper()
+
+	req, err := http.NewRequest(method, c.url+path, strings.NewReader(body))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.SetBasicAuth(c.userID, c.password)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return resp, string(data)
+}
+
+// addTask saves a task for the client's user
+func (c *caldavClient) addTask(title string, status models.TaskStatus, scheduled, due *time.Time) *models.Task {
+	c.t.Helper()
+
+	task := models.NewTask(title, "", c.userID)
+	task.Status = status
+	task.ScheduledDate = scheduled
+	task.DueDate = due
+	if err := c.tasks.SaveForUser(task, c.userID); err != nil {
+		c.t.Fatal(err)
+	}
+	return task
+}
+
+// todo returns a VCALENDAR holding one VTODO with the given properties
+func todo(props ...string) string {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//Test//Client//EN", "BEGIN:VTODO"}
+	lines = append(lines, props...)
+	lines = append(lines, "END:VTODO", "END:VCALENDAR", "")
+	return strings.Join(lines, "\r\n")
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestCalDAVRequiresCredentials(t *testing.T) {
+	c := startCalDAVServer(t)
+
+	c.password = "wrong"
+	resp, _ := c.do("PROPFIND", "/caldav/", "", "Depth", "0")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+	if resp.Header.Get("WWW-Authenticate") == "" {
+		t.Error("missing WWW-Authenticate header")
+	}
+}
+
+func TestCalDAVPropfind(t *testing.T) {
+	c := startCalDAVServer(t)
+	task := c.addTask("Call mom", models.StatusNext, nil, nil)
+	c.addTask("Inbox item", models.StatusInbox, nil, nil)
+
+	resp, body := c.do("PROPFIND", "/caldav/", "", "Depth", "1")
+	if resp.StatusCode != http.StatusMultiStatus {
+		t.Fatalf("root status = %d, want %d", resp.StatusCode, http.StatusMultiStatus)
+	}
+	for _, list := range caldavLists {
+		if !strings.Contains(body, "<d:href>/caldav/"+list.Name+"/</d:href>") {
+			t.Errorf("root listing is missing the %s list", list.Name)
+		}
+	}
+
+	resp, body = c.do("PROPFIND", "/caldav/next/",
+		`<d:propfind xmlns:d="DAV:"><d:prop><d:getetag/><d:displayname/></d:prop></d:propfind>`,
+		"Depth", "1")
+	if resp.StatusCode != http.StatusMultiStatus {
+		t.Fatalf("list status = %d, want %d", resp.StatusCode, http.StatusMultiStatus)
+	}
+	if !strings.Contains(body, "/caldav/next/"+task.ID+".ics") {
+		t.Errorf("next list is missing the task:\n%s", body)
+	}
+	if !strings.Contains(body, xmlEscape(taskETag(task))) {
+		t.Errorf("next list is missing the task's ETag:\n%s", body)
+	}
+	if strings.Contains(body, "Inbox item") || strings.Count(body, ".ics") != 1 {
+		t.Errorf("next list holds tasks of other lists:\n%s", body)
+	}
+
+	resp, _ = c.do("PROPFIND", "/caldav/waiting/"+task.ID+".ics", "", "Depth", "0")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("task in another list: status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestCalDAVReport(t *testing.T) {
+	c := startCalDAVServer(t)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 9, 0, 0, 0, time.UTC) }
+
+	// Tasks without dates match ranges ending after they were created, which is now
+	undated := c.addTask("Undated", models.StatusNext, nil, nil)
+	dueEarly := c.addTask("Due early", models.StatusNext, nil, timePtr(day(2)))
+	dueInside := c.addTask("Due inside", models.StatusNext, nil, timePtr(day(12)))
+	startsInside := c.addTask("Starts inside", models.StatusNext, timePtr(day(14)), nil)
+	spanning := c.addTask("Spanning", models.StatusNext, timePtr(day(1)), timePtr(day(28)))
+	c.addTask("Waiting", models.StatusWaiting, nil, nil)
+
+	query := func(filter string) string {
+		return `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+			`<d:prop><d:getetag/><c:calendar-data/></d:prop>` +
+			`<c:filter><c:comp-filter name="VCALENDAR">` + filter + `</c:comp-filter></c:filter>` +
+			`</c:calendar-query>`
+	}
+
+	tests := []struct {
+		name   string
+		filter string
+		status int
+		want   []*models.Task
+	}{
+		{
+			name:   "all to-dos",
+			filter: `<c:comp-filter name="VTODO"/>`,
+			status: http.StatusMultiStatus,
+			want:   []*models.Task{undated, dueEarly, dueInside, startsInside, spanning},
+		},
+		{
+			name:   "events only",
+			filter: `<c:comp-filter name="VEVENT"/>`,
+			status: http.StatusMultiStatus,
+		},
+		{
+			name:   "time range",
+			filter: `<c:comp-filter name="VTODO"><c:time-range start="20260310T000000Z" end="20260320T000000Z"/></c:comp-filter>`,
+			status: http.StatusMultiStatus,
+			want:   []*models.Task{dueInside, startsInside, spanning},
+		},
+		{
+			name:   "open start",
+			filter: `<c:comp-filter name="VTODO"><c:time-range end="20260305T000000Z"/></c:comp-filter>`,
+			status: http.StatusMultiStatus,
+			want:   []*models.Task{dueEarly, spanning},
+		},
+		{
+			name:   "open end",
+			filter: `<c:comp-filter name="VTODO"><c:time-range start="20260310T000000Z"/></c:comp-filter>`,
+			status: http.StatusMultiStatus,
+			want:   []*models.Task{undated, dueInside, startsInside, spanning},
+		},
+		{
+			name:   "invalid time range",
+			filter: `<c:comp-filter name="VTODO"><c:time-range start="2026-03-10"/></c:comp-filter>`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := c.do("REPORT", "/caldav/next/", query(tt.filter), "Depth", "1")
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d\n%s", resp.StatusCode, tt.status, body)
+			}
+			if resp.StatusCode != http.StatusMultiStatus {
+				return
+			}
+			if got := strings.Count(body, "<d:response>"); got != len(tt.want) {
+				t.Errorf("got %d tasks, want %d:\n%s", got, len(tt.want), body)
+			}
+			for _, task := range tt.want {
+				if !strings.Contains(body, "SUMMARY:"+task.Title) {
+					t.Errorf("missing %q", task.Title)
+				}
+			}
+		})
+	}
+
+	t.Run("multiget", func(t *testing.T) {
+		body := `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+			`<d:prop><d:getetag/><c:calendar-data/></d:prop>` +
+			`<d:href>/caldav/next/` + undated.ID + `.ics</d:href>` +
+			`<d:href>/caldav/waiting/` + undated.ID + `.ics</d:href>` +
+			`</c:calendar-multiget>`
+		resp, got := c.do("REPORT", "/caldav/next/", body, "Depth", "1")
+		if resp.StatusCode != http.StatusMultiStatus {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusMultiStatus)
+		}
+		if !strings.Contains(got, "SUMMARY:Undated") {
+			t.Errorf("missing the requested task:\n%s", got)
+		}
+		if !strings.Contains(got, "<d:href>/caldav/waiting/"+undated.ID+".ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>") {
+			t.Errorf("a task outside the list is not reported missing:\n%s", got)
+		}
+	})
+}
+
+func TestCalDAVPutCreatesAndUpdates(t *testing.T) {
+	c := startCalDAVServer(t)
+	path := "/caldav/next/new-task.ics"
+
+	resp, _ := c.do(http.MethodPut, path, todo("UID:new-task", "SUMMARY:Buy milk", "DUE;VALUE=DATE:20260401"),
+		"Content-Type", "text/calendar", "If-None-Match", "*")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	etag := resp.Header.Get("ETag")
+
+	task, err := c.tasks.GetForUser("new-task", c.userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Title != "Buy milk" || task.Status != models.StatusNext {
+		t.Errorf("task = %q in %s, want %q in %s", task.Title, task.Status, "Buy milk", models.StatusNext)
+	}
+	if task.DueDate == nil || task.DueDate.Day() != 1 {
+		t.Errorf("due date = %v, want April 1", task.DueDate)
+	}
+
+	resp, _ = c.do(http.MethodPut, path, todo("UID:new-task", "SUMMARY:Again"), "If-None-Match", "*")
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("creating twice: status = %d, want %d", resp.StatusCode, http.StatusPreconditionFailed)
+	}
+
+	resp, _ = c.do(http.MethodPut, path, todo("UID:new-task", "SUMMARY:Buy oat milk"), "If-Match", etag)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("update status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	if resp.Header.Get(undoTokenHeader) == "" {
+		t.Error("update has no undo token")
+	}
+
+	resp, _ = c.do(http.MethodPut, path, todo("UID:new-task", "SUMMARY:Stale edit"), "If-Match", etag)
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("update from a stale ETag: status = %d, want %d", resp.StatusCode, http.StatusPreconditionFailed)
+	}
+	if task, _ := c.tasks.GetForUser("new-task", c.userID); task.Title != "Buy oat milk" {
+		t.Errorf("title = %q after a stale update, want %q", task.Title, "Buy oat milk")
+	}
+
+	resp, body := c.do(http.MethodGet, path, "")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "SUMMARY:Buy oat milk") {
+		t.Errorf("GET = %d:\n%s", resp.StatusCode, body)
+	}
+}
+
+func TestCalDAVPutCompletes(t *testing.T) {
+	c := startCalDAVServer(t)
+	task := c.addTask("File taxes", models.StatusNext, nil, nil)
+
+	resp, _ := c.do(http.MethodPut, "/caldav/next/"+task.ID+".ics",
+		todo("UID:"+task.ID, "SUMMARY:File taxes", "STATUS:COMPLETED"), "If-Match", taskETag(task))
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	if resp.Header.Get("ETag") != "" {
+		t.Error("a completed task that left the list still has an ETag")
+	}
+
+	stored, err := c.tasks.GetForUser(task.ID, c.userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != models.StatusDone {
+		t.Errorf("status = %s, want %s", stored.Status, models.StatusDone)
+	}
+}
+
+func TestCalDAVDelete(t *testing.T) {
+	c := startCalDAVServer(t)
+	parent := c.addTask("Plan trip", models.StatusNext, nil, nil)
+	child := models.NewTask("Book hotel", "", c.userID)
+	child.ParentID = parent.ID
+	if err := c.tasks.SaveForUser(child, c.userID); err != nil {
+		t.Fatal(err)
+	}
+	path := "/caldav/next/" + parent.ID + ".ics"
+
+	resp, _ := c.do(http.MethodDelete, path, "", "If-Match", `"stale"`)
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("stale delete: status = %d, want %d", resp.StatusCode, http.StatusPreconditionFailed)
+	}
+	if _, err := c.tasks.GetForUser(parent.ID, c.userID); err != nil {
+		t.Fatalf("task gone after a failed delete: %v", err)
+	}
+
+	resp, _ = c.do(http.MethodDelete, path, "", "If-Match", taskETag(parent))
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	for _, task := range []*models.Task{parent, child} {
+		if _, err := c.tasks.GetForUser(task.ID, c.userID); err != models.ErrTaskNotFound {
+			t.Errorf("%q after delete: got %v, want %v", task.Title, err, models.ErrTaskNotFound)
+		}
+	}
+
+	token := resp.Header.Get(undoTokenHeader)
+	if token == "" {
+		t.Fatal("delete has no undo token")
+	}
+	if _, err := c.undo.Undo(context.Background(), c.tasks, token, c.userID); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	for _, task := range []*models.Task{parent, child} {
+		if _, err := c.tasks.GetForUser(task.ID, c.userID); err != nil {
+			t.Errorf("%q after undo: %v", task.Title, err)
+		}
+	}
+
+	resp, _ = c.do(http.MethodDelete, "/caldav/next/missing.ics", "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("deleting a missing task: status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
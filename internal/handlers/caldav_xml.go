@@ -0,0 +1,453 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// XML namespaces of WebDAV and its calendar extensions
+const (
+	nsDAV            = "DAV:"
+	nsCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	nsCalendarServer = "http://calendarserver.org/ns/"
+)
+
+// davPrefixes are the namespace prefixes declared in multistatus responses
+var davPrefixes = map[string]string{nsDAV: "d", nsCalDAV: "c", nsCalendarServer: "cs"}
+
+// davCompFilter is a comp-filter of a calendar-query; nested filters narrow it to subcomponents
+type davCompFilter struct {
+	Name      string          `xml:"name,attr"`
+	TimeRange *davTimeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	Filters   []davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// davTimeRange is a time-range filter; its bounds are UTC date-times and either may be left open
+type davTimeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+// davTimeFormat is the iCalendar UTC date-time format of time-range bounds
+const davTimeFormat = "20060102T150405Z"
+
+// bounds parses the range; an open bound is returned as the zero time
+func (tr *davTimeRange) bounds() (start, end time.Time, err error) {
+	if tr.Start != "" {
+		if start, err = time.Parse(davTimeFormat, tr.Start); err != nil {
+			return start, end, fmt.Errorf("invalid time-range start %q", tr.Start)
+		}
+	}
+	if tr.End != "" {
+		if end, err = time.Parse(davTimeFormat, tr.End); err != nil {
+			return start, end, fmt.Errorf("invalid time-range end %q", tr.End)
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		return start, end, fmt.Errorf("time-range end must be after its start")
+	}
+	return start, end, nil
+}
+
+// todoOverlaps reports whether the VTODO of a task overlaps [start, end) as defined for VTODOs
+// in RFC 4791 section 9.9. Only open tasks are served, so COMPLETED never takes part.
+func todoOverlaps(task *models.Task, start, end time.Time) bool {
+	// Open bounds reach as far as needed
+	afterStart := func(t time.Time, inclusive bool) bool {
+		return start.IsZero() || start.Before(t) || (inclusive && start.Equal(t))
+	}
+	beforeEnd := func(t time.Time, inclusive bool) bool {
+		return end.IsZero() || end.After(t) || (inclusive && end.Equal(t))
+	}
+
+	dtstart, due := task.ScheduledDate, task.DueDate
+	switch {
+	case dtstart != nil && due != nil:
+		return (afterStart(*due, false) || afterStart(*dtstart, true)) &&
+			(beforeEnd(*dtstart, false) || beforeEnd(*due, true))
+	case dtstart != nil:
+		return afterStart(*dtstart, true) && beforeEnd(*dtstart, false)
+	case due != nil:
+		return afterStart(*due, false) && beforeEnd(*due, true)
+	default:
+		return beforeEnd(task.CreatedAt, false)
+	}
+}
+
+// davRequest is the body of a PROPFIND or REPORT request. Only the parts this server uses are read.
+type davRequest struct {
+	XMLName xml.Name
+	Prop    *struct {
+		Names []struct {
+			XMLName xml.Name
+		} `xml:",any"`
+	} `xml:"DAV: prop"`
+	Hrefs  []string `xml:"DAV: href"`
+	Filter *struct {
+		Comp davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// readDAVRequest decodes a request body. An empty body is an allprop PROPFIND.
+func readDAVRequest(w http.ResponseWriter, r *http.Request) (*davRequest, error) {
+	req := &davRequest{}
+	err := xml.NewDecoder(http.MaxBytesReader(w, r.Body, maxCalDAVBodyBytes)).Decode(req)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return req, nil
+}
+
+// propNames returns the requested properties, or nil for all properties
+func (req *davRequest) propNames() []xml.Name {
+	if req.Prop == nil {
+		return nil
+	}
+	names := make([]xml.Name, 0, len(req.Prop.Names))
+	for _, prop := range req.Prop.Names {
+		names = append(names, prop.XMLName)
+	}
+	return names
+}
+
+// todoFilter returns the comp-filter a calendar-query applies to VTODOs, the only components served.
+// It reports false when the filter excludes VTODOs; a query without one selects them all.
+func (req *davRequest) todoFilter() (*davCompFilter, bool) {
+	if req.Filter == nil || len(req.Filter.Comp.Filters) == 0 {
+		return nil, true
+	}
+	for i, filter := range req.Filter.Comp.Filters {
+		if strings.EqualFold(filter.Name, "VTODO") {
+			return &req.Filter.Comp.Filters[i], true
+		}
+	}
+	return nil, false
+}
+
+// davProperty is a property with its value as escaped XML
+type davProperty struct {
+	Name  xml.Name
+	Value string
+}
+
+// davResponse is the response element of a single resource in a multistatus
+type davResponse struct {
+	Href    string
+	Found   []davProperty
+	Missing []xml.Name
+	Status  int // Set instead of properties for resources that don't exist
+}
+
+// newDAVResponse selects the requested properties of a resource. Requesting all properties
+// leaves out calendar-data, which clients ask for explicitly.
+func newDAVResponse(href string, props []davProperty, requested []xml.Name) davResponse {
+	response := davResponse{Href: href}
+	if requested == nil {
+		for _, prop := range props {
+			if prop.Name != (xml.Name{Space: nsCalDAV, Local: "calendar-data"}) {
+				response.Found = append(response.Found, prop)
+			}
+		}
+		return response
+	}
+
+	for _, name := range requested {
+		found := false
+		for _, prop := range props {
+			if prop.Name == name {
+				response.Found = append(response.Found, prop)
+				found = true
+				break
+			}
+		}
+		if !found {
+			response.Missing = append(response.Missing, name)
+		}
+	}
+	return response
+}
+
+// xmlEscape escapes text for use in element content
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// hrefValue is the value of a property holding a URL
+func hrefValue(href string) string {
+	return "<d:href>" + xmlEscape(href) + "</d:href>"
+}
+
+// davProp creates a property in one of the declared namespaces
+func davProp(space, local, value string) davProperty {
+	return davProperty{Name: xml.Name{Space: space, Local: local}, Value: value}
+}
+
+// principalProps are the properties every resource has
+func principalProps() []davProperty {
+	return []davProperty{
+		davProp(nsDAV, "current-user-principal", hrefValue(caldavRoot)),
+		davProp(nsDAV, "owner", hrefValue(caldavRoot)),
+		davProp(nsDAV, "current-user-privilege-set",
+			"<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>"+
+				"<d:privilege><d:write-content/></d:privilege><d:privilege><d:bind/></d:privilege>"+
+				"<d:privilege><d:unbind/></d:privilege>"),
+	}
+}
+
+// rootProps describes the server root, which is both the user's principal and calendar home
+func rootProps() []davProperty {
+	return append(principalProps(),
+		davProp(nsDAV, "resourcetype", "<d:collection/><d:principal/>"),
+		davProp(nsDAV, "displayname", "GTD"),
+		davProp(nsDAV, "principal-URL", hrefValue(caldavRoot)),
+		davProp(nsCalDAV, "calendar-home-set", hrefValue(caldavRoot)),
+	)
+}
+
+// listProps describes a list's calendar collection
+func listProps(list caldavList, tasks []*models.Task) []davProperty {
+	return append(principalProps(),
+		davProp(nsDAV, "resourcetype", "<d:collection/><c:calendar/>"),
+		davProp(nsDAV, "displayname", xmlEscape(list.DisplayName)),
+		davProp(nsCalDAV, "supported-calendar-component-set", `<c:comp name="VTODO"/>`),
+		davProp(nsDAV, "supported-report-set",
+			"<d:supported-report><d:report><c:calendar-query/></d:report></d:supported-report>"+
+				"<d:supported-report><d:report><c:calendar-multiget/></d:report></d:supported-report>"),
+		davProp(nsCalendarServer, "getctag", xmlEscape(listCTag(tasks))),
+	)
+}
+
+// taskProps describes a task resource
+func taskProps(task *models.Task) []davProperty {
+	return append(principalProps(),
+		davProp(nsDAV, "resourcetype", ""),
+		davProp(nsDAV, "getetag", xmlEscape(taskETag(task))),
+		davProp(nsDAV, "getcontenttype", "text/calendar; charset=utf-8; component=VTODO"),
+		davProp(nsDAV, "getlastmodified", task.UpdatedAt.UTC().Format(http.TimeFormat)),
+		davProp(nsCalDAV, "calendar-data", xmlEscape(string(todoCalendar(task)))),
+	)
+}
+
+// writeMultistatus writes a 207 Multi-Status response
+func writeMultistatus(w http.ResponseWriter, responses []davResponse) {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="` + nsCalDAV + `" xmlns:cs="` + nsCalendarServer + `">` + "\n")
+
+	for _, response := range responses {
+		b.WriteString("<d:response>" + hrefValue(response.Href))
+		if response.Status != 0 {
+			writeDAVStatus(&b, response.Status)
+		}
+		if len(response.Found) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, prop := range response.Found {
+				writeDAVElement(&b, prop.Name, prop.Value)
+			}
+			b.WriteString("</d:prop>")
+			writeDAVStatus(&b, http.StatusOK)
+			b.WriteString("</d:propstat>")
+		}
+		if len(response.Missing) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, name := range response.Missing {
+				writeDAVElement(&b, name, "")
+			}
+			b.WriteString("</d:prop>")
+			writeDAVStatus(&b, http.StatusNotFound)
+			b.WriteString("</d:propstat>")
+		}
+		b.WriteString("</d:response>\n")
+	}
+	b.WriteString("</d:multistatus>\n")
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, b.String())
+}
+
+// writeDAVElement writes an element, declaring its namespace inline if it has no prefix
+func writeDAVElement(b *strings.Builder, name xml.Name, value string) {
+	tag := name.Local
+	declaration := ""
+	if prefix, ok := davPrefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag = "x:" + name.Local
+		declaration = ` xmlns:x="` + xmlEscape(name.Space) + `"`
+	}
+
+	if value == "" {
+		b.WriteString("<" + tag + declaration + "/>")
+		return
+	}
+	b.WriteString("<" + tag + declaration + ">" + value + "</" + tag + ">")
+}
+
+// writeDAVStatus writes a status element
+func writeDAVStatus(b *strings.Builder, status int) {
+	fmt.Fprintf(b, "<d:status>HTTP/1.1 %d %s</d:status>", status, http.StatusText(status))
+}
+
+// Propfind returns the properties of the server root, a list or a task. With a Depth
+// other than 0, the lists below the root and the tasks in a list are included.
+func (h *CalDAVHandler) Propfind(w http.ResponseWriter, r *http.Request) {
+	req, err := readDAVRequest(w, r)
+	if err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	requested := req.propNames()
+	withMembers := r.Header.Get("Depth") != "0"
+	userID := caldavUserID(r)
+
+	var responses []davResponse
+	switch {
+	case chi.URLParam(r, "name") != "":
+		_, _, task, ok := h.loadCalDAVTask(w, r)
+		if !ok {
+			return
+		}
+		if task == nil {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		list, _ := findCalDAVList(chi.URLParam(r, "list"))
+		responses = append(responses, newDAVResponse(taskHref(list, task), taskProps(task), requested))
+
+	case chi.URLParam(r, "list") != "":
+		list, ok := findCalDAVList(chi.URLParam(r, "list"))
+		if !ok {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		tasks, err := h.listTasks(list, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		responses = append(responses, newDAVResponse(caldavRoot+list.Name+"/", listProps(list, tasks), requested))
+		if withMembers {
+			for _, task := range tasks {
+				responses = append(responses, newDAVResponse(taskHref(list, task), taskProps(task), requested))
+			}
+		}
+
+	default:
+		responses = append(responses, newDAVResponse(caldavRoot, rootProps(), requested))
+		if withMembers {
+			for _, list := range caldavLists {
+				tasks, err := h.listTasks(list, userID)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				responses = append(responses, newDAVResponse(caldavRoot+list.Name+"/", listProps(list, tasks), requested))
+			}
+		}
+	}
+
+	writeMultistatus(w, responses)
+}
+
+// Report answers calendar-query reports on a list and calendar-multiget reports.
+// Queries return the tasks of the list within the VTODO filter's time range, if any;
+// clients apply property filters themselves.
+func (h *CalDAVHandler) Report(w http.ResponseWriter, r *http.Request) {
+	req, err := readDAVRequest(w, r)
+	if err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	requested := req.propNames()
+	userID := caldavUserID(r)
+
+	var responses []davResponse
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		list, ok := findCalDAVList(chi.URLParam(r, "list"))
+		if !ok {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		filter, ok := req.todoFilter()
+		if !ok {
+			break
+		}
+		var start, end time.Time
+		if filter != nil && filter.TimeRange != nil {
+			if start, end, err = filter.TimeRange.bounds(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		tasks, err := h.listTasks(list, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, task := range tasks {
+			if todoOverlaps(task, start, end) {
+				responses = append(responses, newDAVResponse(taskHref(list, task), taskProps(task), requested))
+			}
+		}
+
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		for _, href := range req.Hrefs {
+			list, id, ok := parseTaskHref(href)
+			if !ok {
+				responses = append(responses, davResponse{Href: href, Status: http.StatusNotFound})
+				continue
+			}
+
+			task, err := h.store.GetForUser(id, userID)
+			if err != nil && err != models.ErrTaskNotFound {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if task == nil || task.Status != list.Status {
+				responses = append(responses, davResponse{Href: href, Status: http.StatusNotFound})
+				continue
+			}
+			responses = append(responses, newDAVResponse(taskHref(list, task), taskProps(task), requested))
+		}
+
+	default:
+		http.Error(w, "Unsupported report", http.StatusForbidden)
+		return
+	}
+
+	writeMultistatus(w, responses)
+}
+
+// parseTaskHref resolves the href of a task resource, which may be a path or an absolute URL
+func parseTaskHref(href string) (caldavList, string, bool) {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return caldavList{}, "", false
+	}
+
+	rest, ok := strings.CutPrefix(u.Path, caldavRoot)
+	if !ok {
+		return caldavList{}, "", false
+	}
+	listName, name, _ := strings.Cut(rest, "/")
+
+	list, ok := findCalDAVList(listName)
+	match := caldavResourceName.FindStringSubmatch(name)
+	if !ok || match == nil {
+		return caldavList{}, "", false
+	}
+	return list, match[1], true
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/ical"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// maxCalendarImportBytes limits the size of an uploaded .ics file
+const maxCalendarImportBytes = 5 << 20
+
+// CalendarHandler serves the iCalendar feed of a user's tasks and imports calendar events
+type CalendarHandler struct {
+	store    models.TaskStore
+	tokens   models.CalendarTokenStore
+	contexts models.ContextStore
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(store models.TaskStore, tokens models.CalendarTokenStore, contexts models.ContextStore) *CalendarHandler {
+	return &CalendarHandler{
+		store:    store,
+		tokens:   tokens,
+		contexts: contexts,
+	}
+}
+
+// RegisterPublicRoutes registers the feed route. Calendar apps can't log in,
+// so the feed is protected by the token in its URL instead of a session.
+func (h *CalendarHandler) RegisterPublicRoutes(r chi.Router) {
+	r.Get("/calendar/{token}.ics", h.Feed)
+}
+
+// RegisterRoutes registers the routes for managing the feed and importing calendars
+func (h *CalendarHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/calendar", func(r chi.Router) {
+		r.Get("/feed", h.GetFeedAPI)
+		r.Post("/feed/regenerate", h.RegenerateFeedAPI)
+		r.Post("/import", h.ImportAPI)
+	})
+}
+
+// Feed serves the calendar of the token owner's scheduled and due tasks
+func (h *CalendarHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.tokens.GetUserID(chi.URLParam(r, "token"))
+	if err != nil {
+		if err == models.ErrCalendarTokenNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tasks, err := h.store.GetAllByUserID(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="gtd.ics"`)
+	ical.Encode(w, ical.Feed("GTD Tasks", tasks, time.Now()))
+}
+
+// CalendarFeedResponse is the URL to subscribe to in a calendar app
+type CalendarFeedResponse struct {
+	URL string `json:"url"`
+}
+
+// feedURL returns the absolute URL of a feed token, based on the host the request was made to
+func feedURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/calendar/" + token + ".ics"
+}
+
+// GetFeedAPI returns the user's calendar feed URL, creating it on first use
+func (h *CalendarHandler) GetFeedAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := h.tokens.GetOrCreate(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CalendarFeedResponse{URL: feedURL(r, token)})
+}
+
+// RegenerateFeedAPI replaces the user's feed URL, e.g. after it was shared by mistake
+func (h *CalendarHandler) RegenerateFeedAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := h.tokens.Regenerate(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CalendarFeedResponse{URL: feedURL(r, token)})
+}
+
+// CalendarImportResponse summarizes an import
+type CalendarImportResponse struct {
+	Imported int            `json:"imported"`
+	Skipped  int            `json:"skipped"` // Cancelled or undated events and events from this app's own feed
+	Tasks    []*models.Task `json:"tasks"`
+}
+
+// ImportAPI creates a scheduled task for every event of an uploaded .ics file.
+// The file is sent as the "file" field of a multipart form or as the raw request body;
+// an optional timezone (IANA name) applies to floating times and all-day events.
+func (h *CalendarHandler) ImportAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCalendarImportBytes)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Missing calendar file: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	loc, err := requestLocation(r, r.FormValue("timezone"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, skipped, err := ical.ImportEvents(body, user.ID, loc)
+	if err != nil {
+		http.Error(w, "Invalid calendar: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := CalendarImportResponse{Skipped: skipped, Tasks: []*models.Task{}}
+	for _, event := range events {
+		// Re-importing this app's own feed must not duplicate the user's tasks
+		if id, ok := ical.TaskIDFromUID(event.UID); ok {
+			if _, err := h.store.GetForUser(id, user.ID); err == nil {
+				response.Skipped++
+				continue
+			}
+		}
+
+		if err := models.ContextsToTags(r.Context(), h.contexts, user.ID, event.Task); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := h.store.Save(event.Task); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response.Tasks = append(response.Tasks, event.Task)
+	}
+	response.Imported = len(response.Tasks)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/views/partials"
+)
+
+// CaptureHandler manages email capture addresses and task attachments
+type CaptureHandler struct {
+	store       models.TaskStore
+	tokens      models.CaptureTokenStore
+	attachments models.AttachmentStore
+	domain      string // Domain of the capture addresses; empty when mail capture is disabled
+}
+
+// NewCaptureHandler creates a new capture handler. Pass an empty domain when mail capture is disabled.
+func NewCaptureHandler(store models.TaskStore, tokens models.CaptureTokenStore, attachments models.AttachmentStore, domain string, templatesDir string) (*CaptureHandler, error) {
+	return &CaptureHandler{
+		store:       store,
+		tokens:      tokens,
+		attachments: attachments,
+		domain:      domain,
+	}, nil
+}
+
+// RegisterRoutes registers all capture address and attachment routes
+func (h *CaptureHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/capture/address", func(r chi.Router) {
+		r.Get("/", h.GetCaptureAddressAPI)
+		r.Post("/regenerate", h.RegenerateCaptureAddressAPI)
+	})
+
+	r.Get("/api/tasks/{id}/attachments", h.ListAttachmentsAPI)
+	r.Get("/api/attachments/{id}", h.DownloadAttachment)
+
+	// HTMX fragments
+	r.Get("/capture/address", h.CaptureAddressFragment)
+	r.Get("/tasks/{id}/attachments", h.TaskAttachmentsFragment)
+}
+
+// CaptureAddressResponse is the user's email capture address
+type CaptureAddressResponse struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address,omitempty"`
+}
+
+// captureAddress returns the user's capture address, or "" when mail capture is disabled
+func (h *CaptureHandler) captureAddress(userID string) (string, error) {
+	if h.domain == "" {
+		return "", nil
+	}
+
+	token, err := h.tokens.GetOrCreate(userID)
+	if err != nil {
+		return "", err
+	}
+	return models.CaptureAddress(token, h.domain), nil
+}
+
+// GetCaptureAddressAPI returns the address that files email into the user's inbox
+func (h *CaptureHandler) GetCaptureAddressAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	address, err := h.captureAddress(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CaptureAddressResponse{Enabled: address != "", Address: address})
+}
+
+// RegenerateCaptureAddressAPI replaces the user's capture address, e.g. after it leaked
+func (h *CaptureHandler) RegenerateCaptureAddressAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if h.domain == "" {
+		http.Error(w, "Mail capture is disabled", http.StatusNotFound)
+		return
+	}
+
+	token, err := h.tokens.Regenerate(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CaptureAddressResponse{Enabled: true, Address: models.CaptureAddress(token, h.domain)})
+}
+
+// ListAttachmentsAPI returns the attachments of a task
+func (h *CaptureHandler) ListAttachmentsAPI(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachments.ListByTask(task.ID, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// DownloadAttachment sends the content of an attachment
+func (h *CaptureHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	attachment, err := h.attachments.GetForUser(chi.URLParam(r, "id"), user.ID)
+	if err != nil {
+		if err == models.ErrAttachmentNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Always download, so captured HTML can't run in the app's origin
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("Content-Length", strconv.Itoa(len(attachment.Data)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write(attachment.Data)
+}
+
+// CaptureAddressFragment renders the user's capture address for the home page
+func (h *CaptureHandler) CaptureAddressFragment(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	address, err := h.captureAddress(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	partials.CaptureAddress(address).Render(r.Context(), w)
+}
+
+// TaskAttachmentsFragment renders the attachment list of the task detail page
+func (h *CaptureHandler) TaskAttachmentsFragment(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachments.ListByTask(task.ID, user.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load attachments: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	infos := make([]partials.AttachmentInfo, len(attachments))
+	for i, attachment := range attachments {
+		infos[i] = partials.AttachmentInfo{
+			ID:          attachment.ID,
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			CreatedAt:   attachment.CreatedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	partials.TaskAttachments(infos).Render(r.Context(), w)
+}
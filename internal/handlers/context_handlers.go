@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/views/pages"
+	"github.com/melihkorkmaz/gtd/internal/views/partials"
+)
+
+// ContextHandler manages the contexts users set up for their tasks
+type ContextHandler struct {
+	store    models.TaskStore
+	projects models.ProjectStore
+	contexts models.ContextStore
+}
+
+// NewContextHandler creates a new context handler
+func NewContextHandler(store models.TaskStore, projects models.ProjectStore, contexts models.ContextStore) *ContextHandler {
+	return &ContextHandler{
+		store:    store,
+		projects: projects,
+		contexts: contexts,
+	}
+}
+
+// RegisterRoutes registers all context routes
+func (h *ContextHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/contexts", func(r chi.Router) {
+		r.Get("/", h.ListContextsAPI)
+		r.Post("/", h.CreateContextAPI)
+		r.Get("/{id}", h.GetContextAPI)
+		r.Put("/{id}", h.UpdateContextAPI)
+		r.Delete("/{id}", h.DeleteContextAPI)
+		r.Post("/{id}/merge", h.MergeContextAPI)
+	})
+
+	r.Get("/contexts/nav", h.ContextNavFragment)
+	r.Get("/contexts/{id}", h.ContextPage)
+}
+
+// ContextRequest is the request body for creating or updating a context
+type ContextRequest struct {
+	Name     string                `json:"name"` // Renames the context on every task when changed
+	Icon     string                `json:"icon"`
+	Color    string                `json:"color"`
+	Location string                `json:"location"`
+	Hours    []models.ContextHours `json:"hours"`
+}
+
+// apply copies the request onto a context
+func (req ContextRequest) apply(def *models.ContextDefinition) {
+	def.Name = models.Context(req.Name)
+	def.Icon = strings.TrimSpace(req.Icon)
+	def.Color = strings.TrimSpace(req.Color)
+	def.Location = strings.TrimSpace(req.Location)
+	def.Hours = req.Hours
+}
+
+// MergeContextRequest names the context another one is merged into
+type MergeContextRequest struct {
+	Into string `json:"into"` // ID of the context that is kept
+}
+
+// ContextResponse is a context along with how it is used
+type ContextResponse struct {
+	*models.ContextDefinition
+	OpenTasks    int  `json:"openTasks"`
+	Available    bool `json:"available"`              // Whether the context's hours include the current time
+	TasksUpdated int  `json:"tasksUpdated,omitempty"` // Tasks rewritten by a rename or merge
+}
+
+// loadOwnedContext fetches the context named in the URL for the current user
+func (h *ContextHandler) loadOwnedContext(w http.ResponseWriter, r *http.Request) (*models.ContextDefinition, *models.User, bool) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	def, err := h.contexts.GetForUser(chi.URLParam(r, "id"), user.ID)
+	if err != nil {
+		if err == models.ErrContextNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return nil, nil, false
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	return def, user, true
+}
+
+// sendContext writes a context as JSON along with its open task count
+func (h *ContextHandler) sendContext(w http.ResponseWriter, r *http.Request, status int, def *models.ContextDefinition, tasksUpdated int) {
+	tasks, err := models.TasksInContext(r.Context(), h.store, def.UserID, def.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ContextResponse{
+		ContextDefinition: def,
+		OpenTasks:         len(tasks),
+		Available:         def.AvailableAt(time.Now()),
+		TasksUpdated:      tasksUpdated,
+	})
+}
+
+// writeContextError reports a context that can't be saved
+func writeContextError(w http.ResponseWriter, err error) {
+	switch err {
+	case models.ErrContextNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case models.ErrContextExists:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+// ListContextsAPI returns the current user's contexts with their open task counts
+func (h *ContextHandler) ListContextsAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	defs, err := h.contexts.List(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	counts, err := models.CountTasksByContext(r.Context(), h.store, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	resp := make([]ContextResponse, len(defs))
+	for i, def := range defs {
+		resp[i] = ContextResponse{
+			ContextDefinition: def,
+			OpenTasks:         counts[strings.ToLower(string(def.Name))],
+			Available:         def.AvailableAt(now),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// CreateContextAPI sets up a new context
+func (h *ContextHandler) CreateContextAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req ContextRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	def := models.NewContextDefinition(models.Context(req.Name), user.ID)
+	req.apply(def)
+	if _, err := models.SaveContext(r.Context(), h.contexts, h.store, h.projects, def, "", user.ID); err != nil {
+		writeContextError(w, err)
+		return
+	}
+
+	h.sendContext(w, r, http.StatusCreated, def, 0)
+}
+
+// GetContextAPI returns a single context
+func (h *ContextHandler) GetContextAPI(w http.ResponseWriter, r *http.Request) {
+	def, _, ok := h.loadOwnedContext(w, r)
+	if !ok {
+		return
+	}
+
+	h.sendContext(w, r, http.StatusOK, def, 0)
+}
+
+// UpdateContextAPI replaces a context's details. A new name is carried over to every task and project using the context.
+func (h *ContextHandler) UpdateContextAPI(w http.ResponseWriter, r *http.Request) {
+	def, user, ok := h.loadOwnedContext(w, r)
+	if !ok {
+		return
+	}
+
+	var req ContextRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	oldName := def.Name
+	req.apply(def)
+	def.UpdatedAt = time.Now()
+	updated, err := models.SaveContext(r.Context(), h.contexts, h.store, h.projects, def, oldName, user.ID)
+	if err != nil {
+		if updated > 0 {
+			http.Error(w, fmt.Sprintf("Renamed on %d tasks before failing: %v", updated, err), http.StatusInternalServerError)
+			return
+		}
+		writeContextError(w, err)
+		return
+	}
+
+	h.sendContext(w, r, http.StatusOK, def, updated)
+}
+
+// MergeContextAPI moves every task and project of a context to another context and deletes it
+func (h *ContextHandler) MergeContextAPI(w http.ResponseWriter, r *http.Request) {
+	source, user, ok := h.loadOwnedContext(w, r)
+	if !ok {
+		return
+	}
+
+	var req MergeContextRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	target, err := h.contexts.GetForUser(req.Into, user.ID)
+	if err != nil {
+		writeContextError(w, err)
+		return
+	}
+	if target.ID == source.ID {
+		http.Error(w, "a context cannot be merged into itself", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := models.MergeContexts(r.Context(), h.contexts, h.store, h.projects, source, target, user.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Merged %d tasks before failing: %v", updated, err), http.StatusInternalServerError)
+		return
+	}
+
+	h.sendContext(w, r, http.StatusOK, target, updated)
+}
+
+// DeleteContextAPI deletes a context and takes it off every task and project
+func (h *ContextHandler) DeleteContextAPI(w http.ResponseWriter, r *http.Request) {
+	def, user, ok := h.loadOwnedContext(w, r)
+	if !ok {
+		return
+	}
+
+	if updated, err := models.DeleteContext(r.Context(), h.contexts, h.store, h.projects, def, user.ID); err != nil {
+		http.Error(w, fmt.Sprintf("Removed from %d tasks before failing: %v", updated, err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// contextStatusGroups lists the open statuses in the order a context's tasks are shown
+var contextStatusGroups = []struct {
+	status models.TaskStatus
+	name   string
+}{
+	{models.StatusNext, "Next Actions"},
+	{models.StatusWaiting, "Waiting For"},
+	{models.StatusInbox, "Inbox"},
+	{models.StatusSomeday, "Someday/Maybe"},
+}
+
+// ContextPage renders a context's open tasks, grouped by status
+func (h *ContextHandler) ContextPage(w http.ResponseWriter, r *http.Request) {
+	if currentUser(r) == nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	def, user, ok := h.loadOwnedContext(w, r)
+	if !ok {
+		return
+	}
+
+	tasks, err := models.TasksInContext(r.Context(), h.store, user.ID, def.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var groups []pages.TaskGroup
+	for _, group := range contextStatusGroups {
+		var infos []partials.TaskCardInfo
+		for _, task := range tasks {
+			if task.Status == group.status {
+				infos = append(infos, getTaskCardInfo(task))
+			}
+		}
+		if len(infos) > 0 {
+			groups = append(groups, pages.TaskGroup{Name: group.name, Tasks: infos})
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	pages.TasksListPage("@"+string(def.Name), groups).Render(r.Context(), w)
+}
+
+// ContextNavFragment renders the sidebar entries of the user's contexts with their open task counts
+func (h *ContextHandler) ContextNavFragment(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	defs, err := h.contexts.List(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	counts, err := models.CountTasksByContext(r.Context(), h.store, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	items := make([]partials.ContextNavItem, len(defs))
+	for i, def := range defs {
+		items[i] = partials.ContextNavItem{
+			ID:        def.ID,
+			Name:      string(def.Name),
+			Icon:      def.Icon,
+			Color:     def.Color,
+			OpenTasks: counts[strings.ToLower(string(def.Name))],
+			Available: def.AvailableAt(now),
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	partials.ContextNav(items).Render(r.Context(), w)
+}
+
+// resolveContexts checks that the contexts of a task or project are ones the user has set up and
+// spells them as defined. On failure it writes the error response and returns false.
+func resolveContexts(w http.ResponseWriter, r *http.Request, contexts models.ContextStore, names *[]models.Context, userID string) bool {
+	resolved, unknown, err := models.ResolveContexts(r.Context(), contexts, userID, *names)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if len(unknown) > 0 {
+		names := make([]string, len(unknown))
+		for i, name := range unknown {
+			names[i] = string(name)
+		}
+		http.Error(w, "unknown context: "+strings.Join(names, ", "), http.StatusBadRequest)
+		return false
+	}
+
+	*names = resolved
+	return true
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/views/pages"
+)
+
+// EngageHandler suggests what to work on next
+type EngageHandler struct {
+	store    models.TaskStore
+	projects models.ProjectStore
+}
+
+// NewEngageHandler creates a new engage handler
+func NewEngageHandler(store models.TaskStore, projects models.ProjectStore, templatesDir string) (*EngageHandler, error) {
+	return &EngageHandler{
+		store:    store,
+		projects: projects,
+	}, nil
+}
+
+// RegisterRoutes registers all engage routes
+func (h *EngageHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/engage", h.EngageAPI)
+	r.Get("/engage", h.EngagePage)
+}
+
+// engageCriteriaFromRequest reads the user's situation from URL query parameters
+func engageCriteriaFromRequest(r *http.Request) (models.EngageCriteria, error) {
+	params := r.URL.Query()
+
+	criteria := models.EngageCriteria{
+		Context: models.Context(params.Get("context")),
+		Energy:  params.Get("energy"),
+	}
+	if minutes := params.Get("minutes"); minutes != "" {
+		n, err := strconv.Atoi(minutes)
+		if err != nil {
+			return criteria, errors.New("minutes must be a whole number")
+		}
+		criteria.Minutes = n
+	}
+	if limit := params.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return criteria, errors.New("limit must be a whole number")
+		}
+		criteria.Limit = n
+	}
+
+	return criteria, criteria.Validate()
+}
+
+// EngageAPI returns a ranked shortlist of next actions for the user's situation, with the reasons
+// behind each pick. Supported query parameters: context, minutes, energy (low, medium or high) and limit.
+func (h *EngageHandler) EngageAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	criteria, err := engageCriteriaFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := models.Recommend(r.Context(), h.store, h.projects, user.ID, criteria, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// EngagePage renders the "what should I do now?" page
+func (h *EngageHandler) EngagePage(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	params := r.URL.Query()
+	data := pages.EngagePageData{
+		Context: params.Get("context"),
+		Minutes: params.Get("minutes"),
+		Energy:  params.Get("energy"),
+	}
+
+	criteria, err := engageCriteriaFromRequest(r)
+	if err != nil {
+		data.Error = err.Error()
+	} else {
+		result, err := models.Recommend(r.Context(), h.store, h.projects, user.ID, criteria, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		data.Considered = result.Considered
+		for _, rec := range result.Recommendations {
+			pick := pages.EngagePickInfo{ID: rec.Task.ID, Title: rec.Task.Title, Score: rec.Score}
+			for _, factor := range rec.Factors {
+				pick.Factors = append(pick.Factors, pages.EngageFactorInfo{Points: factor.Points, Reason: factor.Reason})
+			}
+			data.Picks = append(data.Picks, pick)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	pages.EngagePage(data).Render(r.Context(), w)
+}
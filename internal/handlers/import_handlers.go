@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/importer"
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/views/pages"
+	"github.com/melihkorkmaz/gtd/internal/views/partials"
+)
+
+// maxImportBytes limits the size of an uploaded export file
+const maxImportBytes = 10 << 20
+
+// ImportHandler imports tasks and projects from other task managers
+type ImportHandler struct {
+	importer *importer.Importer
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(store models.TaskStore, projects models.ProjectStore, contexts models.ContextStore, templatesDir string) (*ImportHandler, error) {
+	return &ImportHandler{
+		importer: importer.New(store, projects, contexts),
+	}, nil
+}
+
+// RegisterRoutes registers the import routes
+func (h *ImportHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/import", func(r chi.Router) {
+		r.Get("/formats", h.ListFormatsAPI)
+		r.Post("/", h.ImportAPI)
+	})
+
+	r.Get("/import", h.ImportPage)
+	r.Post("/import", h.ImportFragment)
+}
+
+// ImportFormatsResponse lists the supported formats and the fields of the generic CSV format
+type ImportFormatsResponse struct {
+	Formats   []importer.Format `json:"formats"`
+	CSVFields []string          `json:"csvFields"`
+}
+
+// ListFormatsAPI returns the supported import formats
+func (h *ImportHandler) ListFormatsAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ImportFormatsResponse{Formats: importer.Formats(), CSVFields: importer.CSVFields()})
+}
+
+// importDefaultStatuses are the lists imported tasks can default to
+var importDefaultStatuses = map[string]models.TaskStatus{
+	"":        models.StatusInbox,
+	"inbox":   models.StatusInbox,
+	"next":    models.StatusNext,
+	"someday": models.StatusSomeday,
+}
+
+// runImport parses the uploaded file and imports it for the current user. The file is sent as the
+// "file" field of a multipart form or as the raw request body; options are form or query values:
+// format, dryRun, project, status (inbox, next or someday), includeCompleted, timezone, and
+// mapping, a JSON object of generic CSV fields to column headers.
+// Problems with the request are returned as an importRequestError.
+func (h *ImportHandler) runImport(w http.ResponseWriter, r *http.Request, userID string) (*importer.Report, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, badImport("Missing export file: " + err.Error())
+		}
+		defer file.Close()
+		body = file
+	}
+
+	format := r.FormValue("format")
+	if _, ok := importer.Lookup(format); !ok {
+		return nil, badImport("Unknown import format: " + format)
+	}
+
+	loc, err := requestLocation(r, r.FormValue("timezone"))
+	if err != nil {
+		return nil, badImport(err.Error())
+	}
+
+	status, ok := importDefaultStatuses[r.FormValue("status")]
+	if !ok {
+		return nil, badImport("Invalid status: " + r.FormValue("status"))
+	}
+
+	opts := importer.Options{
+		Location:         loc,
+		Project:          strings.TrimSpace(r.FormValue("project")),
+		DefaultStatus:    status,
+		IncludeCompleted: r.FormValue("includeCompleted") == "true",
+	}
+	if mapping := strings.TrimSpace(r.FormValue("mapping")); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Columns); err != nil {
+			return nil, badImport("Invalid column mapping: " + err.Error())
+		}
+	}
+
+	items, err := importer.Parse(format, body, opts)
+	if err != nil {
+		return nil, badImport("Could not read file: " + err.Error())
+	}
+
+	report, err := h.importer.Run(r.Context(), userID, items, opts, r.FormValue("dryRun") == "true")
+	if err != nil {
+		return nil, err
+	}
+	report.Format = format
+	return report, nil
+}
+
+// importRequestError is a problem with the uploaded file or the import options
+type importRequestError struct {
+	message string
+}
+
+func (e *importRequestError) Error() string {
+	return e.message
+}
+
+func badImport(message string) error {
+	return &importRequestError{message: message}
+}
+
+// ImportAPI imports an export file, or previews the import with dryRun=true, and returns the report
+func (h *ImportHandler) ImportAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	report, err := h.runImport(w, r, user.ID)
+	if err != nil {
+		var requestErr *importRequestError
+		if errors.As(err, &requestErr) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ImportPage renders the import form
+func (h *ImportHandler) ImportPage(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	formats := importer.Formats()
+	infos := make([]pages.ImportFormatInfo, 0, len(formats))
+	for _, format := range formats {
+		infos = append(infos, pages.ImportFormatInfo{Name: format.Name, Label: format.Label, Description: format.Description})
+	}
+
+	ctx := context.WithValue(r.Context(), "user", user)
+	if err := pages.ImportPage(infos).Render(ctx, w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ImportFragment runs an import from the import page and renders its report for HTMX
+func (h *ImportHandler) ImportFragment(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var info partials.ImportReportInfo
+	report, err := h.runImport(w, r, user.ID)
+	if err != nil {
+		var requestErr *importRequestError
+		if !errors.As(err, &requestErr) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		info.Error = err.Error()
+	} else {
+		info = importReportInfo(report)
+	}
+
+	if err := partials.ImportReport(info).Render(r.Context(), w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// importReportInfo converts an import report for display
+func importReportInfo(report *importer.Report) partials.ImportReportInfo {
+	info := partials.ImportReportInfo{
+		DryRun:          report.DryRun,
+		Total:           report.Total,
+		Created:         report.Created,
+		Duplicates:      report.Duplicates,
+		Skipped:         report.Skipped,
+		ProjectsCreated: report.ProjectsCreated,
+		ProjectsMatched: report.ProjectsMatched,
+		Items:           make([]partials.ImportItemInfo, 0, len(report.Items)),
+	}
+	for _, item := range report.Items {
+		info.Items = append(info.Items, partials.ImportItemInfo{
+			Line:    item.Line,
+			Title:   item.Title,
+			Action:  item.Action,
+			Reason:  item.Reason,
+			Project: item.Project,
+			Status:  string(item.Status),
+		})
+	}
+	return info
+}
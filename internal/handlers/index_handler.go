@@ -9,20 +9,24 @@ import (
 
 // IndexHandler handles the home page
 type IndexHandler struct {
-	store     models.TaskStore
-	templates *TemplateRenderer
+	store      models.TaskStore
+	projects   models.ProjectStore
+	thresholds models.StaleThresholdStore
+	templates  *TemplateRenderer
 }
 
 // NewIndexHandler creates a new index handler
-func NewIndexHandler(store models.TaskStore, templatesDir string) (*IndexHandler, error) {
+func NewIndexHandler(store models.TaskStore, projects models.ProjectStore, thresholds models.StaleThresholdStore, templatesDir string) (*IndexHandler, error) {
 	templates, err := NewTemplateRenderer(templatesDir)
 	if err != nil {
 		return nil, err
 	}
 
 	return &IndexHandler{
-		store:     store,
-		templates: templates,
+		store:      store,
+		projects:   projects,
+		thresholds: thresholds,
+		templates:  templates,
 	}, nil
 }
 
@@ -42,32 +46,50 @@ func (h *IndexHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 	// Get counts for different task statuses
 	stats := TaskStats{}
 
-	// Get all tasks
-	tasks, err := h.store.GetAll()
+	user := currentUser(r)
+	if user == nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	// Get all of the user's tasks
+	tasks, err := h.store.GetAllByUserID(user.ID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Count tasks by status
+	// Count tasks by status; next actions still waiting on a blocker aren't actionable yet
+	blocked := models.BlockedTasks(tasks)
 	stats.Total = len(tasks)
 	for _, task := range tasks {
 		switch task.Status {
 		case models.StatusInbox:
 			stats.Inbox++
 		case models.StatusNext:
-			stats.Next++
+			if !blocked[task.ID] {
+				stats.Next++
+			}
 		case models.StatusWaiting:
 			stats.Waiting++
 		case models.StatusSomeday:
 			stats.Someday++
-		case models.StatusProject:
-			stats.Projects++
 		case models.StatusDone:
 			stats.Done++
 		}
 	}
 
+	// Count the user's open projects
+	projects, err := h.projects.List(r.Context(), models.ProjectFilter{
+		UserID: user.ID,
+		States: []models.ProjectState{models.ProjectActive, models.ProjectOnHold},
+	}, models.ProjectSortCreatedDesc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	stats.Projects = len(projects)
+
 	// Convert to template-friendly format
 	systemStats := pages.SystemStats{
 		Inbox:    stats.Inbox,
@@ -75,16 +97,44 @@ func (h *IndexHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 		Projects: stats.Projects,
 	}
 
+	// Find the items that need attention
+	report, err := findStaleItems(r, h.store, h.projects, h.thresholds, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	// Render the page using the new templ system
-	indexPage := pages.IndexPage(systemStats)
+	indexPage := pages.IndexPage(systemStats, staleSummary(report))
 	w.Header().Set("Content-Type", "text/html")
 	indexPage.Render(r.Context(), w)
 }
 
-// WeeklyReviewPage renders the weekly review page
-func (h *IndexHandler) WeeklyReviewPage(w http.ResponseWriter, r *http.Request) {
-	// Render the weekly review page using the new templ system
-	weeklyReviewPage := pages.WeeklyReviewPage()
-	w.Header().Set("Content-Type", "text/html")
-	weeklyReviewPage.Render(r.Context(), w)
+// staleSummary converts a stale report to the dashboard view model
+func staleSummary(report *models.StaleReport) pages.StaleSummary {
+	summary := pages.StaleSummary{
+		Counts:      make(map[string]int, len(report.Counts)),
+		InboxDays:   report.Thresholds.InboxDays,
+		WaitingDays: report.Thresholds.WaitingDays,
+		SomedayDays: report.Thresholds.SomedayDays,
+	}
+
+	for kind, count := range report.Counts {
+		summary.Counts[string(kind)] = count
+	}
+
+	for _, item := range report.Items {
+		url := "/tasks/" + item.ID
+		if item.Kind == models.StaleProject {
+			url = "/projects/" + item.ID
+		}
+		summary.Items = append(summary.Items, pages.StaleItemInfo{
+			Kind:   string(item.Kind),
+			Title:  item.Title,
+			URL:    url,
+			Reason: item.Reason,
+		})
+	}
+
+	return summary
 }
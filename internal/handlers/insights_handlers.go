@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// InsightsHandler serves analyses of the user's system, such as stale item detection
+type InsightsHandler struct {
+	store      models.TaskStore
+	projects   models.ProjectStore
+	thresholds models.StaleThresholdStore
+}
+
+// NewInsightsHandler creates a new insights handler
+func NewInsightsHandler(store models.TaskStore, projects models.ProjectStore, thresholds models.StaleThresholdStore, templatesDir string) (*InsightsHandler, error) {
+	return &InsightsHandler{
+		store:      store,
+		projects:   projects,
+		thresholds: thresholds,
+	}, nil
+}
+
+// RegisterRoutes registers all insights routes
+func (h *InsightsHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/insights", func(r chi.Router) {
+		r.Get("/stale", h.StaleItemsAPI)
+		r.Get("/stale/thresholds", h.GetStaleThresholdsAPI)
+		r.Put("/stale/thresholds", h.UpdateStaleThresholdsAPI)
+	})
+}
+
+// findStaleItems analyzes the user's tasks and projects with their own thresholds
+func findStaleItems(r *http.Request, store models.TaskStore, projects models.ProjectStore, thresholds models.StaleThresholdStore, userID string) (*models.StaleReport, error) {
+	userThresholds, err := thresholds.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	return models.FindStaleItems(r.Context(), store, projects, userID, userThresholds, time.Now())
+}
+
+// StaleItemsAPI returns the items that need attention. The optional "kind" query
+// parameter takes a comma separated list of stale kinds to include.
+func (h *InsightsHandler) StaleItemsAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	report, err := findStaleItems(r, h.store, h.projects, h.thresholds, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if kinds := r.URL.Query().Get("kind"); kinds != "" {
+		wanted := make(map[models.StaleKind]bool)
+		for _, kind := range strings.Split(kinds, ",") {
+			wanted[models.StaleKind(strings.TrimSpace(kind))] = true
+		}
+
+		items := []models.StaleItem{}
+		for _, item := range report.Items {
+			if wanted[item.Kind] {
+				items = append(items, item)
+			}
+		}
+		report.Items = items
+		for kind := range report.Counts {
+			if !wanted[kind] {
+				delete(report.Counts, kind)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetStaleThresholdsAPI returns the user's stale item thresholds
+func (h *InsightsHandler) GetStaleThresholdsAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	thresholds, err := h.thresholds.Get(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(thresholds)
+}
+
+// UpdateStaleThresholdsAPI changes the user's stale item thresholds; omitted fields keep their value
+func (h *InsightsHandler) UpdateStaleThresholdsAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	thresholds, err := h.thresholds.Get(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&thresholds); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := thresholds.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.thresholds.Save(user.ID, thresholds); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(thresholds)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// currentUser returns the authenticated user from the request context, or nil
+func currentUser(r *http.Request) *models.User {
+	user, ok := r.Context().Value("user").(*models.User)
+	if !ok {
+		return nil
+	}
+	return user
+}
+
+// loadOwnedTask fetches the task named by the given URL parameter for the current user.
+// Tasks that belong to other users are reported as not found so their existence isn't leaked.
+// On failure it writes the error response and returns false.
+func loadOwnedTask(w http.ResponseWriter, r *http.Request, store models.TaskStore, param string) (*models.Task, *models.User, bool) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	task, err := store.GetForUser(chi.URLParam(r, param), user.ID)
+	if err != nil {
+		if err == models.ErrTaskNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return nil, nil, false
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	return task, user, true
+}
+
+// loadOwnedProject fetches the project named by the given URL parameter for the current user.
+// Like loadOwnedTask, other users' projects are reported as not found.
+func loadOwnedProject(w http.ResponseWriter, r *http.Request, store models.ProjectStore, param string) (*models.Project, *models.User, bool) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	project, err := store.GetForUser(chi.URLParam(r, param), user.ID)
+	if err != nil {
+		if err == models.ErrProjectNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return nil, nil, false
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	return project, user, true
+}
@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// ownershipApp serves every route of the app as alice, with bob's data in the same stores
+type ownershipApp struct {
+	t      *testing.T
+	router chi.Router
+
+	tasks         *models.MemoryTaskStore
+	projects      *models.MemoryProjectStore
+	reviews       *models.MemoryReviewStore
+	contexts      *models.MemoryContextStore
+	perspectives  *models.MemoryPerspectiveStore
+	notifications *models.MemoryNotificationStore
+	attachments   *models.MemoryAttachmentStore
+	calendar      *models.MemoryCalendarTokenStore
+	caldav        *models.MemoryCalDAVTokenStore
+	undo          *models.UndoStack
+}
+
+func newOwnershipApp(t *testing.T) *ownershipApp {
+	t.Helper()
+
+	app := &ownershipApp{
+		t:             t,
+		tasks:         models.NewMemoryTaskStore(),
+		projects:      models.NewMemoryProjectStore(),
+		reviews:       models.NewMemoryReviewStore(),
+		contexts:      models.NewMemoryContextStore(),
+		perspectives:  models.NewMemoryPerspectiveStore(),
+		notifications: models.NewMemoryNotificationStore(),
+		attachments:   models.NewMemoryAttachmentStore(),
+		calendar:      models.NewMemoryCalendarTokenStore(),
+		caldav:        models.NewMemoryCalDAVTokenStore(),
+	}
+	app.undo = models.NewUndoStack(models.NewMemoryUndoStore(), time.Hour, 10)
+	thresholds := models.NewMemoryStaleThresholdStore()
+	reminders := models.NewMemoryReminderStore()
+	templatesDir := "../templates"
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	taskHandler, err := NewTaskHandler(app.tasks, app.projects, app.contexts, app.undo, templatesDir)
+	must(err)
+	projectHandler, err := NewProjectHandler(app.tasks, app.projects, app.contexts, app.undo, templatesDir)
+	must(err)
+	reviewHandler, err := NewReviewHandler(app.tasks, app.projects, app.reviews, thresholds, templatesDir)
+	must(err)
+	trashHandler, err := NewTrashHandler(app.tasks, app.projects, 30, templatesDir)
+	must(err)
+	perspectiveHandler, err := NewPerspectiveHandler(app.tasks, app.projects, app.perspectives, templatesDir)
+	must(err)
+	reminderHandler, err := NewReminderHandler(reminders, app.notifications, templatesDir)
+	must(err)
+	captureHandler, err := NewCaptureHandler(app.tasks, models.NewMemoryCaptureTokenStore(), app.attachments, "", templatesDir)
+	must(err)
+	backupHandler, err := NewBackupHandler(app.tasks, app.projects, app.reviews, app.contexts, app.perspectives, thresholds, reminders, templatesDir)
+	must(err)
+	calendarHandler := NewCalendarHandler(app.tasks, app.calendar, app.contexts)
+	caldavHandler := NewCalDAVHandler(app.tasks, app.caldav, app.contexts, app.undo)
+
+	r := chi.NewRouter()
+	calendarHandler.RegisterPublicRoutes(r)
+	caldavHandler.RegisterPublicRoutes(r)
+	r.Group(func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := context.WithValue(r.Context(), "user", &models.User{ID: "alice"})
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+		})
+		taskHandler.RegisterRoutes(r)
+		taskHandler.RegisterTaskStatusRoutes(r)
+		projectHandler.RegisterRoutes(r)
+		reviewHandler.RegisterRoutes(r)
+		trashHandler.RegisterRoutes(r)
+		NewUndoHandler(app.tasks, app.undo).RegisterRoutes(r)
+		perspectiveHandler.RegisterRoutes(r)
+		NewContextHandler(app.tasks, app.projects, app.contexts).RegisterRoutes(r)
+		reminderHandler.RegisterRoutes(r)
+		captureHandler.RegisterRoutes(r)
+		backupHandler.RegisterRoutes(r)
+		calendarHandler.RegisterRoutes(r)
+		caldavHandler.RegisterRoutes(r)
+	})
+	app.router = r
+	return app
+}
+
+// serve sends a request through the router; headers are given as name, value pairs
+func (app *ownershipApp) serve(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	app.router.ServeHTTP(w, req)
+	return w
+}
+
+// userData is what a user owns, created through the stores
+type userData struct {
+	task, other, deleted *models.Task
+	project, deletedProj *models.Project
+	review               *models.ReviewSession
+	perspective          *models.Perspective
+	context              *models.ContextDefinition
+	notification         *models.Notification
+	attachment           *models.Attachment
+	undoToken            string
+	calendarToken        string
+	caldavPassword       string
+}
+
+func (app *ownershipApp) seed(userID string) *userData {
+	t := app.t
+	t.Helper()
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	newTask := func(title string) *models.Task {
+		task := models.NewTask(title, "", userID)
+		task.Status = models.StatusNext
+		must(app.tasks.SaveForUser(task, userID))
+		return task
+	}
+
+	d := &userData{}
+	d.project = models.NewProject(userID+"'s project", "", userID)
+	must(app.projects.SaveForUser(d.project, userID))
+	d.deletedProj = models.NewProject(userID+"'s old project", "", userID)
+	must(app.projects.SaveForUser(d.deletedProj, userID))
+	must(app.projects.Delete(d.deletedProj.ID))
+
+	d.task = newTask(userID + "'s task")
+	due := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	d.task.DueDate = &due
+	must(app.tasks.SaveForUser(d.task, userID))
+	d.other = newTask(userID + "'s other task")
+	d.deleted = newTask(userID + "'s deleted task")
+	must(app.tasks.Delete(d.deleted.ID))
+
+	d.review = models.NewReviewSession(userID, models.ReviewSnapshot{})
+	must(app.reviews.Save(d.review))
+	d.perspective = models.NewPerspective(userID+"'s perspective", "status:next", userID)
+	must(app.perspectives.SaveForUser(d.perspective, userID))
+	d.context = models.NewContextDefinition(models.Context(userID+"-phone"), userID)
+	must(app.contexts.SaveForUser(d.context, userID))
+	d.notification = models.NewNotification(userID, d.task.ID, "Due soon", "")
+	must(app.notifications.Create(d.notification))
+	d.attachment = models.NewAttachment(d.task, "notes.txt", "text/plain", []byte("notes"))
+	must(app.attachments.Save(d.attachment))
+
+	action := app.undo.Begin(userID, "Task updated")
+	action.Record(d.task)
+	d.task.Title = userID + "'s task"
+	must(app.tasks.SaveForUser(d.task, userID))
+	token, err := app.undo.Push(action)
+	must(err)
+	d.undoToken = token
+
+	d.calendarToken, err = app.calendar.GetOrCreate(userID)
+	must(err)
+	d.caldavPassword, err = app.caldav.GetOrCreate(userID)
+	must(err)
+	return d
+}
+
+// placeholders maps {name.field} in a path or body to the IDs of a user's data
+func (d *userData) placeholders(name string) []string {
+	return []string{
+		"{" + name + ".task}", d.task.ID,
+		"{" + name + ".deleted}", d.deleted.ID,
+		"{" + name + ".project}", d.project.ID,
+		"{" + name + ".deletedProj}", d.deletedProj.ID,
+		"{" + name + ".review}", d.review.ID,
+		"{" + name + ".perspective}", d.perspective.ID,
+		"{" + name + ".context}", d.context.ID,
+		"{" + name + ".notification}", d.notification.ID,
+		"{" + name + ".attachment}", d.attachment.ID,
+		"{" + name + ".undo}", d.undoToken,
+	}
+}
+
+func TestOtherUsersDataIsNotFound(t *testing.T) {
+	form := []string{"Content-Type", "application/x-www-form-urlencoded"}
+	tests := []struct {
+		method  string
+		path    string
+		body    string
+		headers []string
+	}{
+		// Tasks
+		{method: "GET", path: "/api/tasks/{bob.task}"},
+		{method: "PUT", path: "/api/tasks/{bob.task}", body: `{"title":"Mine now","userId":"alice"}`},
+		{method: "DELETE", path: "/api/tasks/{bob.task}"},
+		{method: "GET", path: "/api/tasks/{bob.task}/blockers"},
+		{method: "PUT", path: "/api/tasks/{bob.task}/blockers/{alice.task}"},
+		{method: "PUT", path: "/api/tasks/{alice.task}/blockers/{bob.task}"},
+		{method: "DELETE", path: "/api/tasks/{bob.task}/blockers/{alice.task}"},
+		{method: "GET", path: "/api/tasks/{bob.task}/subtasks"},
+		{method: "POST", path: "/api/tasks/{bob.task}/subtasks", body: `{"title":"Sneaky"}`},
+		{method: "PUT", path: "/api/tasks/{bob.task}/parent", body: `{"parentId":""}`},
+		{method: "PUT", path: "/api/tasks/{alice.task}/parent", body: `{"parentId":"{bob.task}"}`},
+		{method: "PUT", path: "/api/tasks/{bob.task}/next"},
+		{method: "PUT", path: "/api/tasks/{bob.task}/waiting"},
+		{method: "PUT", path: "/api/tasks/{bob.task}/someday"},
+		{method: "PUT", path: "/api/tasks/{bob.task}/done"},
+		{method: "PUT", path: "/api/tasks/{bob.task}/scheduled", body: `{"date":"2026-12-01"}`},
+		{method: "POST", path: "/api/tasks/bulk", body: `{"ids":["{alice.task}","{bob.task}"],"action":"done"}`},
+		{method: "PUT", path: "/api/tasks/{bob.task}/project"},
+		{method: "GET", path: "/tasks/{bob.task}"},
+		{method: "GET", path: "/tasks/{bob.task}/edit"},
+		{method: "GET", path: "/tasks/{bob.task}/subtasks"},
+		{method: "POST", path: "/tasks/{bob.task}/subtasks", body: "title=Sneaky", headers: form},
+		{method: "POST", path: "/tasks/{bob.task}/auto-complete", body: "autoComplete=true", headers: form},
+
+		// History
+		{method: "GET", path: "/api/tasks/{bob.task}/history"},
+		{method: "GET", path: "/tasks/{bob.task}/history"},
+
+		// Attachments
+		{method: "GET", path: "/api/tasks/{bob.task}/attachments"},
+		{method: "GET", path: "/api/attachments/{bob.attachment}"},
+		{method: "GET", path: "/tasks/{bob.task}/attachments"},
+
+		// Projects
+		{method: "GET", path: "/api/projects/{bob.project}"},
+		{method: "PUT", path: "/api/projects/{bob.project}", body: `{"title":"Mine now"}`},
+		{method: "DELETE", path: "/api/projects/{bob.project}"},
+		{method: "PUT", path: "/api/projects/{bob.project}/state", body: `{"state":"on_hold"}`},
+		{method: "PUT", path: "/api/projects/{bob.project}/complete"},
+		{method: "PUT", path: "/api/projects/{bob.project}/archive"},
+		{method: "PUT", path: "/api/projects/{bob.project}/tasks/{alice.task}"},
+		{method: "PUT", path: "/api/projects/{alice.project}/tasks/{bob.task}"},
+		{method: "GET", path: "/api/projects/{bob.project}/dependencies"},
+		{method: "GET", path: "/projects/{bob.project}"},
+		{method: "GET", path: "/projects/{bob.project}/edit"},
+		{method: "POST", path: "/projects/{bob.project}/tasks", body: "title=Sneaky", headers: form},
+		{method: "GET", path: "/projects/{bob.project}/dependencies"},
+
+		// Reviews
+		{method: "GET", path: "/api/reviews/{bob.review}"},
+		{method: "PUT", path: "/api/reviews/{bob.review}/steps/collect", body: `{"completed":true}`},
+		{method: "PUT", path: "/api/reviews/{bob.review}/finish"},
+
+		// Trash
+		{method: "POST", path: "/api/trash/tasks/{bob.deleted}/restore"},
+		{method: "DELETE", path: "/api/trash/tasks/{bob.deleted}"},
+		{method: "POST", path: "/api/trash/projects/{bob.deletedProj}/restore"},
+		{method: "DELETE", path: "/api/trash/projects/{bob.deletedProj}"},
+
+		// Undo
+		{method: "POST", path: "/api/undo/{bob.undo}"},
+
+		// Perspectives
+		{method: "GET", path: "/api/perspectives/{bob.perspective}"},
+		{method: "PUT", path: "/api/perspectives/{bob.perspective}", body: `{"name":"Mine now","query":"status:next"}`},
+		{method: "DELETE", path: "/api/perspectives/{bob.perspective}"},
+		{method: "GET", path: "/api/perspectives/{bob.perspective}/tasks"},
+		{method: "GET", path: "/perspectives/{bob.perspective}"},
+
+		// Contexts
+		{method: "GET", path: "/api/contexts/{bob.context}"},
+		{method: "PUT", path: "/api/contexts/{bob.context}", body: `{"name":"mine-now"}`},
+		{method: "DELETE", path: "/api/contexts/{bob.context}"},
+		{method: "POST", path: "/api/contexts/{bob.context}/merge", body: `{"into":"{alice.context}"}`},
+		{method: "POST", path: "/api/contexts/{alice.context}/merge", body: `{"into":"{bob.context}"}`},
+		{method: "GET", path: "/contexts/{bob.context}"},
+
+		// Notifications
+		{method: "PUT", path: "/api/notifications/{bob.notification}/read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			app := newOwnershipApp(t)
+			alice := app.seed("alice")
+			bob := app.seed("bob")
+			ids := strings.NewReplacer(append(alice.placeholders("alice"), bob.placeholders("bob")...)...)
+
+			w := app.serve(tt.method, ids.Replace(tt.path), ids.Replace(tt.body), tt.headers...)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want %d: %s", w.Code, http.StatusNotFound, strings.TrimSpace(w.Body.String()))
+			}
+			app.assertUntouched(bob)
+		})
+	}
+}
+
+func TestOtherUsersDataIsNotFoundOverCalDAV(t *testing.T) {
+	app := newOwnershipApp(t)
+	alice := app.seed("alice")
+	bob := app.seed("bob")
+
+	auth := []string{"Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte("alice:"+alice.caldavPassword))}
+	href := "/caldav/next/" + bob.task.ID + ".ics"
+	for _, method := range []string{"GET", "HEAD", "PROPFIND", "PUT", "DELETE"} {
+		t.Run(method, func(t *testing.T) {
+			body := ""
+			if method == "PUT" {
+				body = todo("UID:"+bob.task.ID, "SUMMARY:Mine now")
+			}
+			w := app.serve(method, href, body, append(auth, "Depth", "0")...)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+			}
+		})
+	}
+
+	t.Run("multiget", func(t *testing.T) {
+		body := `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+			`<d:prop><c:calendar-data/></d:prop><d:href>` + href + `</d:href></c:calendar-multiget>`
+		w := app.serve("REPORT", "/caldav/next/", body, auth...)
+		if !strings.Contains(w.Body.String(), "<d:status>HTTP/1.1 404 Not Found</d:status>") {
+			t.Errorf("bob's task is not reported missing:\n%s", w.Body.String())
+		}
+	})
+
+	t.Run("listing", func(t *testing.T) {
+		w := app.serve("PROPFIND", "/caldav/next/", "", append(auth, "Depth", "1")...)
+		if strings.Contains(w.Body.String(), bob.task.ID) {
+			t.Errorf("alice's list holds bob's task:\n%s", w.Body.String())
+		}
+	})
+
+	t.Run("bob's password", func(t *testing.T) {
+		w := app.serve("PROPFIND", "/caldav/", "", "Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("alice:"+bob.caldavPassword)), "Depth", "0")
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+	})
+
+	app.assertUntouched(bob)
+}
+
+func TestExportsLeaveOutOtherUsersData(t *testing.T) {
+	app := newOwnershipApp(t)
+	alice := app.seed("alice")
+	bob := app.seed("bob")
+
+	for _, path := range []string{
+		"/api/export?format=json",
+		"/api/export?format=markdown",
+		"/calendar/" + url.PathEscape(alice.calendarToken) + ".ics",
+	} {
+		w := app.serve("GET", path, "")
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s: status = %d, want %d", path, w.Code, http.StatusOK)
+			continue
+		}
+		if !strings.Contains(w.Body.String(), alice.task.ID) && !strings.Contains(w.Body.String(), "alice's task") {
+			t.Errorf("GET %s: alice's own task is missing", path)
+		}
+		for _, leaked := range []string{bob.task.ID, "bob's task", bob.project.ID, "bob's project"} {
+			if strings.Contains(w.Body.String(), leaked) {
+				t.Errorf("GET %s: holds %q", path, leaked)
+			}
+		}
+	}
+}
+
+// assertUntouched checks that a user's data survived requests made by another user
+func (app *ownershipApp) assertUntouched(d *userData) {
+	t := app.t
+	t.Helper()
+
+	task, err := app.tasks.GetForUser(d.task.ID, d.task.UserID)
+	if err != nil {
+		t.Fatalf("task: %v", err)
+	}
+	if task.Title != d.task.Title || task.Status != models.StatusNext || task.UserID != d.task.UserID ||
+		task.ProjectID != "" || task.ParentID != "" || len(task.BlockedBy) != 0 {
+		t.Errorf("task changed: %+v", task)
+	}
+	children, err := app.tasks.GetChildren(context.Background(), task.ID, task.UserID)
+	if err != nil || len(children) != 0 {
+		t.Errorf("task got sub-tasks: %v, %v", children, err)
+	}
+
+	project, err := app.projects.GetForUser(d.project.ID, d.project.UserID)
+	if err != nil {
+		t.Fatalf("project: %v", err)
+	}
+	if project.Title != d.project.Title || project.State != d.project.State {
+		t.Errorf("project changed: %+v", project)
+	}
+	deleted, err := app.tasks.ListDeleted(context.Background(), d.task.UserID)
+	if err != nil || len(deleted) != 1 {
+		t.Errorf("trash holds %d tasks, want 1 (%v)", len(deleted), err)
+	}
+
+	review, err := app.reviews.GetForUser(d.review.ID, d.review.UserID)
+	if err != nil || review.IsFinished() {
+		t.Errorf("review changed: %+v, %v", review, err)
+	}
+	if _, err := app.perspectives.GetForUser(d.perspective.ID, d.perspective.UserID); err != nil {
+		t.Errorf("perspective: %v", err)
+	}
+	def, err := app.contexts.GetForUser(d.context.ID, d.context.UserID)
+	if err != nil || def.Name != d.context.Name {
+		t.Errorf("context changed: %+v, %v", def, err)
+	}
+	if actions, err := app.undo.List(d.task.UserID); err != nil || len(actions) != 1 {
+		t.Errorf("undo stack holds %d actions, want 1 (%v)", len(actions), err)
+	}
+	if unread, err := app.notifications.CountUnread(d.task.UserID); err != nil || unread != 1 {
+		t.Errorf("%d unread notifications, want 1 (%v)", unread, err)
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/parser"
+	"github.com/melihkorkmaz/gtd/internal/views/partials"
+)
+
+// requestLocation returns the time zone relative dates are resolved in: the IANA zone name a client
+// sent (browsers report it through Intl.DateTimeFormat), falling back to the X-Timezone header and
+// then to the server's local zone
+func requestLocation(r *http.Request, name string) (*time.Location, error) {
+	if name == "" {
+		name = r.Header.Get("X-Timezone")
+	}
+	if name == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// parseCapture parses quick-capture text for a user and looks up the project it names.
+// The project is nil when none was named or no open project matches.
+func (h *TaskHandler) parseCapture(r *http.Request, text, timezone, userID string) (*parser.Result, *models.Project, error) {
+	loc, err := requestLocation(r, timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := parser.Parse(text, time.Now().In(loc))
+	if result.Project == "" {
+		return result, nil, nil
+	}
+
+	projects, err := h.projects.List(r.Context(), models.ProjectFilter{
+		UserID: userID,
+		States: []models.ProjectState{models.ProjectActive, models.ProjectOnHold},
+	}, models.ProjectSortTitleAsc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, parser.MatchProject(result.Project, projects), nil
+}
+
+// applyCapture sets the parsed fields on a task. A project name that matched no project
+// is kept in the title so nothing that was captured gets lost.
+func applyCapture(task *models.Task, result *parser.Result, project *models.Project) {
+	result.Apply(task)
+
+	if project != nil {
+		task.ProjectID = project.ID
+		return
+	}
+	for _, detection := range result.Detected {
+		if detection.Kind == parser.DetectProject {
+			task.Title += " " + detection.Text
+		}
+	}
+}
+
+// ParsePreviewResponse shows what quick-capture parsing found in a line of text
+type ParsePreviewResponse struct {
+	*parser.Result
+	ProjectID    string `json:"projectId,omitempty"`
+	ProjectTitle string `json:"projectTitle,omitempty"`
+}
+
+// PreviewParseAPI shows what would be detected in quick-capture text without creating a task.
+// Query parameters: text and timezone (IANA name such as "Europe/Istanbul", defaults to the server zone).
+func (h *TaskHandler) PreviewParseAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	result, project, err := h.parseCapture(r, r.URL.Query().Get("text"), r.URL.Query().Get("timezone"), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := ParsePreviewResponse{Result: result}
+	if project != nil {
+		response.ProjectID = project.ID
+		response.ProjectTitle = project.Title
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// PreviewParseFragment renders the detected fields below the quick capture title as the user types.
+// It reads the title and timezone form fields the quick capture form sends along.
+func (h *TaskHandler) PreviewParseFragment(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	result, project, err := h.parseCapture(r, r.URL.Query().Get("title"), r.URL.Query().Get("timezone"), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var items []partials.CapturePreviewItem
+	for _, detection := range result.Detected {
+		item := partials.CapturePreviewItem{Label: string(detection.Kind), Value: detection.Text}
+		switch detection.Kind {
+		case parser.DetectDue:
+			item.Value = formatCaptureDate(*result.DueDate)
+		case parser.DetectScheduled:
+			item.Value = formatCaptureDate(*result.ScheduledDate)
+		case parser.DetectEstimate:
+			item.Value = fmt.Sprintf("%d min", result.TimeEstimate)
+		case parser.DetectProject:
+			if project != nil {
+				item.Value = project.Title
+			} else {
+				item.Value = result.Project + " (no matching project)"
+				item.Warning = true
+			}
+		}
+		items = append(items, item)
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	partials.CapturePreview(result.Title, items).Render(r.Context(), w)
+}
+
+// formatCaptureDate formats a parsed date, leaving out midnight times of date-only input
+func formatCaptureDate(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 {
+		return t.Format("Mon, Jan 2 2006")
+	}
+	return t.Format("Mon, Jan 2 2006 3:04 PM")
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/views/pages"
+	"github.com/melihkorkmaz/gtd/internal/views/partials"
+)
+
+// PerspectiveHandler manages saved search perspectives
+type PerspectiveHandler struct {
+	store        models.TaskStore
+	projects     models.ProjectStore
+	perspectives models.PerspectiveStore
+}
+
+// NewPerspectiveHandler creates a new perspective handler
+func NewPerspectiveHandler(store models.TaskStore, projects models.ProjectStore, perspectives models.PerspectiveStore, templatesDir string) (*PerspectiveHandler, error) {
+	return &PerspectiveHandler{
+		store:        store,
+		projects:     projects,
+		perspectives: perspectives,
+	}, nil
+}
+
+// RegisterRoutes registers all perspective routes
+func (h *PerspectiveHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/perspectives", func(r chi.Router) {
+		r.Get("/", h.ListPerspectivesAPI)
+		r.Post("/", h.CreatePerspectiveAPI)
+		r.Get("/{id}", h.GetPerspectiveAPI)
+		r.Put("/{id}", h.UpdatePerspectiveAPI)
+		r.Delete("/{id}", h.DeletePerspectiveAPI)
+		r.Get("/{id}/tasks", h.PerspectiveTasksAPI)
+	})
+
+	r.Get("/perspectives/nav", h.PerspectiveNavFragment)
+	r.Get("/perspectives/{id}", h.PerspectivePage)
+}
+
+// PerspectiveRequest is the request body for creating or updating a perspective
+type PerspectiveRequest struct {
+	Name    string `json:"name"`
+	Query   string `json:"query"`   // Search in the syntax of the task search box
+	Sort    string `json:"sort"`    // Any task list sort; best matches first when empty
+	GroupBy string `json:"groupBy"` // "project", "context", "due" or empty
+}
+
+// apply copies the request onto a perspective
+func (req PerspectiveRequest) apply(perspective *models.Perspective) {
+	perspective.Name = req.Name
+	perspective.Query = req.Query
+	perspective.Sort = models.TaskSort(req.Sort)
+	perspective.GroupBy = models.PerspectiveGrouping(req.GroupBy)
+}
+
+// loadOwnedPerspective fetches the perspective named in the URL for the current user
+func (h *PerspectiveHandler) loadOwnedPerspective(w http.ResponseWriter, r *http.Request) (*models.Perspective, *models.User, bool) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	perspective, err := h.perspectives.GetForUser(chi.URLParam(r, "id"), user.ID)
+	if err != nil {
+		if err == models.ErrPerspectiveNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return nil, nil, false
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	return perspective, user, true
+}
+
+// ListPerspectivesAPI returns the current user's perspectives
+func (h *PerspectiveHandler) ListPerspectivesAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	perspectives, err := h.perspectives.List(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(perspectives)
+}
+
+// CreatePerspectiveAPI saves a new perspective
+func (h *PerspectiveHandler) CreatePerspectiveAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req PerspectiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	perspective := models.NewPerspective(req.Name, req.Query, user.ID)
+	req.apply(perspective)
+	if err := h.perspectives.SaveForUser(perspective, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(perspective)
+}
+
+// GetPerspectiveAPI returns a single perspective
+func (h *PerspectiveHandler) GetPerspectiveAPI(w http.ResponseWriter, r *http.Request) {
+	perspective, _, ok := h.loadOwnedPerspective(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(perspective)
+}
+
+// UpdatePerspectiveAPI replaces a perspective's name, query, sort and grouping
+func (h *PerspectiveHandler) UpdatePerspectiveAPI(w http.ResponseWriter, r *http.Request) {
+	perspective, user, ok := h.loadOwnedPerspective(w, r)
+	if !ok {
+		return
+	}
+
+	var req PerspectiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.apply(perspective)
+	perspective.UpdatedAt = time.Now()
+	if err := h.perspectives.SaveForUser(perspective, user.ID); err != nil {
+		if err == models.ErrPerspectiveNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(perspective)
+}
+
+// DeletePerspectiveAPI removes a perspective. Its tasks are left alone.
+func (h *PerspectiveHandler) DeletePerspectiveAPI(w http.ResponseWriter, r *http.Request) {
+	perspective, user, ok := h.loadOwnedPerspective(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.perspectives.Delete(perspective.ID, user.ID); err != nil {
+		if err == models.ErrPerspectiveNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// perspectiveGroups runs a perspective and groups its tasks
+func (h *PerspectiveHandler) perspectiveGroups(r *http.Request, perspective *models.Perspective) ([]models.TaskGroup, error) {
+	now := time.Now()
+	tasks, err := perspective.Tasks(r.Context(), h.store, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var titles map[string]string
+	if perspective.GroupBy == models.GroupProject {
+		projects, err := h.projects.List(r.Context(), models.ProjectFilter{UserID: perspective.UserID}, models.ProjectSortTitleAsc)
+		if err != nil {
+			return nil, err
+		}
+		titles = make(map[string]string, len(projects))
+		for _, project := range projects {
+			titles[project.ID] = project.Title
+		}
+	}
+
+	return models.GroupTasks(tasks, perspective.GroupBy, titles, now), nil
+}
+
+// PerspectiveTasksAPI returns a perspective's tasks in its groups
+func (h *PerspectiveHandler) PerspectiveTasksAPI(w http.ResponseWriter, r *http.Request) {
+	perspective, _, ok := h.loadOwnedPerspective(w, r)
+	if !ok {
+		return
+	}
+
+	groups, err := h.perspectiveGroups(r, perspective)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(groups)
+}
+
+// PerspectivePage renders a perspective as a task list
+func (h *PerspectiveHandler) PerspectivePage(w http.ResponseWriter, r *http.Request) {
+	if currentUser(r) == nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	perspective, _, ok := h.loadOwnedPerspective(w, r)
+	if !ok {
+		return
+	}
+
+	groups, err := h.perspectiveGroups(r, perspective)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pageGroups := make([]pages.TaskGroup, len(groups))
+	for i, group := range groups {
+		pageGroups[i] = pages.TaskGroup{Name: group.Name, Tasks: make([]partials.TaskCardInfo, len(group.Tasks))}
+		for j, task := range group.Tasks {
+			pageGroups[i].Tasks[j] = getTaskCardInfo(task)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	pages.TasksListPage(perspective.Name, pageGroups).Render(r.Context(), w)
+}
+
+// PerspectiveNavFragment renders the sidebar entries of the user's perspectives with their task counts
+func (h *PerspectiveHandler) PerspectiveNavFragment(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	perspectives, err := h.perspectives.List(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	items := make([]partials.PerspectiveNavItem, 0, len(perspectives))
+	for _, perspective := range perspectives {
+		count, err := perspective.Count(r.Context(), h.store, now)
+		if err != nil {
+			// A perspective that can't run still gets its entry, so it can be opened and fixed
+			count = 0
+		}
+		items = append(items, partials.PerspectiveNavItem{
+			ID:    perspective.ID,
+			Name:  perspective.Name,
+			Count: count,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	partials.PerspectiveNav(items).Render(r.Context(), w)
+}
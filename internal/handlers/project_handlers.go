@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -15,13 +17,19 @@ import (
 
 // ProjectHandler manages project-related HTTP endpoints
 type ProjectHandler struct {
-	store models.TaskStore
+	store    models.TaskStore
+	projects models.ProjectStore
+	contexts models.ContextStore // Project contexts sent to the API must be defined here
+	undo     *models.UndoStack
 }
 
 // NewProjectHandler creates a new project handler
-func NewProjectHandler(store models.TaskStore, templatesDir string) (*ProjectHandler, error) {
+func NewProjectHandler(store models.TaskStore, projects models.ProjectStore, contexts models.ContextStore, undo *models.UndoStack, templatesDir string) (*ProjectHandler, error) {
 	return &ProjectHandler{
-		store: store,
+		store:    store,
+		projects: projects,
+		contexts: contexts,
+		undo:     undo,
 	}, nil
 }
 
@@ -34,11 +42,16 @@ func (h *ProjectHandler) RegisterRoutes(r chi.Router) {
 		r.Get("/{id}", h.GetProjectAPI)
 		r.Put("/{id}", h.UpdateProjectAPI)
 		r.Delete("/{id}", h.DeleteProjectAPI)
+		r.Put("/{id}/state", h.SetProjectStateAPI)
 		r.Put("/{id}/complete", h.CompleteProjectAPI)
 		r.Put("/{id}/archive", h.ArchiveProjectAPI)
 		r.Put("/{id}/tasks/{taskId}", h.AddTaskToProjectAPI)
+		r.Get("/{id}/dependencies", h.ProjectDependenciesAPI)
 	})
 
+	// Converting a task creates a project, so it lives with the project routes
+	r.Put("/api/tasks/{id}/project", h.ConvertTaskToProjectAPI)
+
 	// HTML routes for server-side rendering
 	r.Route("/projects", func(r chi.Router) {
 		r.Get("/", h.ListProjectsPage)
@@ -46,9 +59,47 @@ func (h *ProjectHandler) RegisterRoutes(r chi.Router) {
 		r.Get("/{id}", h.ViewProjectPage)
 		r.Get("/{id}/edit", h.EditProjectForm)
 		r.Post("/{id}/tasks", h.AddTaskToProjectSubmit)
+		r.Get("/{id}/dependencies", h.ProjectDependenciesFragment)
 	})
 }
 
+// projectSummary is a project together with the progress of its tasks
+type projectSummary struct {
+	Project        *models.Project
+	TaskCount      int
+	CompletedCount int
+}
+
+// CompletionPercentage returns the share of the project's tasks that are done
+func (s projectSummary) CompletionPercentage() int {
+	if s.TaskCount == 0 {
+		return 0
+	}
+	return (s.CompletedCount * 100) / s.TaskCount
+}
+
+// parseProjectFilter converts the filter query parameter into the states to list.
+// An empty filter or "open" lists active and on-hold projects, "all" lists every state,
+// anything else is a comma-separated list of states.
+func parseProjectFilter(filter string) ([]models.ProjectState, error) {
+	switch filter {
+	case "", "open":
+		return []models.ProjectState{models.ProjectActive, models.ProjectOnHold}, nil
+	case "all":
+		return nil, nil
+	}
+
+	var states []models.ProjectState
+	for _, value := range strings.Split(filter, ",") {
+		state, err := models.ParseProjectState(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %s", value)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
 // ListProjectsAPI returns a JSON list of all projects
 func (h *ProjectHandler) ListProjectsAPI(w http.ResponseWriter, r *http.Request) {
 	// Get user from context if authenticated
@@ -59,53 +110,112 @@ func (h *ProjectHandler) ListProjectsAPI(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Get filter and sort parameters
-	filter := r.URL.Query().Get("filter")
+	states, err := parseProjectFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	sort := r.URL.Query().Get("sort")
 
-	// Get all tasks with project status for this user
-	projects, err := h.getProjects(filter, sort, user.ID)
+	// Get the matching projects for this user
+	summaries, err := h.getProjects(r.Context(), states, sort, user.ID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	projects := make([]*models.Project, len(summaries))
+	for i, summary := range summaries {
+		projects[i] = summary.Project
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(projects)
 }
 
-// getProjects retrieves projects with optional filtering and sorting
-func (h *ProjectHandler) getProjects(filter, sort string, userID string) ([]*models.Task, error) {
-	// First get all tasks with project status for this user
-	tasks, err := h.store.GetByStatusAndUserID(models.StatusProject, userID)
+// getProjects retrieves projects in the given states along with their progress.
+// Besides the store's sort orders, order can be "progress-asc" or "progress-desc".
+func (h *ProjectHandler) getProjects(ctx context.Context, states []models.ProjectState, order string, userID string) ([]projectSummary, error) {
+	storeOrder, _ := models.ParseProjectSort(order)
+	projects, err := h.projects.List(ctx, models.ProjectFilter{
+		UserID: userID,
+		States: states,
+	}, storeOrder)
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter projects if needed
-	var filteredProjects []*models.Task
-	if filter == "" || filter == "all" {
-		filteredProjects = tasks
-	} else {
-		// In a real implementation, we would filter by project properties
-		// For now, we'll just return all projects
-		filteredProjects = tasks
+	summaries := make([]projectSummary, 0, len(projects))
+	for _, project := range projects {
+		// Get tasks for this project
+		projectTasks, err := h.getProjectTasks(ctx, project.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		summary := projectSummary{Project: project, TaskCount: len(projectTasks)}
+		for _, task := range projectTasks {
+			if task.Status == models.StatusDone {
+				summary.CompletedCount++
+			}
+		}
+		summaries = append(summaries, summary)
 	}
 
-	// Sort projects if needed
-	// In a real implementation, we would sort by created date, progress, etc.
-	// For now, we'll just return the projects as is
+	// Progress depends on the project's tasks, so it can't be sorted by the store
+	switch strings.ReplaceAll(order, "_", "-") {
+	case "progress-asc":
+		sort.SliceStable(summaries, func(i, j int) bool {
+			return summaries[i].CompletionPercentage() < summaries[j].CompletionPercentage()
+		})
+	case "progress-desc":
+		sort.SliceStable(summaries, func(i, j int) bool {
+			return summaries[i].CompletionPercentage() > summaries[j].CompletionPercentage()
+		})
+	}
+
+	return summaries, nil
+}
+
+// projectInfo converts a project summary to the view model used by the templates
+func projectInfo(summary projectSummary) partials.ProjectInfo {
+	project := summary.Project
+
+	// Convert Context type to string slice
+	contexts := make([]string, len(project.Contexts))
+	for i, ctx := range project.Contexts {
+		contexts[i] = string(ctx)
+	}
 
-	return filteredProjects, nil
+	return partials.ProjectInfo{
+		ID:                   project.ID,
+		Title:                project.Title,
+		Description:          project.Description,
+		Outcome:              project.Outcome,
+		Notes:                project.Notes,
+		State:                string(project.State),
+		DueDate:              project.DueDate,
+		ReviewDate:           project.ReviewDate,
+		Contexts:             contexts,
+		Tags:                 project.Tags,
+		CreatedAt:            project.CreatedAt,
+		TaskCount:            summary.TaskCount,
+		CompletedTaskCount:   summary.CompletedCount,
+		CompletionPercentage: summary.CompletionPercentage(),
+	}
 }
 
 // CreateProjectAPI creates a new project from JSON input
 func (h *ProjectHandler) CreateProjectAPI(w http.ResponseWriter, r *http.Request) {
 	var request struct {
-		Title       string    `json:"title"`
-		Description string    `json:"description"`
-		DueDate     time.Time `json:"dueDate,omitempty"`
-		Contexts    []string  `json:"contexts,omitempty"`
-		Tags        []string  `json:"tags,omitempty"`
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		Outcome     string     `json:"outcome,omitempty"`
+		Notes       string     `json:"notes,omitempty"`
+		DueDate     time.Time  `json:"dueDate,omitempty"`
+		ReviewDate  *time.Time `json:"reviewDate,omitempty"`
+		Contexts    []string   `json:"contexts,omitempty"`
+		Tags        []string   `json:"tags,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -120,9 +230,11 @@ func (h *ProjectHandler) CreateProjectAPI(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Create a new task with project status
-	project := models.NewTask(request.Title, request.Description, user.ID)
-	project.MarkAsProject()
+	// Create a new active project
+	project := models.NewProject(request.Title, request.Description, user.ID)
+	project.Outcome = request.Outcome
+	project.Notes = request.Notes
+	project.ReviewDate = request.ReviewDate
 
 	// Set due date if provided
 	if !request.DueDate.IsZero() {
@@ -133,11 +245,19 @@ func (h *ProjectHandler) CreateProjectAPI(w http.ResponseWriter, r *http.Request
 	for _, ctx := range request.Contexts {
 		project.Contexts = append(project.Contexts, models.Context(ctx))
 	}
+	if !resolveContexts(w, r, h.contexts, &project.Contexts, user.ID) {
+		return
+	}
 
 	// Set tags
 	project.Tags = request.Tags
 
-	if err := h.store.Save(project); err != nil {
+	if err := project.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.projects.SaveForUser(project, user.ID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -149,16 +269,8 @@ func (h *ProjectHandler) CreateProjectAPI(w http.ResponseWriter, r *http.Request
 
 // GetProjectAPI returns a single project as JSON
 func (h *ProjectHandler) GetProjectAPI(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	project, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
-
-	// Verify that it's a project
-	if project.Status != models.StatusProject {
-		http.Error(w, "Not a project", http.StatusBadRequest)
+	project, _, ok := loadOwnedProject(w, r, h.projects, "id")
+	if !ok {
 		return
 	}
 
@@ -166,34 +278,83 @@ func (h *ProjectHandler) GetProjectAPI(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(project)
 }
 
+// projectUpdateTargets maps the JSON fields a client may update to the project fields they decode into
+func projectUpdateTargets(p *models.Project) map[string]interface{} {
+	return map[string]interface{}{
+		"title":       &p.Title,
+		"description": &p.Description,
+		"outcome":     &p.Outcome,
+		"notes":       &p.Notes,
+		"contexts":    &p.Contexts,
+		"tags":        &p.Tags,
+		"dueDate":     &p.DueDate,
+		"reviewDate":  &p.ReviewDate,
+	}
+}
+
+// projectReadOnlyFields are accepted in update requests but never applied
+var projectReadOnlyFields = map[string]bool{
+	"id":          true,
+	"userId":      true,
+	"createdAt":   true,
+	"updatedAt":   true,
+	"completedAt": true,
+	"deletedAt":   true,
+}
+
+// applyProjectUpdate copies the whitelisted fields of an update request onto a project.
+// A "state" field goes through SetState so the completion date stays consistent.
+func applyProjectUpdate(project *models.Project, fields map[string]json.RawMessage) error {
+	if raw, ok := fields["state"]; ok {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("invalid value for state: %v", err)
+		}
+		state, err := models.ParseProjectState(value)
+		if err != nil {
+			return err
+		}
+		if state != project.State {
+			if err := project.SetState(state); err != nil {
+				return err
+			}
+		}
+		delete(fields, "state")
+	}
+
+	if err := applyFieldUpdates(fields, projectUpdateTargets(project), projectReadOnlyFields); err != nil {
+		return err
+	}
+
+	return project.Validate()
+}
+
 // UpdateProjectAPI updates a project from JSON input
 func (h *ProjectHandler) UpdateProjectAPI(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-
 	// First get the existing project
-	project, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	project, user, ok := loadOwnedProject(w, r, h.projects, "id")
+	if !ok {
 		return
 	}
 
-	// Verify that it's a project
-	if project.Status != models.StatusProject {
-		http.Error(w, "Not a project", http.StatusBadRequest)
+	// Decode the update request
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Decode the update request
-	if err := json.NewDecoder(r.Body).Decode(project); err != nil {
+	// Only apply the fields a client is allowed to change
+	if err := applyProjectUpdate(project, fields); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	// Ensure it remains a project
-	project.Status = models.StatusProject
+	if _, ok := fields["contexts"]; ok && !resolveContexts(w, r, h.contexts, &project.Contexts, user.ID) {
+		return
+	}
 
 	// Save the updated project
-	if err := h.store.Save(project); err != nil {
+	if err := h.projects.SaveForUser(project, user.ID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -202,53 +363,69 @@ func (h *ProjectHandler) UpdateProjectAPI(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(project)
 }
 
-// DeleteProjectAPI deletes a project
+// DeleteProjectAPI moves a project to the trash; with cascade=true its tasks go along with it
 func (h *ProjectHandler) DeleteProjectAPI(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
+	project, user, ok := loadOwnedProject(w, r, h.projects, "id")
+	if !ok {
+		return
+	}
 
-	// First check if it's a project
-	project, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	// Delete the project
+	cascade := r.URL.Query().Get("cascade") == "true"
+	if _, err := models.DeleteProject(r.Context(), h.store, h.projects, project, user.ID, cascade); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if project.Status != models.StatusProject {
-		http.Error(w, "Not a project", http.StatusBadRequest)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetProjectStateRequest represents the request to move a project to another lifecycle state
+type SetProjectStateRequest struct {
+	State string `json:"state"`
+}
+
+// SetProjectStateAPI moves a project to the requested lifecycle state
+func (h *ProjectHandler) SetProjectStateAPI(w http.ResponseWriter, r *http.Request) {
+	var req SetProjectStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Delete the project
-	if err := h.store.Delete(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	state, err := models.ParseProjectState(req.State)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	h.changeProjectState(w, r, state)
 }
 
 // CompleteProjectAPI marks a project as complete
 func (h *ProjectHandler) CompleteProjectAPI(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
+	h.changeProjectState(w, r, models.ProjectCompleted)
+}
 
-	// First get the project
-	project, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+// ArchiveProjectAPI archives a project
+func (h *ProjectHandler) ArchiveProjectAPI(w http.ResponseWriter, r *http.Request) {
+	h.changeProjectState(w, r, models.ProjectArchived)
+}
+
+// changeProjectState loads the project from the URL, moves it to the given state and returns it as JSON
+func (h *ProjectHandler) changeProjectState(w http.ResponseWriter, r *http.Request, state models.ProjectState) {
+	project, user, ok := loadOwnedProject(w, r, h.projects, "id")
+	if !ok {
 		return
 	}
 
-	// Verify that it's a project
-	if project.Status != models.StatusProject {
-		http.Error(w, "Not a project", http.StatusBadRequest)
+	if err := project.SetState(state); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Mark as done
-	project.MarkAsDone()
-
 	// Save the updated project
-	if err := h.store.Save(project); err != nil {
+	if err := h.projects.SaveForUser(project, user.ID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -257,72 +434,88 @@ func (h *ProjectHandler) CompleteProjectAPI(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(project)
 }
 
-// ArchiveProjectAPI archives a project
-func (h *ProjectHandler) ArchiveProjectAPI(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
+// ConvertTaskToProjectAPI turns a task into a project with the same ID. Its sub-tasks move into the
+// project, and the task is removed for good so it can't come back from the trash next to a project
+// with its ID; for the same reason the conversion can't be undone.
+func (h *ProjectHandler) ConvertTaskToProjectAPI(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
+		return
+	}
 
-	// First get the project
-	project, err := h.store.Get(id)
+	children, err := h.store.GetChildren(r.Context(), task.ID, user.ID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Verify that it's a project
-	if project.Status != models.StatusProject {
-		http.Error(w, "Not a project", http.StatusBadRequest)
+	// Trash the task first: a conflicting edit then stops the conversion before anything else changes
+	task.Delete()
+	if err := h.store.SaveForUser(task, user.ID); err != nil {
+		writeTaskSaveError(w, h.store, task, user.ID, err)
 		return
 	}
 
-	// For now, we'll just mark it as done since we don't have a separate archive status
-	// In a real implementation, you might want to add an "archived" flag to the Task model
-	project.MarkAsDone()
+	project := models.ProjectFromTask(task)
+	if err := h.projects.SaveForUser(project, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	// Save the updated project
-	if err := h.store.Save(project); err != nil {
+	// The direct sub-tasks become the project's top-level tasks
+	for _, child := range children {
+		child.ParentID = ""
+		if err := moveTaskTree(r.Context(), h.store, child, project.ID, user.ID, nil); err != nil {
+			writeTaskSaveError(w, h.store, child, user.ID, err)
+			return
+		}
+	}
+
+	if err := h.store.Purge(task.ID, user.ID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(project)
+	json.NewEncoder(w).Encode(StatusChangeResponse{
+		Success: true,
+		Message: "Task converted to Project",
+		TaskID:  project.ID,
+		Status:  string(models.StatusProject),
+	})
 }
 
 // AddTaskToProjectAPI adds a task to a project
 func (h *ProjectHandler) AddTaskToProjectAPI(w http.ResponseWriter, r *http.Request) {
-	projectID := chi.URLParam(r, "id")
-	taskID := chi.URLParam(r, "taskId")
-
 	// Get project
-	project, err := h.store.Get(projectID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	project, user, ok := loadOwnedProject(w, r, h.projects, "id")
+	if !ok {
 		return
 	}
 
-	// Verify that it's a project
-	if project.Status != models.StatusProject {
-		http.Error(w, "Not a project", http.StatusBadRequest)
+	// Get task
+	task, _, ok := loadOwnedTask(w, r, h.store, "taskId")
+	if !ok {
 		return
 	}
-
-	// Get task
-	task, err := h.store.Get(taskID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	if !checkTaskVersion(w, r, task) {
 		return
 	}
 
-	// Add task to project
-	task.ProjectID = project.ID
-
-	// Save the updated task
-	if err := h.store.Save(task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// Add task to project; its sub-tasks come along
+	action := h.undo.Begin(user.ID, "Task moved to "+project.Title)
+	action.Record(task)
+	if err := moveTaskTree(r.Context(), h.store, task, project.ID, user.ID, action); err != nil {
+		writeTaskSaveError(w, h.store, task, user.ID, err)
 		return
 	}
 
+	pushUndo(w, h.undo, action)
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(task))
 	json.NewEncoder(w).Encode(task)
 }
 
@@ -341,58 +534,23 @@ func (h *ProjectHandler) ListProjectsPage(w http.ResponseWriter, r *http.Request
 	filter := r.URL.Query().Get("filter")
 	sort := r.URL.Query().Get("sort")
 
-	// Get all tasks with project status for this user
-	projects, err := h.getProjects(filter, sort, user.ID)
+	states, err := parseProjectFilter(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Get the matching projects for this user
+	summaries, err := h.getProjects(r.Context(), states, sort, user.ID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Enhance projects with additional metadata
-	enhancedProjects := make([]partials.ProjectInfo, 0, len(projects))
-	for _, project := range projects {
-		// Get tasks for this project
-		projectTasks, err := h.getProjectTasks(project.ID, user.ID)
-		if err != nil {
-			fmt.Printf("Error getting tasks for project %s: %v\n", project.ID, err)
-			continue
-		}
-
-		// Count completed tasks
-		completedCount := 0
-		for _, task := range projectTasks {
-			if task.Status == models.StatusDone {
-				completedCount++
-			}
-		}
-
-		// Calculate completion percentage
-		completionPercentage := 0
-		if len(projectTasks) > 0 {
-			completionPercentage = (completedCount * 100) / len(projectTasks)
-		}
-
-		// Convert Context type to string slice
-		contexts := make([]string, len(project.Contexts))
-		for i, ctx := range project.Contexts {
-			contexts[i] = string(ctx)
-		}
-
-		enhancedProject := partials.ProjectInfo{
-			ID:                   project.ID,
-			Title:                project.Title,
-			Description:          project.Description,
-			Status:               string(project.Status),
-			DueDate:              project.DueDate,
-			Contexts:             contexts,
-			Tags:                 project.Tags,
-			CreatedAt:            project.CreatedAt,
-			TaskCount:            len(projectTasks),
-			CompletedTaskCount:   completedCount,
-			CompletionPercentage: completionPercentage,
-		}
-
-		enhancedProjects = append(enhancedProjects, enhancedProject)
+	enhancedProjects := make([]partials.ProjectInfo, 0, len(summaries))
+	for _, summary := range summaries {
+		enhancedProjects = append(enhancedProjects, projectInfo(summary))
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -400,9 +558,9 @@ func (h *ProjectHandler) ListProjectsPage(w http.ResponseWriter, r *http.Request
 
 	// Add user to context and use the base template
 	ctx := context.WithValue(r.Context(), "user", user)
-	
+
 	// Render the page
-	component := pages.ProjectsPage(enhancedProjects)
+	component := pages.ProjectsPage(enhancedProjects, filter, sort)
 	if err := component.Render(ctx, w); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -410,22 +568,14 @@ func (h *ProjectHandler) ListProjectsPage(w http.ResponseWriter, r *http.Request
 }
 
 // getProjectTasks retrieves all tasks associated with a project
-func (h *ProjectHandler) getProjectTasks(projectID string, userID string) ([]*models.Task, error) {
-	// Get all tasks for this user
-	allTasks, err := h.store.GetAllByUserID(userID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Filter tasks for this project
-	var projectTasks []*models.Task
-	for _, task := range allTasks {
-		if task.ProjectID == projectID {
-			projectTasks = append(projectTasks, task)
-		}
-	}
-
-	return projectTasks, nil
+func (h *ProjectHandler) getProjectTasks(ctx context.Context, projectID string, userID string) ([]*models.Task, error) {
+	return models.QueryAll(ctx, h.store, models.TaskQuery{
+		Filter: models.TaskFilter{
+			UserID:    userID,
+			ProjectID: projectID,
+		},
+		Sort: models.SortCreatedAsc,
+	})
 }
 
 // CreateProjectSubmit handles form submission for creating a new project
@@ -437,7 +587,9 @@ func (h *ProjectHandler) CreateProjectSubmit(w http.ResponseWriter, r *http.Requ
 
 	title := r.FormValue("title")
 	description := r.FormValue("description")
+	outcome := r.FormValue("outcome")
 	dueDateStr := r.FormValue("due_date")
+	reviewDateStr := r.FormValue("review_date")
 
 	// Get user from context if authenticated
 	user, ok := r.Context().Value("user").(*models.User)
@@ -446,9 +598,9 @@ func (h *ProjectHandler) CreateProjectSubmit(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Create a new task with project status
-	project := models.NewTask(title, description, user.ID)
-	project.MarkAsProject()
+	// Create a new active project
+	project := models.NewProject(title, description, user.ID)
+	project.Outcome = outcome
 
 	// Set due date if provided
 	if dueDateStr != "" {
@@ -460,8 +612,18 @@ func (h *ProjectHandler) CreateProjectSubmit(w http.ResponseWriter, r *http.Requ
 		project.DueDate = &dueDate
 	}
 
+	// Set review date if provided
+	if reviewDateStr != "" {
+		reviewDate, err := time.Parse("2006-01-02", reviewDateStr)
+		if err != nil {
+			http.Error(w, "Invalid review date format", http.StatusBadRequest)
+			return
+		}
+		project.ReviewDate = &reviewDate
+	}
+
 	// Save project
-	if err := h.store.Save(project); err != nil {
+	if err := h.projects.SaveForUser(project, user.ID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -481,67 +643,36 @@ func (h *ProjectHandler) ViewProjectPage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	id := chi.URLParam(r, "id")
-	project, err := h.store.Get(id)
+	project, err := h.projects.GetForUser(chi.URLParam(r, "id"), user.ID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
-	// Verify that it's a project
-	if project.Status != models.StatusProject {
-		http.Error(w, "Not a project", http.StatusBadRequest)
-		return
-	}
-
 	// Get tasks for this project
-	projectTasks, err := h.getProjectTasks(project.ID, user.ID)
+	projectTasks, err := h.getProjectTasks(r.Context(), project.ID, user.ID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Count completed tasks
-	completedCount := 0
+	summary := projectSummary{Project: project, TaskCount: len(projectTasks)}
 	for _, task := range projectTasks {
 		if task.Status == models.StatusDone {
-			completedCount++
+			summary.CompletedCount++
 		}
 	}
 
-	// Calculate completion percentage
-	completionPercentage := 0
-	if len(projectTasks) > 0 {
-		completionPercentage = (completedCount * 100) / len(projectTasks)
-	}
-
 	// Get available tasks (not assigned to any project)
-	availableTasks, err := h.getAvailableTasks(user.ID)
+	availableTasks, err := h.getAvailableTasks(r.Context(), user.ID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Convert Context type to string slice
-	contexts := make([]string, len(project.Contexts))
-	for i, ctx := range project.Contexts {
-		contexts[i] = string(ctx)
-	}
-
 	// Create project info for templ
-	enhancedProject := partials.ProjectInfo{
-		ID:                   project.ID,
-		Title:                project.Title,
-		Description:          project.Description,
-		Status:               string(project.Status),
-		DueDate:              project.DueDate,
-		Contexts:             contexts,
-		Tags:                 project.Tags,
-		CreatedAt:            project.CreatedAt,
-		TaskCount:            len(projectTasks),
-		CompletedTaskCount:   completedCount,
-		CompletionPercentage: completionPercentage,
-	}
+	enhancedProject := projectInfo(summary)
 
 	// Convert tasks
 	templTasks := make([]partials.TaskInfo, len(projectTasks))
@@ -579,7 +710,7 @@ func (h *ProjectHandler) ViewProjectPage(w http.ResponseWriter, r *http.Request)
 
 	// Add user to context and use the base template
 	ctx := context.WithValue(r.Context(), "user", user)
-	
+
 	// Render the page
 	component := pages.ProjectDetailPage(enhancedProject, templTasks, templAvailableTasks)
 	if err := component.Render(ctx, w); err != nil {
@@ -589,45 +720,30 @@ func (h *ProjectHandler) ViewProjectPage(w http.ResponseWriter, r *http.Request)
 }
 
 // getAvailableTasks retrieves tasks that aren't already assigned to a project
-func (h *ProjectHandler) getAvailableTasks(userID string) ([]*models.Task, error) {
-	// Get all tasks for this user
-	allTasks, err := h.store.GetAllByUserID(userID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Filter tasks that don't have a project ID and are not projects themselves
-	var availableTasks []*models.Task
-	for _, task := range allTasks {
-		if task.ProjectID == "" && task.Status != models.StatusProject {
-			availableTasks = append(availableTasks, task)
-		}
-	}
-
-	return availableTasks, nil
+func (h *ProjectHandler) getAvailableTasks(ctx context.Context, userID string) ([]*models.Task, error) {
+	// Only open tasks without a project ID
+	return models.QueryAll(ctx, h.store, models.TaskQuery{
+		Filter: models.TaskFilter{
+			UserID:    userID,
+			NoProject: true,
+			Statuses: []models.TaskStatus{
+				models.StatusInbox,
+				models.StatusNext,
+				models.StatusWaiting,
+				models.StatusScheduled,
+				models.StatusSomeday,
+			},
+		},
+	})
 }
 
 // EditProjectForm renders the form to edit a project
 func (h *ProjectHandler) EditProjectForm(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	project, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
-
-	// Verify that it's a project
-	if project.Status != models.StatusProject {
-		http.Error(w, "Not a project", http.StatusBadRequest)
+	project, _, ok := loadOwnedProject(w, r, h.projects, "id")
+	if !ok {
 		return
 	}
 
-	// Convert Context type to string slice (for future use)
-	contexts := make([]string, len(project.Contexts))
-	for i, ctx := range project.Contexts {
-		contexts[i] = string(ctx)
-	}
-
 	// TODO: Implement edit project form
 	// We'll need project info when we create the edit form
 
@@ -639,18 +755,9 @@ func (h *ProjectHandler) EditProjectForm(w http.ResponseWriter, r *http.Request)
 
 // AddTaskToProjectSubmit handles form submission for adding a task to a project
 func (h *ProjectHandler) AddTaskToProjectSubmit(w http.ResponseWriter, r *http.Request) {
-	projectID := chi.URLParam(r, "id")
-
 	// Get project
-	project, err := h.store.Get(projectID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
-
-	// Verify that it's a project
-	if project.Status != models.StatusProject {
-		http.Error(w, "Not a project", http.StatusBadRequest)
+	project, user, ok := loadOwnedProject(w, r, h.projects, "id")
+	if !ok {
 		return
 	}
 
@@ -664,13 +771,6 @@ func (h *ProjectHandler) AddTaskToProjectSubmit(w http.ResponseWriter, r *http.R
 	status := r.FormValue("status")
 	dueDateStr := r.FormValue("due_date")
 
-	// Get user from context if authenticated
-	user, ok := r.Context().Value("user").(*models.User)
-	if !ok || user == nil {
-		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
-		return
-	}
-
 	// Create a new task
 	task := models.NewTask(title, description, user.ID)
 
@@ -683,7 +783,7 @@ func (h *ProjectHandler) AddTaskToProjectSubmit(w http.ResponseWriter, r *http.R
 	}
 
 	// Set project ID
-	task.ProjectID = projectID
+	task.ProjectID = project.ID
 
 	// Set due date if provided
 	if dueDateStr != "" {
@@ -696,13 +796,13 @@ func (h *ProjectHandler) AddTaskToProjectSubmit(w http.ResponseWriter, r *http.R
 	}
 
 	// Save task
-	if err := h.store.Save(task); err != nil {
+	if err := h.store.SaveForUser(task, user.ID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Redirect back to the project view
-	http.Redirect(w, r, "/projects/"+projectID, http.StatusSeeOther)
+	http.Redirect(w, r, "/projects/"+project.ID, http.StatusSeeOther)
 }
 
 // generateProjectsPageHtml creates the HTML for the projects page
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+func TestConvertTaskToProjectMovesSubtasks(t *testing.T) {
+	app := newOwnershipApp(t)
+
+	save := func(title, parentID string) *models.Task {
+		t.Helper()
+		task := models.NewTask(title, "", "alice")
+		task.ParentID = parentID
+		if err := app.tasks.SaveForUser(task, "alice"); err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+	task := save("Plan the trip", "")
+	child := save("Book flights", task.ID)
+	grandchild := save("Compare prices", child.ID)
+
+	w := app.serve("PUT", "/api/tasks/"+task.ID+"/project", "", "If-Match", `"0"`)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: status = %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+
+	w = app.serve("PUT", "/api/tasks/"+task.ID+"/project", "", "If-Match", taskETag(task))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+
+	if _, err := app.projects.GetForUser(task.ID, "alice"); err != nil {
+		t.Fatalf("project: %v", err)
+	}
+	deleted, err := app.tasks.ListDeleted(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("trash holds %d tasks, want the converted task gone for good", len(deleted))
+	}
+
+	for _, tt := range []struct {
+		task     *models.Task
+		parentID string
+	}{
+		{task: child, parentID: ""},
+		{task: grandchild, parentID: child.ID},
+	} {
+		stored, err := app.tasks.GetForUser(tt.task.ID, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.ProjectID != task.ID || stored.ParentID != tt.parentID {
+			t.Errorf("%s: project = %q, parent = %q, want %q, %q", stored.Title, stored.ProjectID, stored.ParentID, task.ID, tt.parentID)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// maxRecurrencePreview limits how many occurrences a preview may list
+const maxRecurrencePreview = 50
+
+// RecurrencePreviewResponse lists the upcoming occurrences of a recurrence rule
+type RecurrencePreviewResponse struct {
+	Rule        string      `json:"rule"` // Canonical RRULE form of the requested rule
+	Start       time.Time   `json:"start"`
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+// PreviewRecurrenceAPI lists the next occurrences of a recurrence rule.
+// Query parameters: rule (RRULE or shorthand), start (YYYY-MM-DD, defaults to today) and count (default 5).
+func (h *TaskHandler) PreviewRecurrenceAPI(w http.ResponseWriter, r *http.Request) {
+	rule, err := models.ParseRecurrenceRule(r.URL.Query().Get("rule"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	if value := r.URL.Query().Get("start"); value != "" {
+		start, err = time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			http.Error(w, "Invalid start date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+
+	count := 5
+	if value := r.URL.Query().Get("count"); value != "" {
+		count, err = strconv.Atoi(value)
+		if err != nil || count < 1 {
+			http.Error(w, "Invalid count", http.StatusBadRequest)
+			return
+		}
+		if count > maxRecurrencePreview {
+			count = maxRecurrencePreview
+		}
+	}
+
+	occurrences := rule.Occurrences(start, count)
+	if occurrences == nil {
+		occurrences = []time.Time{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RecurrencePreviewResponse{
+		Rule:        rule.String(),
+		Start:       start,
+		Occurrences: occurrences,
+	})
+}
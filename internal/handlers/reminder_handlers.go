@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/views/pages"
+)
+
+// notificationPageLimit caps the number of notifications shown on the notifications page
+const notificationPageLimit = 100
+
+// ReminderHandler manages reminder settings and the in-app notification inbox
+type ReminderHandler struct {
+	reminders     models.ReminderStore
+	notifications models.NotificationStore
+}
+
+// NewReminderHandler creates a new reminder handler
+func NewReminderHandler(reminders models.ReminderStore, notifications models.NotificationStore, templatesDir string) (*ReminderHandler, error) {
+	return &ReminderHandler{
+		reminders:     reminders,
+		notifications: notifications,
+	}, nil
+}
+
+// RegisterRoutes registers all reminder and notification routes
+func (h *ReminderHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/reminders", func(r chi.Router) {
+		r.Get("/settings", h.GetReminderSettingsAPI)
+		r.Put("/settings", h.UpdateReminderSettingsAPI)
+	})
+
+	r.Route("/api/notifications", func(r chi.Router) {
+		r.Get("/", h.ListNotificationsAPI)
+		r.Put("/read-all", h.MarkAllNotificationsReadAPI)
+		r.Put("/{id}/read", h.MarkNotificationReadAPI)
+	})
+
+	r.Get("/notifications", h.NotificationsPage)
+}
+
+// GetReminderSettingsAPI returns the user's reminder settings
+func (h *ReminderHandler) GetReminderSettingsAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := h.reminders.GetSettings(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// UpdateReminderSettingsAPI changes the user's reminder settings; omitted fields keep their value
+func (h *ReminderHandler) UpdateReminderSettingsAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := h.reminders.GetSettings(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	settings.UserID = user.ID
+
+	if err := settings.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.reminders.SaveSettings(settings); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// ListNotificationsAPI returns the user's notifications, newest first.
+// Pass unread=true to only return unread notifications.
+func (h *ReminderHandler) ListNotificationsAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+	notifications, err := h.notifications.ListByUser(r.Context(), user.ID, unreadOnly, notificationPageLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	unread, err := h.notifications.CountUnread(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"notifications": notifications,
+		"unread":        unread,
+	})
+}
+
+// MarkNotificationReadAPI marks a notification as read
+func (h *ReminderHandler) MarkNotificationReadAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.notifications.MarkRead(chi.URLParam(r, "id"), user.ID); err != nil {
+		if err == models.ErrNotificationNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkAllNotificationsReadAPI marks all of the user's notifications as read
+func (h *ReminderHandler) MarkAllNotificationsReadAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.notifications.MarkAllRead(user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// NotificationsPage renders the notification inbox
+func (h *ReminderHandler) NotificationsPage(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	notifications, err := h.notifications.ListByUser(r.Context(), user.ID, false, notificationPageLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	infos := make([]pages.NotificationInfo, len(notifications))
+	for i, notification := range notifications {
+		infos[i] = pages.NotificationInfo{
+			ID:        notification.ID,
+			TaskID:    notification.TaskID,
+			Title:     notification.Title,
+			Body:      notification.Body,
+			CreatedAt: notification.CreatedAt,
+			Read:      notification.IsRead(),
+		}
+	}
+
+	// Add user to context and use the base template
+	ctx := context.WithValue(r.Context(), "user", user)
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := pages.NotificationsPage(infos).Render(ctx, w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/views/pages"
+)
+
+// reviewHistoryLimit caps the number of past reviews shown on the history page
+const reviewHistoryLimit = 52
+
+// ReviewHandler manages the weekly review endpoints
+type ReviewHandler struct {
+	store      models.TaskStore
+	projects   models.ProjectStore
+	reviews    models.ReviewStore
+	thresholds models.StaleThresholdStore
+}
+
+// NewReviewHandler creates a new weekly review handler
+func NewReviewHandler(store models.TaskStore, projects models.ProjectStore, reviews models.ReviewStore, thresholds models.StaleThresholdStore, templatesDir string) (*ReviewHandler, error) {
+	return &ReviewHandler{
+		store:      store,
+		projects:   projects,
+		reviews:    reviews,
+		thresholds: thresholds,
+	}, nil
+}
+
+// RegisterRoutes registers all weekly review routes
+func (h *ReviewHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/reviews", func(r chi.Router) {
+		r.Get("/", h.ListReviewsAPI)
+		r.Post("/", h.StartReviewAPI)
+		r.Get("/active", h.ActiveReviewAPI)
+		r.Get("/{id}", h.GetReviewAPI)
+		r.Put("/{id}/steps/{step}", h.UpdateReviewStepAPI)
+		r.Put("/{id}/finish", h.FinishReviewAPI)
+	})
+
+	r.Get("/weekly-review", h.WeeklyReviewPage)
+	r.Get("/weekly-review/history", h.ReviewHistoryPage)
+}
+
+// ReviewResponse is a review session along with its progress
+type ReviewResponse struct {
+	*models.ReviewSession
+	Progress int `json:"progress"`
+}
+
+// UpdateReviewStepRequest represents the request to check off a review step
+type UpdateReviewStepRequest struct {
+	Completed bool    `json:"completed"`
+	Notes     *string `json:"notes,omitempty"`
+}
+
+// FinishReviewRequest represents the request to finish a review
+type FinishReviewRequest struct {
+	Notes string `json:"notes"`
+}
+
+// sendReview writes a review session as JSON
+func sendReview(w http.ResponseWriter, status int, session *models.ReviewSession) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ReviewResponse{
+		ReviewSession: session,
+		Progress:      session.Progress(),
+	})
+}
+
+// loadOwnedReview fetches the review session named in the URL for the current user
+func (h *ReviewHandler) loadOwnedReview(w http.ResponseWriter, r *http.Request) (*models.ReviewSession, *models.User, bool) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	session, err := h.reviews.GetForUser(chi.URLParam(r, "id"), user.ID)
+	if err != nil {
+		if err == models.ErrReviewNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return nil, nil, false
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	return session, user, true
+}
+
+// takeSnapshot counts the user's open items using their stale thresholds
+func (h *ReviewHandler) takeSnapshot(r *http.Request, userID string) (models.ReviewSnapshot, error) {
+	thresholds, err := h.thresholds.Get(userID)
+	if err != nil {
+		return models.ReviewSnapshot{}, err
+	}
+	return models.TakeReviewSnapshot(r.Context(), h.store, h.projects, userID, thresholds)
+}
+
+// StartReviewAPI starts a new weekly review, or returns the one already in progress
+func (h *ReviewHandler) StartReviewAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Resume instead of starting a second review
+	active, err := h.reviews.GetActive(user.ID)
+	if err == nil {
+		sendReview(w, http.StatusOK, active)
+		return
+	}
+	if err != models.ErrReviewNotFound {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	snapshot, err := h.takeSnapshot(r, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	session := models.NewReviewSession(user.ID, snapshot)
+	if err := h.reviews.Save(session); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sendReview(w, http.StatusCreated, session)
+}
+
+// ActiveReviewAPI returns the review in progress so it can be resumed
+func (h *ReviewHandler) ActiveReviewAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	session, err := h.reviews.GetActive(user.ID)
+	if err != nil {
+		if err == models.ErrReviewNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sendReview(w, http.StatusOK, session)
+}
+
+// GetReviewAPI returns a single review session as JSON
+func (h *ReviewHandler) GetReviewAPI(w http.ResponseWriter, r *http.Request) {
+	session, _, ok := h.loadOwnedReview(w, r)
+	if !ok {
+		return
+	}
+
+	sendReview(w, http.StatusOK, session)
+}
+
+// ListReviewsAPI returns the user's past and current reviews, newest first
+func (h *ReviewHandler) ListReviewsAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessions, err := h.reviews.ListByUser(r.Context(), user.ID, reviewHistoryLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]ReviewResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = ReviewResponse{ReviewSession: session, Progress: session.Progress()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateReviewStepAPI records the completion and notes of a review step
+func (h *ReviewHandler) UpdateReviewStepAPI(w http.ResponseWriter, r *http.Request) {
+	session, _, ok := h.loadOwnedReview(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateReviewStepRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := session.UpdateStep(chi.URLParam(r, "step"), req.Completed, req.Notes); err != nil {
+		status := http.StatusBadRequest
+		if err == models.ErrReviewFinished {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if err := h.reviews.Save(session); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sendReview(w, http.StatusOK, session)
+}
+
+// FinishReviewAPI closes a review and snapshots the system at its end
+func (h *ReviewHandler) FinishReviewAPI(w http.ResponseWriter, r *http.Request) {
+	session, user, ok := h.loadOwnedReview(w, r)
+	if !ok {
+		return
+	}
+
+	var req FinishReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := h.takeSnapshot(r, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := session.Finish(snapshot, req.Notes); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if err := h.reviews.Save(session); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sendReview(w, http.StatusOK, session)
+}
+
+// reviewSnapshotInfo converts a snapshot to the view model used by the templates
+func reviewSnapshotInfo(snapshot models.ReviewSnapshot) pages.ReviewSnapshotInfo {
+	return pages.ReviewSnapshotInfo{
+		Inbox:                     snapshot.Inbox,
+		NextActions:               snapshot.NextActions,
+		Waiting:                   snapshot.Waiting,
+		StaleWaiting:              snapshot.StaleWaiting,
+		Someday:                   snapshot.Someday,
+		ActiveProjects:            snapshot.ActiveProjects,
+		ProjectsWithoutNextAction: snapshot.ProjectsWithoutNextAction,
+	}
+}
+
+// reviewSessionInfo converts a review session to the view model used by the templates
+func reviewSessionInfo(session *models.ReviewSession) pages.ReviewSessionInfo {
+	info := pages.ReviewSessionInfo{
+		ID:          session.ID,
+		StartedAt:   session.StartedAt,
+		CompletedAt: session.CompletedAt,
+		Progress:    session.Progress(),
+		Notes:       session.Notes,
+		Start:       reviewSnapshotInfo(session.StartSnapshot),
+	}
+
+	for _, step := range session.Steps {
+		info.Steps = append(info.Steps, pages.ReviewStepInfo{
+			Key:       step.Key,
+			Title:     step.Title,
+			Completed: step.Completed,
+			Notes:     step.Notes,
+		})
+	}
+
+	if session.EndSnapshot != nil {
+		end := reviewSnapshotInfo(*session.EndSnapshot)
+		info.End = &end
+	}
+
+	return info
+}
+
+// WeeklyReviewPage renders the weekly review page, resuming the review in progress if there is one
+func (h *ReviewHandler) WeeklyReviewPage(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	var info *pages.ReviewSessionInfo
+	session, err := h.reviews.GetActive(user.ID)
+	switch err {
+	case nil:
+		sessionInfo := reviewSessionInfo(session)
+		info = &sessionInfo
+	case models.ErrReviewNotFound:
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Add user to context and use the base template
+	ctx := context.WithValue(r.Context(), "user", user)
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := pages.WeeklyReviewPage(info).Render(ctx, w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ReviewHistoryPage renders the list of past weekly reviews
+func (h *ReviewHandler) ReviewHistoryPage(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	sessions, err := h.reviews.ListByUser(r.Context(), user.ID, reviewHistoryLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	infos := make([]pages.ReviewSessionInfo, len(sessions))
+	for i, session := range sessions {
+		infos[i] = reviewSessionInfo(session)
+	}
+
+	// Add user to context and use the base template
+	ctx := context.WithValue(r.Context(), "user", user)
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := pages.ReviewHistoryPage(infos).Render(ctx, w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
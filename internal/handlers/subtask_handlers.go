@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/views/partials"
+)
+
+// CreateSubtaskRequest is the request body for adding a sub-task
+type CreateSubtaskRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// SubtasksResponse lists a task's sub-tasks at every depth along with their rolled-up progress
+type SubtasksResponse struct {
+	Progress models.SubtaskProgress `json:"progress"`
+	Tasks    []*models.Task         `json:"tasks"` // Level by level; ParentID tells where each one hangs
+}
+
+// SetParentRequest moves a task under another task, or to the top level when ParentID is empty
+type SetParentRequest struct {
+	ParentID string `json:"parentId"`
+}
+
+// ListSubtasksAPI returns every sub-task of a task
+func (h *TaskHandler) ListSubtasksAPI(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+
+	subtree, err := h.store.GetSubtree(r.Context(), task.ID, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SubtasksResponse{
+		Progress: models.Progress(subtree),
+		Tasks:    subtree,
+	})
+}
+
+// CreateSubtaskAPI adds a sub-task under a task
+func (h *TaskHandler) CreateSubtaskAPI(w http.ResponseWriter, r *http.Request) {
+	parent, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+
+	var req CreateSubtaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	child := models.NewSubtask(parent, req.Title, req.Description)
+	if err := h.store.SaveForUser(child, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(child)
+}
+
+// SetParentAPI moves a task, with its sub-tasks, under another task. The moved tasks join the new
+// parent's project.
+func (h *TaskHandler) SetParentAPI(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
+		return
+	}
+
+	var req SetParentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	parent, err := models.CheckParent(r.Context(), h.store, task, req.ParentID, user.ID)
+	if err != nil {
+		switch err {
+		case models.ErrSubtaskCycle:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case models.ErrTaskNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	projectID := task.ProjectID
+	if parent != nil {
+		projectID = parent.ProjectID
+	}
+
+	action := h.undo.Begin(user.ID, "Task moved")
+	action.Record(task)
+	task.ParentID = req.ParentID
+	if err := moveTaskTree(r.Context(), h.store, task, projectID, user.ID, action); err != nil {
+		writeTaskSaveError(w, h.store, task, user.ID, err)
+		return
+	}
+
+	pushUndo(w, h.undo, action)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(task))
+	json.NewEncoder(w).Encode(task)
+}
+
+// SubtasksFragment renders the sub-task tree shown on the task detail page
+func (h *TaskHandler) SubtasksFragment(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+
+	h.renderSubtasks(w, r, task, user.ID)
+}
+
+// CreateSubtaskSubmit adds a sub-task from the task detail page and renders the updated tree
+func (h *TaskHandler) CreateSubtaskSubmit(w http.ResponseWriter, r *http.Request) {
+	parent, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	child := models.NewSubtask(parent, r.FormValue("title"), "")
+	if err := h.store.SaveForUser(child, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.renderSubtasks(w, r, parent, user.ID)
+}
+
+// AutoCompleteSubmit turns completing a task with its sub-tasks on or off and renders the updated tree
+func (h *TaskHandler) AutoCompleteSubmit(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	task.AutoComplete = r.FormValue("auto_complete") == "on"
+	task.UpdatedAt = time.Now()
+	if err := h.store.SaveForUser(task, user.ID); err != nil {
+		writeTaskSaveError(w, h.store, task, user.ID, err)
+		return
+	}
+
+	h.renderSubtasks(w, r, task, user.ID)
+}
+
+// renderSubtasks renders a task's sub-task tree with its progress
+func (h *TaskHandler) renderSubtasks(w http.ResponseWriter, r *http.Request, task *models.Task, userID string) {
+	subtree, err := h.store.GetSubtree(r.Context(), task.ID, userID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load sub-tasks: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var parent *partials.SubtaskNode
+	if task.ParentID != "" {
+		if p, err := h.store.GetForUser(task.ParentID, userID); err == nil {
+			parent = &partials.SubtaskNode{ID: p.ID, Title: p.Title, Status: string(p.Status)}
+		}
+	}
+
+	progress := models.Progress(subtree)
+	w.Header().Set("Content-Type", "text/html")
+	partials.TaskSubtasks(task.ID, parent, progress.Done, progress.Total, subtaskNodes(task.ID, subtree), task.AutoComplete).Render(r.Context(), w)
+}
+
+// subtaskNodes arranges the children of parentID, and theirs in turn, into a tree
+func subtaskNodes(parentID string, subtree []*models.Task) []partials.SubtaskNode {
+	var nodes []partials.SubtaskNode
+	for _, task := range subtree {
+		if task.ParentID == parentID {
+			nodes = append(nodes, partials.SubtaskNode{
+				ID:       task.ID,
+				Title:    task.Title,
+				Status:   string(task.Status),
+				Children: subtaskNodes(task.ID, subtree),
+			})
+		}
+	}
+	return nodes
+}
+
+// moveTaskTree saves a task in a project along with all of its sub-tasks. Record the task on
+// action before changing it; the sub-tasks are recorded here. A nil action records nothing.
+func moveTaskTree(ctx context.Context, store models.TaskStore, task *models.Task, projectID string, userID string, action *models.UndoAction) error {
+	subtree, err := store.GetSubtree(ctx, task.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	task.ProjectID = projectID
+	task.UpdatedAt = time.Now()
+	if err := store.SaveForUser(task, userID); err != nil {
+		return err
+	}
+
+	for _, descendant := range subtree {
+		if descendant.ProjectID == projectID {
+			continue
+		}
+		action.Record(descendant)
+		descendant.ProjectID = projectID
+		descendant.UpdatedAt = time.Now()
+		if err := store.SaveForUser(descendant, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteTaskTree moves a task and all of its sub-tasks to the trash, recording each of them on action.
+// It returns how many sub-tasks were deleted along with the task.
+func deleteTaskTree(ctx context.Context, store models.TaskStore, task *models.Task, userID string, action *models.UndoAction) (int, error) {
+	subtree, err := store.GetSubtree(ctx, task.ID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Deepest first, so a failure never leaves a sub-task without its parent. Each deletion is saved
+	// from the recorded copy, so the action knows which history events to revert.
+	for i := len(subtree) - 1; i >= 0; i-- {
+		action.Record(subtree[i])
+		subtree[i].Delete()
+		if err := store.SaveForUser(subtree[i], userID); err != nil {
+			return len(subtree) - 1 - i, err
+		}
+	}
+
+	action.Record(task)
+	task.Delete()
+	return len(subtree), store.SaveForUser(task, userID)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// maxBulkTasks caps the number of tasks a single bulk request may change
+const maxBulkTasks = 500
+
+// BulkUpdateRequest applies one action to several tasks.
+// Action is one of next, waiting, someday, done, delete or move; move uses ProjectID,
+// and an empty ProjectID removes the tasks from their project.
+type BulkUpdateRequest struct {
+	IDs       []string `json:"ids"`
+	Action    string   `json:"action"`
+	ProjectID string   `json:"projectId,omitempty"`
+}
+
+// BulkUpdateResponse reports the outcome of a bulk update
+type BulkUpdateResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	Updated   int    `json:"updated"`
+	UndoToken string `json:"undoToken,omitempty"`
+}
+
+// bulkActionLabels describes each bulk action for the undo stack
+var bulkActionLabels = map[string]string{
+	"next":    "marked as Next Action",
+	"waiting": "marked as Waiting For",
+	"someday": "marked as Someday/Maybe",
+	"done":    "marked as Done",
+	"delete":  "deleted",
+	"move":    "moved",
+}
+
+// BulkUpdateTasksAPI applies a status change, delete or project move to several tasks at once.
+// All tasks must belong to the user; the whole batch is undone with a single token.
+func (h *TaskHandler) BulkUpdateTasksAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req BulkUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	label, ok := bulkActionLabels[req.Action]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown action: %s", req.Action), http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) == 0 || len(req.IDs) > maxBulkTasks {
+		http.Error(w, fmt.Sprintf("ids must list between 1 and %d tasks", maxBulkTasks), http.StatusBadRequest)
+		return
+	}
+
+	if req.Action == "move" && req.ProjectID != "" {
+		if _, err := h.projects.GetForUser(req.ProjectID, user.ID); err != nil {
+			writeBulkLoadError(w, err)
+			return
+		}
+	}
+
+	// Load every task first, so an unknown ID rejects the batch before anything changes
+	tasks := make([]*models.Task, 0, len(req.IDs))
+	seen := make(map[string]bool)
+	for _, id := range req.IDs {
+		task, err := h.store.GetForUser(id, user.ID)
+		if err != nil {
+			writeBulkLoadError(w, err)
+			return
+		}
+		if !seen[task.ID] {
+			seen[task.ID] = true
+			tasks = append(tasks, task)
+		}
+	}
+
+	action := h.undo.Begin(user.ID, fmt.Sprintf("%d tasks %s", len(tasks), label))
+	updated := 0
+	for _, loaded := range tasks {
+		// Completing an earlier task of the batch may have promoted this one, so start from the stored copy
+		task, err := h.store.GetForUser(loaded.ID, user.ID)
+		if err == models.ErrTaskNotFound && req.Action == "delete" {
+			// Already deleted along with a parent earlier in the batch
+			updated++
+			continue
+		}
+		if err == nil {
+			action.Record(task)
+			err = h.applyBulkAction(r.Context(), task, req, user.ID, action)
+		}
+		if err != nil {
+			// Whatever already changed can still be undone
+			pushUndo(w, h.undo, action)
+			http.Error(w, fmt.Sprintf("updated %d of %d tasks: %v", updated, len(tasks), err), http.StatusInternalServerError)
+			return
+		}
+		updated++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(BulkUpdateResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("%d tasks %s", updated, label),
+		Updated:   updated,
+		UndoToken: pushUndo(w, h.undo, action),
+	})
+}
+
+// applyBulkAction applies the requested action to one task and saves it
+func (h *TaskHandler) applyBulkAction(ctx context.Context, task *models.Task, req BulkUpdateRequest, userID string, action *models.UndoAction) error {
+	switch req.Action {
+	case "next":
+		task.MarkAsNext()
+	case "waiting":
+		task.MarkAsWaiting()
+	case "someday":
+		task.MarkAsSomeday()
+	case "done":
+		_, _, err := completeTask(ctx, h.store, task, userID, action)
+		return err
+	case "delete":
+		_, err := deleteTaskTree(ctx, h.store, task, userID, action)
+		return err
+	case "move":
+		return moveTaskTree(ctx, h.store, task, req.ProjectID, userID, action)
+	}
+
+	return h.store.SaveForUser(task, userID)
+}
+
+// writeBulkLoadError reports a task or project that doesn't belong to the user as not found
+func writeBulkLoadError(w http.ResponseWriter, err error) {
+	if err == models.ErrTaskNotFound || err == models.ErrProjectNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
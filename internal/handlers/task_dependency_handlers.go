@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/views/partials"
+)
+
+// TaskBlockersResponse lists the tasks a task waits on and the tasks waiting on it
+type TaskBlockersResponse struct {
+	Blocked   bool           `json:"blocked"` // Whether a blocker is still open
+	BlockedBy []*models.Task `json:"blockedBy"`
+	Blocking  []*models.Task `json:"blocking"`
+}
+
+// ListBlockersAPI returns the dependencies of a task within its project
+func (h *TaskHandler) ListBlockersAPI(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+
+	resp := TaskBlockersResponse{BlockedBy: []*models.Task{}, Blocking: []*models.Task{}}
+	if task.ProjectID != "" {
+		siblings, err := models.QueryAll(r.Context(), h.store, models.TaskQuery{
+			Filter: models.TaskFilter{UserID: user.ID, ProjectID: task.ProjectID},
+			Sort:   models.SortCreatedAsc,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		waitsOn := make(map[string]bool)
+		for _, id := range task.BlockedBy {
+			waitsOn[id] = true
+		}
+		for _, sibling := range siblings {
+			if waitsOn[sibling.ID] {
+				resp.BlockedBy = append(resp.BlockedBy, sibling)
+			}
+			for _, id := range sibling.BlockedBy {
+				if id == task.ID {
+					resp.Blocking = append(resp.Blocking, sibling)
+				}
+			}
+		}
+		resp.Blocked = models.BlockedTasks(siblings)[task.ID]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// AddBlockerAPI makes a task wait on another task of its project
+func (h *TaskHandler) AddBlockerAPI(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
+		return
+	}
+
+	if err := models.AddBlocker(r.Context(), h.store, task, chi.URLParam(r, "blockerId"), user.ID); err != nil {
+		writeDependencyError(w, h.store, task, user.ID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(task))
+	json.NewEncoder(w).Encode(task)
+}
+
+// RemoveBlockerAPI stops a task from waiting on another task
+func (h *TaskHandler) RemoveBlockerAPI(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
+		return
+	}
+
+	if err := models.RemoveBlocker(h.store, task, chi.URLParam(r, "blockerId"), user.ID); err != nil {
+		writeTaskSaveError(w, h.store, task, user.ID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(task))
+	json.NewEncoder(w).Encode(task)
+}
+
+// writeDependencyError reports a dependency that can't be added
+func writeDependencyError(w http.ResponseWriter, store models.TaskStore, task *models.Task, userID string, err error) {
+	switch err {
+	case models.ErrDependencySelf, models.ErrDependencyProject:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case models.ErrDependencyCycle:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeTaskSaveError(w, store, task, userID, err)
+	}
+}
+
+// ProjectStep is one step of a project's dependency order
+type ProjectStep struct {
+	Step  int            `json:"step"`
+	Tasks []*models.Task `json:"tasks"`
+}
+
+// projectSteps loads a project's tasks in dependency order
+func (h *ProjectHandler) projectSteps(r *http.Request, projectID string, userID string) ([][]*models.Task, map[string]bool, error) {
+	tasks, err := h.getProjectTasks(r.Context(), projectID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return models.DependencyLevels(tasks), models.BlockedTasks(tasks), nil
+}
+
+// ProjectDependenciesAPI returns a project's tasks grouped into steps, each step waiting on the ones before it
+func (h *ProjectHandler) ProjectDependenciesAPI(w http.ResponseWriter, r *http.Request) {
+	project, user, ok := loadOwnedProject(w, r, h.projects, "id")
+	if !ok {
+		return
+	}
+
+	levels, _, err := h.projectSteps(r, project.ID, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	steps := make([]ProjectStep, len(levels))
+	for i, tasks := range levels {
+		steps[i] = ProjectStep{Step: i + 1, Tasks: tasks}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(steps)
+}
+
+// ProjectDependenciesFragment renders the dependency order shown on the project page
+func (h *ProjectHandler) ProjectDependenciesFragment(w http.ResponseWriter, r *http.Request) {
+	project, user, ok := loadOwnedProject(w, r, h.projects, "id")
+	if !ok {
+		return
+	}
+
+	levels, blocked, err := h.projectSteps(r, project.ID, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	titles := make(map[string]string)
+	for _, tasks := range levels {
+		for _, task := range tasks {
+			titles[task.ID] = task.Title
+		}
+	}
+
+	steps := make([][]partials.DependencyTaskInfo, len(levels))
+	for i, tasks := range levels {
+		for _, task := range tasks {
+			info := partials.DependencyTaskInfo{
+				ID:      task.ID,
+				Title:   task.Title,
+				Status:  string(task.Status),
+				Blocked: blocked[task.ID],
+			}
+			for _, id := range task.BlockedBy {
+				if title, ok := titles[id]; ok {
+					info.WaitsOn = append(info.WaitsOn, title)
+				}
+			}
+			steps[i] = append(steps[i], info)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	partials.ProjectDependencies(steps).Render(r.Context(), w)
+}
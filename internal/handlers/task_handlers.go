@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/melihkorkmaz/gtd/internal/models"
@@ -15,11 +18,14 @@ import (
 // TaskHandler manages task-related HTTP endpoints
 type TaskHandler struct {
 	store     models.TaskStore
+	projects  models.ProjectStore // Used to match project names in quick-capture text
+	contexts  models.ContextStore // Task contexts sent to the API must be defined here
+	undo      *models.UndoStack
 	templates *TemplateRenderer
 }
 
 // NewTaskHandler creates a new task handler
-func NewTaskHandler(store models.TaskStore, templatesDir string) (*TaskHandler, error) {
+func NewTaskHandler(store models.TaskStore, projects models.ProjectStore, contexts models.ContextStore, undo *models.UndoStack, templatesDir string) (*TaskHandler, error) {
 	templates, err := NewTemplateRenderer(templatesDir)
 	if err != nil {
 		return nil, err
@@ -27,6 +33,9 @@ func NewTaskHandler(store models.TaskStore, templatesDir string) (*TaskHandler,
 
 	return &TaskHandler{
 		store:     store,
+		projects:  projects,
+		contexts:  contexts,
+		undo:      undo,
 		templates: templates,
 	}, nil
 }
@@ -37,6 +46,8 @@ type CreateTaskRequest struct {
 	Description string   `json:"description"`
 	Contexts    []string `json:"contexts,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
+	Parse       bool     `json:"parse,omitempty"`    // Extract contexts, tags, dates, etc. from the title (also ?parse=true)
+	Timezone    string   `json:"timezone,omitempty"` // IANA time zone for relative dates when parsing
 }
 
 // Helper function to convert Task model to TaskCardInfo for templates
@@ -66,34 +77,127 @@ func (h *TaskHandler) RegisterRoutes(r chi.Router) {
 	r.Route("/api/tasks", func(r chi.Router) {
 		r.Get("/", h.ListTasksAPI)
 		r.Post("/", h.CreateTaskAPI)
+		r.Post("/bulk", h.BulkUpdateTasksAPI)
 		r.Post("/quick-capture", h.QuickCaptureAPI)
+		r.Get("/parse", h.PreviewParseAPI)
 		r.Get("/search", h.SearchTasksAPI)
 		r.Get("/{id}", h.GetTaskAPI)
 		r.Put("/{id}", h.UpdateTaskAPI)
 		r.Delete("/{id}", h.DeleteTaskAPI)
+		r.Get("/{id}/history", h.TaskHistoryAPI)
+		r.Get("/{id}/blockers", h.ListBlockersAPI)
+		r.Put("/{id}/blockers/{blockerId}", h.AddBlockerAPI)
+		r.Delete("/{id}/blockers/{blockerId}", h.RemoveBlockerAPI)
+		r.Get("/{id}/subtasks", h.ListSubtasksAPI)
+		r.Post("/{id}/subtasks", h.CreateSubtaskAPI)
+		r.Put("/{id}/parent", h.SetParentAPI)
 	})
 
+	r.Get("/api/recurrence/preview", h.PreviewRecurrenceAPI)
+
 	// HTML routes for server-side rendering
 	r.Route("/tasks", func(r chi.Router) {
 		r.Get("/", h.ListTasksPage)
 		r.Get("/search", h.SearchTasksPage)
 		r.Get("/new", h.NewTaskForm)
 		r.Post("/", h.CreateTaskSubmit)
+		r.Get("/parse-preview", h.PreviewParseFragment)
 		r.Get("/{id}", h.ViewTaskPage)
 		r.Get("/{id}/edit", h.EditTaskForm)
+		r.Get("/{id}/history", h.TaskHistoryFragment)
+		r.Get("/{id}/subtasks", h.SubtasksFragment)
+		r.Post("/{id}/subtasks", h.CreateSubtaskSubmit)
+		r.Post("/{id}/auto-complete", h.AutoCompleteSubmit)
 	})
 }
 
-// ListTasksAPI returns a JSON list of all tasks
+// ListTasksAPI returns a JSON page of the current user's tasks.
+// Supported query parameters: status (comma-separated), project, context, tag,
+// due_after, due_before (YYYY-MM-DD), energy, priority, unblocked (true), sort, cursor and limit.
 func (h *TaskHandler) ListTasksAPI(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.store.GetAll()
+	// Get user from context if authenticated
+	user, ok := r.Context().Value("user").(*models.User)
+	if !ok || user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query, err := taskQueryFromRequest(r, user.ID)
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.store.Query(r.Context(), query)
+	if err != nil {
+		if err == models.ErrInvalidCursor {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(tasks)
+	json.NewEncoder(w).Encode(page)
+}
+
+// taskQueryFromRequest builds a task query for a user from URL query parameters
+func taskQueryFromRequest(r *http.Request, userID string) (models.TaskQuery, error) {
+	params := r.URL.Query()
+
+	query := models.TaskQuery{
+		Filter: models.TaskFilter{
+			UserID:    userID,
+			ProjectID: params.Get("project"),
+			Context:   models.Context(params.Get("context")),
+			Tag:       params.Get("tag"),
+			Energy:    params.Get("energy"),
+			Unblocked: params.Get("unblocked") == "true",
+		},
+		Sort:   models.ParseTaskSort(params.Get("sort")),
+		Cursor: params.Get("cursor"),
+	}
+
+	if status := params.Get("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			query.Filter.Statuses = append(query.Filter.Statuses, models.TaskStatus(strings.TrimSpace(s)))
+		}
+	}
+
+	if value := params.Get("priority"); value != "" {
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			return query, fmt.Errorf("invalid priority: %s", value)
+		}
+		query.Filter.Priority = priority
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return query, fmt.Errorf("invalid limit: %s", value)
+		}
+		query.Limit = limit
+	}
+
+	if value := params.Get("due_after"); value != "" {
+		dueAfter, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return query, fmt.Errorf("invalid due_after date. Use YYYY-MM-DD")
+		}
+		query.Filter.DueAfter = &dueAfter
+	}
+
+	if value := params.Get("due_before"); value != "" {
+		dueBefore, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return query, fmt.Errorf("invalid due_before date. Use YYYY-MM-DD")
+		}
+		query.Filter.DueBefore = &dueBefore
+	}
+
+	return query, nil
 }
 
 // CreateTaskAPI creates a new task from JSON input
@@ -113,13 +217,25 @@ func (h *TaskHandler) CreateTaskAPI(w http.ResponseWriter, r *http.Request) {
 
 	task := models.NewTask(request.Title, request.Description, user.ID)
 
+	if request.Parse || r.URL.Query().Get("parse") == "true" {
+		result, project, err := h.parseCapture(r, request.Title, request.Timezone, user.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		applyCapture(task, result, project)
+	}
+
 	// Convert string contexts to Context type
 	for _, ctx := range request.Contexts {
 		task.Contexts = append(task.Contexts, models.Context(ctx))
 	}
+	if !resolveContexts(w, r, h.contexts, &task.Contexts, user.ID) {
+		return
+	}
 
 	// Add tags
-	task.Tags = request.Tags
+	task.Tags = append(task.Tags, request.Tags...)
 
 	if err := h.store.Save(task); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -131,58 +247,232 @@ func (h *TaskHandler) CreateTaskAPI(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(task)
 }
 
-// GetTaskAPI returns a single task as JSON
+// GetTaskAPI returns a single task as JSON; its ETag can be sent back in If-Match when changing it
 func (h *TaskHandler) GetTaskAPI(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	task, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	task, _, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(task))
 	json.NewEncoder(w).Encode(task)
 }
 
-// UpdateTaskAPI updates a task from JSON input
+// UpdateTaskAPI updates a task from JSON input. Changes made from an outdated copy, told by
+// If-Match or the version field, are refused with the current task.
 func (h *TaskHandler) UpdateTaskAPI(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-
 	// First get the existing task
-	task, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
 		return
 	}
 
 	// Decode the update request
-	if err := json.NewDecoder(r.Body).Decode(task); err != nil {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Save the updated task
-	if err := h.store.Save(task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// A client sending back a whole task also tells which version it edited
+	if raw, ok := fields["version"]; ok {
+		var version int
+		if err := json.Unmarshal(raw, &version); err != nil {
+			http.Error(w, fmt.Sprintf("invalid value for version: %v", err), http.StatusBadRequest)
+			return
+		}
+		if version != task.Version {
+			writeTaskConflict(w, http.StatusConflict, task)
+			return
+		}
+	}
+
+	// Only apply the fields a client is allowed to change. A new status is held back and applied
+	// the way the status endpoints apply it.
+	status := task.Status
+	if err := applyTaskUpdate(task, fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	newStatus := task.Status
+	task.Status = status
+	if newStatus == models.StatusScheduled && newStatus != status && task.ScheduledDate == nil {
+		http.Error(w, "scheduledDate is required to schedule a task", http.StatusBadRequest)
+		return
+	}
+	if _, ok := fields["contexts"]; ok && !resolveContexts(w, r, h.contexts, &task.Contexts, user.ID) {
+		return
+	}
+
+	action := h.undo.Begin(user.ID, "Task updated")
+	action.Record(task)
+	if newStatus != status {
+		if _, err := changeTaskStatus(r.Context(), h.store, task, newStatus, user.ID, action); err != nil {
+			writeTaskSaveError(w, h.store, task, user.ID, err)
+			return
+		}
+	} else {
+		task.UpdatedAt = time.Now()
+		if err := h.store.SaveForUser(task, user.ID); err != nil {
+			writeTaskSaveError(w, h.store, task, user.ID, err)
+			return
+		}
+	}
 
+	pushUndo(w, h.undo, action)
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(task))
 	json.NewEncoder(w).Encode(task)
 }
 
-// DeleteTaskAPI deletes a task
+// taskUpdateTargets maps the JSON fields a client may update to the task fields they decode into
+func taskUpdateTargets(t *models.Task) map[string]interface{} {
+	return map[string]interface{}{
+		"title":          &t.Title,
+		"description":    &t.Description,
+		"status":         &t.Status,
+		"contexts":       &t.Contexts,
+		"tags":           &t.Tags,
+		"dueDate":        &t.DueDate,
+		"scheduledDate":  &t.ScheduledDate,
+		"timeEstimate":   &t.TimeEstimate,
+		"energyRequired": &t.EnergyRequired,
+		"priority":       &t.Priority,
+		"timeframe":      &t.Timeframe,
+		"isRecurring":    &t.IsRecurring,
+		"recurringRule":  &t.RecurringRule,
+		"recurrenceFrom": &t.RecurrenceFrom,
+		"autoComplete":   &t.AutoComplete,
+	}
+}
+
+// taskReadOnlyFields are accepted in update requests (so clients can send back a whole task)
+// but never applied
+var taskReadOnlyFields = map[string]bool{
+	"id":          true,
+	"userId":      true,
+	"projectId":   true,
+	"parentId":    true, // Changed through the parent endpoint, which checks for cycles
+	"blockedBy":   true, // Changed through the blocker endpoints, which check for cycles
+	"createdAt":   true,
+	"updatedAt":   true,
+	"completedAt": true,
+	"deletedAt":   true,
+	"version":     true, // Checked against the stored version instead
+}
+
+// applyTaskUpdate copies the whitelisted fields of an update request onto a task
+func applyTaskUpdate(task *models.Task, fields map[string]json.RawMessage) error {
+	if err := applyFieldUpdates(fields, taskUpdateTargets(task), taskReadOnlyFields); err != nil {
+		return err
+	}
+
+	return task.Validate()
+}
+
+// applyFieldUpdates decodes each field of an update request into its target.
+// Read-only fields are skipped; any other unknown field is rejected.
+func applyFieldUpdates(fields map[string]json.RawMessage, targets map[string]interface{}, readOnly map[string]bool) error {
+	for name, value := range fields {
+		target, ok := targets[name]
+		if !ok {
+			if readOnly[name] {
+				continue
+			}
+			return fmt.Errorf("unknown field: %s", name)
+		}
+
+		if err := json.Unmarshal(value, target); err != nil {
+			return fmt.Errorf("invalid value for %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteTaskAPI moves a task to the trash; the undo token is returned in the X-Undo-Token header
 func (h *TaskHandler) DeleteTaskAPI(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
+		return
+	}
 
-	if err := h.store.Delete(id); err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	action := h.undo.Begin(user.ID, "Task deleted")
+	subtasks, err := deleteTaskTree(r.Context(), h.store, task, user.ID, action)
+	if err != nil {
+		switch err {
+		case models.ErrTaskConflict:
+			current, loadErr := h.store.GetForUser(task.ID, user.ID)
+			if loadErr != nil {
+				http.Error(w, err.Error(), http.StatusPreconditionFailed)
+				return
+			}
+			writeTaskConflict(w, http.StatusPreconditionFailed, current)
+		case models.ErrTaskNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
+	if subtasks > 0 {
+		action.Label = fmt.Sprintf("Task and %d sub-task(s) deleted", subtasks)
+	}
 
+	pushUndo(w, h.undo, action)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// TaskHistoryAPI returns the recorded changes of a task, oldest first
+func (h *TaskHandler) TaskHistoryAPI(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+
+	events, err := h.store.History(r.Context(), task.ID, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// TaskHistoryFragment renders the timeline of the task detail page, newest first
+func (h *TaskHandler) TaskHistoryFragment(w http.ResponseWriter, r *http.Request) {
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+
+	events, err := h.store.History(r.Context(), task.ID, user.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load history: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	infos := make([]partials.TaskEventInfo, len(events))
+	for i, event := range events {
+		infos[len(events)-1-i] = partials.TaskEventInfo{
+			Type:      string(event.Type),
+			Summary:   event.Describe(),
+			CreatedAt: event.CreatedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	partials.TaskHistory(infos).Render(r.Context(), w)
+}
+
 // ListTasksPage renders the tasks list page
 func (h *TaskHandler) ListTasksPage(w http.ResponseWriter, r *http.Request) {
 	// Get user from context if authenticated
@@ -207,7 +497,18 @@ func (h *TaskHandler) ListTasksPage(w http.ResponseWriter, r *http.Request) {
 	if status != "" {
 		// Filter tasks by status and user ID
 		fmt.Printf("Filtering tasks by status: %s for user: %s\n", status, user.ID)
-		tasks, err = h.store.GetByStatusAndUserID(models.TaskStatus(status), user.ID)
+		if status == string(models.StatusNext) {
+			// Next actions waiting on another task aren't actionable yet
+			tasks, err = models.QueryAll(r.Context(), h.store, models.TaskQuery{
+				Filter: models.TaskFilter{
+					UserID:    user.ID,
+					Statuses:  []models.TaskStatus{models.StatusNext},
+					Unblocked: true,
+				},
+			})
+		} else {
+			tasks, err = h.store.GetByStatusAndUserID(models.TaskStatus(status), user.ID)
+		}
 
 		// Set title based on status
 		switch status {
@@ -250,11 +551,19 @@ func (h *TaskHandler) ListTasksPage(w http.ResponseWriter, r *http.Request) {
 	ctx := context.WithValue(r.Context(), "user", user)
 
 	// Render the page
-	tasksPage := pages.TasksListPage(title, taskInfos)
+	tasksPage := pages.TasksListPage(title, ungroupedTasks(taskInfos))
 	w.Header().Set("Content-Type", "text/html")
 	tasksPage.Render(ctx, w)
 }
 
+// ungroupedTasks puts a list of task cards into a single unnamed group
+func ungroupedTasks(infos []partials.TaskCardInfo) []pages.TaskGroup {
+	if len(infos) == 0 {
+		return nil
+	}
+	return []pages.TaskGroup{{Tasks: infos}}
+}
+
 // ViewTaskPage renders a single task view
 func (h *TaskHandler) ViewTaskPage(w http.ResponseWriter, r *http.Request) {
 	// Get user from context if authenticated
@@ -267,10 +576,8 @@ func (h *TaskHandler) ViewTaskPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get task ID from URL
-	id := chi.URLParam(r, "id")
-	task, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	task, _, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
 		return
 	}
 
@@ -294,10 +601,8 @@ func (h *TaskHandler) NewTaskForm(w http.ResponseWriter, r *http.Request) {
 
 // EditTaskForm renders the form for editing a task
 func (h *TaskHandler) EditTaskForm(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	_, err := h.store.Get(id) // Just verify task exists, we'll use it in the actual implementation
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	// Just verify the task exists and belongs to the user, we'll use it in the actual implementation
+	if _, _, ok := loadOwnedTask(w, r, h.store, "id"); !ok {
 		return
 	}
 
@@ -352,8 +657,19 @@ func (h *TaskHandler) QuickCaptureAPI(w http.ResponseWriter, r *http.Request) {
 	title := r.FormValue("title")
 	description := r.FormValue("description")
 
+	result, project, err := h.parseCapture(r, title, r.FormValue("timezone"), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	task := models.NewTask(title, description, user.ID)
+	applyCapture(task, result, project)
 	task.Status = models.StatusInbox // Quick capture always goes to inbox
+	if err := models.ContextsToTitle(r.Context(), h.contexts, user.ID, task); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	if err := h.store.Save(task); err != nil {
 		http.Error(w, "Failed to save task: "+err.Error(), http.StatusInternalServerError)
@@ -365,7 +681,8 @@ func (h *TaskHandler) QuickCaptureAPI(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("<div class='alert alert-success'>Task captured successfully!</div>"))
 }
 
-// SearchTasksAPI searches for tasks and returns JSON results
+// SearchTasksAPI searches the user's tasks and returns the results, best matches first.
+// The q parameter takes the syntax of models.ParseSearchQuery.
 func (h *TaskHandler) SearchTasksAPI(w http.ResponseWriter, r *http.Request) {
 	// Get user from context if authenticated
 	user, ok := r.Context().Value("user").(*models.User)
@@ -374,22 +691,26 @@ func (h *TaskHandler) SearchTasksAPI(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := r.URL.Query().Get("q")
-	if query == "" {
+	query, err := models.ParseSearchQuery(r.URL.Query().Get("q"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Empty() {
 		// No query, return empty results
 		w.Header().Set("Content-Type", "application/json")
 		w.Write([]byte("[]"))
 		return
 	}
 
-	tasks, err := h.store.SearchByUserID(query, user.ID)
+	results, err := h.store.SearchTasks(r.Context(), user.ID, query)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(tasks)
+	json.NewEncoder(w).Encode(results)
 }
 
 // SearchTasksPage renders the search results page for tasks
@@ -404,34 +725,40 @@ func (h *TaskHandler) SearchTasksPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get search query
-	query := r.URL.Query().Get("q")
+	searchResults := partials.SearchResultsData{SearchQuery: r.URL.Query().Get("q")}
 
-	var tasks []*models.Task
-	var err error
-
-	if query != "" {
+	query, err := models.ParseSearchQuery(searchResults.SearchQuery, time.Now())
+	if err != nil {
+		searchResults.Error = err.Error()
+	} else if !query.Empty() {
 		// Search tasks for this user
-		tasks, err = h.store.SearchByUserID(query, user.ID)
+		results, err := h.store.SearchTasks(r.Context(), user.ID, query)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-	}
-
-	// Convert tasks to template-friendly format
-	taskInfos := make([]partials.TaskCardInfo, len(tasks))
-	for i, task := range tasks {
-		taskInfos[i] = getTaskCardInfo(task)
-	}
 
-	// Create search results data
-	searchResults := partials.SearchResultsData{
-		SearchQuery:  query,
-		ResultsCount: len(tasks),
-		Tasks:        taskInfos,
+		// Convert results to template-friendly format
+		searchResults.ResultsCount = len(results)
+		for _, result := range results {
+			searchResults.Results = append(searchResults.Results, partials.SearchResultInfo{
+				Task:    getTaskCardInfo(result.Task),
+				Title:   highlightParts(result.Title),
+				Snippet: highlightParts(result.Snippet),
+			})
+		}
 	}
 
 	// Render search results using the templ component
 	w.Header().Set("Content-Type", "text/html")
 	partials.SearchResults(searchResults).Render(r.Context(), w)
 }
+
+// highlightParts converts highlighted search text for the template
+func highlightParts(parts []models.SnippetPart) []partials.HighlightPart {
+	converted := make([]partials.HighlightPart, len(parts))
+	for i, part := range parts {
+		converted[i] = partials.HighlightPart{Text: part.Text, Match: part.Match}
+	}
+	return converted
+}
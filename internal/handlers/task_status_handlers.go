@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -15,6 +16,15 @@ type StatusChangeResponse struct {
 	Message string `json:"message"`
 	TaskID  string `json:"taskId"`
 	Status  string `json:"status"`
+
+	// NextTaskID is set when completing a recurring task created its next occurrence
+	NextTaskID string `json:"nextTaskId,omitempty"`
+
+	// PromotedTaskIDs lists the tasks that waited on the completed one and moved to Next Actions
+	PromotedTaskIDs []string `json:"promotedTaskIds,omitempty"`
+
+	// UndoToken reverts the change when posted to /api/undo/{token}
+	UndoToken string `json:"undoToken,omitempty"`
 }
 
 // RegisterTaskStatusRoutes registers routes for task status transitions
@@ -23,98 +33,249 @@ func (h *TaskHandler) RegisterTaskStatusRoutes(r chi.Router) {
 	r.Put("/api/tasks/{id}/waiting", h.MarkTaskAsWaiting)
 	r.Put("/api/tasks/{id}/someday", h.MarkTaskAsSomeday)
 	r.Put("/api/tasks/{id}/done", h.MarkTaskAsDone)
-	r.Put("/api/tasks/{id}/project", h.MarkTaskAsProject)
 	r.Put("/api/tasks/{id}/scheduled", h.ScheduleTask)
 }
 
 // MarkTaskAsNext marks a task as a next action
 func (h *TaskHandler) MarkTaskAsNext(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	task, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
 		return
 	}
 
+	action := h.undo.Begin(user.ID, "Task marked as Next Action")
+	action.Record(task)
 	task.MarkAsNext()
-	if err := h.store.Save(task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.store.SaveForUser(task, user.ID); err != nil {
+		writeTaskSaveError(w, h.store, task, user.ID, err)
 		return
 	}
 
-	sendStatusChangeResponse(w, task, "Task marked as Next Action")
+	sendStatusChangeResponse(w, task, "Task marked as Next Action", pushUndo(w, h.undo, action))
 }
 
 // MarkTaskAsWaiting marks a task as waiting for someone else
 func (h *TaskHandler) MarkTaskAsWaiting(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	task, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
 		return
 	}
 
+	action := h.undo.Begin(user.ID, "Task marked as Waiting For")
+	action.Record(task)
 	task.MarkAsWaiting()
-	if err := h.store.Save(task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.store.SaveForUser(task, user.ID); err != nil {
+		writeTaskSaveError(w, h.store, task, user.ID, err)
 		return
 	}
 
-	sendStatusChangeResponse(w, task, "Task marked as Waiting For")
+	sendStatusChangeResponse(w, task, "Task marked as Waiting For", pushUndo(w, h.undo, action))
 }
 
 // MarkTaskAsSomeday marks a task as a someday/maybe item
 func (h *TaskHandler) MarkTaskAsSomeday(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	task, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
 		return
 	}
 
+	action := h.undo.Begin(user.ID, "Task marked as Someday/Maybe")
+	action.Record(task)
 	task.MarkAsSomeday()
-	if err := h.store.Save(task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.store.SaveForUser(task, user.ID); err != nil {
+		writeTaskSaveError(w, h.store, task, user.ID, err)
 		return
 	}
 
-	sendStatusChangeResponse(w, task, "Task marked as Someday/Maybe")
+	sendStatusChangeResponse(w, task, "Task marked as Someday/Maybe", pushUndo(w, h.undo, action))
 }
 
 // MarkTaskAsDone marks a task as done
 func (h *TaskHandler) MarkTaskAsDone(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	task, err := h.store.Get(id)
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
+		return
+	}
+
+	action := h.undo.Begin(user.ID, "Task marked as Done")
+	action.Record(task)
+	next, promoted, err := completeTask(r.Context(), h.store, task, user.ID, action)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeTaskSaveError(w, h.store, task, user.ID, err)
 		return
 	}
 
+	resp := StatusChangeResponse{
+		Success:   true,
+		Message:   "Task marked as Done",
+		TaskID:    task.ID,
+		Status:    string(task.Status),
+		UndoToken: pushUndo(w, h.undo, action),
+	}
+	if next != nil {
+		resp.Message = "Task marked as Done. Next occurrence created"
+		resp.NextTaskID = next.ID
+	}
+	for _, dependent := range promoted {
+		resp.PromotedTaskIDs = append(resp.PromotedTaskIDs, dependent.ID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(task))
+	json.NewEncoder(w).Encode(resp)
+}
+
+// completeTask marks a user's task as done and saves its next occurrence if it recurs. Dependents and
+// a waiting parent follow; changes are recorded on action unless it is nil.
+func completeTask(ctx context.Context, store models.TaskStore, task *models.Task, userID string, action *models.UndoAction) (*models.Task, []*models.Task, error) {
+	// Completing an already completed task must not spawn another occurrence
+	alreadyDone := task.Status == models.StatusDone
+
 	task.MarkAsDone()
-	if err := h.store.Save(task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+
+	var next *models.Task
+	if !alreadyDone {
+		var err error
+		if next, err = nextOccurrence(store, task, userID); err != nil {
+			return nil, nil, err
+		}
+		if next != nil {
+			task.NextOccurrenceID = next.ID
+		}
+	}
+
+	if err := store.SaveForUser(task, userID); err != nil {
+		return nil, nil, err
+	}
+
+	if alreadyDone {
+		return nil, nil, nil
 	}
 
-	sendStatusChangeResponse(w, task, "Task marked as Done")
+	promoted, err := promoteDependents(ctx, store, task, userID, action)
+	if err != nil {
+		return nil, promoted, err
+	}
+	if err := completeParent(ctx, store, task, userID, action); err != nil {
+		return nil, promoted, err
+	}
+
+	if next == nil {
+		return nil, promoted, nil
+	}
+	if err := store.SaveForUser(next, userID); err != nil {
+		return nil, promoted, err
+	}
+	if action != nil {
+		action.RecordCreated(next)
+	}
+
+	return next, promoted, nil
 }
 
-// MarkTaskAsProject marks a task as a project
-func (h *TaskHandler) MarkTaskAsProject(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	task, err := h.store.Get(id)
+// nextOccurrence returns the occurrence to create for a completed task, or nil when it doesn't recur
+// or the occurrence created when it was completed before still exists
+func nextOccurrence(store models.TaskStore, task *models.Task, userID string) (*models.Task, error) {
+	if task.NextOccurrenceID != "" {
+		_, err := store.GetForUser(task.NextOccurrenceID, userID)
+		if err == nil {
+			return nil, nil
+		}
+		if err != models.ErrTaskNotFound {
+			return nil, err
+		}
+	}
+	return task.NextOccurrence()
+}
+
+// changeTaskStatus moves a user's task to another list and saves it, the same way the status
+// endpoints do. Completing goes through completeTask, whose next occurrence it returns; a scheduled
+// task must already have its scheduled date. The change is recorded on action.
+func changeTaskStatus(ctx context.Context, store models.TaskStore, task *models.Task, status models.TaskStatus, userID string, action *models.UndoAction) (*models.Task, error) {
+	switch status {
+	case models.StatusDone:
+		next, _, err := completeTask(ctx, store, task, userID, action)
+		return next, err
+	case models.StatusNext:
+		task.MarkAsNext()
+	case models.StatusWaiting:
+		task.MarkAsWaiting()
+	case models.StatusSomeday:
+		task.MarkAsSomeday()
+	case models.StatusScheduled:
+		task.MarkAsScheduled(*task.ScheduledDate)
+	default:
+		// A reopened task is no longer completed
+		task.Status = status
+		task.CompletedAt = nil
+		task.UpdatedAt = time.Now()
+	}
+
+	return nil, store.SaveForUser(task, userID)
+}
+
+// promoteDependents moves the tasks that only waited on a completed task to Next Actions
+func promoteDependents(ctx context.Context, store models.TaskStore, completed *models.Task, userID string, action *models.UndoAction) ([]*models.Task, error) {
+	ready, err := models.ReadyDependents(ctx, store, completed, userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
+		return nil, err
 	}
 
-	task.MarkAsProject()
-	if err := h.store.Save(task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	var promoted []*models.Task
+	for _, dependent := range ready {
+		if action != nil {
+			action.Record(dependent)
+		}
+		dependent.MarkAsNext()
+		if err := store.SaveForUser(dependent, userID); err != nil {
+			return promoted, err
+		}
+		promoted = append(promoted, dependent)
+	}
+
+	return promoted, nil
+}
+
+// completeParent completes the parent of a finished sub-task when the parent is set to complete
+// with its sub-tasks and none of them are left open
+func completeParent(ctx context.Context, store models.TaskStore, child *models.Task, userID string, action *models.UndoAction) error {
+	if child.ParentID == "" {
+		return nil
+	}
+
+	parent, err := store.GetForUser(child.ParentID, userID)
+	if err == models.ErrTaskNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !parent.AutoComplete || parent.Status == models.StatusDone {
+		return nil
+	}
+
+	done, err := models.AllChildrenDone(ctx, store, parent.ID, userID)
+	if err != nil || !done {
+		return err
 	}
 
-	sendStatusChangeResponse(w, task, "Task converted to Project")
+	if action != nil {
+		action.Record(parent)
+	}
+	_, _, err = completeTask(ctx, store, parent, userID, action)
+	return err
 }
 
 // ScheduleRequest represents the request to schedule a task
@@ -124,10 +285,11 @@ type ScheduleRequest struct {
 
 // ScheduleTask schedules a task for a specific date
 func (h *TaskHandler) ScheduleTask(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	task, err := h.store.Get(id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	task, user, ok := loadOwnedTask(w, r, h.store, "id")
+	if !ok {
+		return
+	}
+	if !checkTaskVersion(w, r, task) {
 		return
 	}
 
@@ -143,23 +305,27 @@ func (h *TaskHandler) ScheduleTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	action := h.undo.Begin(user.ID, "Task scheduled")
+	action.Record(task)
 	task.MarkAsScheduled(scheduleDate)
-	if err := h.store.Save(task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.store.SaveForUser(task, user.ID); err != nil {
+		writeTaskSaveError(w, h.store, task, user.ID, err)
 		return
 	}
 
-	sendStatusChangeResponse(w, task, "Task scheduled")
+	sendStatusChangeResponse(w, task, "Task scheduled", pushUndo(w, h.undo, action))
 }
 
 // Helper function to send a standard response for status changes
-func sendStatusChangeResponse(w http.ResponseWriter, task *models.Task, message string) {
+func sendStatusChangeResponse(w http.ResponseWriter, task *models.Task, message string, undoToken string) {
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(task))
 	resp := StatusChangeResponse{
-		Success: true,
-		Message: message,
-		TaskID:  task.ID,
-		Status:  string(task.Status),
+		Success:   true,
+		Message:   message,
+		TaskID:    task.ID,
+		Status:    string(task.Status),
+		UndoToken: undoToken,
 	}
 	json.NewEncoder(w).Encode(resp)
 }
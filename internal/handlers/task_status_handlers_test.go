@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+func TestReopenedRecurringTaskKeepsOneNextOccurrence(t *testing.T) {
+	app := newOwnershipApp(t)
+
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := models.NewTask("Water the plants", "", "alice")
+	task.Status = models.StatusNext
+	task.DueDate = &due
+	task.IsRecurring = true
+	task.RecurringRule = "FREQ=WEEKLY"
+	if err := app.tasks.SaveForUser(task, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		status    string
+		completed bool
+	}{
+		{status: "done", completed: true},
+		{status: "next"},
+		{status: "done", completed: true},
+		{status: "waiting"},
+		{status: "someday"},
+		{status: "done", completed: true},
+	} {
+		w := app.serve("PUT", "/api/tasks/"+task.ID+"/"+step.status, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("PUT %s: status = %d: %s", step.status, w.Code, w.Body.String())
+		}
+
+		stored, err := app.tasks.GetForUser(task.ID, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (stored.CompletedAt != nil) != step.completed {
+			t.Errorf("after %s: completedAt = %v, want set %v", step.status, stored.CompletedAt, step.completed)
+		}
+	}
+
+	tasks, err := app.tasks.GetAllByUserID("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var occurrences []*models.Task
+	for _, other := range tasks {
+		if other.ID != task.ID && other.Title == task.Title {
+			occurrences = append(occurrences, other)
+		}
+	}
+	if len(occurrences) != 1 {
+		t.Fatalf("got %d next occurrences, want 1", len(occurrences))
+	}
+	if want := due.AddDate(0, 0, 7); !occurrences[0].DueDate.Equal(want) {
+		t.Errorf("next occurrence due %s, want %s", occurrences[0].DueDate, want)
+	}
+
+	// Once the next occurrence is gone, completing the task again creates it anew
+	if err := app.tasks.Delete(occurrences[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	app.serve("PUT", "/api/tasks/"+task.ID+"/next", "")
+	w := app.serve("PUT", "/api/tasks/"+task.ID+"/done", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT done: status = %d: %s", w.Code, w.Body.String())
+	}
+	stored, err := app.tasks.GetForUser(task.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.NextOccurrenceID == "" || stored.NextOccurrenceID == occurrences[0].ID {
+		t.Errorf("next occurrence = %q, want a new one", stored.NextOccurrenceID)
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// TaskConflictResponse is returned when a change was based on an outdated copy of a task
+type TaskConflictResponse struct {
+	Error string       `json:"error"`
+	Task  *models.Task `json:"task"` // The task as it is stored now
+}
+
+// taskETag derives a task's entity tag from its version, which the store bumps on every save
+func taskETag(task *models.Task) string {
+	return fmt.Sprintf(`"%d"`, task.Version)
+}
+
+// checkTaskVersion applies the request's If-Match header to the task it is about to change.
+// When the client's copy is outdated it responds with 412 and the current task, and returns false.
+func checkTaskVersion(w http.ResponseWriter, r *http.Request, task *models.Task) bool {
+	match := r.Header.Get("If-Match")
+	if match == "" || match == "*" || strings.Contains(match, taskETag(task)) {
+		return true
+	}
+
+	writeTaskConflict(w, http.StatusPreconditionFailed, task)
+	return false
+}
+
+// writeTaskConflict responds with the stored state of a task a client tried to change from an older copy
+func writeTaskConflict(w http.ResponseWriter, status int, current *models.Task) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(current))
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(TaskConflictResponse{
+		Error: models.ErrTaskConflict.Error(),
+		Task:  current,
+	})
+}
+
+// writeTaskSaveError reports a failed save. A task changed by another request since it was
+// loaded is answered with 409 and its current state.
+func writeTaskSaveError(w http.ResponseWriter, store models.TaskStore, task *models.Task, userID string, err error) {
+	switch err {
+	case models.ErrTaskConflict:
+		current, loadErr := store.GetForUser(task.ID, userID)
+		if loadErr != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeTaskConflict(w, http.StatusConflict, current)
+	case models.ErrTaskNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
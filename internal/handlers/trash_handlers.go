@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/views/pages"
+)
+
+// TrashHandler lists deleted tasks and projects and restores or permanently removes them
+type TrashHandler struct {
+	store         models.TaskStore
+	projects      models.ProjectStore
+	retentionDays int // Days before deleted items are purged; 0 keeps them forever
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(store models.TaskStore, projects models.ProjectStore, retentionDays int, templatesDir string) (*TrashHandler, error) {
+	return &TrashHandler{
+		store:         store,
+		projects:      projects,
+		retentionDays: retentionDays,
+	}, nil
+}
+
+// RegisterRoutes registers the trash routes
+func (h *TrashHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/api/trash", func(r chi.Router) {
+		r.Get("/", h.ListTrashAPI)
+		r.Delete("/", h.EmptyTrashAPI)
+		r.Post("/tasks/{id}/restore", h.RestoreTaskAPI)
+		r.Delete("/tasks/{id}", h.PurgeTaskAPI)
+		r.Post("/projects/{id}/restore", h.RestoreProjectAPI)
+		r.Delete("/projects/{id}", h.PurgeProjectAPI)
+	})
+
+	r.Get("/trash", h.TrashPage)
+}
+
+// TrashProject is a deleted project with the number of tasks that were deleted along with it
+type TrashProject struct {
+	*models.Project
+	DeletedTasks int `json:"deletedTasks"`
+}
+
+// TrashResponse lists the contents of the trash
+type TrashResponse struct {
+	Projects      []TrashProject `json:"projects"`
+	Tasks         []*models.Task `json:"tasks"`
+	RetentionDays int            `json:"retentionDays"` // 0 when deleted items are kept forever
+}
+
+// TrashActionResponse reports how many items a restore or purge affected
+type TrashActionResponse struct {
+	Projects int `json:"projects"`
+	Tasks    int `json:"tasks"`
+}
+
+// listTrash loads the user's deleted projects and tasks
+func (h *TrashHandler) listTrash(ctx context.Context, userID string) (*TrashResponse, error) {
+	tasks, err := h.store.ListDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := h.projects.ListDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TrashResponse{
+		Projects:      make([]TrashProject, 0, len(projects)),
+		Tasks:         tasks,
+		RetentionDays: h.retentionDays,
+	}
+	for _, project := range projects {
+		count := 0
+		for _, task := range tasks {
+			if task.ProjectID == project.ID && task.DeletedAt.Equal(*project.DeletedAt) {
+				count++
+			}
+		}
+		response.Projects = append(response.Projects, TrashProject{Project: project, DeletedTasks: count})
+	}
+	return response, nil
+}
+
+// purgeDate returns when an item deleted at the given time will be purged, or nil if it is kept forever
+func (h *TrashHandler) purgeDate(deletedAt time.Time) *time.Time {
+	if h.retentionDays <= 0 {
+		return nil
+	}
+	date := deletedAt.AddDate(0, 0, h.retentionDays)
+	return &date
+}
+
+// ListTrashAPI returns the user's deleted projects and tasks, most recently deleted first
+func (h *TrashHandler) ListTrashAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.listTrash(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// EmptyTrashAPI permanently removes everything in the user's trash
+func (h *TrashHandler) EmptyTrashAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tasks, projects, err := models.EmptyTrash(r.Context(), h.store, h.projects, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TrashActionResponse{Projects: projects, Tasks: tasks})
+}
+
+// RestoreTaskAPI brings a deleted task back and returns it
+func (h *TrashHandler) RestoreTaskAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.store.Restore(id, user.ID); err != nil {
+		writeTrashError(w, err)
+		return
+	}
+
+	task, err := h.store.GetForUser(id, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// PurgeTaskAPI permanently removes a deleted task
+func (h *TrashHandler) PurgeTaskAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.store.Purge(chi.URLParam(r, "id"), user.ID); err != nil {
+		writeTrashError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreProjectAPI brings a deleted project back; with cascade=true the tasks deleted along with
+// it are restored too
+func (h *TrashHandler) RestoreProjectAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	cascade := r.URL.Query().Get("cascade") == "true"
+	tasks, err := models.RestoreProject(r.Context(), h.store, h.projects, chi.URLParam(r, "id"), user.ID, cascade)
+	if err != nil {
+		writeTrashError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TrashActionResponse{Projects: 1, Tasks: tasks})
+}
+
+// PurgeProjectAPI permanently removes a deleted project; with cascade=true the tasks deleted along
+// with it are removed too
+func (h *TrashHandler) PurgeProjectAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	cascade := r.URL.Query().Get("cascade") == "true"
+	tasks, err := models.PurgeProject(r.Context(), h.store, h.projects, chi.URLParam(r, "id"), user.ID, cascade)
+	if err != nil {
+		writeTrashError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TrashActionResponse{Projects: 1, Tasks: tasks})
+}
+
+// writeTrashError reports items missing from the trash as not found
+func writeTrashError(w http.ResponseWriter, err error) {
+	if err == models.ErrTaskNotFound || err == models.ErrProjectNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// TrashPage renders the trash
+func (h *TrashHandler) TrashPage(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
+		return
+	}
+
+	trash, err := h.listTrash(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	projects := make([]pages.TrashProjectInfo, 0, len(trash.Projects))
+	for _, project := range trash.Projects {
+		projects = append(projects, pages.TrashProjectInfo{
+			ID:           project.ID,
+			Title:        project.Title,
+			DeletedTasks: project.DeletedTasks,
+			DeletedAt:    *project.DeletedAt,
+			PurgeAt:      h.purgeDate(*project.DeletedAt),
+		})
+	}
+	tasks := make([]pages.TrashTaskInfo, 0, len(trash.Tasks))
+	for _, task := range trash.Tasks {
+		tasks = append(tasks, pages.TrashTaskInfo{
+			ID:        task.ID,
+			Title:     task.Title,
+			Status:    string(task.Status),
+			DeletedAt: *task.DeletedAt,
+			PurgeAt:   h.purgeDate(*task.DeletedAt),
+		})
+	}
+
+	ctx := context.WithValue(r.Context(), "user", user)
+	if err := pages.TrashPage(projects, tasks, h.retentionDays).Render(ctx, w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// undoTokenHeader carries the undo token of an action, so responses without a body can return one too
+const undoTokenHeader = "X-Undo-Token"
+
+// UndoHandler lists and reverts the user's recent actions
+type UndoHandler struct {
+	store models.TaskStore
+	undo  *models.UndoStack
+}
+
+// NewUndoHandler creates a new undo handler
+func NewUndoHandler(store models.TaskStore, undo *models.UndoStack) *UndoHandler {
+	return &UndoHandler{
+		store: store,
+		undo:  undo,
+	}
+}
+
+// RegisterRoutes registers the undo routes
+func (h *UndoHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/undo", h.ListUndoAPI)
+	r.Post("/api/undo/{token}", h.UndoAPI)
+}
+
+// UndoResponse reports the action that was reverted
+type UndoResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Tasks   int    `json:"tasks"` // Tasks changed back or deleted
+}
+
+// ListUndoAPI returns the user's actions that can still be undone, newest first
+func (h *UndoHandler) ListUndoAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	actions, err := h.undo.List(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(actions)
+}
+
+// UndoAPI reverts the action with the given token
+func (h *UndoHandler) UndoAPI(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	action, err := h.undo.Undo(r.Context(), h.store, chi.URLParam(r, "token"), user.ID)
+	if err != nil {
+		switch err {
+		case models.ErrUndoNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case models.ErrUndoUnavailable:
+			http.Error(w, err.Error(), http.StatusGone)
+		case models.ErrTaskConflict:
+			http.Error(w, "a task was changed again since; the action can't be undone without overwriting that change", http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UndoResponse{
+		Success: true,
+		Message: "Undone: " + action.Label,
+		Tasks:   action.TaskCount(),
+	})
+}
+
+// pushUndo makes a successful action undoable and sets the undo token header; it returns the token.
+// The action already happened, so failing to keep it only costs the undo.
+func pushUndo(w http.ResponseWriter, undo *models.UndoStack, action *models.UndoAction) string {
+	token, err := undo.Push(action)
+	if err != nil {
+		log.Printf("Undo: keeping %q for user %s: %v", action.Label, action.UserID, err)
+		return ""
+	}
+	if token != "" {
+		w.Header().Set(undoTokenHeader, token)
+	}
+	return token
+}
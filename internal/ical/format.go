@@ -0,0 +1,377 @@
+// Package ical reads and writes the parts of iCalendar (RFC 5545) used to publish tasks as a
+// calendar feed and to import events as scheduled tasks.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the longest content line before it is folded
+const maxLineOctets = 75
+
+// maxNesting limits how deeply components may be nested in parsed input
+const maxNesting = 10
+
+// Property is a content line such as "DTSTART;TZID=Europe/Istanbul:20260301T090000"
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string // Raw value; use Text for TEXT values
+}
+
+// Param returns a parameter value, or "" if it isn't set
+func (p Property) Param(name string) string {
+	return p.Params[name]
+}
+
+// Text returns the value with TEXT escapes removed
+func (p Property) Text() string {
+	return UnescapeText(p.Value)
+}
+
+// Component is a calendar object such as VCALENDAR, VEVENT or VTODO
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+// NewComponent creates an empty component
+func NewComponent(name string) *Component {
+	return &Component{Name: name}
+}
+
+// Get returns the first property with the given name
+func (c *Component) Get(name string) (Property, bool) {
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
+
+// Add appends a property with a raw value and optional parameters given as name, value pairs
+func (c *Component) Add(name, value string, params ...string) {
+	p := Property{Name: name, Value: value}
+	if len(params) > 0 {
+		p.Params = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			p.Params[params[i]] = params[i+1]
+		}
+	}
+	c.Properties = append(c.Properties, p)
+}
+
+// AddText appends a property with an escaped TEXT value
+func (c *Component) AddText(name, text string) {
+	c.Add(name, EscapeText(text))
+}
+
+// AddTime appends a UTC DATE-TIME property
+func (c *Component) AddTime(name string, t time.Time) {
+	c.Add(name, FormatDateTime(t))
+}
+
+// AddDate appends a DATE property
+func (c *Component) AddDate(name string, t time.Time) {
+	c.Add(name, FormatDate(t), "VALUE", "DATE")
+}
+
+// Encode writes a component as iCalendar text with CRLF line endings and folded long lines
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+	encodeComponent(bw, c)
+	return bw.Flush()
+}
+
+func encodeComponent(w *bufio.Writer, c *Component) {
+	writeLine(w, "BEGIN:"+c.Name)
+	for _, p := range c.Properties {
+		writeLine(w, formatProperty(p))
+	}
+	for _, child := range c.Components {
+		encodeComponent(w, child)
+	}
+	writeLine(w, "END:"+c.Name)
+}
+
+// formatProperty renders a property as a single unfolded content line
+func formatProperty(p Property) string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := p.Params[name]
+		if strings.ContainsAny(value, ":;,") {
+			value = `"` + strings.ReplaceAll(value, `"`, "") + `"`
+		}
+		b.WriteString(";" + name + "=" + value)
+	}
+
+	b.WriteString(":" + p.Value)
+	return b.String()
+}
+
+// writeLine writes a content line, folding it after 75 octets without splitting UTF-8 characters
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1 // Continuation lines start with a space
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// Decode reads all top-level components, usually VCALENDARs, from iCalendar text
+func Decode(r io.Reader) ([]*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []*Component
+	var stack []*Component
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch p.Name {
+		case "BEGIN":
+			if len(stack) >= maxNesting {
+				return nil, fmt.Errorf("line %d: components nested too deeply", i+1)
+			}
+			c := NewComponent(strings.ToUpper(p.Value))
+			if len(stack) == 0 {
+				roots = append(roots, c)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(p.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, p.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property outside of a component", i+1)
+			}
+			c := stack[len(stack)-1]
+			c.Properties = append(c.Properties, p)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].Name)
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("no calendar data found")
+	}
+	return roots, nil
+}
+
+// unfold reads content lines, joining folded continuation lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty splits a content line into name, parameters and value
+func parseProperty(line string) (Property, error) {
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return Property{}, errors.New("malformed content line")
+	}
+
+	p := Property{Name: strings.ToUpper(line[:end])}
+	rest := line[end:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.Index(rest, "=")
+		if eq <= 0 {
+			return Property{}, errors.New("malformed parameter")
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.Index(rest[1:], `"`)
+			if closing < 0 {
+				return Property{}, errors.New("unterminated parameter value")
+			}
+			value = rest[1 : closing+1]
+			rest = rest[closing+2:]
+		} else {
+			stop := strings.IndexAny(rest, ";:")
+			if stop < 0 {
+				return Property{}, errors.New("malformed parameter")
+			}
+			value = rest[:stop]
+			rest = rest[stop:]
+		}
+
+		if p.Params == nil {
+			p.Params = make(map[string]string)
+		}
+		p.Params[name] = value
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return Property{}, errors.New("missing property value")
+	}
+	p.Value = rest[1:]
+	return p, nil
+}
+
+// EscapeText escapes a TEXT value
+func EscapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// UnescapeText removes the escapes of a TEXT value
+func UnescapeText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// SplitText splits a multi-valued TEXT value such as CATEGORIES on unescaped commas
+func SplitText(s string) []string {
+	var values []string
+	var current strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			current.WriteString(s[i : i+2])
+			i++
+		case s[i] == ',':
+			values = append(values, UnescapeText(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(s[i])
+		}
+	}
+	return append(values, UnescapeText(current.String()))
+}
+
+// FormatDateTime formats a time as a UTC DATE-TIME value
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// FormatDate formats the day of a time as a DATE value
+func FormatDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// ParseTime reads a DATE or DATE-TIME property. UTC times end in Z, TZID names the zone of
+// local times, and floating times and dates are taken in loc. allDay is true for DATE values.
+func ParseTime(p Property, loc *time.Location) (t time.Time, allDay bool, err error) {
+	value := p.Value
+	if p.Param("VALUE") == "DATE" || len(value) == 8 {
+		t, err = time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	if tzid := p.Param("TZID"); tzid != "" {
+		// Unknown zone names (e.g. Windows names) fall back to loc
+		if zone, zoneErr := time.LoadLocation(strings.TrimPrefix(tzid, "/")); zoneErr == nil {
+			loc = zone
+		}
+	}
+	t, err = time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// ParseDuration reads a DURATION value such as "PT1H30M", "P1D" or "-PT15M"
+func ParseDuration(s string) (time.Duration, error) {
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	number := ""
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			number += string(c)
+		case c == 'T':
+			inTime = true
+		default:
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			number = ""
+
+			switch {
+			case c == 'W' && !inTime:
+				d += time.Duration(n) * 7 * 24 * time.Hour
+			case c == 'D' && !inTime:
+				d += time.Duration(n) * 24 * time.Hour
+			case c == 'H' && inTime:
+				d += time.Duration(n) * time.Hour
+			case c == 'M' && inTime:
+				d += time.Duration(n) * time.Minute
+			case c == 'S' && inTime:
+				d += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,343 @@
+package ical
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// defaultEventDuration is the length of published events for tasks without a time estimate
+const defaultEventDuration = 30 * time.Minute
+
+// uidDomain is the right-hand side of the UIDs of published tasks
+const uidDomain = "@gtd"
+
+// dueSuffix tells the to-do UID of a task apart from its event UID
+const dueSuffix = "-due"
+
+// Feed builds the calendar of a user's open tasks: scheduled tasks are published as events
+// (lasting their time estimate) and tasks with a due date as to-dos
+func Feed(name string, tasks []*models.Task, now time.Time) *Component {
+	cal := NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", "-//GTD App//Tasks//EN")
+	cal.Add("CALSCALE", "GREGORIAN")
+	cal.Add("METHOD", "PUBLISH")
+	cal.AddText("X-WR-CALNAME", name)
+
+	for _, task := range tasks {
+		if task.IsDeleted() || task.Status == models.StatusDone {
+			continue
+		}
+		if task.Status == models.StatusScheduled && task.ScheduledDate != nil {
+			cal.Components = append(cal.Components, taskEvent(task, now))
+		}
+		if task.DueDate != nil {
+			cal.Components = append(cal.Components, Todo(task, task.ID+dueSuffix+uidDomain, now))
+		}
+	}
+
+	return cal
+}
+
+// taskEvent publishes a scheduled task as a VEVENT
+func taskEvent(task *models.Task, now time.Time) *Component {
+	event := NewComponent("VEVENT")
+	addTaskProperties(event, task, task.ID+uidDomain, now)
+
+	start := *task.ScheduledDate
+	if task.TimeEstimate == 0 && isMidnight(start) {
+		// A date without a time of day is an all-day event
+		event.AddDate("DTSTART", start)
+		event.AddDate("DTEND", start.AddDate(0, 0, 1))
+	} else {
+		duration := time.Duration(task.TimeEstimate) * time.Minute
+		if duration == 0 {
+			duration = defaultEventDuration
+		}
+		event.AddTime("DTSTART", start)
+		event.AddTime("DTEND", start.Add(duration))
+	}
+
+	addRecurrence(event, task)
+	event.Add("TRANSP", "OPAQUE")
+	return event
+}
+
+// Todo represents a task as a VTODO with the given UID. The scheduled date becomes DTSTART.
+func Todo(task *models.Task, uid string, now time.Time) *Component {
+	todo := NewComponent("VTODO")
+	addTaskProperties(todo, task, uid, now)
+
+	if task.ScheduledDate != nil {
+		addDateOrTime(todo, "DTSTART", *task.ScheduledDate)
+	}
+	if task.DueDate != nil {
+		addDateOrTime(todo, "DUE", *task.DueDate)
+	}
+
+	addRecurrence(todo, task)
+	if task.Status == models.StatusDone {
+		todo.Add("STATUS", "COMPLETED")
+		if task.CompletedAt != nil {
+			todo.AddTime("COMPLETED", *task.CompletedAt)
+		}
+	} else {
+		todo.Add("STATUS", "NEEDS-ACTION")
+	}
+	return todo
+}
+
+// ApplyTodo replaces the fields of a task that Todo publishes with the content of a VTODO:
+// title, description, scheduled and due dates, priority, contexts, tags and recurrence.
+// Other task fields are kept. Floating times and dates are taken in loc.
+// It reports whether the to-do is marked as completed; changing the status is left to the caller.
+func ApplyTodo(todo *Component, task *models.Task, loc *time.Location) (completed bool) {
+	task.Title = ""
+	if summary, ok := todo.Get("SUMMARY"); ok {
+		task.Title = strings.TrimSpace(summary.Text())
+	}
+	if task.Title == "" {
+		task.Title = "Untitled task"
+	}
+
+	task.Description = ""
+	if desc, ok := todo.Get("DESCRIPTION"); ok {
+		task.Description = desc.Text()
+	}
+
+	task.ScheduledDate = optionalTime(todo, "DTSTART", loc)
+	task.DueDate = optionalTime(todo, "DUE", loc)
+
+	task.Priority = 0
+	if priority, ok := todo.Get("PRIORITY"); ok {
+		switch n, _ := strconv.Atoi(priority.Value); {
+		case n >= 1 && n <= 4:
+			task.Priority = 1
+		case n == 5:
+			task.Priority = 2
+		case n >= 6 && n <= 9:
+			task.Priority = 3
+		}
+	}
+
+	task.Contexts = nil
+	task.Tags = nil
+	if categories, ok := todo.Get("CATEGORIES"); ok {
+		applyCategories(task, categories.Value)
+	}
+
+	task.IsRecurring = false
+	task.RecurringRule = ""
+	if rrule, ok := todo.Get("RRULE"); ok {
+		applyRecurrence(task, rrule.Value)
+	}
+
+	status, _ := todo.Get("STATUS")
+	_, hasCompleted := todo.Get("COMPLETED")
+	return strings.EqualFold(status.Value, "COMPLETED") || (hasCompleted && status.Value == "")
+}
+
+// optionalTime reads a DATE or DATE-TIME property, returning nil if it is missing or invalid
+func optionalTime(c *Component, name string, loc *time.Location) *time.Time {
+	p, ok := c.Get(name)
+	if !ok {
+		return nil
+	}
+	t, _, err := ParseTime(p, loc)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// addDateOrTime adds a DATE for times without a time of day and a DATE-TIME otherwise
+func addDateOrTime(c *Component, name string, t time.Time) {
+	if isMidnight(t) {
+		c.AddDate(name, t)
+	} else {
+		c.AddTime(name, t)
+	}
+}
+
+// addTaskProperties adds the properties events and to-dos share
+func addTaskProperties(c *Component, task *models.Task, uid string, now time.Time) {
+	c.Add("UID", uid)
+	c.AddTime("DTSTAMP", now)
+	c.AddTime("CREATED", task.CreatedAt)
+	c.AddTime("LAST-MODIFIED", task.UpdatedAt)
+	c.AddText("SUMMARY", task.Title)
+	if task.Description != "" {
+		c.AddText("DESCRIPTION", task.Description)
+	}
+
+	var categories []string
+	for _, context := range task.Contexts {
+		categories = append(categories, EscapeText("@"+string(context)))
+	}
+	for _, tag := range task.Tags {
+		categories = append(categories, EscapeText(tag))
+	}
+	if len(categories) > 0 {
+		c.Add("CATEGORIES", strings.Join(categories, ","))
+	}
+
+	// iCalendar priorities run from 1 (highest) to 9 (lowest)
+	switch task.Priority {
+	case 1:
+		c.Add("PRIORITY", "1")
+	case 2:
+		c.Add("PRIORITY", "5")
+	case 3:
+		c.Add("PRIORITY", "9")
+	}
+}
+
+// addRecurrence publishes the task's recurrence rule as an RRULE
+func addRecurrence(c *Component, task *models.Task) {
+	if !task.IsRecurring || task.RecurringRule == "" {
+		return
+	}
+	if rule, err := models.ParseRecurrenceRule(task.RecurringRule); err == nil {
+		c.Add("RRULE", rule.String())
+	}
+}
+
+// TaskIDFromUID returns the ID of the task a UID from Feed was generated for
+func TaskIDFromUID(uid string) (string, bool) {
+	id, ok := strings.CutSuffix(uid, uidDomain)
+	if !ok || id == "" {
+		return "", false
+	}
+	return strings.TrimSuffix(id, dueSuffix), true
+}
+
+// ImportedEvent is a task created from a calendar event
+type ImportedEvent struct {
+	UID  string
+	Task *models.Task
+}
+
+// ImportEvents reads a calendar and turns each of its events into a scheduled task for the user.
+// Floating times and dates are taken in loc. Cancelled events and events without a start are skipped;
+// the second result counts them.
+func ImportEvents(r io.Reader, userID string, loc *time.Location) ([]ImportedEvent, int, error) {
+	calendars, err := Decode(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var events []ImportedEvent
+	skipped := 0
+	for _, cal := range calendars {
+		for _, c := range cal.Components {
+			if c.Name != "VEVENT" {
+				continue
+			}
+
+			task, ok := eventTask(c, userID, loc)
+			if !ok {
+				skipped++
+				continue
+			}
+
+			uid, _ := c.Get("UID")
+			events = append(events, ImportedEvent{UID: uid.Value, Task: task})
+		}
+	}
+
+	return events, skipped, nil
+}
+
+// eventTask converts a VEVENT into a scheduled task
+func eventTask(event *Component, userID string, loc *time.Location) (*models.Task, bool) {
+	if status, ok := event.Get("STATUS"); ok && strings.EqualFold(status.Value, "CANCELLED") {
+		return nil, false
+	}
+
+	startProp, ok := event.Get("DTSTART")
+	if !ok {
+		return nil, false
+	}
+	start, allDay, err := ParseTime(startProp, loc)
+	if err != nil {
+		return nil, false
+	}
+
+	title := "Untitled event"
+	if summary, ok := event.Get("SUMMARY"); ok && strings.TrimSpace(summary.Text()) != "" {
+		title = strings.TrimSpace(summary.Text())
+	}
+
+	var description []string
+	if desc, ok := event.Get("DESCRIPTION"); ok && desc.Text() != "" {
+		description = append(description, desc.Text())
+	}
+	if location, ok := event.Get("LOCATION"); ok && location.Text() != "" {
+		description = append(description, "Location: "+location.Text())
+	}
+
+	task := models.NewTask(title, strings.Join(description, "\n\n"), userID)
+	task.Status = models.StatusScheduled
+	task.ScheduledDate = &start
+
+	if !allDay {
+		if duration := eventDuration(event, start, loc); duration > 0 {
+			task.TimeEstimate = int(duration.Round(time.Minute) / time.Minute)
+		}
+	}
+
+	if categories, ok := event.Get("CATEGORIES"); ok {
+		applyCategories(task, categories.Value)
+	}
+
+	if rrule, ok := event.Get("RRULE"); ok {
+		applyRecurrence(task, rrule.Value)
+	}
+
+	return task, true
+}
+
+// applyCategories adds CATEGORIES to a task: "@name" as a context and anything else as a tag
+func applyCategories(task *models.Task, value string) {
+	for _, category := range SplitText(value) {
+		category = strings.TrimSpace(category)
+		if context, ok := strings.CutPrefix(category, "@"); ok && context != "" {
+			task.Contexts = append(task.Contexts, models.Context(strings.ToLower(context)))
+		} else if category != "" {
+			task.Tags = append(task.Tags, strings.ToLower(category))
+		}
+	}
+}
+
+// applyRecurrence makes a task recur by an RRULE. Rules using parts the app doesn't
+// support are dropped rather than failing the import or sync.
+func applyRecurrence(task *models.Task, value string) {
+	if rule, err := models.ParseRecurrenceRule(value); err == nil {
+		task.IsRecurring = true
+		task.RecurringRule = rule.String()
+	}
+}
+
+// eventDuration returns the length of an event from its DTEND or DURATION
+func eventDuration(event *Component, start time.Time, loc *time.Location) time.Duration {
+	if endProp, ok := event.Get("DTEND"); ok {
+		if end, _, err := ParseTime(endProp, loc); err == nil {
+			return end.Sub(start)
+		}
+	}
+	if durationProp, ok := event.Get("DURATION"); ok {
+		if d, err := ParseDuration(durationProp.Value); err == nil {
+			return d
+		}
+	}
+	return 0
+}
+
+// isMidnight reports whether a time has no time of day, as dates entered without one
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+}
@@ -0,0 +1,198 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// parseOmniFocusCSV reads the CSV export of OmniFocus (Task ID, Type, Name, Status, Project, Context,
+// Start Date, Due Date, Completion Date, Duration, Flagged, Notes, Tags). Actions are imported;
+// project rows only name projects. Contexts and tags both become contexts, a start date schedules
+// the task, and flagged actions get the highest priority. Dropped actions count as completed.
+func parseOmniFocusCSV(r io.Reader, opts Options) ([]Item, error) {
+	table, err := newCSVTable(r)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has("name") || !table.has("type") {
+		return nil, errors.New("not an OmniFocus CSV: missing Name or Type column")
+	}
+
+	loc := opts.location()
+	var items []Item
+	for {
+		row, err := table.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row.empty() || !strings.EqualFold(row.get("type"), "action") {
+			continue
+		}
+
+		item := Item{
+			Line:          row.line,
+			Title:         row.get("name"),
+			Description:   row.get("notes"),
+			Project:       row.get("project"),
+			ScheduledDate: parseDateValue(row.get("start date"), loc),
+			DueDate:       parseDateValue(row.get("due date"), loc),
+			TimeEstimate:  parseEstimateValue(row.get("duration")),
+		}
+		item.addContexts(row.get("context"))
+		item.addContexts(splitList(row.get("tags"))...)
+		if parseBoolValue(row.get("flagged")) {
+			item.Priority = 1
+		}
+
+		completedAt := parseDateValue(row.get("completion date"), loc)
+		switch status := strings.ToLower(row.get("status")); {
+		case completedAt != nil, status == "completed", status == "dropped":
+			item.Completed = true
+			item.CompletedAt = completedAt
+		case status == "on hold":
+			item.Status = models.StatusSomeday
+		}
+
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Fields of the generic CSV format, in the order they are documented
+var csvFields = []string{
+	"title", "description", "project", "status", "contexts", "tags",
+	"due", "scheduled", "priority", "estimate", "recurrence", "completed",
+}
+
+// csvFieldAliases are the headers matched to a field when no mapping is given
+var csvFieldAliases = map[string][]string{
+	"title":       {"title", "name", "task", "task name", "content", "subject", "summary"},
+	"description": {"description", "notes", "note", "details"},
+	"project":     {"project", "project name"},
+	"status":      {"status", "list"},
+	"contexts":    {"contexts", "context", "labels", "label"},
+	"tags":        {"tags", "tag"},
+	"due":         {"due", "due date", "deadline"},
+	"scheduled":   {"scheduled", "scheduled date", "start", "start date", "defer date"},
+	"priority":    {"priority"},
+	"estimate":    {"estimate", "time estimate", "duration"},
+	"recurrence":  {"recurrence", "repeat", "recurring rule"},
+	"completed":   {"completed", "done", "completed at", "completion date"},
+}
+
+// csvColumns resolves the header of each field: explicit mappings first, then aliases
+func csvColumns(table *csvTable, mapping map[string]string) (map[string]string, error) {
+	columns := make(map[string]string)
+	mapped := make(map[string]bool)
+	for field, header := range mapping {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if _, ok := csvFieldAliases[field]; !ok {
+			return nil, fmt.Errorf("unknown field %q; fields are %s", field, strings.Join(csvFields, ", "))
+		}
+		mapped[field] = true
+		if header == "" {
+			continue
+		}
+		if !table.has(strings.TrimSpace(header)) {
+			return nil, fmt.Errorf("column %q mapped to %s not found", header, field)
+		}
+		columns[field] = strings.TrimSpace(header)
+	}
+
+	for field, aliases := range csvFieldAliases {
+		if _, ok := columns[field]; ok {
+			continue
+		}
+		if mapped[field] {
+			continue // Explicitly unmapped
+		}
+		for _, alias := range aliases {
+			if table.has(alias) {
+				columns[field] = alias
+				break
+			}
+		}
+	}
+
+	if columns["title"] == "" {
+		return nil, errors.New("no title column; map one with the title field")
+	}
+	return columns, nil
+}
+
+// parseGenericCSV reads a CSV file with a header row. Columns are matched to fields by name
+// (see csvFieldAliases) unless Options.Columns maps them; contexts and tags are comma or
+// semicolon separated, and a completed column holds a flag or a completion date.
+func parseGenericCSV(r io.Reader, opts Options) ([]Item, error) {
+	table, err := newCSVTable(r)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := csvColumns(table, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	value := func(row *csvRow, field string) string {
+		if columns[field] == "" {
+			return ""
+		}
+		return row.get(columns[field])
+	}
+
+	loc := opts.location()
+	var items []Item
+	for {
+		row, err := table.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row.empty() {
+			continue
+		}
+
+		item := Item{
+			Line:          row.line,
+			Title:         value(row, "title"),
+			Description:   value(row, "description"),
+			Project:       value(row, "project"),
+			DueDate:       parseDateValue(value(row, "due"), loc),
+			ScheduledDate: parseDateValue(value(row, "scheduled"), loc),
+			Priority:      parsePriorityValue(value(row, "priority")),
+			TimeEstimate:  parseEstimateValue(value(row, "estimate")),
+			RecurringRule: recurrenceValue(value(row, "recurrence")),
+		}
+		item.addContexts(splitList(value(row, "contexts"))...)
+		item.addTags(splitList(value(row, "tags"))...)
+
+		if status, ok := parseStatusValue(value(row, "status")); ok {
+			item.Status = status
+		}
+		if completed := value(row, "completed"); parseBoolValue(completed) {
+			item.Completed = true
+		} else if item.CompletedAt = parseDateValue(completed, loc); item.CompletedAt != nil {
+			item.Completed = true
+		}
+		if item.Status == models.StatusDone {
+			item.Completed = true
+			item.Status = ""
+		}
+
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// CSVFields returns the field names a generic CSV mapping can use
+func CSVFields() []string {
+	return append([]string(nil), csvFields...)
+}
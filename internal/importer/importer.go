@@ -0,0 +1,354 @@
+// Package importer brings tasks over from other task managers. Parsers for each supported
+// export format turn a file into Items; an Importer maps the items onto tasks and projects,
+// skipping duplicates, and reports what was (or, in a dry run, would be) created.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// Item is a task read from an export file, before it is mapped onto a Task
+type Item struct {
+	Line          int // Line or record number in the file, for the report
+	Title         string
+	Description   string
+	Project       string // Project title; matched to an existing project or created
+	Status        models.TaskStatus
+	Contexts      []models.Context
+	Tags          []string
+	DueDate       *time.Time
+	ScheduledDate *time.Time
+	Priority      int // 1-3, 1 highest
+	TimeEstimate  int // Minutes
+	RecurringRule string
+	Completed     bool
+	CompletedAt   *time.Time
+}
+
+// Options controls how a file is parsed and imported
+type Options struct {
+	Location         *time.Location    // Zone of dates without one; defaults to time.Local
+	Project          string            // Project for items without one, e.g. a per-project Todoist CSV
+	DefaultStatus    models.TaskStatus // Status of items that don't determine one; defaults to inbox
+	IncludeCompleted bool              // Import completed items as done instead of skipping them
+	Columns          map[string]string // Field name to column header, for the generic CSV format
+}
+
+// location returns the zone for dates without one
+func (o Options) location() *time.Location {
+	if o.Location == nil {
+		return time.Local
+	}
+	return o.Location
+}
+
+// Parser reads the items of an export file
+type Parser interface {
+	Parse(r io.Reader, opts Options) ([]Item, error)
+}
+
+// ParserFunc adapts a function to the Parser interface
+type ParserFunc func(r io.Reader, opts Options) ([]Item, error)
+
+// Parse calls f
+func (f ParserFunc) Parse(r io.Reader, opts Options) ([]Item, error) {
+	return f(r, opts)
+}
+
+// Format is a registered import format
+type Format struct {
+	Name        string `json:"name"` // Identifier used by the API
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Parser      Parser `json:"-"`
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   = make(map[string]Format)
+)
+
+// Register adds an import format, replacing a format with the same name
+func Register(format Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[format.Name] = format
+}
+
+// Lookup returns the format with the given name
+func Lookup(name string) (Format, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	format, ok := formats[name]
+	return format, ok
+}
+
+// Formats returns the registered formats sorted by name
+func Formats() []Format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	list := make([]Format, 0, len(formats))
+	for _, format := range formats {
+		list = append(list, format)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func init() {
+	Register(Format{Name: "todoist-csv", Label: "Todoist CSV", Parser: ParserFunc(parseTodoistCSV),
+		Description: "A project exported from Todoist as a CSV template; set the project name when importing"})
+	Register(Format{Name: "todoist-json", Label: "Todoist JSON", Parser: ParserFunc(parseTodoistJSON),
+		Description: "Projects and tasks from the Todoist API, as a sync backup or a list of tasks"})
+	Register(Format{Name: "taskpaper", Label: "TaskPaper", Parser: ParserFunc(parseTaskPaper),
+		Description: "TaskPaper text with projects, \"- \" tasks, notes and @tags"})
+	Register(Format{Name: "omnifocus-csv", Label: "OmniFocus CSV", Parser: ParserFunc(parseOmniFocusCSV),
+		Description: "The CSV export of OmniFocus and apps using its columns"})
+	Register(Format{Name: "csv", Label: "Generic CSV", Parser: ParserFunc(parseGenericCSV),
+		Description: "Any CSV with a header row; columns are matched by name or mapped explicitly"})
+}
+
+// Item actions in a report
+const (
+	ActionCreate    = "create"
+	ActionDuplicate = "duplicate"
+	ActionSkip      = "skip"
+)
+
+// ItemResult is what happened to a single item
+type ItemResult struct {
+	Line    int               `json:"line"`
+	Title   string            `json:"title"`
+	Action  string            `json:"action"`
+	Reason  string            `json:"reason,omitempty"`
+	Status  models.TaskStatus `json:"status,omitempty"`
+	Project string            `json:"project,omitempty"`
+	TaskID  string            `json:"taskId,omitempty"` // The created task, or the existing one for duplicates
+}
+
+// Report summarizes an import
+type Report struct {
+	Format          string       `json:"format"`
+	DryRun          bool         `json:"dryRun"`
+	Total           int          `json:"total"`
+	Created         int          `json:"created"`
+	Duplicates      int          `json:"duplicates"`
+	Skipped         int          `json:"skipped"`
+	ProjectsCreated []string     `json:"projectsCreated"`
+	ProjectsMatched []string     `json:"projectsMatched"`
+	Items           []ItemResult `json:"items"`
+}
+
+// Importer maps parsed items onto a user's tasks and projects
+type Importer struct {
+	tasks    models.TaskStore
+	projects models.ProjectStore
+	contexts models.ContextStore
+}
+
+// New creates an importer writing to the given stores
+func New(tasks models.TaskStore, projects models.ProjectStore, contexts models.ContextStore) *Importer {
+	return &Importer{tasks: tasks, projects: projects, contexts: contexts}
+}
+
+// Parse reads a file in the named format
+func Parse(format string, r io.Reader, opts Options) ([]Item, error) {
+	f, ok := Lookup(format)
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q", format)
+	}
+	return f.Parser.Parse(r, opts)
+}
+
+// Run imports items for a user. Items matching an existing task or an earlier item by title,
+// project, due and scheduled dates are reported as duplicates; projects are matched by title or created.
+// Contexts the user hasn't set up are imported as tags.
+// A dry run reports the same outcome without saving anything.
+func (im *Importer) Run(ctx context.Context, userID string, items []Item, opts Options, dryRun bool) (*Report, error) {
+	report := &Report{
+		DryRun:          dryRun,
+		Total:           len(items),
+		ProjectsCreated: []string{},
+		ProjectsMatched: []string{},
+		Items:           make([]ItemResult, 0, len(items)),
+	}
+
+	existingProjects, err := im.projects.List(ctx, models.ProjectFilter{UserID: userID}, models.ProjectSortCreatedAsc)
+	if err != nil {
+		return nil, err
+	}
+	projectsByTitle := make(map[string]*models.Project)
+	projectTitles := make(map[string]string) // Project ID to normalized title
+	for _, project := range existingProjects {
+		key := normalizeTitle(project.Title)
+		if _, ok := projectsByTitle[key]; !ok {
+			projectsByTitle[key] = project
+		}
+		projectTitles[project.ID] = key
+	}
+
+	loc := opts.location()
+	seen, err := im.existingTaskKeys(ctx, userID, projectTitles, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[string]bool) // Projects already reported, by normalized title
+	for _, item := range items {
+		result := ItemResult{Line: item.Line, Title: strings.TrimSpace(item.Title)}
+
+		projectTitle := strings.TrimSpace(item.Project)
+		if projectTitle == "" {
+			projectTitle = strings.TrimSpace(opts.Project)
+		}
+		result.Project = projectTitle
+
+		switch {
+		case result.Title == "":
+			result.Action, result.Reason = ActionSkip, "missing title"
+		case item.Completed && !opts.IncludeCompleted:
+			result.Action, result.Reason = ActionSkip, "completed"
+		}
+		if result.Action != "" {
+			report.Skipped++
+			report.Items = append(report.Items, result)
+			continue
+		}
+
+		task := itemTask(item, userID, opts)
+		if err := models.ContextsToTags(ctx, im.contexts, userID, task); err != nil {
+			return nil, err
+		}
+		key := duplicateKey(task, normalizeTitle(projectTitle), loc)
+		if id, ok := seen[key]; ok {
+			result.Action, result.TaskID = ActionDuplicate, id
+			report.Duplicates++
+			report.Items = append(report.Items, result)
+			continue
+		}
+
+		if err := task.Validate(); err != nil {
+			result.Action, result.Reason = ActionSkip, err.Error()
+			report.Skipped++
+			report.Items = append(report.Items, result)
+			continue
+		}
+		result.Status = task.Status
+
+		if projectTitle != "" {
+			projectKey := normalizeTitle(projectTitle)
+			project, ok := projectsByTitle[projectKey]
+			if ok {
+				if !matched[projectKey] {
+					report.ProjectsMatched = append(report.ProjectsMatched, project.Title)
+				}
+			} else {
+				project = models.NewProject(projectTitle, "", userID)
+				if !dryRun {
+					if err := im.projects.SaveForUser(project, userID); err != nil {
+						return nil, err
+					}
+				}
+				projectsByTitle[projectKey] = project
+				report.ProjectsCreated = append(report.ProjectsCreated, project.Title)
+			}
+			matched[projectKey] = true
+			task.ProjectID = project.ID
+			result.Project = project.Title
+		}
+
+		if !dryRun {
+			if err := im.tasks.SaveForUser(task, userID); err != nil {
+				return nil, fmt.Errorf("line %d: %w", item.Line, err)
+			}
+			result.TaskID = task.ID
+		}
+		seen[key] = task.ID
+
+		result.Action = ActionCreate
+		report.Created++
+		report.Items = append(report.Items, result)
+	}
+
+	return report, nil
+}
+
+// itemTask creates the task for an item
+func itemTask(item Item, userID string, opts Options) *models.Task {
+	task := models.NewTask(strings.TrimSpace(item.Title), strings.TrimSpace(item.Description), userID)
+	task.Contexts = item.Contexts
+	task.Tags = item.Tags
+	task.DueDate = item.DueDate
+	task.ScheduledDate = item.ScheduledDate
+	task.TimeEstimate = item.TimeEstimate
+	if item.Priority >= 1 && item.Priority <= 3 {
+		task.Priority = item.Priority
+	}
+
+	if item.RecurringRule != "" {
+		if rule, err := models.ParseRecurrenceRule(item.RecurringRule); err == nil {
+			task.IsRecurring = true
+			task.RecurringRule = rule.String()
+		}
+	}
+
+	switch {
+	case item.Completed:
+		task.Status = models.StatusDone
+		task.CompletedAt = item.CompletedAt
+		if task.CompletedAt == nil {
+			now := time.Now()
+			task.CompletedAt = &now
+		}
+	case item.Status != "":
+		task.Status = item.Status
+	case item.ScheduledDate != nil:
+		task.Status = models.StatusScheduled
+	case opts.DefaultStatus != "":
+		task.Status = opts.DefaultStatus
+	}
+	return task
+}
+
+// normalizeTitle folds case and whitespace so titles compare as people read them
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// existingTaskKeys loads a user's tasks and returns their duplicate keys mapped to task IDs
+func (im *Importer) existingTaskKeys(ctx context.Context, userID string, projectTitles map[string]string, loc *time.Location) (map[string]string, error) {
+	tasks, err := models.QueryAll(ctx, im.tasks, models.TaskQuery{
+		Filter: models.TaskFilter{UserID: userID},
+		Sort:   models.SortCreatedAsc,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]string)
+	for _, task := range tasks {
+		seen[duplicateKey(task, projectTitles[task.ProjectID], loc)] = task.ID
+	}
+	return seen, nil
+}
+
+// duplicateKey identifies a task by its title within a project and the days it is due and scheduled,
+// so a repeated task on other days isn't taken for a duplicate
+func duplicateKey(task *models.Task, projectKey string, loc *time.Location) string {
+	day := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.In(loc).Format("2006-01-02")
+	}
+	return strings.Join([]string{normalizeTitle(task.Title), projectKey, day(task.DueDate), day(task.ScheduledDate)}, "\x00")
+}
@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// taskPaperTag matches "@tag" and "@tag(value)"
+var taskPaperTag = regexp.MustCompile(`(?:^|\s)@([\w.-]+)(?:\(([^)]*)\))?`)
+
+// taskPaperProject is an open project and the indentation it was written at
+type taskPaperProject struct {
+	indent int
+	name   string
+}
+
+// parseTaskPaper reads TaskPaper text: lines ending in ":" are projects, lines starting with "- " are
+// tasks of the nearest project above them, and other lines are notes of the task above them.
+// Tasks in an "Inbox:" project go to the inbox. Tags map onto task fields:
+//
+//	@due(date) @start(date) @defer(date)  due and scheduled dates
+//	@done(date)                           completed
+//	@flagged @today @priority(1-3)        priority
+//	@estimate(30m) @duration(1h)          time estimate
+//	@waiting @someday @maybe              list
+//	@repeat(every monday)                 recurrence
+//	@tag(value)                           other tags with a value become tags
+//	@home                                 other tags without a value become contexts
+func parseTaskPaper(r io.Reader, opts Options) ([]Item, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var items []Item
+	var projects []taskPaperProject
+	lastTask := -1 // Index of the task notes are added to
+	taskIndent := 0
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		text := strings.TrimLeft(raw, " \t")
+		if text == "" {
+			continue
+		}
+		indent := taskPaperIndent(raw[:len(raw)-len(text)])
+
+		// Leaving a project's indentation closes it
+		for len(projects) > 0 && projects[len(projects)-1].indent >= indent {
+			projects = projects[:len(projects)-1]
+		}
+
+		switch {
+		case strings.HasPrefix(text, "- ") || text == "-":
+			item := Item{Line: lineNumber}
+			item.Title = applyTaskPaperTags(&item, strings.TrimSpace(strings.TrimPrefix(text, "-")), opts)
+			if len(projects) > 0 {
+				project := projects[len(projects)-1].name
+				if strings.EqualFold(project, "Inbox") {
+					if item.Status == "" {
+						item.Status = models.StatusInbox
+					}
+				} else {
+					item.Project = project
+				}
+			}
+			items = append(items, item)
+			lastTask, taskIndent = len(items)-1, indent
+
+		case strings.HasSuffix(taskPaperTag.ReplaceAllString(text, ""), ":"):
+			name := strings.TrimSuffix(strings.TrimSpace(taskPaperTag.ReplaceAllString(text, "")), ":")
+			projects = append(projects, taskPaperProject{indent: indent, name: strings.TrimSpace(name)})
+			lastTask = -1
+
+		default:
+			if lastTask >= 0 && indent > taskIndent {
+				task := &items[lastTask]
+				task.Description = strings.TrimSpace(task.Description + "\n" + text)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// taskPaperIndent counts indentation levels; four spaces count as a tab
+func taskPaperIndent(whitespace string) int {
+	tabs := strings.Count(whitespace, "\t")
+	return tabs + strings.Count(whitespace, " ")/4
+}
+
+// applyTaskPaperTags applies the tags of a task line to an item and returns the line without them
+func applyTaskPaperTags(item *Item, text string, opts Options) string {
+	loc := opts.location()
+	for _, match := range taskPaperTag.FindAllStringSubmatch(text, -1) {
+		name, value := strings.ToLower(match[1]), strings.TrimSpace(match[2])
+		hasValue := strings.Contains(match[0], "(")
+
+		switch name {
+		case "due":
+			item.DueDate = parseDateValue(value, loc)
+		case "start", "defer":
+			item.ScheduledDate = parseDateValue(value, loc)
+		case "done":
+			item.Completed = true
+			item.CompletedAt = parseDateValue(value, loc)
+		case "flagged", "today":
+			item.Priority = 1
+		case "priority":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				item.Priority = min(n, 3)
+			}
+		case "estimate", "duration":
+			item.TimeEstimate = parseEstimateValue(value)
+		case "waiting":
+			item.Status = models.StatusWaiting
+		case "someday", "maybe":
+			item.Status = models.StatusSomeday
+		case "repeat":
+			item.RecurringRule = recurrenceValue(value)
+		default:
+			if hasValue {
+				item.addTags(name)
+			} else {
+				item.addContexts(name)
+			}
+		}
+	}
+	return strings.Join(strings.Fields(taskPaperTag.ReplaceAllString(text, " ")), " ")
+}
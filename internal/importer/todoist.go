@@ -0,0 +1,243 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// todoistPriority converts Todoist's priority, where 4 is "p1" and 1 means none
+func todoistPriority(priority int) int {
+	switch priority {
+	case 4:
+		return 1
+	case 3:
+		return 2
+	case 2:
+		return 3
+	}
+	return 0
+}
+
+// todoistContent splits "@labels" off the content of a Todoist task
+func todoistContent(content string) (string, []string) {
+	var title, labels []string
+	for _, word := range strings.Fields(content) {
+		if len(word) > 1 && word[0] == '@' {
+			labels = append(labels, word[1:])
+			continue
+		}
+		title = append(title, word)
+	}
+	return strings.Join(title, " "), labels
+}
+
+// todoistDuration converts a duration amount and unit into minutes
+func todoistDuration(amount int, unit string) int {
+	if amount > 0 && (unit == "" || strings.EqualFold(unit, "minute")) {
+		return amount
+	}
+	return 0
+}
+
+// parseTodoistCSV reads a project exported from Todoist as a CSV template
+// (TYPE, CONTENT, DESCRIPTION, PRIORITY, INDENT, ..., DATE, DURATION, DURATION_UNIT).
+// Labels written as @label become contexts and notes are added to the task above them.
+// Sub-tasks are imported alongside their parents; sections are not carried over.
+func parseTodoistCSV(r io.Reader, opts Options) ([]Item, error) {
+	table, err := newCSVTable(r)
+	if err != nil {
+		return nil, err
+	}
+	if !table.has("type") || !table.has("content") {
+		return nil, errors.New("not a Todoist CSV: missing TYPE or CONTENT column")
+	}
+
+	var items []Item
+	for {
+		row, err := table.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch strings.ToLower(row.get("type")) {
+		case "task":
+			title, labels := todoistContent(row.get("content"))
+			item := Item{Line: row.line, Title: title, Description: row.get("description")}
+			item.addContexts(labels...)
+
+			priority, _ := strconv.Atoi(row.get("priority"))
+			item.Priority = todoistPriority(priority)
+
+			duration, _ := strconv.Atoi(row.get("duration"))
+			item.TimeEstimate = todoistDuration(duration, row.get("duration_unit"))
+
+			loc := opts.location()
+			if zone, err := time.LoadLocation(row.get("timezone")); err == nil && row.get("timezone") != "" {
+				loc = zone
+			}
+			if date := row.get("date"); strings.HasPrefix(strings.ToLower(date), "every") {
+				item.RecurringRule = recurrenceValue(date)
+			} else {
+				item.DueDate = parseDateValue(date, loc)
+			}
+
+			items = append(items, item)
+		case "note":
+			if len(items) > 0 {
+				last := &items[len(items)-1]
+				last.Description = strings.TrimSpace(last.Description + "\n\n" + row.get("content"))
+			}
+		}
+	}
+	return items, nil
+}
+
+// todoistID is a Todoist ID, a number in older API versions and a string in newer ones
+type todoistID string
+
+// UnmarshalJSON accepts both forms
+func (id *todoistID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = todoistID(s)
+		return nil
+	}
+	*id = todoistID(strings.TrimSpace(string(data)))
+	return nil
+}
+
+// todoistTask is a task ("item") of the Todoist REST or Sync API
+type todoistTask struct {
+	Content     string      `json:"content"`
+	Description string      `json:"description"`
+	ProjectID   todoistID   `json:"project_id"`
+	Labels      []todoistID `json:"labels"`
+	Priority    int         `json:"priority"`
+	Checked     bool        `json:"checked"`
+	IsCompleted bool        `json:"is_completed"`
+	CompletedAt string      `json:"completed_at"`
+	Due         *struct {
+		Date        string `json:"date"`
+		Datetime    string `json:"datetime"`
+		String      string `json:"string"`
+		Timezone    string `json:"timezone"`
+		IsRecurring bool   `json:"is_recurring"`
+	} `json:"due"`
+	Duration *struct {
+		Amount int    `json:"amount"`
+		Unit   string `json:"unit"`
+	} `json:"duration"`
+}
+
+// todoistBackup is the part of a Sync API response or backup this importer reads
+type todoistBackup struct {
+	Projects []struct {
+		ID           todoistID `json:"id"`
+		Name         string    `json:"name"`
+		InboxProject bool      `json:"inbox_project"`
+	} `json:"projects"`
+	Labels []struct {
+		ID   todoistID `json:"id"`
+		Name string    `json:"name"`
+	} `json:"labels"`
+	Items []todoistTask `json:"items"`
+	Tasks []todoistTask `json:"tasks"`
+}
+
+// parseTodoistJSON reads Todoist tasks from a Sync API response or backup (an object with
+// "projects" and "items") or from a REST API task list. Tasks of the Todoist inbox go to the inbox,
+// labels become contexts, and recurring due dates keep their repeat when the app supports it.
+func parseTodoistJSON(r io.Reader, opts Options) ([]Item, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var backup todoistBackup
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &backup.Tasks)
+	} else {
+		err = json.Unmarshal(data, &backup)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make(map[todoistID]string)
+	inbox := make(map[todoistID]bool)
+	for _, project := range backup.Projects {
+		projects[project.ID] = project.Name
+		if project.InboxProject || strings.EqualFold(project.Name, "Inbox") {
+			inbox[project.ID] = true
+		}
+	}
+	labels := make(map[todoistID]string)
+	for _, label := range backup.Labels {
+		labels[label.ID] = label.Name
+	}
+
+	tasks := append(backup.Items, backup.Tasks...)
+	items := make([]Item, 0, len(tasks))
+	for i, task := range tasks {
+		title, inlineLabels := todoistContent(task.Content)
+		item := Item{
+			Line:        i + 1,
+			Title:       title,
+			Description: task.Description,
+			Priority:    todoistPriority(task.Priority),
+			Completed:   task.Checked || task.IsCompleted,
+		}
+
+		if inbox[task.ProjectID] {
+			item.Status = models.StatusInbox
+		} else {
+			item.Project = projects[task.ProjectID]
+		}
+
+		item.addContexts(inlineLabels...)
+		for _, label := range task.Labels {
+			if name, ok := labels[label]; ok {
+				item.addContexts(name)
+			} else {
+				item.addContexts(string(label))
+			}
+		}
+
+		if task.Due != nil {
+			loc := opts.location()
+			if zone, err := time.LoadLocation(task.Due.Timezone); err == nil && task.Due.Timezone != "" {
+				loc = zone
+			}
+			item.DueDate = parseDateValue(task.Due.Datetime, loc)
+			if item.DueDate == nil {
+				item.DueDate = parseDateValue(task.Due.Date, loc)
+			}
+			if task.Due.IsRecurring {
+				item.RecurringRule = recurrenceValue(task.Due.String)
+			}
+		}
+
+		if task.Duration != nil {
+			item.TimeEstimate = todoistDuration(task.Duration.Amount, task.Duration.Unit)
+		}
+		if item.Completed {
+			item.CompletedAt = parseDateValue(task.CompletedAt, opts.location())
+		}
+
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,288 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+	"github.com/melihkorkmaz/gtd/internal/parser"
+)
+
+// dateLayouts are the date formats found in exports, tried in order.
+// Slashed dates are read month first, as US-centric apps write them.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"1/2/2006 15:04",
+	"1/2/2006 3:04 PM",
+	"1/2/2006",
+	"2.1.2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+}
+
+// parseDateValue reads a date or date-time; times without a zone are taken in loc.
+// Phrases such as "tomorrow" or "next friday" are understood as in quick capture.
+// Empty and unrecognized values give nil.
+func parseDateValue(s string, loc *time.Location) *time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return &t
+		}
+	}
+
+	result := parser.Parse(s, time.Now().In(loc))
+	if result.Title == "" && result.DueDate != nil {
+		return result.DueDate
+	}
+	return nil
+}
+
+// parseEstimateValue reads a duration such as "30", "45m", "1h30m", "1.5 hours" or "30 min" as minutes.
+// A bare number means minutes.
+func parseEstimateValue(s string) int {
+	s = strings.ToLower(strings.Join(strings.Fields(s), ""))
+	if s == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return n
+	}
+
+	s = strings.NewReplacer("hours", "h", "hour", "h", "hrs", "h", "hr", "h",
+		"minutes", "m", "minute", "m", "mins", "m", "min", "m").Replace(s)
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Minute {
+		return 0
+	}
+	return int(d.Round(time.Minute) / time.Minute)
+}
+
+// parsePriorityValue reads "1".."3", "p1".."p3" or high, medium and low
+func parsePriorityValue(s string) int {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "1", "p1", "high", "!1":
+		return 1
+	case "2", "p2", "medium", "normal", "!2":
+		return 2
+	case "3", "p3", "low", "!3":
+		return 3
+	}
+	return 0
+}
+
+// parseBoolValue reads the ways exports write a checked box
+func parseBoolValue(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "true", "yes", "y", "x", "done", "completed", "complete", "flagged":
+		return true
+	}
+	return false
+}
+
+// parseStatusValue reads a GTD list name such as "next", "Next Actions" or "Waiting For"
+func parseStatusValue(s string) (models.TaskStatus, bool) {
+	switch strings.ToLower(strings.Join(strings.Fields(s), " ")) {
+	case "inbox":
+		return models.StatusInbox, true
+	case "next", "next action", "next actions", "active":
+		return models.StatusNext, true
+	case "waiting", "waiting for", "waiting on", "delegated":
+		return models.StatusWaiting, true
+	case "scheduled", "calendar":
+		return models.StatusScheduled, true
+	case "someday", "maybe", "someday/maybe", "someday maybe", "on hold":
+		return models.StatusSomeday, true
+	case "reference":
+		return models.StatusReference, true
+	case "done", "completed", "complete":
+		return models.StatusDone, true
+	}
+	return "", false
+}
+
+// splitList splits a list of contexts or tags on commas and semicolons
+func splitList(s string) []string {
+	var values []string
+	for _, value := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// contextName turns a context or label into a context: "@Phone" becomes "phone", and a
+// nested OmniFocus context such as "Errands : Grocery" its innermost part, "grocery"
+func contextName(s string) models.Context {
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		s = s[i+1:]
+	}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "@")
+	return models.Context(strings.ToLower(strings.Join(strings.Fields(s), "-")))
+}
+
+// tagName normalizes a tag the way quick capture does
+func tagName(s string) string {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	return strings.ToLower(strings.Join(strings.Fields(s), "-"))
+}
+
+// addContexts appends contexts to an item, skipping empty and repeated ones
+func (item *Item) addContexts(names ...string) {
+	for _, name := range names {
+		context := contextName(name)
+		if context == "" || containsContext(item.Contexts, context) {
+			continue
+		}
+		item.Contexts = append(item.Contexts, context)
+	}
+}
+
+// addTags appends tags to an item, skipping empty and repeated ones
+func (item *Item) addTags(names ...string) {
+	for _, name := range names {
+		tag := tagName(name)
+		if tag == "" || containsTag(item.Tags, tag) {
+			continue
+		}
+		item.Tags = append(item.Tags, tag)
+	}
+}
+
+func containsContext(contexts []models.Context, context models.Context) bool {
+	for _, c := range contexts {
+		if c == context {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// recurrenceValue converts a natural-language repeat such as Todoist's "every monday" or
+// "every 2 weeks" into a rule; it returns "" for repeats the app can't express
+func recurrenceValue(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	candidates := []string{s}
+	switch rest, ok := strings.CutPrefix(s, "every "); {
+	case !ok:
+	case rest == "day":
+		candidates = append(candidates, "daily")
+	case rest == "week":
+		candidates = append(candidates, "weekly")
+	case rest == "month":
+		candidates = append(candidates, "monthly")
+	case rest == "year":
+		candidates = append(candidates, "yearly")
+	default:
+		candidates = append(candidates, "weekly on "+rest)
+	}
+
+	for _, candidate := range candidates {
+		if rule, err := models.ParseRecurrenceRule(candidate); err == nil {
+			return rule.String()
+		}
+	}
+	return ""
+}
+
+// csvTable is a CSV file read by header names
+type csvTable struct {
+	reader  *csv.Reader
+	columns map[string]int // Lowercased header to column index
+}
+
+// newCSVTable reads the header row of a CSV file
+func newCSVTable(r io.Reader) (*csvTable, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("empty file")
+		}
+		return nil, err
+	}
+
+	table := &csvTable{reader: reader, columns: make(map[string]int, len(header))}
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // Byte order mark written by spreadsheet apps
+		}
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, ok := table.columns[key]; !ok {
+			table.columns[key] = i
+		}
+	}
+	return table, nil
+}
+
+// csvRow is a record of a csvTable
+type csvRow struct {
+	table  *csvTable
+	fields []string
+	line   int
+}
+
+// next returns the next record, or io.EOF
+func (t *csvTable) next() (*csvRow, error) {
+	fields, err := t.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := t.reader.FieldPos(0)
+	return &csvRow{table: t, fields: fields, line: line}, nil
+}
+
+// has reports whether the table has a column
+func (t *csvTable) has(name string) bool {
+	_, ok := t.columns[strings.ToLower(name)]
+	return ok
+}
+
+// get returns the trimmed value of a column, or "" if the row or table lacks it
+func (row *csvRow) get(name string) string {
+	i, ok := row.table.columns[strings.ToLower(name)]
+	if !ok || i >= len(row.fields) {
+		return ""
+	}
+	return strings.TrimSpace(row.fields[i])
+}
+
+// empty reports whether every field of the row is blank
+func (row *csvRow) empty() bool {
+	for _, field := range row.fields {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
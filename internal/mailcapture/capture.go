@@ -0,0 +1,98 @@
+package mailcapture
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// Capturer is the Backend that files mail sent to inbox+<token>@domain into the token owner's inbox
+type Capturer struct {
+	tasks       models.TaskStore
+	tokens      models.CaptureTokenStore
+	attachments models.AttachmentStore
+	contexts    models.ContextStore
+	domain      string
+}
+
+// NewCapturer creates a capture backend for addresses at domain
+func NewCapturer(tasks models.TaskStore, tokens models.CaptureTokenStore, attachments models.AttachmentStore, contexts models.ContextStore, domain string) *Capturer {
+	return &Capturer{
+		tasks:       tasks,
+		tokens:      tokens,
+		attachments: attachments,
+		contexts:    contexts,
+		domain:      domain,
+	}
+}
+
+// userForAddress returns the user that owns a capture address
+func (c *Capturer) userForAddress(address string) (string, error) {
+	token, ok := models.ParseCaptureAddress(address, c.domain)
+	if !ok {
+		return "", models.ErrCaptureTokenNotFound
+	}
+	return c.tokens.GetUserID(token)
+}
+
+// AcceptRecipient accepts the capture addresses of existing users
+func (c *Capturer) AcceptRecipient(address string) error {
+	_, err := c.userForAddress(address)
+	return err
+}
+
+// Deliver creates an inbox task for every recipient of the message
+func (c *Capturer) Deliver(from string, to []string, data []byte) error {
+	msg, err := ParseMessage(data)
+	if err != nil {
+		return fmt.Errorf("parsing message: %w", err)
+	}
+
+	for _, address := range to {
+		userID, err := c.userForAddress(address)
+		if err != nil {
+			return err
+		}
+
+		task, err := c.capture(msg, userID)
+		if err != nil {
+			return err
+		}
+		log.Printf("Mail capture: created task %s from %s with %d attachment(s)", task.ID, from, len(msg.Attachments))
+	}
+
+	return nil
+}
+
+// capture turns a message into an inbox task of the user and stores its attachments.
+// Contexts in the subject that the user hasn't set up stay in the title.
+func (c *Capturer) capture(msg *Message, userID string) (*models.Task, error) {
+	title, contexts, tags := models.ExtractCaptureTags(msg.Subject)
+	if title == "" {
+		title = "Email from " + msg.From
+		if msg.From == "" {
+			title = "Captured email"
+		}
+	}
+
+	task := models.NewTask(title, msg.Text, userID)
+	task.Contexts = contexts
+	task.Tags = tags
+	if err := models.ContextsToTitle(context.Background(), c.contexts, userID, task); err != nil {
+		return nil, err
+	}
+
+	if err := c.tasks.SaveForUser(task, userID); err != nil {
+		return nil, err
+	}
+
+	for _, a := range msg.Attachments {
+		if err := c.attachments.Save(models.NewAttachment(task, a.Filename, a.ContentType, a.Data)); err != nil {
+			return nil, err
+		}
+	}
+
+	return task, nil
+}
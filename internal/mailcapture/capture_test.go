@@ -0,0 +1,304 @@
+package mailcapture
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+const testDomain = "capture.test"
+
+// captureServer runs a capture SMTP server on a local port until the test ends
+type captureServer struct {
+	addr        string
+	tasks       *models.MemoryTaskStore
+	tokens      *models.MemoryCaptureTokenStore
+	attachments *models.MemoryAttachmentStore
+	contexts    *models.MemoryContextStore
+}
+
+func startCaptureServer(t *testing.T) *captureServer {
+	t.Helper()
+
+	cs := &captureServer{
+		tasks:       models.NewMemoryTaskStore(),
+		tokens:      models.NewMemoryCaptureTokenStore(),
+		attachments: models.NewMemoryAttachmentStore(),
+		contexts:    models.NewMemoryContextStore(),
+	}
+	server := NewServer("", testDomain, 1<<20, NewCapturer(cs.tasks, cs.tokens, cs.attachments, cs.contexts, testDomain))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs.addr = l.Addr().String()
+	go server.Serve(l)
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	})
+	return cs
+}
+
+// addressFor returns the capture address of a user
+func (cs *captureServer) addressFor(t *testing.T, userID string) string {
+	t.Helper()
+
+	token, err := cs.tokens.GetOrCreate(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return models.CaptureAddress(token, testDomain)
+}
+
+// send delivers a message through net/smtp; lines are joined with CRLF
+func (cs *captureServer) send(to string, lines ...string) error {
+	return smtp.SendMail(cs.addr, nil, "sender@example.com", []string{to}, []byte(strings.Join(lines, "\r\n")+"\r\n"))
+}
+
+// inbox returns the inbox tasks of a user
+func (cs *captureServer) inbox(t *testing.T, userID string) []*models.Task {
+	t.Helper()
+
+	tasks, err := cs.tasks.GetByStatusAndUserID(models.StatusInbox, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tasks
+}
+
+// defineContext sets up a context for a user
+func (cs *captureServer) defineContext(t *testing.T, userID string, name models.Context) {
+	t.Helper()
+
+	if err := cs.contexts.SaveForUser(models.NewContextDefinition(name, userID), userID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCaptureRoutesByToken(t *testing.T) {
+	cs := startCaptureServer(t)
+	alice := cs.addressFor(t, "alice")
+	cs.addressFor(t, "bob")
+	cs.defineContext(t, "alice", "Phone")
+
+	err := cs.send(alice,
+		"From: Sender <sender@example.com>",
+		"To: "+alice,
+		"Subject: Call the dentist @phone #health",
+		"",
+		"Ask about the appointment.",
+	)
+	if err != nil {
+		t.Fatalf("sending: %v", err)
+	}
+
+	tasks := cs.inbox(t, "alice")
+	if len(tasks) != 1 {
+		t.Fatalf("alice has %d inbox tasks, want 1", len(tasks))
+	}
+	task := tasks[0]
+	if task.Title != "Call the dentist" {
+		t.Errorf("title = %q, want %q", task.Title, "Call the dentist")
+	}
+	if task.Description != "Ask about the appointment." {
+		t.Errorf("description = %q", task.Description)
+	}
+	if len(task.Contexts) != 1 || task.Contexts[0] != "Phone" {
+		t.Errorf("contexts = %v, want [Phone]", task.Contexts)
+	}
+	if len(task.Tags) != 1 || task.Tags[0] != "health" {
+		t.Errorf("tags = %v, want [health]", task.Tags)
+	}
+
+	if tasks := cs.inbox(t, "bob"); len(tasks) != 0 {
+		t.Errorf("bob has %d inbox tasks, want none", len(tasks))
+	}
+}
+
+func TestCaptureKeepsUnknownContextsInTitle(t *testing.T) {
+	cs := startCaptureServer(t)
+	alice := cs.addressFor(t, "alice")
+	cs.defineContext(t, "alice", "errands")
+
+	err := cs.send(alice,
+		"From: sender@example.com",
+		"Subject: Pick up parcel @errands @car",
+		"",
+		"Body",
+	)
+	if err != nil {
+		t.Fatalf("sending: %v", err)
+	}
+
+	tasks := cs.inbox(t, "alice")
+	if len(tasks) != 1 {
+		t.Fatalf("alice has %d inbox tasks, want 1", len(tasks))
+	}
+	if tasks[0].Title != "Pick up parcel @car" {
+		t.Errorf("title = %q, want %q", tasks[0].Title, "Pick up parcel @car")
+	}
+	if len(tasks[0].Contexts) != 1 || tasks[0].Contexts[0] != "errands" {
+		t.Errorf("contexts = %v, want [errands]", tasks[0].Contexts)
+	}
+}
+
+func TestCaptureRejectsUnknownToken(t *testing.T) {
+	cs := startCaptureServer(t)
+	cs.addressFor(t, "alice")
+
+	for _, to := range []string{
+		models.CaptureAddress("nosuchtoken", testDomain),
+		"someone@" + testDomain,
+		models.CaptureAddress("nosuchtoken", "other.test"),
+	} {
+		err := cs.send(to,
+			"From: sender@example.com",
+			"Subject: Should not arrive",
+			"",
+			"Body",
+		)
+		if err == nil || !strings.Contains(err.Error(), "550") {
+			t.Errorf("sending to %s: got %v, want a 550 rejection", to, err)
+		}
+	}
+
+	if tasks := cs.inbox(t, "alice"); len(tasks) != 0 {
+		t.Errorf("alice has %d inbox tasks, want none", len(tasks))
+	}
+}
+
+func TestCaptureMultipartMessage(t *testing.T) {
+	cs := startCaptureServer(t)
+	alice := cs.addressFor(t, "alice")
+
+	err := cs.send(alice,
+		"From: sender@example.com",
+		"Subject: =?ISO-8859-1?Q?R=E9sum=E9_review?=",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="outer"`,
+		"",
+		"--outer",
+		`Content-Type: multipart/alternative; boundary="inner"`,
+		"",
+		"--inner",
+		"Content-Type: text/plain; charset=iso-8859-1",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"Caf=E9 at noon, na=EFve plan.",
+		"--inner",
+		"Content-Type: text/html; charset=utf-8",
+		"",
+		"<p>Ignored because there is a text part</p>",
+		"--inner--",
+		"--outer",
+		"Content-Type: application/pdf",
+		`Content-Disposition: attachment; filename="cv.pdf"`,
+		"Content-Transfer-Encoding: base64",
+		"",
+		"JVBERi0xLjQK",
+		"--outer--",
+	)
+	if err != nil {
+		t.Fatalf("sending: %v", err)
+	}
+
+	tasks := cs.inbox(t, "alice")
+	if len(tasks) != 1 {
+		t.Fatalf("alice has %d inbox tasks, want 1", len(tasks))
+	}
+	task := tasks[0]
+	if task.Title != "Résumé review" {
+		t.Errorf("title = %q, want %q", task.Title, "Résumé review")
+	}
+	if task.Description != "Café at noon, naïve plan." {
+		t.Errorf("description = %q, want %q", task.Description, "Café at noon, naïve plan.")
+	}
+
+	attachments, err := cs.attachments.ListByTask(task.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attachments) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(attachments))
+	}
+	if attachments[0].Filename != "cv.pdf" || attachments[0].ContentType != "application/pdf" {
+		t.Errorf("attachment = %s (%s), want cv.pdf (application/pdf)", attachments[0].Filename, attachments[0].ContentType)
+	}
+	stored, err := cs.attachments.GetForUser(attachments[0].ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stored.Data) != "%PDF-1.4\n" {
+		t.Errorf("attachment data = %q", stored.Data)
+	}
+}
+
+func TestCaptureTextBodyCharsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		body    string
+		want    string
+	}{
+		{
+			name: "windows-1252 quotes",
+			headers: []string{
+				"Content-Type: text/plain; charset=windows-1252",
+				"Content-Transfer-Encoding: quoted-printable",
+			},
+			body: "=93Quoted=94 =96 done",
+			want: "“Quoted” – done",
+		},
+		{
+			name:    "utf-8 without transfer encoding",
+			headers: []string{"Content-Type: text/plain; charset=utf-8"},
+			body:    "Grüße",
+			want:    "Grüße",
+		},
+		{
+			name: "html only",
+			headers: []string{
+				"Content-Type: text/html; charset=iso-8859-1",
+				"Content-Transfer-Encoding: quoted-printable",
+			},
+			body: "<p>Line one</p><p>Caf=E9</p>",
+			want: "Line one\nCafé",
+		},
+		{
+			name:    "unknown charset",
+			headers: []string{"Content-Type: text/plain; charset=x-unknown"},
+			body:    "Still readable",
+			want:    "Still readable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := startCaptureServer(t)
+			alice := cs.addressFor(t, "alice")
+
+			lines := append([]string{"From: sender@example.com", "Subject: Charset", "MIME-Version: 1.0"}, tt.headers...)
+			lines = append(lines, "", tt.body)
+			if err := cs.send(alice, lines...); err != nil {
+				t.Fatalf("sending: %v", err)
+			}
+
+			tasks := cs.inbox(t, "alice")
+			if len(tasks) != 1 {
+				t.Fatalf("alice has %d inbox tasks, want 1", len(tasks))
+			}
+			if tasks[0].Description != tt.want {
+				t.Errorf("description = %q, want %q", tasks[0].Description, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package mailcapture
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// maxMultipartDepth limits how deeply nested multipart bodies are walked
+const maxMultipartDepth = 10
+
+// Attachment is a file attached to a received message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is the content of a received email that matters for capture
+type Message struct {
+	From        string
+	Subject     string
+	Text        string // Plain text body, or the text of the HTML body when there is no plain text part
+	Attachments []Attachment
+}
+
+// headerDecoder decodes RFC 2047 encoded words in headers and file names
+var headerDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// charsetReader converts text in the named charset to UTF-8. It knows the charsets browsers do,
+// which covers what mail clients send.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "", "utf-8", "utf8", "us-ascii":
+		return input, nil
+	}
+
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
+
+// decodeText converts a text part to UTF-8. Text in an unknown charset is kept with its invalid
+// bytes replaced, as most of it is usually still readable.
+func decodeText(data []byte, charset string) string {
+	reader, err := charsetReader(charset, bytes.NewReader(data))
+	if err == nil {
+		if decoded, err := io.ReadAll(reader); err == nil {
+			data = decoded
+		}
+	}
+	return strings.ToValidUTF8(string(data), "\uFFFD")
+}
+
+// ParseMessage reads an RFC 5322 message with MIME parts
+func ParseMessage(data []byte) (*Message, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{}
+	if subject, err := headerDecoder.DecodeHeader(m.Header.Get("Subject")); err == nil {
+		msg.Subject = strings.TrimSpace(subject)
+	} else {
+		msg.Subject = strings.TrimSpace(m.Header.Get("Subject"))
+	}
+	if from, err := m.Header.AddressList("From"); err == nil && len(from) > 0 {
+		msg.From = from[0].Address
+	}
+
+	var htmlText string
+	err = walkPart(msg, &htmlText, m.Header.Get("Content-Type"), m.Header.Get("Content-Transfer-Encoding"), m.Header.Get("Content-Disposition"), m.Body, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.Text == "" && htmlText != "" {
+		msg.Text = htmlText
+	}
+	msg.Text = strings.TrimSpace(strings.ReplaceAll(msg.Text, "\r\n", "\n"))
+
+	return msg, nil
+}
+
+// walkPart collects the text and attachments of a message part, descending into multipart bodies
+func walkPart(msg *Message, htmlText *string, contentType, encoding, disposition string, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMultipartDepth {
+			return nil
+		}
+
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			// NextRawPart leaves the transfer encoding to decodeBody
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			err = walkPart(msg, htmlText,
+				part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part.Header.Get("Content-Disposition"),
+				part, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeBody(body, encoding))
+	if err != nil {
+		return err
+	}
+
+	dispositionType, dispositionParams, _ := mime.ParseMediaType(disposition)
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if decoded, err := headerDecoder.DecodeHeader(filename); err == nil {
+		filename = decoded
+	}
+
+	isAttachment := dispositionType == "attachment" || filename != ""
+	switch {
+	case !isAttachment && mediaType == "text/plain":
+		if msg.Text == "" {
+			msg.Text = decodeText(data, params["charset"])
+		}
+	case !isAttachment && mediaType == "text/html":
+		if *htmlText == "" {
+			*htmlText = htmlToText(decodeText(data, params["charset"]))
+		}
+	default:
+		if filename == "" {
+			filename = "attachment"
+		}
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Filename:    filename,
+			ContentType: mediaType,
+			Data:        data,
+		})
+	}
+
+	return nil
+}
+
+// decodeBody undoes the content transfer encoding of a part
+func decodeBody(body io.Reader, encoding string) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+var (
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>|</h[1-6]>`)
+	htmlTags   = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlHidden = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText roughly converts an HTML body to plain text for messages without a text part
+func htmlToText(s string) string {
+	s = htmlHidden.ReplaceAllString(s, "")
+	s = htmlBreaks.ReplaceAllString(s, "\n")
+	s = htmlTags.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
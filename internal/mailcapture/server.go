@@ -0,0 +1,329 @@
+// Package mailcapture runs a small SMTP receiver that turns incoming email into inbox tasks.
+package mailcapture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRecipients limits the recipients of a single message
+const maxRecipients = 100
+
+// commandTimeout is how long the server waits for the next command of a client
+const commandTimeout = 5 * time.Minute
+
+// Backend decides which recipients are accepted and receives the accepted messages
+type Backend interface {
+	AcceptRecipient(address string) error
+	Deliver(from string, to []string, data []byte) error
+}
+
+// Server is an SMTP server that hands received mail to a Backend.
+// It speaks enough of RFC 5321 for mail transfer agents and SMTP clients such as net/smtp;
+// it doesn't support TLS or authentication, so it is meant to run behind a mail relay
+// or on a trusted network.
+type Server struct {
+	addr            string
+	domain          string
+	maxMessageBytes int64
+	backend         Backend
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closing  bool
+	wg       sync.WaitGroup
+}
+
+// NewServer creates an SMTP server listening on addr that accepts messages up to maxMessageBytes
+func NewServer(addr, domain string, maxMessageBytes int64, backend Backend) *Server {
+	return &Server{
+		addr:            addr,
+		domain:          domain,
+		maxMessageBytes: maxMessageBytes,
+		backend:         backend,
+		conns:           make(map[net.Conn]struct{}),
+	}
+}
+
+// ErrServerClosed is returned by ListenAndServe and Serve after Shutdown
+var ErrServerClosed = errors.New("mailcapture: server closed")
+
+// ListenAndServe listens on the server address and serves SMTP sessions until Shutdown
+func (s *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts SMTP sessions on l until Shutdown
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closing := s.closing
+			s.mu.Unlock()
+			if closing {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// Shutdown stops accepting connections and waits for open sessions to end.
+// Sessions still open when ctx expires are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// session holds the state of one SMTP conversation
+type session struct {
+	server *Server
+	conn   net.Conn
+	text   *textproto.Conn
+	helo   bool
+	from   string
+	to     []string
+	inMail bool
+}
+
+// reset clears the current mail transaction
+func (ss *session) reset() {
+	ss.from = ""
+	ss.to = nil
+	ss.inMail = false
+}
+
+// reply writes a single line response
+func (ss *session) reply(code int, format string, args ...interface{}) error {
+	return ss.text.PrintfLine("%d %s", code, fmt.Sprintf(format, args...))
+}
+
+// serveConn runs an SMTP session on conn
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	ss := &session{
+		server: s,
+		conn:   conn,
+		text:   textproto.NewConn(conn),
+	}
+
+	if err := ss.reply(220, "%s ESMTP GTD mail capture", s.domain); err != nil {
+		return
+	}
+
+	for {
+		s.mu.Lock()
+		closing := s.closing
+		s.mu.Unlock()
+		if closing {
+			ss.reply(421, "%s shutting down", s.domain)
+			return
+		}
+
+		conn.SetReadDeadline(time.Now().Add(commandTimeout))
+		line, err := ss.text.ReadLine()
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("Mail capture: read from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		if quit := ss.handle(strings.ToUpper(verb), strings.TrimSpace(arg)); quit {
+			return
+		}
+	}
+}
+
+// handle runs a single command and reports whether the session is over
+func (ss *session) handle(verb, arg string) bool {
+	var err error
+
+	switch verb {
+	case "HELO":
+		ss.helo = true
+		ss.reset()
+		err = ss.reply(250, "%s", ss.server.domain)
+	case "EHLO":
+		ss.helo = true
+		ss.reset()
+		err = ss.text.PrintfLine("250-%s\r\n250-SIZE %d\r\n250 8BITMIME", ss.server.domain, ss.server.maxMessageBytes)
+	case "MAIL":
+		err = ss.handleMail(arg)
+	case "RCPT":
+		err = ss.handleRcpt(arg)
+	case "DATA":
+		err = ss.handleData()
+	case "RSET":
+		ss.reset()
+		err = ss.reply(250, "OK")
+	case "NOOP":
+		err = ss.reply(250, "OK")
+	case "VRFY":
+		err = ss.reply(252, "Cannot verify user")
+	case "QUIT":
+		ss.reply(221, "Bye")
+		return true
+	case "STARTTLS", "AUTH":
+		err = ss.reply(502, "Command not implemented")
+	default:
+		err = ss.reply(500, "Unknown command")
+	}
+
+	return err != nil
+}
+
+// parsePath extracts the address from a "FROM:<address> [params]" or "TO:<address>" argument
+func parsePath(arg, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	path := strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(path, "<") {
+		return "", false
+	}
+	end := strings.Index(path, ">")
+	if end < 0 {
+		return "", false
+	}
+	return path[1:end], true
+}
+
+// handleMail starts a mail transaction
+func (ss *session) handleMail(arg string) error {
+	if !ss.helo {
+		return ss.reply(503, "Send HELO or EHLO first")
+	}
+	if ss.inMail {
+		return ss.reply(503, "Nested MAIL command")
+	}
+
+	from, ok := parsePath(arg, "FROM:")
+	if !ok {
+		return ss.reply(501, "Syntax: MAIL FROM:<address>")
+	}
+
+	ss.from = from
+	ss.inMail = true
+	return ss.reply(250, "OK")
+}
+
+// handleRcpt adds a recipient accepted by the backend
+func (ss *session) handleRcpt(arg string) error {
+	if !ss.inMail {
+		return ss.reply(503, "Send MAIL first")
+	}
+	if len(ss.to) >= maxRecipients {
+		return ss.reply(452, "Too many recipients")
+	}
+
+	to, ok := parsePath(arg, "TO:")
+	if !ok {
+		return ss.reply(501, "Syntax: RCPT TO:<address>")
+	}
+
+	if err := ss.server.backend.AcceptRecipient(to); err != nil {
+		return ss.reply(550, "No such mailbox: %s", to)
+	}
+
+	ss.to = append(ss.to, to)
+	return ss.reply(250, "OK")
+}
+
+// handleData receives the message and hands it to the backend
+func (ss *session) handleData() error {
+	if !ss.inMail || len(ss.to) == 0 {
+		return ss.reply(503, "Send RCPT first")
+	}
+
+	if err := ss.reply(354, "End data with <CR><LF>.<CR><LF>"); err != nil {
+		return err
+	}
+
+	ss.conn.SetReadDeadline(time.Now().Add(commandTimeout))
+	body := ss.text.DotReader()
+	data, err := io.ReadAll(io.LimitReader(body, ss.server.maxMessageBytes+1))
+	if err != nil {
+		return err
+	}
+
+	from, to := ss.from, ss.to
+	ss.reset()
+
+	if int64(len(data)) > ss.server.maxMessageBytes {
+		// Drain the rest of the message so the session can continue
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return err
+		}
+		return ss.reply(552, "Message exceeds maximum size of %d bytes", ss.server.maxMessageBytes)
+	}
+
+	if err := ss.server.backend.Deliver(from, to, data); err != nil {
+		log.Printf("Mail capture: delivering message from %s: %v", from, err)
+		return ss.reply(451, "Could not deliver message")
+	}
+
+	return ss.reply(250, "OK: message captured")
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrAttachmentNotFound is returned when an attachment doesn't exist or belongs to another user
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// Attachment is a file attached to a task, such as an attachment of a captured email
+type Attachment struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userId"`
+	TaskID      string    `json:"taskId"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"contentType"`
+	Size        int       `json:"size"`
+	Data        []byte    `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// NewAttachment creates an attachment for a task
+func NewAttachment(task *Task, filename, contentType string, data []byte) *Attachment {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &Attachment{
+		ID:          GenerateID(),
+		UserID:      task.UserID,
+		TaskID:      task.ID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        len(data),
+		Data:        data,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// AttachmentStore defines the interface for task attachment storage
+type AttachmentStore interface {
+	Save(attachment *Attachment) error
+	GetForUser(id string, userID string) (*Attachment, error)       // Includes the data
+	ListByTask(taskID string, userID string) ([]*Attachment, error) // Metadata only
+}
+
+// MemoryAttachmentStore implements AttachmentStore interface with in-memory storage
+type MemoryAttachmentStore struct {
+	attachments map[string]*Attachment
+	mutex       sync.RWMutex
+}
+
+// NewMemoryAttachmentStore creates a new in-memory attachment store
+func NewMemoryAttachmentStore() *MemoryAttachmentStore {
+	return &MemoryAttachmentStore{
+		attachments: make(map[string]*Attachment),
+	}
+}
+
+// Save stores an attachment
+func (s *MemoryAttachmentStore) Save(attachment *Attachment) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.attachments[attachment.ID] = attachment
+	return nil
+}
+
+// GetForUser retrieves an attachment with its data, hiding other users' attachments
+func (s *MemoryAttachmentStore) GetForUser(id string, userID string) (*Attachment, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	attachment, ok := s.attachments[id]
+	if !ok || attachment.UserID != userID {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// ListByTask returns the attachments of one of the user's tasks, oldest first
+func (s *MemoryAttachmentStore) ListByTask(taskID string, userID string) ([]*Attachment, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	attachments := []*Attachment{}
+	for _, attachment := range s.attachments {
+		if attachment.TaskID == taskID && attachment.UserID == userID {
+			metadata := *attachment
+			metadata.Data = nil
+			attachments = append(attachments, &metadata)
+		}
+	}
+
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].CreatedAt.Before(attachments[j].CreatedAt)
+	})
+
+	return attachments, nil
+}
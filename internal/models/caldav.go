@@ -0,0 +1,84 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// ErrCalDAVTokenNotFound is returned when no user owns a CalDAV token
+var ErrCalDAVTokenNotFound = errors.New("caldav credentials not found")
+
+// NewCalDAVToken returns a random, unguessable password for CalDAV clients
+func NewCalDAVToken() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CalDAVTokenStore defines the interface for the app passwords CalDAV clients sign in with.
+// Clients use HTTP Basic authentication, so they get a generated password instead of the account's.
+type CalDAVTokenStore interface {
+	GetUserID(token string) (string, error)
+	GetOrCreate(userID string) (string, error)
+	Regenerate(userID string) (string, error) // Replaces the token, signing out clients using the old one
+}
+
+// MemoryCalDAVTokenStore implements CalDAVTokenStore interface with in-memory storage
+type MemoryCalDAVTokenStore struct {
+	tokens map[string]string // Token to user ID
+	users  map[string]string // User ID to token
+	mutex  sync.RWMutex
+}
+
+// NewMemoryCalDAVTokenStore creates a new in-memory CalDAV token store
+func NewMemoryCalDAVTokenStore() *MemoryCalDAVTokenStore {
+	return &MemoryCalDAVTokenStore{
+		tokens: make(map[string]string),
+		users:  make(map[string]string),
+	}
+}
+
+// GetUserID returns the owner of a CalDAV token
+func (s *MemoryCalDAVTokenStore) GetUserID(token string) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	userID, ok := s.tokens[token]
+	if !ok {
+		return "", ErrCalDAVTokenNotFound
+	}
+	return userID, nil
+}
+
+// GetOrCreate returns the user's CalDAV token, creating one on first use
+func (s *MemoryCalDAVTokenStore) GetOrCreate(userID string) (string, error) {
+	s.mutex.RLock()
+	token, ok := s.users[userID]
+	s.mutex.RUnlock()
+	if ok {
+		return token, nil
+	}
+	return s.Regenerate(userID)
+}
+
+// Regenerate replaces the user's CalDAV token
+func (s *MemoryCalDAVTokenStore) Regenerate(userID string) (string, error) {
+	token, err := NewCalDAVToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if old, ok := s.users[userID]; ok {
+		delete(s.tokens, old)
+	}
+	s.tokens[token] = userID
+	s.users[userID] = token
+	return token, nil
+}
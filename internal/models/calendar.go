@@ -0,0 +1,84 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// ErrCalendarTokenNotFound is returned when no user owns a calendar feed token
+var ErrCalendarTokenNotFound = errors.New("calendar feed not found")
+
+// NewCalendarToken returns a random, unguessable token for a calendar feed URL
+func NewCalendarToken() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CalendarTokenStore defines the interface for the tokens that protect per-user calendar feeds.
+// Calendar apps can't log in, so the token in the feed URL is the only credential.
+type CalendarTokenStore interface {
+	GetUserID(token string) (string, error)
+	GetOrCreate(userID string) (string, error)
+	Regenerate(userID string) (string, error) // Replaces the token, disabling the old feed URL
+}
+
+// MemoryCalendarTokenStore implements CalendarTokenStore interface with in-memory storage
+type MemoryCalendarTokenStore struct {
+	tokens map[string]string // Token to user ID
+	users  map[string]string // User ID to token
+	mutex  sync.RWMutex
+}
+
+// NewMemoryCalendarTokenStore creates a new in-memory calendar token store
+func NewMemoryCalendarTokenStore() *MemoryCalendarTokenStore {
+	return &MemoryCalendarTokenStore{
+		tokens: make(map[string]string),
+		users:  make(map[string]string),
+	}
+}
+
+// GetUserID returns the owner of a calendar feed token
+func (s *MemoryCalendarTokenStore) GetUserID(token string) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	userID, ok := s.tokens[token]
+	if !ok {
+		return "", ErrCalendarTokenNotFound
+	}
+	return userID, nil
+}
+
+// GetOrCreate returns the user's calendar feed token, creating one on first use
+func (s *MemoryCalendarTokenStore) GetOrCreate(userID string) (string, error) {
+	s.mutex.RLock()
+	token, ok := s.users[userID]
+	s.mutex.RUnlock()
+	if ok {
+		return token, nil
+	}
+	return s.Regenerate(userID)
+}
+
+// Regenerate replaces the user's calendar feed token
+func (s *MemoryCalendarTokenStore) Regenerate(userID string) (string, error) {
+	token, err := NewCalendarToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if old, ok := s.users[userID]; ok {
+		delete(s.tokens, old)
+	}
+	s.tokens[token] = userID
+	s.users[userID] = token
+	return token, nil
+}
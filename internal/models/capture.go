@@ -0,0 +1,128 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrCaptureTokenNotFound is returned when no user owns a mail capture token
+var ErrCaptureTokenNotFound = errors.New("capture address not found")
+
+// captureTokenEncoding produces lowercase tokens, as mail servers may change the case of addresses
+var captureTokenEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// NewCaptureToken returns a random, unguessable token for a capture address
+func NewCaptureToken() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return captureTokenEncoding.EncodeToString(b), nil
+}
+
+// CaptureAddress returns the email address that files mail into the inbox of the token's owner
+func CaptureAddress(token, domain string) string {
+	return "inbox+" + token + "@" + domain
+}
+
+// ParseCaptureAddress extracts the token from an inbox+<token>@domain address
+func ParseCaptureAddress(address, domain string) (string, bool) {
+	at := strings.LastIndex(address, "@")
+	if at < 0 || !strings.EqualFold(address[at+1:], domain) {
+		return "", false
+	}
+
+	token, ok := strings.CutPrefix(strings.ToLower(address[:at]), "inbox+")
+	if !ok || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// ExtractCaptureTags removes "@context" and "#tag" words from captured text, optionally
+// written with a leading plus as in "+@phone", and returns them separately
+func ExtractCaptureTags(text string) (string, []Context, []string) {
+	var words []string
+	var contexts []Context
+	var tags []string
+
+	for _, word := range strings.Fields(text) {
+		marker := strings.TrimPrefix(word, "+")
+		switch {
+		case len(marker) > 1 && marker[0] == '@':
+			contexts = append(contexts, Context(strings.ToLower(marker[1:])))
+		case len(marker) > 1 && marker[0] == '#':
+			tags = append(tags, strings.ToLower(marker[1:]))
+		default:
+			words = append(words, word)
+		}
+	}
+
+	return strings.Join(words, " "), contexts, tags
+}
+
+// CaptureTokenStore defines the interface for the tokens of per-user mail capture addresses
+type CaptureTokenStore interface {
+	GetUserID(token string) (string, error)
+	GetOrCreate(userID string) (string, error)
+	Regenerate(userID string) (string, error) // Replaces the token, disabling the old address
+}
+
+// MemoryCaptureTokenStore implements CaptureTokenStore interface with in-memory storage
+type MemoryCaptureTokenStore struct {
+	tokens map[string]string // Token to user ID
+	users  map[string]string // User ID to token
+	mutex  sync.RWMutex
+}
+
+// NewMemoryCaptureTokenStore creates a new in-memory capture token store
+func NewMemoryCaptureTokenStore() *MemoryCaptureTokenStore {
+	return &MemoryCaptureTokenStore{
+		tokens: make(map[string]string),
+		users:  make(map[string]string),
+	}
+}
+
+// GetUserID returns the owner of a capture token
+func (s *MemoryCaptureTokenStore) GetUserID(token string) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	userID, ok := s.tokens[token]
+	if !ok {
+		return "", ErrCaptureTokenNotFound
+	}
+	return userID, nil
+}
+
+// GetOrCreate returns the user's capture token, creating one on first use
+func (s *MemoryCaptureTokenStore) GetOrCreate(userID string) (string, error) {
+	s.mutex.RLock()
+	token, ok := s.users[userID]
+	s.mutex.RUnlock()
+	if ok {
+		return token, nil
+	}
+	return s.Regenerate(userID)
+}
+
+// Regenerate replaces the user's capture token
+func (s *MemoryCaptureTokenStore) Regenerate(userID string) (string, error) {
+	token, err := NewCaptureToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if old, ok := s.users[userID]; ok {
+		delete(s.tokens, old)
+	}
+	s.tokens[token] = userID
+	s.users[userID] = token
+	return token, nil
+}
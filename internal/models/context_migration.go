@@ -0,0 +1,80 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// defineTaskContexts sets up a context for every context name already used on tasks and projects,
+// which used to be free-form. Names differing only in case or a leading "@" become one context.
+func defineTaskContexts(ctx context.Context, tx pgx.Tx) error {
+	rows, err := tx.Query(ctx, `
+		SELECT user_id, name FROM (
+			SELECT t.user_id, c.name, t.created_at
+			FROM tasks t, jsonb_array_elements_text(
+				CASE WHEN jsonb_typeof(t.contexts) = 'array' THEN t.contexts ELSE '[]'::jsonb END
+			) AS c(name)
+			WHERE t.user_id IS NOT NULL AND t.user_id <> ''
+			UNION ALL
+			SELECT p.user_id, c.name, p.created_at
+			FROM projects p, jsonb_array_elements_text(
+				CASE WHEN jsonb_typeof(p.contexts) = 'array' THEN p.contexts ELSE '[]'::jsonb END
+			) AS c(name)
+			WHERE p.user_id IS NOT NULL AND p.user_id <> ''
+		) used
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return err
+	}
+
+	type usedContext struct {
+		userID string
+		name   Context
+	}
+	var used []usedContext
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var userID, name string
+		if err := rows.Scan(&userID, &name); err != nil {
+			rows.Close()
+			return err
+		}
+
+		// The first spelling used is kept
+		def := ContextDefinition{UserID: userID, Name: NormalizeContext(Context(name))}
+		key := userID + "\x00" + contextKey(def.Name)
+		if seen[key] || def.Validate() != nil {
+			continue
+		}
+		seen[key] = true
+		used = append(used, usedContext{userID: userID, name: def.Name})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	generator := NewULIDGenerator()
+	now := time.Now()
+	for _, c := range used {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO contexts (id, user_id, name, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4)
+			ON CONFLICT DO NOTHING
+		`, generator.NewID(), c.userID, string(c.name), now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// keepTaskContexts rolls back defineTaskContexts. The contexts are left in place, since users may have
+// changed them since; rolling back 0017_create_contexts removes them.
+func keepTaskContexts(ctx context.Context, tx pgx.Tx) error {
+	return nil
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContextStore defines the interface for storing the contexts users set up
+type ContextStore interface {
+	GetForUser(id string, userID string) (*ContextDefinition, error)
+	List(ctx context.Context, userID string) ([]*ContextDefinition, error) // Alphabetical by name
+	SaveForUser(def *ContextDefinition, userID string) error
+	Delete(id string, userID string) error
+}
+
+// MemoryContextStore implements ContextStore interface with in-memory storage
+type MemoryContextStore struct {
+	contexts map[string]*ContextDefinition
+	mutex    sync.RWMutex
+}
+
+// NewMemoryContextStore creates a new in-memory context store
+func NewMemoryContextStore() *MemoryContextStore {
+	return &MemoryContextStore{
+		contexts: make(map[string]*ContextDefinition),
+	}
+}
+
+// copyContext returns a copy of a context that shares no slices with it
+func copyContext(def *ContextDefinition) *ContextDefinition {
+	copied := *def
+	copied.Hours = make([]ContextHours, len(def.Hours))
+	for i, hours := range def.Hours {
+		copied.Hours[i] = hours
+		copied.Hours[i].Days = append([]time.Weekday(nil), hours.Days...)
+	}
+	return &copied
+}
+
+// GetForUser retrieves a user's context by ID
+func (s *MemoryContextStore) GetForUser(id string, userID string) (*ContextDefinition, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	def, ok := s.contexts[id]
+	if !ok || def.UserID != userID {
+		return nil, ErrContextNotFound
+	}
+
+	return copyContext(def), nil
+}
+
+// List returns a user's contexts sorted by name
+func (s *MemoryContextStore) List(ctx context.Context, userID string) ([]*ContextDefinition, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	defs := []*ContextDefinition{}
+	for _, def := range s.contexts {
+		if def.UserID == userID {
+			defs = append(defs, copyContext(def))
+		}
+	}
+
+	sort.Slice(defs, func(i, j int) bool {
+		a, b := strings.ToLower(string(defs[i].Name)), strings.ToLower(string(defs[j].Name))
+		if a != b {
+			return a < b
+		}
+		return defs[i].ID < defs[j].ID
+	})
+
+	return defs, nil
+}
+
+// SaveForUser creates or updates a context on behalf of a user.
+// It refuses to write contexts owned by someone else or to reuse the name of another of the user's contexts.
+func (s *MemoryContextStore) SaveForUser(def *ContextDefinition, userID string) error {
+	if def.UserID != userID {
+		return ErrContextNotFound
+	}
+
+	if err := def.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if existing, ok := s.contexts[def.ID]; ok && existing.UserID != userID {
+		return ErrContextNotFound
+	}
+	for _, other := range s.contexts {
+		if other.UserID == userID && other.ID != def.ID && contextKey(other.Name) == contextKey(def.Name) {
+			return ErrContextExists
+		}
+	}
+
+	s.contexts[def.ID] = copyContext(def)
+	return nil
+}
+
+// Delete removes a user's context
+func (s *MemoryContextStore) Delete(id string, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	def, ok := s.contexts[id]
+	if !ok || def.UserID != userID {
+		return ErrContextNotFound
+	}
+
+	delete(s.contexts, id)
+	return nil
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"context"
+	"errors"
+)
+
+// Dependency errors
+var (
+	ErrDependencySelf    = errors.New("a task cannot wait on itself")
+	ErrDependencyProject = errors.New("a task can only wait on tasks in the same project")
+	ErrDependencyCycle   = errors.New("the dependency would create a cycle")
+)
+
+// hasOpenBlocker reports whether a task waits on a blocker that isn't done yet.
+// Only blockers that are still in the task's project count; lookup returns nil for unknown tasks.
+func hasOpenBlocker(task *Task, lookup func(id string) *Task) bool {
+	if task.ProjectID == "" {
+		return false
+	}
+	for _, id := range task.BlockedBy {
+		blocker := lookup(id)
+		if blocker != nil && !blocker.IsDeleted() && blocker.ProjectID == task.ProjectID && blocker.Status != StatusDone {
+			return true
+		}
+	}
+	return false
+}
+
+// indexTasks maps tasks by ID
+func indexTasks(tasks []*Task) map[string]*Task {
+	byID := make(map[string]*Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	return byID
+}
+
+// BlockedTasks returns the IDs of the tasks that still wait on an open blocker.
+// Pass all of a project's tasks, since blockers are looked up among them.
+func BlockedTasks(tasks []*Task) map[string]bool {
+	byID := indexTasks(tasks)
+	lookup := func(id string) *Task { return byID[id] }
+
+	blocked := make(map[string]bool)
+	for _, task := range tasks {
+		if hasOpenBlocker(task, lookup) {
+			blocked[task.ID] = true
+		}
+	}
+	return blocked
+}
+
+// projectTasks loads every task of a user's project
+func projectTasks(ctx context.Context, tasks TaskStore, userID string, projectID string) ([]*Task, error) {
+	return QueryAll(ctx, tasks, TaskQuery{
+		Filter: TaskFilter{UserID: userID, ProjectID: projectID},
+		Sort:   SortCreatedAsc,
+	})
+}
+
+// waitsOn reports whether a task depends on target, directly or through other blockers
+func waitsOn(task *Task, target string, byID map[string]*Task, seen map[string]bool) bool {
+	if seen[task.ID] {
+		return false
+	}
+	seen[task.ID] = true
+
+	for _, id := range task.BlockedBy {
+		if id == target {
+			return true
+		}
+		if blocker, ok := byID[id]; ok && waitsOn(blocker, target, byID, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddBlocker makes a task wait on another task of the same project and saves it.
+// It refuses dependencies that would make the tasks wait on each other.
+func AddBlocker(ctx context.Context, tasks TaskStore, task *Task, blockerID string, userID string) error {
+	if blockerID == task.ID {
+		return ErrDependencySelf
+	}
+	for _, id := range task.BlockedBy {
+		if id == blockerID {
+			return nil
+		}
+	}
+
+	blocker, err := tasks.GetForUser(blockerID, userID)
+	if err != nil {
+		return err
+	}
+	if task.ProjectID == "" || blocker.ProjectID != task.ProjectID {
+		return ErrDependencyProject
+	}
+
+	siblings, err := projectTasks(ctx, tasks, userID, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if waitsOn(blocker, task.ID, indexTasks(siblings), make(map[string]bool)) {
+		return ErrDependencyCycle
+	}
+
+	task.BlockedBy = append(task.BlockedBy, blockerID)
+	return tasks.SaveForUser(task, userID)
+}
+
+// RemoveBlocker stops a task from waiting on another task and saves it
+func RemoveBlocker(tasks TaskStore, task *Task, blockerID string, userID string) error {
+	kept := task.BlockedBy[:0:0]
+	for _, id := range task.BlockedBy {
+		if id != blockerID {
+			kept = append(kept, id)
+		}
+	}
+	if len(kept) == len(task.BlockedBy) {
+		return nil
+	}
+
+	task.BlockedBy = kept
+	return tasks.SaveForUser(task, userID)
+}
+
+// ReadyDependents returns the open tasks that waited on a completed task and have no open
+// blockers left, so they can move to Next Actions. Tasks already there are left out.
+func ReadyDependents(ctx context.Context, tasks TaskStore, completed *Task, userID string) ([]*Task, error) {
+	if completed.ProjectID == "" {
+		return nil, nil
+	}
+
+	siblings, err := projectTasks(ctx, tasks, userID, completed.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := indexTasks(siblings)
+	byID[completed.ID] = completed
+	lookup := func(id string) *Task { return byID[id] }
+
+	var ready []*Task
+	for _, task := range siblings {
+		if task.Status == StatusDone || task.Status == StatusNext || hasOpenBlocker(task, lookup) {
+			continue
+		}
+		for _, id := range task.BlockedBy {
+			if id == completed.ID {
+				ready = append(ready, task)
+				break
+			}
+		}
+	}
+	return ready, nil
+}
+
+// DependencyLevels orders a project's tasks into steps: the first step holds the tasks that wait
+// on nothing, and every later task comes one step after the last of its blockers.
+func DependencyLevels(tasks []*Task) [][]*Task {
+	byID := indexTasks(tasks)
+	level := make(map[string]int, len(tasks))
+
+	var depth func(task *Task, visiting map[string]bool) int
+	depth = func(task *Task, visiting map[string]bool) int {
+		if d, ok := level[task.ID]; ok {
+			return d
+		}
+		// Stores reject cycles, but a broken chain must not recurse forever
+		if visiting[task.ID] {
+			return 0
+		}
+		visiting[task.ID] = true
+
+		d := 0
+		for _, id := range task.BlockedBy {
+			if blocker, ok := byID[id]; ok && blocker.ProjectID == task.ProjectID {
+				if blockerDepth := depth(blocker, visiting) + 1; blockerDepth > d {
+					d = blockerDepth
+				}
+			}
+		}
+		level[task.ID] = d
+		return d
+	}
+
+	var levels [][]*Task
+	for _, task := range tasks {
+		d := depth(task, make(map[string]bool))
+		for len(levels) <= d {
+			levels = append(levels, nil)
+		}
+		levels[d] = append(levels[d], task)
+	}
+	return levels
+}
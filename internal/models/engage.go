@@ -0,0 +1,245 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultEngageLimit is the length of the shortlist when none is asked for
+const DefaultEngageLimit = 5
+
+// MaxEngageLimit caps the length of the shortlist
+const MaxEngageLimit = 20
+
+// Points awarded by each engage factor. A task's score is the sum of its factors.
+const (
+	engageOverduePoints     = 40 // Past its due date
+	engageDueTodayPoints    = 35
+	engageDueTomorrowPoints = 30
+	engageDueWeekPoints     = 20 // Due within 7 days
+	engageDueMonthPoints    = 5  // Due within 30 days
+
+	engagePriority1Points = 20
+	engagePriority2Points = 10
+
+	engageProjectOverduePoints = 20 // The task's project is past its due date
+	engageProjectWeekPoints    = 15 // The project is due within 7 days
+	engageProjectMonthPoints   = 5  // The project is due within 30 days
+
+	engageAgeWeekPoints = 1  // Per full week since the task was captured
+	engageMaxAgePoints  = 10 // Age never outweighs a near due date
+
+	engageFitPoints = 5 // Known to fit in the time available
+)
+
+// energyLevels orders the energy levels a task can require
+var energyLevels = map[string]int{"low": 1, "medium": 2, "high": 3}
+
+// EngageCriteria describes the situation the user is in right now
+type EngageCriteria struct {
+	Context Context `json:"context,omitempty"` // Where the user is or what they have at hand; any when empty
+	Minutes int     `json:"minutes,omitempty"` // Free time; unlimited when 0
+	Energy  string  `json:"energy,omitempty"`  // low, medium or high; any when empty
+	Limit   int     `json:"limit"`             // Length of the shortlist
+}
+
+// Validate checks the criteria and fills in the default limit
+func (c *EngageCriteria) Validate() error {
+	c.Context = Context(strings.TrimPrefix(strings.TrimSpace(string(c.Context)), "@"))
+	c.Energy = strings.ToLower(strings.TrimSpace(c.Energy))
+
+	if c.Minutes < 0 {
+		return errors.New("minutes cannot be negative")
+	}
+	if _, ok := energyLevels[c.Energy]; c.Energy != "" && !ok {
+		return errors.New("energy must be low, medium or high")
+	}
+	if c.Limit <= 0 {
+		c.Limit = DefaultEngageLimit
+	}
+	if c.Limit > MaxEngageLimit {
+		c.Limit = MaxEngageLimit
+	}
+	return nil
+}
+
+// fits reports whether a task can be done in the user's situation. Tasks that don't name a context,
+// an estimate or an energy level aren't held back by it.
+func (c EngageCriteria) fits(task *Task) bool {
+	if c.Context != "" && len(task.Contexts) > 0 && !containsContext(task.Contexts, c.Context) {
+		return false
+	}
+	if c.Minutes > 0 && task.TimeEstimate > c.Minutes {
+		return false
+	}
+	if c.Energy != "" {
+		if required, ok := energyLevels[strings.ToLower(task.EnergyRequired)]; ok && required > energyLevels[c.Energy] {
+			return false
+		}
+	}
+	return true
+}
+
+// EngageFactor is one reason a task was picked, with the points it earned
+type EngageFactor struct {
+	Factor string `json:"factor"` // due, priority, project, age or fit
+	Points int    `json:"points"`
+	Reason string `json:"reason"`
+}
+
+// EngageRecommendation is a task on the shortlist with the breakdown of its score
+type EngageRecommendation struct {
+	Task    *Task          `json:"task"`
+	Score   int            `json:"score"`
+	Factors []EngageFactor `json:"factors"`
+}
+
+// EngageResult is the shortlist of next actions for the user's situation
+type EngageResult struct {
+	Criteria        EngageCriteria          `json:"criteria"`
+	Considered      int                     `json:"considered"` // Next actions that fit the situation
+	Recommendations []*EngageRecommendation `json:"recommendations"`
+	GeneratedAt     time.Time               `json:"generatedAt"`
+}
+
+// Recommend ranks the user's unblocked next actions that fit the criteria and returns the best of them.
+// Criteria must have been validated.
+func Recommend(ctx context.Context, tasks TaskStore, projects ProjectStore, userID string, criteria EngageCriteria, now time.Time) (*EngageResult, error) {
+	result := &EngageResult{
+		Criteria:        criteria,
+		Recommendations: []*EngageRecommendation{},
+		GeneratedAt:     now,
+	}
+
+	next, err := QueryAll(ctx, tasks, TaskQuery{
+		Filter: TaskFilter{UserID: userID, Statuses: []TaskStatus{StatusNext}, Unblocked: true},
+		Sort:   SortCreatedAsc,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*Task
+	for _, task := range next {
+		if criteria.fits(task) {
+			candidates = append(candidates, task)
+		}
+	}
+	result.Considered = len(candidates)
+
+	active, err := projects.List(ctx, ProjectFilter{
+		UserID: userID,
+		States: []ProjectState{ProjectActive},
+	}, ProjectSortDueAsc)
+	if err != nil {
+		return nil, err
+	}
+	projectsByID := make(map[string]*Project, len(active))
+	for _, project := range active {
+		projectsByID[project.ID] = project
+	}
+
+	for _, task := range candidates {
+		result.Recommendations = append(result.Recommendations, scoreTask(task, projectsByID[task.ProjectID], criteria, now))
+	}
+
+	// Highest score first; ties go to the earlier due date, then to the older task
+	sort.SliceStable(result.Recommendations, func(i, j int) bool {
+		a, b := result.Recommendations[i], result.Recommendations[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if (a.Task.DueDate == nil) != (b.Task.DueDate == nil) {
+			return a.Task.DueDate != nil
+		}
+		if a.Task.DueDate != nil && !a.Task.DueDate.Equal(*b.Task.DueDate) {
+			return a.Task.DueDate.Before(*b.Task.DueDate)
+		}
+		return a.Task.CreatedAt.Before(b.Task.CreatedAt)
+	})
+
+	if len(result.Recommendations) > criteria.Limit {
+		result.Recommendations = result.Recommendations[:criteria.Limit]
+	}
+	return result, nil
+}
+
+// scoreTask adds up the factors of a task. project is nil when the task isn't part of an active project.
+func scoreTask(task *Task, project *Project, criteria EngageCriteria, now time.Time) *EngageRecommendation {
+	rec := &EngageRecommendation{Task: task, Factors: []EngageFactor{}}
+	add := func(factor string, points int, reason string) {
+		if points > 0 {
+			rec.Factors = append(rec.Factors, EngageFactor{Factor: factor, Points: points, Reason: reason})
+			rec.Score += points
+		}
+	}
+
+	if task.DueDate != nil {
+		days := calendarDaysUntil(*task.DueDate, now)
+		switch {
+		case days < 0:
+			add("due", engageOverduePoints, fmt.Sprintf("Overdue by %s", pluralDays(-days)))
+		case days == 0:
+			add("due", engageDueTodayPoints, "Due today")
+		case days == 1:
+			add("due", engageDueTomorrowPoints, "Due tomorrow")
+		case days <= 7:
+			add("due", engageDueWeekPoints, fmt.Sprintf("Due in %s", pluralDays(days)))
+		case days <= 30:
+			add("due", engageDueMonthPoints, fmt.Sprintf("Due in %s", pluralDays(days)))
+		}
+	}
+
+	switch task.Priority {
+	case 1:
+		add("priority", engagePriority1Points, "Priority 1")
+	case 2:
+		add("priority", engagePriority2Points, "Priority 2")
+	}
+
+	if project != nil && project.DueDate != nil {
+		days := calendarDaysUntil(*project.DueDate, now)
+		switch {
+		case days < 0:
+			add("project", engageProjectOverduePoints, fmt.Sprintf("Project %q is overdue", project.Title))
+		case days <= 7:
+			add("project", engageProjectWeekPoints, fmt.Sprintf("Project %q is due within a week", project.Title))
+		case days <= 30:
+			add("project", engageProjectMonthPoints, fmt.Sprintf("Project %q is due within a month", project.Title))
+		}
+	}
+
+	if weeks := daysBetween(task.CreatedAt, now) / 7; weeks > 0 {
+		points := weeks * engageAgeWeekPoints
+		if points > engageMaxAgePoints {
+			points = engageMaxAgePoints
+		}
+		add("age", points, fmt.Sprintf("Captured %s ago", pluralDays(daysBetween(task.CreatedAt, now))))
+	}
+
+	if criteria.Minutes > 0 && task.TimeEstimate > 0 {
+		add("fit", engageFitPoints, fmt.Sprintf("Takes about %d of your %d minutes", task.TimeEstimate, criteria.Minutes))
+	}
+
+	return rec
+}
+
+// calendarDaysUntil counts the days from today to the day of t, negative when t is in the past
+func calendarDaysUntil(t time.Time, now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	t = t.In(now.Location())
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+	return int(math.Round(day.Sub(today).Hours() / 24))
+}
+
+// pluralDays formats a number of days
+func pluralDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// IDGenerator creates unique identifiers for stored records
+type IDGenerator interface {
+	NewID() string
+}
+
+// ulidAlphabet is Crockford's base32 alphabet, which keeps encoded IDs lexically sortable
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// legacyIDPattern matches the timestamp IDs ("20060102150405") generated before ULIDs were introduced
+var legacyIDPattern = regexp.MustCompile(`^[0-9]{14}$`)
+
+// ULIDGenerator generates 26 character ULIDs: a 48-bit millisecond timestamp followed by 80 random bits.
+// IDs generated within the same millisecond increment the random part, so they stay unique and ordered.
+type ULIDGenerator struct {
+	mutex    sync.Mutex
+	lastTime uint64
+	lastHi   uint16 // Upper 16 bits of the random part
+	lastLo   uint64 // Lower 64 bits of the random part
+	now      func() time.Time
+}
+
+// NewULIDGenerator creates a ULID generator based on the system clock
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{now: time.Now}
+}
+
+// NewID returns a new ULID for the current time
+func (g *ULIDGenerator) NewID() string {
+	return g.NewIDAt(g.now())
+}
+
+// NewIDAt returns a new ULID carrying the given timestamp
+func (g *ULIDGenerator) NewIDAt(t time.Time) string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	ms := uint64(t.UnixMilli())
+	if ms <= g.lastTime {
+		// Same millisecond (or the clock went backwards): stay monotonic by incrementing
+		ms = g.lastTime
+		g.lastLo++
+		if g.lastLo == 0 {
+			g.lastHi++
+		}
+	} else {
+		var entropy [10]byte
+		if _, err := rand.Read(entropy[:]); err != nil {
+			panic("models: unable to read random bytes: " + err.Error())
+		}
+		g.lastTime = ms
+		g.lastHi = binary.BigEndian.Uint16(entropy[:2])
+		g.lastLo = binary.BigEndian.Uint64(entropy[2:])
+	}
+
+	return encodeULID(ms, g.lastHi, g.lastLo)
+}
+
+// encodeULID encodes the 128-bit ULID value as 26 base32 characters
+func encodeULID(ms uint64, hi uint16, lo uint64) string {
+	var id [16]byte
+	id[0] = byte(ms >> 40)
+	id[1] = byte(ms >> 32)
+	id[2] = byte(ms >> 24)
+	id[3] = byte(ms >> 16)
+	id[4] = byte(ms >> 8)
+	id[5] = byte(ms)
+	binary.BigEndian.PutUint16(id[6:8], hi)
+	binary.BigEndian.PutUint64(id[8:], lo)
+
+	// 26 characters hold 130 bits; the first character only carries the top 3 bits
+	var out [26]byte
+	var acc uint32
+	bits := 2
+	pos := 0
+	for _, b := range id {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = ulidAlphabet[(acc>>uint(bits))&0x1f]
+			pos++
+		}
+	}
+
+	return string(out[:])
+}
+
+var (
+	idGenerator      IDGenerator = NewULIDGenerator()
+	idGeneratorMutex sync.RWMutex
+)
+
+// SetIDGenerator replaces the generator used by GenerateID
+func SetIDGenerator(generator IDGenerator) {
+	idGeneratorMutex.Lock()
+	defer idGeneratorMutex.Unlock()
+	idGenerator = generator
+}
+
+// GenerateID generates a new unique, time-sortable ID
+func GenerateID() string {
+	idGeneratorMutex.RLock()
+	defer idGeneratorMutex.RUnlock()
+	return idGenerator.NewID()
+}
+
+// IsLegacyID reports whether an ID uses the old timestamp format
+func IsLegacyID(id string) bool {
+	return legacyIDPattern.MatchString(id)
+}
+
+// legacyIDTime returns the creation time encoded in a legacy timestamp ID
+func legacyIDTime(id string) (time.Time, bool) {
+	if !IsLegacyID(id) {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("20060102150405", id, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// rekeyLegacyTaskIDs replaces the old timestamp task IDs with ULIDs carrying the same creation time.
+// The old IDs are kept in task_id_aliases so links and references using them keep resolving.
+func rekeyLegacyTaskIDs(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS task_id_aliases (
+			legacy_id TEXT PRIMARY KEY,
+			task_id TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_task_id_aliases_task_id ON task_id_aliases(task_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id FROM tasks WHERE id ~ '^[0-9]{14}$' ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	legacyIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	generator := NewULIDGenerator()
+	for _, legacyID := range legacyIDs {
+		createdAt, _ := legacyIDTime(legacyID)
+		_, err := tx.Exec(ctx,
+			`INSERT INTO task_id_aliases (legacy_id, task_id) VALUES ($1, $2)`,
+			legacyID, generator.NewIDAt(createdAt),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE tasks SET id = a.task_id FROM task_id_aliases a WHERE tasks.id = a.legacy_id;
+		UPDATE tasks SET project_id = a.task_id FROM task_id_aliases a WHERE tasks.project_id = a.legacy_id;
+		UPDATE tasks SET parent_id = a.task_id FROM task_id_aliases a WHERE tasks.parent_id = a.legacy_id;
+	`)
+	return err
+}
+
+// restoreLegacyTaskIDs puts the timestamp task IDs back and drops the alias table
+func restoreLegacyTaskIDs(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE tasks SET parent_id = a.legacy_id FROM task_id_aliases a WHERE tasks.parent_id = a.task_id;
+		UPDATE tasks SET project_id = a.legacy_id FROM task_id_aliases a WHERE tasks.project_id = a.task_id;
+		UPDATE tasks SET id = a.legacy_id FROM task_id_aliases a WHERE tasks.id = a.task_id;
+		DROP TABLE IF EXISTS task_id_aliases;
+	`)
+	return err
+}
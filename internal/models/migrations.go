@@ -0,0 +1,317 @@
+package models
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationFiles holds the numbered SQL migrations, named NNNN_description.up.sql / NNNN_description.down.sql.
+// They cover the tables of every PostgreSQL store; NewPgTaskStore applies them, so the other stores
+// are created on its pool.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the advisory lock key that serializes migrations across server instances
+const migrationLockID int64 = 0x67746473636865 // "gtdsche"
+
+// Migration is a single versioned schema change.
+// Data migrations that can't be expressed in SQL provide UpFunc/DownFunc instead of scripts.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	UpFunc   MigrationFunc
+	DownFunc MigrationFunc
+}
+
+// MigrationFunc performs a migration step inside the migration's transaction
+type MigrationFunc func(ctx context.Context, tx pgx.Tx) error
+
+// goMigrations holds the migrations implemented in Go; their versions must not clash with a SQL file
+var goMigrations = []Migration{
+	{Version: 3, Name: "rekey_legacy_task_ids", UpFunc: rekeyLegacyTaskIDs, DownFunc: restoreLegacyTaskIDs},
+	{Version: 18, Name: "define_task_contexts", UpFunc: defineTaskContexts, DownFunc: keepTaskContexts},
+}
+
+// run executes the up or down step of the migration
+func (m Migration) run(ctx context.Context, tx pgx.Tx, up bool) error {
+	if up {
+		if m.UpFunc != nil {
+			return m.UpFunc(ctx, tx)
+		}
+		_, err := tx.Exec(ctx, m.Up)
+		return err
+	}
+
+	if m.DownFunc != nil {
+		return m.DownFunc(ctx, tx)
+	}
+	_, err := tx.Exec(ctx, m.Down)
+	return err
+}
+
+// reversible reports whether the migration can be rolled back
+func (m Migration) reversible() bool {
+	return m.Down != "" || m.DownFunc != nil
+}
+
+// MigrationStatus reports whether a migration has been applied to the database
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrator applies and rolls back schema migrations on a PostgreSQL database
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the embedded migrations
+func NewMigrator(db *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// LoadMigrations reads the embedded migration files, ordered by version
+func LoadMigrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("unexpected migration file %s", fileName)
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration file %s must be named NNNN_name.%s.sql", fileName, direction)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %v", fileName, err)
+		}
+
+		contents, err := migrationFiles.ReadFile(path.Join("migrations", fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("migration %04d has conflicting names %q and %q", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.Up = string(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	for _, migration := range goMigrations {
+		if existing, ok := byVersion[migration.Version]; ok {
+			return nil, fmt.Errorf("migration %04d_%s clashes with %04d_%s", migration.Version, migration.Name, existing.Version, existing.Name)
+		}
+		migration := migration
+		byVersion[migration.Version] = &migration
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" && migration.UpFunc == nil {
+			return nil, fmt.Errorf("migration %04d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies every pending migration and returns the ones that were applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if err := migration.run(ctx, tx, true); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx,
+					`INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+					migration.Version, migration.Name, time.Now(),
+				)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s failed: %v", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the given number of most recently applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if !migration.reversible() {
+				return fmt.Errorf("migration %04d_%s cannot be reverted", migration.Version, migration.Name)
+			}
+
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if err := migration.run(ctx, tx, false); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %04d_%s failed: %v", migration.Version, migration.Name, err)
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every known migration along with when it was applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if appliedAt, ok := done[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withLock runs fn on a dedicated connection while holding the migration advisory lock,
+// so that concurrently starting server instances don't apply migrations twice
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("unable to acquire migration lock: %v", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// appliedVersions returns the applied migration versions and when they were applied
+func appliedVersions(ctx context.Context, conn *pgxpool.Conn) (map[int]time.Time, error) {
+	rows, err := conn.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		done[int(version)] = appliedAt
+	}
+
+	return done, rows.Err()
+}
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS tasks (
+	id TEXT PRIMARY KEY,
+	title TEXT NOT NULL,
+	description TEXT,
+	status TEXT NOT NULL,
+	user_id TEXT,
+	project_id TEXT,
+	parent_id TEXT,
+	contexts JSONB,
+	tags JSONB,
+	due_date TIMESTAMP WITH TIME ZONE,
+	scheduled_date TIMESTAMP WITH TIME ZONE,
+	time_estimate INTEGER,
+	energy_required TEXT,
+	priority INTEGER,
+	timeframe TEXT,
+	is_recurring BOOLEAN DEFAULT FALSE,
+	recurring_rule TEXT,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	completed_at TIMESTAMP WITH TIME ZONE,
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_user_id ON tasks(user_id);
@@ -0,0 +1,12 @@
+INSERT INTO tasks (
+	id, title, description, status, user_id, contexts, tags,
+	due_date, created_at, updated_at, completed_at, deleted_at
+)
+SELECT
+	id, title, description,
+	CASE WHEN state = 'completed' THEN 'done' ELSE 'project' END,
+	user_id, contexts, tags, due_date, created_at, updated_at, completed_at, deleted_at
+FROM projects
+ON CONFLICT (id) DO NOTHING;
+
+DROP TABLE IF EXISTS projects;
@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS projects (
+	id TEXT PRIMARY KEY,
+	user_id TEXT,
+	title TEXT NOT NULL,
+	description TEXT,
+	outcome TEXT,
+	notes TEXT,
+	state TEXT NOT NULL,
+	contexts JSONB,
+	tags JSONB,
+	due_date TIMESTAMP WITH TIME ZONE,
+	review_date TIMESTAMP WITH TIME ZONE,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	completed_at TIMESTAMP WITH TIME ZONE,
+	deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_projects_user_id ON projects(user_id);
+CREATE INDEX IF NOT EXISTS idx_projects_state ON projects(state);
+
+-- Projects used to be tasks with the "project" status; move them over, keeping their IDs.
+-- Archiving a project marked it done, so done tasks that other tasks still belong to were
+-- projects too; they become completed projects. A done project without tasks can't be told
+-- apart from a done task and stays one.
+INSERT INTO projects (
+	id, user_id, title, description, outcome, notes, state, contexts, tags,
+	due_date, review_date, created_at, updated_at, completed_at, deleted_at
+)
+SELECT
+	id, user_id, title, description, '', '',
+	CASE WHEN status = 'done' THEN 'completed' ELSE 'active' END,
+	contexts, tags, due_date, NULL, created_at, updated_at,
+	CASE WHEN status = 'done' THEN COALESCE(completed_at, updated_at) END,
+	deleted_at
+FROM tasks
+WHERE status = 'project'
+	OR (status = 'done' AND id IN (
+		SELECT project_id FROM tasks WHERE project_id IS NOT NULL AND project_id <> ''
+	))
+ON CONFLICT (id) DO NOTHING;
+
+DELETE FROM tasks WHERE id IN (SELECT id FROM projects);
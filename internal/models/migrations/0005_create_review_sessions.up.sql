@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS review_sessions (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	steps JSONB NOT NULL,
+	notes TEXT NOT NULL DEFAULT '',
+	start_snapshot JSONB NOT NULL,
+	end_snapshot JSONB,
+	started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_sessions_user_started ON review_sessions(user_id, started_at DESC);
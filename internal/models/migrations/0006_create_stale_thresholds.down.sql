@@ -0,0 +1 @@
+DROP TABLE IF EXISTS stale_thresholds;
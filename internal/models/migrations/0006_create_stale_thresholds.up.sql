@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS stale_thresholds (
+	user_id TEXT PRIMARY KEY,
+	inbox_days INTEGER NOT NULL,
+	waiting_days INTEGER NOT NULL,
+	someday_days INTEGER NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
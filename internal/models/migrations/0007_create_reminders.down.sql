@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS reminders;
+DROP TABLE IF EXISTS reminder_settings;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS reminder_settings (
+	user_id TEXT PRIMARY KEY,
+	offsets JSONB NOT NULL,
+	channels JSONB NOT NULL,
+	email TEXT NOT NULL DEFAULT '',
+	webhook_url TEXT NOT NULL DEFAULT '',
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS reminders (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	task_id TEXT NOT NULL,
+	field TEXT NOT NULL,
+	minutes_before INTEGER NOT NULL,
+	channel TEXT NOT NULL,
+	fire_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	sent_at TIMESTAMP WITH TIME ZONE,
+	UNIQUE (task_id, field, minutes_before, channel, fire_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders(fire_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS notifications (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	task_id TEXT NOT NULL DEFAULT '',
+	title TEXT NOT NULL,
+	body TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	read_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications(user_id, created_at DESC);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS capture_tokens (
+	user_id TEXT PRIMARY KEY,
+	token TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS attachments (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	task_id TEXT NOT NULL,
+	filename TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	data BYTEA NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_task ON attachments(task_id);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS caldav_tokens (
+	user_id TEXT PRIMARY KEY,
+	token TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS task_events (
+	id TEXT PRIMARY KEY,
+	task_id TEXT NOT NULL,
+	user_id TEXT NOT NULL,
+	type TEXT NOT NULL,
+	field TEXT NOT NULL DEFAULT '',
+	before_value JSONB,
+	after_value JSONB,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_task_created ON task_events(task_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS blocked_by JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,8 @@
+-- Words of the title rank above tags, which rank above the description
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+	setweight(jsonb_to_tsvector('simple', coalesce(tags, '[]'::jsonb), '["string"]'), 'B') ||
+	setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector);
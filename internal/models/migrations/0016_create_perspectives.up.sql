@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS perspectives (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	query TEXT NOT NULL,
+	sort TEXT NOT NULL DEFAULT '',
+	group_by TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_perspectives_user_id ON perspectives(user_id);
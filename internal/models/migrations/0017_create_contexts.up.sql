@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS contexts (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	icon TEXT NOT NULL DEFAULT '',
+	color TEXT NOT NULL DEFAULT '',
+	location TEXT NOT NULL DEFAULT '',
+	hours JSONB NOT NULL DEFAULT '[]',
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contexts_user_name ON contexts(user_id, LOWER(name));
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS undo_actions (
+	token TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	label TEXT NOT NULL,
+	events_until TEXT NOT NULL,
+	task_ids JSONB NOT NULL DEFAULT '[]',
+	created_ids JSONB NOT NULL DEFAULT '[]',
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_undo_actions_user_id ON undo_actions(user_id, created_at);
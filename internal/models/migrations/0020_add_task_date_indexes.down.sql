@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_scheduled_date;
+DROP INDEX IF EXISTS idx_tasks_due_date;
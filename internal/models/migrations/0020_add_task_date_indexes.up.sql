@@ -0,0 +1,3 @@
+-- The reminder scheduler looks up open tasks by their due and scheduled dates
+CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks(due_date) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_scheduled_date ON tasks(scheduled_date) WHERE deleted_at IS NULL;
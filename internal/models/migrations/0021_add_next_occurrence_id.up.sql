@@ -0,0 +1,2 @@
+-- The occurrence a completed recurring task created, so completing it again after reopening doesn't repeat it
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS next_occurrence_id TEXT;
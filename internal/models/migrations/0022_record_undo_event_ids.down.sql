@@ -0,0 +1,3 @@
+DELETE FROM undo_actions;
+ALTER TABLE undo_actions DROP COLUMN IF EXISTS event_ids;
+ALTER TABLE undo_actions ADD COLUMN IF NOT EXISTS events_until TEXT NOT NULL;
@@ -0,0 +1,5 @@
+-- Undo reverts the exact history events an action wrote instead of every event in its time window.
+-- Pending actions only know their window, so they are dropped.
+DELETE FROM undo_actions;
+ALTER TABLE undo_actions DROP COLUMN IF EXISTS events_until;
+ALTER TABLE undo_actions ADD COLUMN IF NOT EXISTS event_ids JSONB NOT NULL DEFAULT '[]';
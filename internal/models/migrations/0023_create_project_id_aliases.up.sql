@@ -0,0 +1,13 @@
+-- Projects moved out of the tasks table keep the ULIDs their tasks were rekeyed to; carry the
+-- timestamp IDs over as well, so old project links keep resolving
+CREATE TABLE IF NOT EXISTS project_id_aliases (
+	legacy_id TEXT PRIMARY KEY,
+	project_id TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_id_aliases_project_id ON project_id_aliases(project_id);
+
+INSERT INTO project_id_aliases (legacy_id, project_id)
+SELECT legacy_id, task_id FROM task_id_aliases
+WHERE task_id IN (SELECT id FROM projects)
+ON CONFLICT (legacy_id) DO NOTHING;
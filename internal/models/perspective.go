@@ -0,0 +1,213 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxPerspectiveTasks caps how many tasks a perspective shows
+const MaxPerspectiveTasks = 500
+
+// MaxPerspectiveQueryLength caps the length of a perspective's query, which runs on every page
+// that shows the perspective in the sidebar
+const MaxPerspectiveQueryLength = 500
+
+// ErrPerspectiveNotFound is returned when a perspective doesn't exist or belongs to another user
+var ErrPerspectiveNotFound = errors.New("perspective not found")
+
+// PerspectiveGrouping defines how a perspective groups its tasks
+type PerspectiveGrouping string
+
+const (
+	GroupNone    PerspectiveGrouping = ""        // One flat list
+	GroupProject PerspectiveGrouping = "project" // By project, tasks without one last
+	GroupContext PerspectiveGrouping = "context" // By context; a task shows under each of its contexts
+	GroupDueDate PerspectiveGrouping = "due"     // Overdue, today, tomorrow, this week, later, no due date
+)
+
+// Perspective is a saved search shown as its own list, such as "Errands under 15 minutes, low energy"
+type Perspective struct {
+	ID        string              `json:"id"`
+	UserID    string              `json:"userId,omitempty"`
+	Name      string              `json:"name"`
+	Query     string              `json:"query"`             // Search in the syntax of ParseSearchQuery
+	Sort      TaskSort            `json:"sort,omitempty"`    // Best matches first when empty
+	GroupBy   PerspectiveGrouping `json:"groupBy,omitempty"` // How the tasks are grouped
+	CreatedAt time.Time           `json:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+// NewPerspective creates a new perspective
+func NewPerspective(name, query string, userID string) *Perspective {
+	now := time.Now()
+	return &Perspective{
+		ID:        GenerateID(),
+		UserID:    userID,
+		Name:      name,
+		Query:     query,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate checks if the perspective data is valid
+func (p *Perspective) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("perspective name cannot be empty")
+	}
+
+	if len(p.Query) > MaxPerspectiveQueryLength {
+		return fmt.Errorf("perspective query cannot be longer than %d characters", MaxPerspectiveQueryLength)
+	}
+	q, err := ParseSearchQuery(p.Query, time.Now())
+	if err != nil {
+		return err
+	}
+	if q.Empty() {
+		return errors.New("perspective query cannot be empty")
+	}
+
+	if p.Sort != "" && ParseTaskSort(string(p.Sort)) != p.Sort {
+		return errors.New("invalid perspective sort")
+	}
+
+	switch p.GroupBy {
+	case GroupNone, GroupProject, GroupContext, GroupDueDate:
+	default:
+		return errors.New("invalid perspective grouping")
+	}
+
+	return nil
+}
+
+// Tasks runs the perspective's search over a user's tasks and returns them in the perspective's order.
+// Relative due dates in the query are resolved against now.
+func (p *Perspective) Tasks(ctx context.Context, tasks TaskStore, now time.Time) ([]*Task, error) {
+	q, err := ParseSearchQuery(p.Query, now)
+	if err != nil {
+		return nil, err
+	}
+	q.Limit = MaxPerspectiveTasks
+
+	results, err := tasks.SearchTasks(ctx, p.UserID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]*Task, len(results))
+	for i, result := range results {
+		matched[i] = result.Task
+	}
+	if p.Sort != "" {
+		sortTasks(matched, p.Sort)
+	}
+	return matched, nil
+}
+
+// Count returns how many tasks match the perspective, including those past MaxPerspectiveTasks
+func (p *Perspective) Count(ctx context.Context, tasks TaskStore, now time.Time) (int, error) {
+	q, err := ParseSearchQuery(p.Query, now)
+	if err != nil {
+		return 0, err
+	}
+	return tasks.CountSearchTasks(ctx, p.UserID, q)
+}
+
+// TaskGroup is a named group of a perspective's tasks
+type TaskGroup struct {
+	Name  string  `json:"name"`
+	Tasks []*Task `json:"tasks"`
+}
+
+// GroupTasks splits tasks into groups, keeping their order within each group.
+// projectTitles names the groups of GroupProject; tasks of unknown projects count as having none.
+func GroupTasks(tasks []*Task, by PerspectiveGrouping, projectTitles map[string]string, now time.Time) []TaskGroup {
+	if by == GroupNone {
+		if len(tasks) == 0 {
+			return []TaskGroup{}
+		}
+		return []TaskGroup{{Tasks: tasks}}
+	}
+
+	var names []string
+	byName := make(map[string][]*Task)
+	add := func(name string, task *Task) {
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], task)
+	}
+
+	for _, task := range tasks {
+		switch by {
+		case GroupProject:
+			title, ok := projectTitles[task.ProjectID]
+			if !ok {
+				title = "No project"
+			}
+			add(title, task)
+		case GroupContext:
+			if len(task.Contexts) == 0 {
+				add("No context", task)
+			}
+			for _, context := range task.Contexts {
+				add("@"+string(context), task)
+			}
+		case GroupDueDate:
+			add(dueBucket(task, now), task)
+		}
+	}
+
+	// Groups are alphabetical, except that due date groups run in time order
+	// and "No ..." groups come last
+	rank := func(name string) string {
+		if strings.HasPrefix(name, "No ") {
+			return "\xff" + name
+		}
+		if by == GroupDueDate {
+			for i, bucket := range dueBuckets {
+				if bucket == name {
+					return string(rune('0' + i))
+				}
+			}
+		}
+		return strings.ToLower(name)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return rank(names[i]) < rank(names[j])
+	})
+
+	groups := make([]TaskGroup, len(names))
+	for i, name := range names {
+		groups[i] = TaskGroup{Name: name, Tasks: byName[name]}
+	}
+	return groups
+}
+
+// dueBuckets names the due date groups in time order
+var dueBuckets = []string{"Overdue", "Today", "Tomorrow", "This week", "Later", "No due date"}
+
+// dueBucket returns the due date group of a task
+func dueBucket(task *Task, now time.Time) string {
+	if task.DueDate == nil {
+		return "No due date"
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	due := task.DueDate.In(now.Location())
+	switch {
+	case due.Before(today):
+		return "Overdue"
+	case due.Before(today.AddDate(0, 0, 1)):
+		return "Today"
+	case due.Before(today.AddDate(0, 0, 2)):
+		return "Tomorrow"
+	case due.Before(today.AddDate(0, 0, 7)):
+		return "This week"
+	default:
+		return "Later"
+	}
+}
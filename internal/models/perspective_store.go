@@ -0,0 +1,104 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PerspectiveStore defines the interface for saved perspective storage
+type PerspectiveStore interface {
+	GetForUser(id string, userID string) (*Perspective, error)
+	List(ctx context.Context, userID string) ([]*Perspective, error) // Alphabetical by name
+	SaveForUser(perspective *Perspective, userID string) error
+	Delete(id string, userID string) error
+}
+
+// MemoryPerspectiveStore implements PerspectiveStore interface with in-memory storage
+type MemoryPerspectiveStore struct {
+	perspectives map[string]*Perspective
+	mutex        sync.RWMutex
+}
+
+// NewMemoryPerspectiveStore creates a new in-memory perspective store
+func NewMemoryPerspectiveStore() *MemoryPerspectiveStore {
+	return &MemoryPerspectiveStore{
+		perspectives: make(map[string]*Perspective),
+	}
+}
+
+// GetForUser retrieves a user's perspective by ID
+func (s *MemoryPerspectiveStore) GetForUser(id string, userID string) (*Perspective, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	perspective, ok := s.perspectives[id]
+	if !ok || perspective.UserID != userID {
+		return nil, ErrPerspectiveNotFound
+	}
+
+	copied := *perspective
+	return &copied, nil
+}
+
+// List returns a user's perspectives sorted by name
+func (s *MemoryPerspectiveStore) List(ctx context.Context, userID string) ([]*Perspective, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	perspectives := []*Perspective{}
+	for _, perspective := range s.perspectives {
+		if perspective.UserID == userID {
+			copied := *perspective
+			perspectives = append(perspectives, &copied)
+		}
+	}
+
+	sort.Slice(perspectives, func(i, j int) bool {
+		a, b := strings.ToLower(perspectives[i].Name), strings.ToLower(perspectives[j].Name)
+		if a != b {
+			return a < b
+		}
+		return perspectives[i].ID < perspectives[j].ID
+	})
+
+	return perspectives, nil
+}
+
+// SaveForUser creates or updates a perspective on behalf of a user.
+// It refuses to write perspectives owned by someone else.
+func (s *MemoryPerspectiveStore) SaveForUser(perspective *Perspective, userID string) error {
+	if perspective.UserID != userID {
+		return ErrPerspectiveNotFound
+	}
+
+	if err := perspective.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if existing, ok := s.perspectives[perspective.ID]; ok && existing.UserID != userID {
+		return ErrPerspectiveNotFound
+	}
+
+	stored := *perspective
+	s.perspectives[perspective.ID] = &stored
+	return nil
+}
+
+// Delete removes a user's perspective
+func (s *MemoryPerspectiveStore) Delete(id string, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	perspective, ok := s.perspectives[id]
+	if !ok || perspective.UserID != userID {
+		return ErrPerspectiveNotFound
+	}
+
+	delete(s.perspectives, id)
+	return nil
+}
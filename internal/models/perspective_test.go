@@ -0,0 +1,28 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPerspectiveValidateQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "non-breaking space", query: "call\u00a0mom"},
+		{name: "longest allowed", query: strings.Repeat("a", MaxPerspectiveQueryLength)},
+		{name: "too long", query: strings.Repeat("a", MaxPerspectiveQueryLength+1), wantErr: true},
+		{name: "empty", query: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewPerspective("Calls", tt.query, "alice").Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
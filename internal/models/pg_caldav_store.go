@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgCalDAVTokenStore implements CalDAVTokenStore interface with PostgreSQL storage
+type PgCalDAVTokenStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgCalDAVTokenStore creates a CalDAV token store on an existing connection pool
+func NewPgCalDAVTokenStore(db *pgxpool.Pool) *PgCalDAVTokenStore {
+	return &PgCalDAVTokenStore{
+		db: db,
+	}
+}
+
+// GetUserID returns the owner of a CalDAV token
+func (s *PgCalDAVTokenStore) GetUserID(token string) (string, error) {
+	var userID string
+	err := s.db.QueryRow(context.Background(), `SELECT user_id FROM caldav_tokens WHERE token = $1`, token).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", ErrCalDAVTokenNotFound
+		}
+		return "", err
+	}
+	return userID, nil
+}
+
+// GetOrCreate returns the user's CalDAV token, creating one on first use
+func (s *PgCalDAVTokenStore) GetOrCreate(userID string) (string, error) {
+	var token string
+	err := s.db.QueryRow(context.Background(), `SELECT token FROM caldav_tokens WHERE user_id = $1`, userID).Scan(&token)
+	if err == nil {
+		return token, nil
+	}
+	if err != pgx.ErrNoRows {
+		return "", err
+	}
+	return s.Regenerate(userID)
+}
+
+// Regenerate replaces the user's CalDAV token
+func (s *PgCalDAVTokenStore) Regenerate(userID string) (string, error) {
+	token, err := NewCalDAVToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO caldav_tokens (user_id, token, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			token = EXCLUDED.token,
+			created_at = EXCLUDED.created_at
+	`
+
+	if _, err := s.db.Exec(context.Background(), query, userID, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
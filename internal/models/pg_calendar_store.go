@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgCalendarTokenStore implements CalendarTokenStore interface with PostgreSQL storage
+type PgCalendarTokenStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgCalendarTokenStore creates a calendar token store on an existing connection pool
+func NewPgCalendarTokenStore(db *pgxpool.Pool) *PgCalendarTokenStore {
+	return &PgCalendarTokenStore{
+		db: db,
+	}
+}
+
+// GetUserID returns the owner of a calendar feed token
+func (s *PgCalendarTokenStore) GetUserID(token string) (string, error) {
+	var userID string
+	err := s.db.QueryRow(context.Background(), `SELECT user_id FROM calendar_tokens WHERE token = $1`, token).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", ErrCalendarTokenNotFound
+		}
+		return "", err
+	}
+	return userID, nil
+}
+
+// GetOrCreate returns the user's calendar feed token, creating one on first use
+func (s *PgCalendarTokenStore) GetOrCreate(userID string) (string, error) {
+	var token string
+	err := s.db.QueryRow(context.Background(), `SELECT token FROM calendar_tokens WHERE user_id = $1`, userID).Scan(&token)
+	if err == nil {
+		return token, nil
+	}
+	if err != pgx.ErrNoRows {
+		return "", err
+	}
+	return s.Regenerate(userID)
+}
+
+// Regenerate replaces the user's calendar feed token
+func (s *PgCalendarTokenStore) Regenerate(userID string) (string, error) {
+	token, err := NewCalendarToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO calendar_tokens (user_id, token, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			token = EXCLUDED.token,
+			created_at = EXCLUDED.created_at
+	`
+
+	if _, err := s.db.Exec(context.Background(), query, userID, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
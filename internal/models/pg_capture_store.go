@@ -0,0 +1,147 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgCaptureTokenStore implements CaptureTokenStore interface with PostgreSQL storage
+type PgCaptureTokenStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgCaptureTokenStore creates a capture token store on an existing connection pool
+func NewPgCaptureTokenStore(db *pgxpool.Pool) *PgCaptureTokenStore {
+	return &PgCaptureTokenStore{
+		db: db,
+	}
+}
+
+// GetUserID returns the owner of a capture token
+func (s *PgCaptureTokenStore) GetUserID(token string) (string, error) {
+	var userID string
+	err := s.db.QueryRow(context.Background(), `SELECT user_id FROM capture_tokens WHERE token = $1`, token).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", ErrCaptureTokenNotFound
+		}
+		return "", err
+	}
+	return userID, nil
+}
+
+// GetOrCreate returns the user's capture token, creating one on first use
+func (s *PgCaptureTokenStore) GetOrCreate(userID string) (string, error) {
+	var token string
+	err := s.db.QueryRow(context.Background(), `SELECT token FROM capture_tokens WHERE user_id = $1`, userID).Scan(&token)
+	if err == nil {
+		return token, nil
+	}
+	if err != pgx.ErrNoRows {
+		return "", err
+	}
+	return s.Regenerate(userID)
+}
+
+// Regenerate replaces the user's capture token
+func (s *PgCaptureTokenStore) Regenerate(userID string) (string, error) {
+	token, err := NewCaptureToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO capture_tokens (user_id, token, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			token = EXCLUDED.token,
+			created_at = EXCLUDED.created_at
+	`
+
+	if _, err := s.db.Exec(context.Background(), query, userID, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// PgAttachmentStore implements AttachmentStore interface with PostgreSQL storage
+type PgAttachmentStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgAttachmentStore creates an attachment store on an existing connection pool
+func NewPgAttachmentStore(db *pgxpool.Pool) *PgAttachmentStore {
+	return &PgAttachmentStore{
+		db: db,
+	}
+}
+
+// Save stores an attachment
+func (s *PgAttachmentStore) Save(attachment *Attachment) error {
+	query := `
+		INSERT INTO attachments (id, user_id, task_id, filename, content_type, size, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := s.db.Exec(context.Background(), query,
+		attachment.ID, attachment.UserID, attachment.TaskID, attachment.Filename,
+		attachment.ContentType, attachment.Size, attachment.Data, attachment.CreatedAt,
+	)
+	return err
+}
+
+// GetForUser retrieves an attachment with its data, hiding other users' attachments
+func (s *PgAttachmentStore) GetForUser(id string, userID string) (*Attachment, error) {
+	query := `
+		SELECT id, user_id, task_id, filename, content_type, size, data, created_at
+		FROM attachments
+		WHERE id = $1 AND user_id = $2
+	`
+
+	var attachment Attachment
+	err := s.db.QueryRow(context.Background(), query, id, userID).Scan(
+		&attachment.ID, &attachment.UserID, &attachment.TaskID, &attachment.Filename,
+		&attachment.ContentType, &attachment.Size, &attachment.Data, &attachment.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+// ListByTask returns the attachments of one of the user's tasks, oldest first
+func (s *PgAttachmentStore) ListByTask(taskID string, userID string) ([]*Attachment, error) {
+	query := `
+		SELECT id, user_id, task_id, filename, content_type, size, created_at
+		FROM attachments
+		WHERE task_id = $1 AND user_id = $2
+		ORDER BY created_at
+	`
+
+	rows, err := s.db.Query(context.Background(), query, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []*Attachment{}
+	for rows.Next() {
+		var attachment Attachment
+		err := rows.Scan(
+			&attachment.ID, &attachment.UserID, &attachment.TaskID, &attachment.Filename,
+			&attachment.ContentType, &attachment.Size, &attachment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, &attachment)
+	}
+
+	return attachments, rows.Err()
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgContextStore implements ContextStore interface with PostgreSQL storage
+type PgContextStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgContextStore creates a context store on an existing connection pool
+func NewPgContextStore(db *pgxpool.Pool) *PgContextStore {
+	return &PgContextStore{
+		db: db,
+	}
+}
+
+// contextColumns lists the context columns in the order expected by scanContext
+const contextColumns = `id, user_id, name, icon, color, location, hours, created_at, updated_at`
+
+// scanContext reads a single context row selected with contextColumns
+func scanContext(row pgx.Row) (*ContextDefinition, error) {
+	var def ContextDefinition
+	var hoursJSON []byte
+	err := row.Scan(
+		&def.ID, &def.UserID, &def.Name, &def.Icon, &def.Color, &def.Location, &hoursJSON,
+		&def.CreatedAt, &def.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(hoursJSON, &def.Hours); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// GetForUser retrieves a user's context by ID
+func (s *PgContextStore) GetForUser(id string, userID string) (*ContextDefinition, error) {
+	query := `SELECT ` + contextColumns + ` FROM contexts WHERE id = $1 AND user_id = $2`
+
+	def, err := scanContext(s.db.QueryRow(context.Background(), query, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrContextNotFound
+		}
+		return nil, err
+	}
+
+	return def, nil
+}
+
+// List returns a user's contexts sorted by name
+func (s *PgContextStore) List(ctx context.Context, userID string) ([]*ContextDefinition, error) {
+	query := `SELECT ` + contextColumns + `
+		FROM contexts
+		WHERE user_id = $1
+		ORDER BY LOWER(name), id
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	defs := []*ContextDefinition{}
+	for rows.Next() {
+		def, err := scanContext(rows)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+
+	return defs, rows.Err()
+}
+
+// SaveForUser creates or updates a context on behalf of a user.
+// An existing context is only updated if it belongs to that user, and no two of a user's contexts share a name.
+func (s *PgContextStore) SaveForUser(def *ContextDefinition, userID string) error {
+	if def.UserID != userID {
+		return ErrContextNotFound
+	}
+
+	if err := def.Validate(); err != nil {
+		return err
+	}
+
+	var taken bool
+	err := s.db.QueryRow(context.Background(),
+		`SELECT EXISTS (SELECT 1 FROM contexts WHERE user_id = $1 AND LOWER(name) = LOWER($2) AND id <> $3)`,
+		userID, string(def.Name), def.ID,
+	).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrContextExists
+	}
+
+	hours := def.Hours
+	if hours == nil {
+		hours = []ContextHours{}
+	}
+	hoursJSON, err := json.Marshal(hours)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO contexts (id, user_id, name, icon, color, location, hours, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			icon = EXCLUDED.icon,
+			color = EXCLUDED.color,
+			location = EXCLUDED.location,
+			hours = EXCLUDED.hours,
+			updated_at = EXCLUDED.updated_at
+		WHERE contexts.user_id = EXCLUDED.user_id
+	`
+
+	tag, err := s.db.Exec(context.Background(), query,
+		def.ID, def.UserID, string(def.Name), def.Icon, def.Color, def.Location, hoursJSON,
+		def.CreatedAt, def.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrContextNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a user's context
+func (s *PgContextStore) Delete(id string, userID string) error {
+	tag, err := s.db.Exec(context.Background(), `DELETE FROM contexts WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrContextNotFound
+	}
+
+	return nil
+}
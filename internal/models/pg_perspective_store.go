@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgPerspectiveStore implements PerspectiveStore interface with PostgreSQL storage
+type PgPerspectiveStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgPerspectiveStore creates a perspective store on an existing connection pool
+func NewPgPerspectiveStore(db *pgxpool.Pool) *PgPerspectiveStore {
+	return &PgPerspectiveStore{
+		db: db,
+	}
+}
+
+// perspectiveColumns lists the perspective columns in the order expected by scanPerspective
+const perspectiveColumns = `id, user_id, name, query, sort, group_by, created_at, updated_at`
+
+// scanPerspective reads a single perspective row selected with perspectiveColumns
+func scanPerspective(row pgx.Row) (*Perspective, error) {
+	var perspective Perspective
+	err := row.Scan(
+		&perspective.ID, &perspective.UserID, &perspective.Name, &perspective.Query,
+		&perspective.Sort, &perspective.GroupBy, &perspective.CreatedAt, &perspective.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &perspective, nil
+}
+
+// GetForUser retrieves a user's perspective by ID
+func (s *PgPerspectiveStore) GetForUser(id string, userID string) (*Perspective, error) {
+	query := `SELECT ` + perspectiveColumns + ` FROM perspectives WHERE id = $1 AND user_id = $2`
+
+	perspective, err := scanPerspective(s.db.QueryRow(context.Background(), query, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrPerspectiveNotFound
+		}
+		return nil, err
+	}
+
+	return perspective, nil
+}
+
+// List returns a user's perspectives sorted by name
+func (s *PgPerspectiveStore) List(ctx context.Context, userID string) ([]*Perspective, error) {
+	query := `SELECT ` + perspectiveColumns + `
+		FROM perspectives
+		WHERE user_id = $1
+		ORDER BY LOWER(name), id
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	perspectives := []*Perspective{}
+	for rows.Next() {
+		perspective, err := scanPerspective(rows)
+		if err != nil {
+			return nil, err
+		}
+		perspectives = append(perspectives, perspective)
+	}
+
+	return perspectives, rows.Err()
+}
+
+// SaveForUser creates or updates a perspective on behalf of a user.
+// An existing perspective is only updated if it belongs to that user.
+func (s *PgPerspectiveStore) SaveForUser(perspective *Perspective, userID string) error {
+	if perspective.UserID != userID {
+		return ErrPerspectiveNotFound
+	}
+
+	if err := perspective.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO perspectives (id, user_id, name, query, sort, group_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			query = EXCLUDED.query,
+			sort = EXCLUDED.sort,
+			group_by = EXCLUDED.group_by,
+			updated_at = EXCLUDED.updated_at
+		WHERE perspectives.user_id = EXCLUDED.user_id
+	`
+
+	tag, err := s.db.Exec(context.Background(), query,
+		perspective.ID, perspective.UserID, perspective.Name, perspective.Query,
+		string(perspective.Sort), string(perspective.GroupBy), perspective.CreatedAt, perspective.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPerspectiveNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a user's perspective
+func (s *PgPerspectiveStore) Delete(id string, userID string) error {
+	tag, err := s.db.Exec(context.Background(), `DELETE FROM perspectives WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPerspectiveNotFound
+	}
+
+	return nil
+}
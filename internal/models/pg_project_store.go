@@ -0,0 +1,345 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgProjectStore implements ProjectStore interface with PostgreSQL storage
+type PgProjectStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgProjectStore creates a project store on an existing connection pool
+func NewPgProjectStore(db *pgxpool.Pool) *PgProjectStore {
+	return &PgProjectStore{
+		db: db,
+	}
+}
+
+// projectColumns lists the project columns in the order expected by scanProject
+const projectColumns = `
+	id, user_id, title, description, outcome, notes, state, contexts, tags,
+	due_date, review_date, created_at, updated_at, completed_at, deleted_at`
+
+// projectIDMatch matches the project ID given as $1, also resolving the timestamp IDs projects had
+// as tasks before the switch to ULIDs
+const projectIDMatch = `id IN ($1, (SELECT project_id FROM project_id_aliases WHERE legacy_id = $1))`
+
+// scanProject reads a single project row selected with projectColumns
+func scanProject(row pgx.Row) (*Project, error) {
+	var project Project
+	var userID, description, outcome, notes sql.NullString
+	var contextsJSON, tagsJSON []byte
+	var dueDate, reviewDate, completedAt, deletedAt pgtype.Timestamptz
+
+	err := row.Scan(
+		&project.ID, &userID, &project.Title, &description, &outcome, &notes, &project.State, &contextsJSON, &tagsJSON,
+		&dueDate, &reviewDate, &project.CreatedAt, &project.UpdatedAt, &completedAt, &deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	project.UserID = userID.String
+	project.Description = description.String
+	project.Outcome = outcome.String
+	project.Notes = notes.String
+
+	if contextsJSON != nil {
+		var contexts []string
+		if err := json.Unmarshal(contextsJSON, &contexts); err == nil {
+			for _, c := range contexts {
+				project.Contexts = append(project.Contexts, Context(c))
+			}
+		}
+	}
+	if tagsJSON != nil {
+		if err := json.Unmarshal(tagsJSON, &project.Tags); err != nil {
+			fmt.Printf("Error unmarshaling project tags: %v\n", err)
+		}
+	}
+
+	for _, field := range []struct {
+		src pgtype.Timestamptz
+		dst **time.Time
+	}{
+		{dueDate, &project.DueDate},
+		{reviewDate, &project.ReviewDate},
+		{completedAt, &project.CompletedAt},
+		{deletedAt, &project.DeletedAt},
+	} {
+		if field.src.Valid {
+			t := field.src.Time.Local()
+			*field.dst = &t
+		}
+	}
+
+	return &project, nil
+}
+
+// Get retrieves a project by ID
+func (s *PgProjectStore) Get(id string) (*Project, error) {
+	query := `SELECT ` + projectColumns + `
+		FROM projects
+		WHERE ` + projectIDMatch + ` AND deleted_at IS NULL
+	`
+
+	project, err := scanProject(s.db.QueryRow(context.Background(), query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// GetForUser retrieves a project by ID, hiding projects owned by other users
+func (s *PgProjectStore) GetForUser(id string, userID string) (*Project, error) {
+	query := `SELECT ` + projectColumns + `
+		FROM projects
+		WHERE ` + projectIDMatch + ` AND user_id = $2 AND deleted_at IS NULL
+	`
+
+	project, err := scanProject(s.db.QueryRow(context.Background(), query, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// projectSortClauses maps each sort order to its ORDER BY clause
+var projectSortClauses = map[ProjectSort]string{
+	ProjectSortCreatedDesc: "created_at DESC, id",
+	ProjectSortCreatedAsc:  "created_at ASC, id",
+	ProjectSortUpdatedDesc: "updated_at DESC, id",
+	ProjectSortTitleAsc:    "LOWER(title) ASC, id",
+	ProjectSortDueAsc:      "due_date ASC NULLS LAST, id",
+	ProjectSortReviewAsc:   "review_date ASC NULLS LAST, id",
+}
+
+// List returns the projects matching the filter in the requested order
+func (s *PgProjectStore) List(ctx context.Context, filter ProjectFilter, order ProjectSort) ([]*Project, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	if filter.IncludeDeleted {
+		conditions = []string{"TRUE"}
+	}
+	var args []interface{}
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.UserID != "" {
+		addCondition("user_id = $%d", filter.UserID)
+	}
+	if len(filter.States) > 0 {
+		states := make([]string, len(filter.States))
+		for i, state := range filter.States {
+			states[i] = string(state)
+		}
+		addCondition("state = ANY($%d)", states)
+	}
+	if filter.ReviewBefore != nil {
+		addCondition("review_date <= $%d", *filter.ReviewBefore)
+	}
+
+	orderBy, ok := projectSortClauses[order]
+	if !ok {
+		orderBy = projectSortClauses[ProjectSortCreatedDesc]
+	}
+
+	query := fmt.Sprintf(`SELECT %s
+		FROM projects
+		WHERE %s
+		ORDER BY %s
+	`, projectColumns, strings.Join(conditions, " AND "), orderBy)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []*Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
+}
+
+// Save creates or updates a project
+func (s *PgProjectStore) Save(project *Project) error {
+	return s.save(project, "")
+}
+
+// SaveForUser creates or updates a project on behalf of a user.
+// It refuses to write projects owned by someone else or to hand a project over to another user.
+func (s *PgProjectStore) SaveForUser(project *Project, userID string) error {
+	if project.UserID != userID {
+		return ErrProjectNotFound
+	}
+	return s.save(project, userID)
+}
+
+// save upserts a project; when ownerID is set, an existing row is only updated if it belongs to that user
+func (s *PgProjectStore) save(project *Project, ownerID string) error {
+	if err := project.Validate(); err != nil {
+		return err
+	}
+
+	var contextsSlice []string
+	for _, c := range project.Contexts {
+		contextsSlice = append(contextsSlice, string(c))
+	}
+
+	contextsJSON, err := json.Marshal(contextsSlice)
+	if err != nil {
+		return err
+	}
+
+	tagsJSON, err := json.Marshal(project.Tags)
+	if err != nil {
+		return err
+	}
+
+	project.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO projects (
+			id, user_id, title, description, outcome, notes, state, contexts, tags,
+			due_date, review_date, created_at, updated_at, completed_at, deleted_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+		) ON CONFLICT (id) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			title = EXCLUDED.title,
+			description = EXCLUDED.description,
+			outcome = EXCLUDED.outcome,
+			notes = EXCLUDED.notes,
+			state = EXCLUDED.state,
+			contexts = EXCLUDED.contexts,
+			tags = EXCLUDED.tags,
+			due_date = EXCLUDED.due_date,
+			review_date = EXCLUDED.review_date,
+			updated_at = EXCLUDED.updated_at,
+			completed_at = EXCLUDED.completed_at,
+			deleted_at = EXCLUDED.deleted_at
+		WHERE $16 = '' OR projects.user_id = $16
+	`
+
+	tag, err := s.db.Exec(context.Background(), query,
+		project.ID, project.UserID, project.Title, project.Description, project.Outcome, project.Notes,
+		string(project.State), contextsJSON, tagsJSON,
+		project.DueDate, project.ReviewDate, project.CreatedAt, project.UpdatedAt, project.CompletedAt, project.DeletedAt,
+		ownerID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The conflicting row belongs to another user
+	if tag.RowsAffected() == 0 {
+		return ErrProjectNotFound
+	}
+
+	return nil
+}
+
+// Delete soft-deletes a project
+func (s *PgProjectStore) Delete(id string) error {
+	project, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	project.Delete()
+	return s.Save(project)
+}
+
+// ListDeleted returns a user's soft-deleted projects, most recently deleted first
+func (s *PgProjectStore) ListDeleted(ctx context.Context, userID string) ([]*Project, error) {
+	query := `SELECT ` + projectColumns + `
+		FROM projects
+		WHERE deleted_at IS NOT NULL AND user_id = $1
+		ORDER BY deleted_at DESC, id
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []*Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
+}
+
+// Restore brings back a soft-deleted project
+func (s *PgProjectStore) Restore(id string, userID string) error {
+	query := `
+		UPDATE projects SET deleted_at = NULL, updated_at = $3
+		WHERE ` + projectIDMatch + ` AND user_id = $2 AND deleted_at IS NOT NULL
+	`
+
+	tag, err := s.db.Exec(context.Background(), query, id, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrProjectNotFound
+	}
+
+	return nil
+}
+
+// Purge permanently removes a soft-deleted project
+func (s *PgProjectStore) Purge(id string, userID string) error {
+	tag, err := s.db.Exec(context.Background(),
+		`DELETE FROM projects WHERE `+projectIDMatch+` AND user_id = $2 AND deleted_at IS NOT NULL`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrProjectNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently removes all projects deleted before the cutoff and returns how many were removed
+func (s *PgProjectStore) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM projects WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
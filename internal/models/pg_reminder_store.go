@@ -0,0 +1,287 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgReminderStore implements ReminderStore interface with PostgreSQL storage
+type PgReminderStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgReminderStore creates a reminder store on an existing connection pool
+func NewPgReminderStore(db *pgxpool.Pool) *PgReminderStore {
+	return &PgReminderStore{
+		db: db,
+	}
+}
+
+// GetSettings returns the user's reminder settings
+func (s *PgReminderStore) GetSettings(userID string) (ReminderSettings, error) {
+	query := `SELECT offsets, channels, email, webhook_url FROM reminder_settings WHERE user_id = $1`
+
+	settings := ReminderSettings{UserID: userID}
+	var offsetsJSON, channelsJSON []byte
+	err := s.db.QueryRow(context.Background(), query, userID).Scan(
+		&offsetsJSON, &channelsJSON, &settings.Email, &settings.WebhookURL,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return DefaultReminderSettings(userID), nil
+		}
+		return ReminderSettings{}, err
+	}
+
+	if err := json.Unmarshal(offsetsJSON, &settings.Offsets); err != nil {
+		return ReminderSettings{}, err
+	}
+	if err := json.Unmarshal(channelsJSON, &settings.Channels); err != nil {
+		return ReminderSettings{}, err
+	}
+
+	return settings, nil
+}
+
+// SaveSettings stores the user's reminder settings
+func (s *PgReminderStore) SaveSettings(settings ReminderSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	offsetsJSON, err := json.Marshal(settings.Offsets)
+	if err != nil {
+		return err
+	}
+	channelsJSON, err := json.Marshal(settings.Channels)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO reminder_settings (user_id, offsets, channels, email, webhook_url, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			offsets = EXCLUDED.offsets,
+			channels = EXCLUDED.channels,
+			email = EXCLUDED.email,
+			webhook_url = EXCLUDED.webhook_url,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = s.db.Exec(context.Background(), query,
+		settings.UserID, offsetsJSON, channelsJSON, settings.Email, settings.WebhookURL,
+	)
+	return err
+}
+
+// reminderColumns lists the reminder columns in the order expected by scanReminder
+const reminderColumns = `
+	id, user_id, task_id, field, minutes_before, channel, fire_at,
+	status, attempts, last_error, created_at, sent_at`
+
+// scanReminder reads a single reminder row selected with reminderColumns
+func scanReminder(row pgx.Row) (*Reminder, error) {
+	var reminder Reminder
+	err := row.Scan(
+		&reminder.ID, &reminder.UserID, &reminder.TaskID, &reminder.Field, &reminder.MinutesBefore, &reminder.Channel, &reminder.FireAt,
+		&reminder.Status, &reminder.Attempts, &reminder.LastError, &reminder.CreatedAt, &reminder.SentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+// Create schedules a reminder unless the same reminder was scheduled before
+func (s *PgReminderStore) Create(ctx context.Context, reminder *Reminder) error {
+	query := `
+		INSERT INTO reminders (` + reminderColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (task_id, field, minutes_before, channel, fire_at) DO NOTHING
+	`
+
+	tag, err := s.db.Exec(ctx, query,
+		reminder.ID, reminder.UserID, reminder.TaskID, reminder.Field, reminder.MinutesBefore, reminder.Channel, reminder.FireAt,
+		reminder.Status, reminder.Attempts, reminder.LastError, reminder.CreatedAt, reminder.SentAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrReminderExists
+	}
+
+	return nil
+}
+
+// ListPending returns the reminders waiting to be delivered, oldest first
+func (s *PgReminderStore) ListPending(ctx context.Context) ([]*Reminder, error) {
+	query := `SELECT ` + reminderColumns + ` FROM reminders WHERE status = $1 ORDER BY fire_at`
+
+	rows, err := s.db.Query(ctx, query, ReminderPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []*Reminder{}
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+// Claim takes a pending reminder for delivery, so no other run or server sends it too
+func (s *PgReminderStore) Claim(ctx context.Context, reminder *Reminder) error {
+	query := `UPDATE reminders SET status = $2 WHERE id = $1 AND status = $3`
+
+	tag, err := s.db.Exec(ctx, query, reminder.ID, ReminderSending, ReminderPending)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReminderClaimed
+	}
+
+	reminder.Status = ReminderSending
+	return nil
+}
+
+// MaxOffset returns the longest any user's reminders fire before a task date
+func (s *PgReminderStore) MaxOffset(ctx context.Context) (time.Duration, error) {
+	query := `
+		SELECT COALESCE(MAX((offset_value->>'minutesBefore')::INTEGER), 0)
+		FROM reminder_settings, jsonb_array_elements(offsets) AS offset_value
+	`
+
+	var minutes int
+	if err := s.db.QueryRow(ctx, query).Scan(&minutes); err != nil {
+		return 0, err
+	}
+
+	longest := DefaultReminderSettings("").maxOffset()
+	if offset := time.Duration(minutes) * time.Minute; offset > longest {
+		longest = offset
+	}
+	return longest, nil
+}
+
+// Update stores the delivery state of a reminder
+func (s *PgReminderStore) Update(ctx context.Context, reminder *Reminder) error {
+	query := `
+		UPDATE reminders
+		SET status = $2, attempts = $3, last_error = $4, sent_at = $5
+		WHERE id = $1
+	`
+
+	_, err := s.db.Exec(ctx, query,
+		reminder.ID, reminder.Status, reminder.Attempts, reminder.LastError, reminder.SentAt,
+	)
+	return err
+}
+
+// PgNotificationStore implements NotificationStore interface with PostgreSQL storage
+type PgNotificationStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgNotificationStore creates a notification store on an existing connection pool
+func NewPgNotificationStore(db *pgxpool.Pool) *PgNotificationStore {
+	return &PgNotificationStore{
+		db: db,
+	}
+}
+
+// Create adds a notification to the user's inbox
+func (s *PgNotificationStore) Create(notification *Notification) error {
+	query := `
+		INSERT INTO notifications (id, user_id, task_id, title, body, created_at, read_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := s.db.Exec(context.Background(), query,
+		notification.ID, notification.UserID, notification.TaskID, notification.Title, notification.Body,
+		notification.CreatedAt, notification.ReadAt,
+	)
+	return err
+}
+
+// ListByUser returns the user's notifications, newest first; limit <= 0 returns all of them
+func (s *PgNotificationStore) ListByUser(ctx context.Context, userID string, unreadOnly bool, limit int) ([]*Notification, error) {
+	query := `
+		SELECT id, user_id, task_id, title, body, created_at, read_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC
+		LIMIT NULLIF($3, 0)
+	`
+
+	if limit < 0 {
+		limit = 0
+	}
+
+	rows, err := s.db.Query(ctx, query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*Notification{}
+	for rows.Next() {
+		var notification Notification
+		err := rows.Scan(
+			&notification.ID, &notification.UserID, &notification.TaskID, &notification.Title, &notification.Body,
+			&notification.CreatedAt, &notification.ReadAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, &notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+// CountUnread returns the number of unread notifications of the user
+func (s *PgNotificationStore) CountUnread(userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+
+	var count int
+	err := s.db.QueryRow(context.Background(), query, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *PgNotificationStore) MarkRead(id string, userID string) error {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`
+
+	tag, err := s.db.Exec(context.Background(), query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications as read
+func (s *PgNotificationStore) MarkAllRead(userID string) error {
+	query := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+
+	_, err := s.db.Exec(context.Background(), query, userID)
+	return err
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgReviewStore implements ReviewStore interface with PostgreSQL storage
+type PgReviewStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgReviewStore creates a review store on an existing connection pool
+func NewPgReviewStore(db *pgxpool.Pool) *PgReviewStore {
+	return &PgReviewStore{
+		db: db,
+	}
+}
+
+// reviewColumns lists the review session columns in the order expected by scanReview
+const reviewColumns = `id, user_id, steps, notes, start_snapshot, end_snapshot, started_at, updated_at, completed_at`
+
+// scanReview reads a single review session row selected with reviewColumns
+func scanReview(row pgx.Row) (*ReviewSession, error) {
+	var session ReviewSession
+	var stepsJSON, startJSON, endJSON []byte
+
+	err := row.Scan(
+		&session.ID, &session.UserID, &stepsJSON, &session.Notes, &startJSON, &endJSON,
+		&session.StartedAt, &session.UpdatedAt, &session.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(stepsJSON, &session.Steps); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(startJSON, &session.StartSnapshot); err != nil {
+		return nil, err
+	}
+	if endJSON != nil {
+		if err := json.Unmarshal(endJSON, &session.EndSnapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	return &session, nil
+}
+
+// GetForUser retrieves a review session by ID, hiding other users' sessions
+func (s *PgReviewStore) GetForUser(id string, userID string) (*ReviewSession, error) {
+	query := `SELECT ` + reviewColumns + ` FROM review_sessions WHERE id = $1 AND user_id = $2`
+
+	session, err := scanReview(s.db.QueryRow(context.Background(), query, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetActive returns the user's most recent unfinished review session
+func (s *PgReviewStore) GetActive(userID string) (*ReviewSession, error) {
+	query := `SELECT ` + reviewColumns + `
+		FROM review_sessions
+		WHERE user_id = $1 AND completed_at IS NULL
+		ORDER BY started_at DESC
+		LIMIT 1
+	`
+
+	session, err := scanReview(s.db.QueryRow(context.Background(), query, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// ListByUser returns the user's review sessions, newest first; limit <= 0 returns all of them
+func (s *PgReviewStore) ListByUser(ctx context.Context, userID string, limit int) ([]*ReviewSession, error) {
+	query := `SELECT ` + reviewColumns + `
+		FROM review_sessions
+		WHERE user_id = $1
+		ORDER BY started_at DESC
+		LIMIT NULLIF($2, 0)
+	`
+
+	if limit < 0 {
+		limit = 0
+	}
+
+	rows, err := s.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*ReviewSession{}
+	for rows.Next() {
+		session, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Save creates or updates a review session
+func (s *PgReviewStore) Save(session *ReviewSession) error {
+	stepsJSON, err := json.Marshal(session.Steps)
+	if err != nil {
+		return err
+	}
+	startJSON, err := json.Marshal(session.StartSnapshot)
+	if err != nil {
+		return err
+	}
+	var endJSON []byte
+	if session.EndSnapshot != nil {
+		if endJSON, err = json.Marshal(session.EndSnapshot); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO review_sessions (
+			id, user_id, steps, notes, start_snapshot, end_snapshot, started_at, updated_at, completed_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		) ON CONFLICT (id) DO UPDATE SET
+			steps = EXCLUDED.steps,
+			notes = EXCLUDED.notes,
+			end_snapshot = EXCLUDED.end_snapshot,
+			updated_at = EXCLUDED.updated_at,
+			completed_at = EXCLUDED.completed_at
+		WHERE review_sessions.user_id = EXCLUDED.user_id
+	`
+
+	tag, err := s.db.Exec(context.Background(), query,
+		session.ID, session.UserID, stepsJSON, session.Notes, startJSON, endJSON,
+		session.StartedAt, session.UpdatedAt, session.CompletedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The conflicting row belongs to another user
+	if tag.RowsAffected() == 0 {
+		return ErrReviewNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgStaleThresholdStore implements StaleThresholdStore interface with PostgreSQL storage
+type PgStaleThresholdStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgStaleThresholdStore creates a stale threshold store on an existing connection pool
+func NewPgStaleThresholdStore(db *pgxpool.Pool) *PgStaleThresholdStore {
+	return &PgStaleThresholdStore{
+		db: db,
+	}
+}
+
+// Get returns the user's stale thresholds
+func (s *PgStaleThresholdStore) Get(userID string) (StaleThresholds, error) {
+	query := `SELECT inbox_days, waiting_days, someday_days FROM stale_thresholds WHERE user_id = $1`
+
+	var thresholds StaleThresholds
+	err := s.db.QueryRow(context.Background(), query, userID).Scan(
+		&thresholds.InboxDays, &thresholds.WaitingDays, &thresholds.SomedayDays,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return DefaultStaleThresholds(), nil
+		}
+		return StaleThresholds{}, err
+	}
+
+	return thresholds, nil
+}
+
+// Save stores the user's stale thresholds
+func (s *PgStaleThresholdStore) Save(userID string, thresholds StaleThresholds) error {
+	if err := thresholds.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO stale_thresholds (user_id, inbox_days, waiting_days, someday_days, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			inbox_days = EXCLUDED.inbox_days,
+			waiting_days = EXCLUDED.waiting_days,
+			someday_days = EXCLUDED.someday_days,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := s.db.Exec(context.Background(), query,
+		userID, thresholds.InboxDays, thresholds.WaitingDays, thresholds.SomedayDays,
+	)
+	return err
+}
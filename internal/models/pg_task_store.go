@@ -4,8 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -36,50 +36,34 @@ func NewPgTaskStore(connString string) (*PgTaskStore, error) {
 		db: db,
 	}
 
-	// Initialize database schema
-	if err = store.initSchema(); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %v", err)
+	// Bring the database schema up to date
+	if err = store.migrate(); err != nil {
+		return nil, fmt.Errorf("failed to migrate schema: %v", err)
 	}
 
 	return store, nil
 }
 
-// initSchema creates the necessary database tables if they don't exist
-func (s *PgTaskStore) initSchema() error {
-	// Create tasks table
-	_, err := s.db.Exec(context.Background(), `
-		CREATE TABLE IF NOT EXISTS tasks (
-			id TEXT PRIMARY KEY,
-			title TEXT NOT NULL,
-			description TEXT,
-			status TEXT NOT NULL,
-			user_id TEXT,
-			project_id TEXT,
-			parent_id TEXT,
-			contexts JSONB,
-			tags JSONB,
-			due_date TIMESTAMP WITH TIME ZONE,
-			scheduled_date TIMESTAMP WITH TIME ZONE,
-			time_estimate INTEGER,
-			energy_required TEXT,
-			priority INTEGER,
-			timeframe TEXT,
-			is_recurring BOOLEAN DEFAULT FALSE,
-			recurring_rule TEXT,
-			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
-			updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
-			completed_at TIMESTAMP WITH TIME ZONE,
-			deleted_at TIMESTAMP WITH TIME ZONE
-		);
-		
-		CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
-		CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at);
-		CREATE INDEX IF NOT EXISTS idx_tasks_user_id ON tasks(user_id);
-	`)
+// migrate applies any pending schema migrations
+func (s *PgTaskStore) migrate() error {
+	migrator, err := NewMigrator(s.db)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		fmt.Printf("Applied migration %04d_%s\n", migration.Version, migration.Name)
+	}
 
 	return err
 }
 
+// Pool returns the underlying connection pool so other stores can share it
+func (s *PgTaskStore) Pool() *pgxpool.Pool {
+	return s.db
+}
+
 // Close closes the database connection
 func (s *PgTaskStore) Close() {
 	if s.db != nil {
@@ -87,34 +71,45 @@ func (s *PgTaskStore) Close() {
 	}
 }
 
-// Get retrieves a task by ID
-func (s *PgTaskStore) Get(id string) (*Task, error) {
-	query := `
-		SELECT 
-			id, title, description, status, user_id, project_id, parent_id, 
-			contexts, tags, due_date, scheduled_date, time_estimate, 
-			energy_required, priority, timeframe, is_recurring, 
-			recurring_rule, created_at, updated_at, completed_at, deleted_at
-		FROM tasks
-		WHERE id = $1 AND deleted_at IS NULL
-	`
+// taskColumns lists the task columns in the order expected by scanTask
+const taskColumns = `
+	id, title, description, status, user_id, project_id, parent_id,
+	contexts, tags, due_date, scheduled_date, time_estimate,
+	energy_required, priority, timeframe, is_recurring,
+	recurring_rule, recurrence_from, created_at, updated_at, completed_at, deleted_at, version, blocked_by,
+	auto_complete, next_occurrence_id`
+
+// legacyTaskID resolves a task ID given as $1 from before the switch to ULIDs
+const legacyTaskID = `(SELECT task_id FROM task_id_aliases WHERE legacy_id = $1)`
 
+// taskIDMatch matches the task ID given as $1, also resolving IDs from before the switch to ULIDs
+const taskIDMatch = `id IN ($1, ` + legacyTaskID + `)`
+
+// scanTask reads a single task row selected with taskColumns
+func scanTask(row pgx.Row) (*Task, error) {
 	var task Task
-	var contextsJSON, tagsJSON []byte
-	var projectID, parentID, energyRequired, timeframe sql.NullString
+	var contextsJSON, tagsJSON, blockedByJSON []byte
+	var description, projectID, parentID, energyRequired, timeframe sql.NullString
 	var dueDate, scheduledDate, completedAt, deletedAt pgtype.Timestamptz
 	var timeEstimate, priority sql.NullInt32
 	var isRecurring sql.NullBool
-	var recurringRule sql.NullString
+	var recurringRule, recurrenceFrom, nextOccurrenceID sql.NullString
 
-	err := s.db.QueryRow(context.Background(), query, id).Scan(
-		&task.ID, &task.Title, &task.Description, &task.Status, &task.UserID, &projectID, &parentID,
+	err := row.Scan(
+		&task.ID, &task.Title, &description, &task.Status, &task.UserID, &projectID, &parentID,
 		&contextsJSON, &tagsJSON, &dueDate, &scheduledDate, &timeEstimate,
 		&energyRequired, &priority, &timeframe, &isRecurring,
-		&recurringRule, &task.CreatedAt, &task.UpdatedAt, &completedAt, &deletedAt,
+		&recurringRule, &recurrenceFrom, &task.CreatedAt, &task.UpdatedAt, &completedAt, &deletedAt, &task.Version, &blockedByJSON,
+		&task.AutoComplete, &nextOccurrenceID,
 	)
-	
+	if err != nil {
+		return nil, err
+	}
+
 	// Handle nullable string fields
+	if description.Valid {
+		task.Description = description.String
+	}
 	if projectID.Valid {
 		task.ProjectID = projectID.String
 	}
@@ -139,12 +134,11 @@ func (s *PgTaskStore) Get(id string) (*Task, error) {
 	if recurringRule.Valid {
 		task.RecurringRule = recurringRule.String
 	}
-
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, errors.New("task not found")
-		}
-		return nil, err
+	if recurrenceFrom.Valid {
+		task.RecurrenceFrom = RecurrenceAnchor(recurrenceFrom.String)
+	}
+	if nextOccurrenceID.Valid {
+		task.NextOccurrenceID = nextOccurrenceID.String
 	}
 
 	// Convert JSON fields back to Go structures
@@ -164,6 +158,12 @@ func (s *PgTaskStore) Get(id string) (*Task, error) {
 		}
 	}
 
+	if blockedByJSON != nil {
+		if err := json.Unmarshal(blockedByJSON, &task.BlockedBy); err != nil {
+			fmt.Printf("Error unmarshaling blockers: %v\n", err)
+		}
+	}
+
 	// Handle nullable time.Time fields
 	if dueDate.Valid {
 		t := dueDate.Time.Local()
@@ -185,234 +185,97 @@ func (s *PgTaskStore) Get(id string) (*Task, error) {
 	return &task, nil
 }
 
-// GetAll returns all non-deleted tasks
-func (s *PgTaskStore) GetAll() ([]*Task, error) {
-	query := `
-		SELECT 
-			id, title, description, status, user_id, project_id, parent_id, 
-			contexts, tags, due_date, scheduled_date, time_estimate, 
-			energy_required, priority, timeframe, is_recurring, 
-			recurring_rule, created_at, updated_at, completed_at, deleted_at
-		FROM tasks
-		WHERE deleted_at IS NULL
-		ORDER BY created_at DESC
-	`
-
-	rows, err := s.db.Query(context.Background(), query)
-	if err != nil {
-		return nil, err
-	}
+// collectTasks reads all rows selected with taskColumns and closes the result set
+func collectTasks(rows pgx.Rows) ([]*Task, error) {
 	defer rows.Close()
 
 	var tasks []*Task
 	for rows.Next() {
-		var task Task
-		var contextsJSON, tagsJSON []byte
-		var projectID, parentID, energyRequired, timeframe sql.NullString
-		var dueDate, scheduledDate, completedAt, deletedAt pgtype.Timestamptz
-		var timeEstimate, priority sql.NullInt32
-		var isRecurring sql.NullBool
-		var recurringRule sql.NullString
-
-		err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.Status, &task.UserID, &projectID, &parentID,
-			&contextsJSON, &tagsJSON, &dueDate, &scheduledDate, &timeEstimate,
-			&energyRequired, &priority, &timeframe, &isRecurring,
-			&recurringRule, &task.CreatedAt, &task.UpdatedAt, &completedAt, &deletedAt,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
-		
-		// Handle nullable string fields
-		if projectID.Valid {
-			task.ProjectID = projectID.String
-		}
-		if parentID.Valid {
-			task.ParentID = parentID.String
-		}
-		if energyRequired.Valid {
-			task.EnergyRequired = energyRequired.String
-		}
-		if timeframe.Valid {
-			task.Timeframe = Timeframe(timeframe.String)
-		}
-		if timeEstimate.Valid {
-			task.TimeEstimate = int(timeEstimate.Int32)
-		}
-		if priority.Valid {
-			task.Priority = int(priority.Int32)
-		}
-		if isRecurring.Valid {
-			task.IsRecurring = isRecurring.Bool
-		}
-		if recurringRule.Valid {
-			task.RecurringRule = recurringRule.String
-		}
-
-		// Convert JSON fields back to Go structures
-		if contextsJSON != nil {
-			var contexts []string
-			if err := json.Unmarshal(contextsJSON, &contexts); err == nil {
-				for _, c := range contexts {
-					task.Contexts = append(task.Contexts, Context(c))
-				}
-			}
-		}
-
-		if tagsJSON != nil {
-			if err := json.Unmarshal(tagsJSON, &task.Tags); err != nil {
-				// Log the error but continue
-				fmt.Printf("Error unmarshaling tags: %v\n", err)
-			}
-		}
-
-		// Handle nullable time.Time fields
-		if dueDate.Valid {
-			t := dueDate.Time.Local()
-			task.DueDate = &t
-		}
-		if scheduledDate.Valid {
-			t := scheduledDate.Time.Local()
-			task.ScheduledDate = &t
-		}
-		if completedAt.Valid {
-			t := completedAt.Time.Local()
-			task.CompletedAt = &t
-		}
-		if deletedAt.Valid {
-			t := deletedAt.Time.Local()
-			task.DeletedAt = &t
-		}
-
-		tasks = append(tasks, &task)
+		tasks = append(tasks, task)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
 	return tasks, nil
 }
 
-// GetAllByUserID returns all non-deleted tasks for a specific user
-func (s *PgTaskStore) GetAllByUserID(userID string) ([]*Task, error) {
-	query := `
-		SELECT 
-			id, title, description, status, user_id, project_id, parent_id, 
-			contexts, tags, due_date, scheduled_date, time_estimate, 
-			energy_required, priority, timeframe, is_recurring, 
-			recurring_rule, created_at, updated_at, completed_at, deleted_at
+// Get retrieves a task by ID
+func (s *PgTaskStore) Get(id string) (*Task, error) {
+	query := `SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE deleted_at IS NULL AND user_id = $1
-		ORDER BY created_at DESC
+		WHERE ` + taskIDMatch + ` AND deleted_at IS NULL
 	`
 
-	rows, err := s.db.Query(context.Background(), query, userID)
+	task, err := scanTask(s.db.QueryRow(context.Background(), query, id))
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrTaskNotFound
+		}
 		return nil, err
 	}
-	defer rows.Close()
 
-	var tasks []*Task
-	for rows.Next() {
-		var task Task
-		var contextsJSON, tagsJSON []byte
-		var projectID, parentID, energyRequired, timeframe sql.NullString
-		var dueDate, scheduledDate, completedAt, deletedAt pgtype.Timestamptz
-		var timeEstimate, priority sql.NullInt32
-		var isRecurring sql.NullBool
-		var recurringRule sql.NullString
-
-		err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.Status, &task.UserID, &projectID, &parentID,
-			&contextsJSON, &tagsJSON, &dueDate, &scheduledDate, &timeEstimate,
-			&energyRequired, &priority, &timeframe, &isRecurring,
-			&recurringRule, &task.CreatedAt, &task.UpdatedAt, &completedAt, &deletedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		
-		// Handle nullable string fields
-		if projectID.Valid {
-			task.ProjectID = projectID.String
-		}
-		if parentID.Valid {
-			task.ParentID = parentID.String
-		}
-		if energyRequired.Valid {
-			task.EnergyRequired = energyRequired.String
-		}
-		if timeframe.Valid {
-			task.Timeframe = Timeframe(timeframe.String)
-		}
-		if timeEstimate.Valid {
-			task.TimeEstimate = int(timeEstimate.Int32)
-		}
-		if priority.Valid {
-			task.Priority = int(priority.Int32)
-		}
-		if isRecurring.Valid {
-			task.IsRecurring = isRecurring.Bool
-		}
-		if recurringRule.Valid {
-			task.RecurringRule = recurringRule.String
-		}
+	return task, nil
+}
 
-		// Convert JSON fields back to Go structures
-		if contextsJSON != nil {
-			var contexts []string
-			if err := json.Unmarshal(contextsJSON, &contexts); err == nil {
-				for _, c := range contexts {
-					task.Contexts = append(task.Contexts, Context(c))
-				}
-			}
-		}
+// GetForUser retrieves a task by ID, hiding tasks owned by other users
+func (s *PgTaskStore) GetForUser(id string, userID string) (*Task, error) {
+	query := `SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE ` + taskIDMatch + ` AND user_id = $2 AND deleted_at IS NULL
+	`
 
-		if tagsJSON != nil {
-			if err := json.Unmarshal(tagsJSON, &task.Tags); err != nil {
-				// Log the error but continue
-				fmt.Printf("Error unmarshaling tags: %v\n", err)
-			}
+	task, err := scanTask(s.db.QueryRow(context.Background(), query, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrTaskNotFound
 		}
+		return nil, err
+	}
 
-		// Handle nullable time.Time fields
-		if dueDate.Valid {
-			t := dueDate.Time.Local()
-			task.DueDate = &t
-		}
-		if scheduledDate.Valid {
-			t := scheduledDate.Time.Local()
-			task.ScheduledDate = &t
-		}
-		if completedAt.Valid {
-			t := completedAt.Time.Local()
-			task.CompletedAt = &t
-		}
-		if deletedAt.Valid {
-			t := deletedAt.Time.Local()
-			task.DeletedAt = &t
-		}
+	return task, nil
+}
+
+// GetAll returns all non-deleted tasks
+func (s *PgTaskStore) GetAll() ([]*Task, error) {
+	query := `SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+	`
 
-		tasks = append(tasks, &task)
+	rows, err := s.db.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
 	}
 
-	if err = rows.Err(); err != nil {
+	return collectTasks(rows)
+}
+
+// GetAllByUserID returns all non-deleted tasks for a specific user
+func (s *PgTaskStore) GetAllByUserID(userID string) ([]*Task, error) {
+	query := `SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE deleted_at IS NULL AND user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.Query(context.Background(), query, userID)
+	if err != nil {
 		return nil, err
 	}
 
-	return tasks, nil
+	return collectTasks(rows)
 }
 
 // GetByStatus returns all tasks with the specified status
 func (s *PgTaskStore) GetByStatus(status TaskStatus) ([]*Task, error) {
-	query := `
-		SELECT 
-			id, title, description, status, user_id, project_id, parent_id, 
-			contexts, tags, due_date, scheduled_date, time_estimate, 
-			energy_required, priority, timeframe, is_recurring, 
-			recurring_rule, created_at, updated_at, completed_at, deleted_at
+	query := `SELECT ` + taskColumns + `
 		FROM tasks
 		WHERE status = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -422,209 +285,276 @@ func (s *PgTaskStore) GetByStatus(status TaskStatus) ([]*Task, error) {
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var tasks []*Task
-	for rows.Next() {
-		var task Task
-		var contextsJSON, tagsJSON []byte
-		var projectID, parentID, energyRequired, timeframe sql.NullString
-		var dueDate, scheduledDate, completedAt, deletedAt pgtype.Timestamptz
-		var timeEstimate, priority sql.NullInt32
-		var isRecurring sql.NullBool
-		var recurringRule sql.NullString
-
-		err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.Status, &task.UserID, &projectID, &parentID,
-			&contextsJSON, &tagsJSON, &dueDate, &scheduledDate, &timeEstimate,
-			&energyRequired, &priority, &timeframe, &isRecurring,
-			&recurringRule, &task.CreatedAt, &task.UpdatedAt, &completedAt, &deletedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		
-		// Handle nullable string fields
-		if projectID.Valid {
-			task.ProjectID = projectID.String
-		}
-		if parentID.Valid {
-			task.ParentID = parentID.String
-		}
-		if energyRequired.Valid {
-			task.EnergyRequired = energyRequired.String
-		}
-		if timeframe.Valid {
-			task.Timeframe = Timeframe(timeframe.String)
-		}
-		if timeEstimate.Valid {
-			task.TimeEstimate = int(timeEstimate.Int32)
-		}
-		if priority.Valid {
-			task.Priority = int(priority.Int32)
-		}
-		if isRecurring.Valid {
-			task.IsRecurring = isRecurring.Bool
-		}
-		if recurringRule.Valid {
-			task.RecurringRule = recurringRule.String
-		}
+	return collectTasks(rows)
+}
 
-		// Convert JSON fields back to Go structures
-		if contextsJSON != nil {
-			var contexts []string
-			if err := json.Unmarshal(contextsJSON, &contexts); err == nil {
-				for _, c := range contexts {
-					task.Contexts = append(task.Contexts, Context(c))
-				}
-			}
-		}
+// GetByStatusAndUserID returns all tasks with the specified status for a specific user
+func (s *PgTaskStore) GetByStatusAndUserID(status TaskStatus, userID string) ([]*Task, error) {
+	query := `SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE status = $1 AND user_id = $2 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`
 
-		if tagsJSON != nil {
-			if err := json.Unmarshal(tagsJSON, &task.Tags); err != nil {
-				fmt.Printf("Error unmarshaling tags: %v\n", err)
-			}
-		}
+	rows, err := s.db.Query(context.Background(), query, string(status), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectTasks(rows)
+}
+
+// taskSortClauses maps each sort order to its ORDER BY clause; id breaks ties so pages are stable
+var taskSortClauses = map[TaskSort]string{
+	SortCreatedDesc: "created_at DESC, id",
+	SortCreatedAsc:  "created_at ASC, id",
+	SortUpdatedDesc: "updated_at DESC, id",
+	SortDueAsc:      "due_date ASC NULLS LAST, id",
+	SortPriorityAsc: "NULLIF(priority, 0) ASC NULLS LAST, id",
+	SortTitleAsc:    "LOWER(title) ASC, id",
+}
+
+// taskSortColumns maps each sort order to the expression its ORDER BY clause sorts on first
+var taskSortColumns = map[TaskSort]string{
+	SortCreatedDesc: "created_at",
+	SortCreatedAsc:  "created_at",
+	SortUpdatedDesc: "updated_at",
+	SortDueAsc:      "due_date",
+	SortPriorityAsc: "NULLIF(priority, 0)",
+	SortTitleAsc:    "LOWER(title)",
+}
 
-		// Handle nullable time.Time fields
-		if dueDate.Valid {
-			t := dueDate.Time.Local()
-			task.DueDate = &t
+// keysetCondition returns the condition selecting the tasks that sort after a cursor, matching the
+// order of taskSortClauses, with its arguments numbered after the argCount already in use
+func keysetCondition(c *taskCursor, argCount int) (string, []interface{}) {
+	column := taskSortColumns[c.Sort]
+	id := fmt.Sprintf("$%d", argCount+1)
+	if c.Key == nil {
+		// Only tasks without a key are left, ordered by ID
+		return fmt.Sprintf("(%s IS NULL AND id > %s)", column, id), []interface{}{c.ID}
+	}
+
+	last, _ := c.probe()
+	var key interface{}
+	switch c.Sort {
+	case SortCreatedAsc, SortCreatedDesc:
+		key = last.CreatedAt
+	case SortUpdatedDesc:
+		key = last.UpdatedAt
+	case SortDueAsc:
+		key = *last.DueDate
+	case SortPriorityAsc:
+		key = last.Priority
+	case SortTitleAsc:
+		key = last.Title
+	}
+
+	k := fmt.Sprintf("$%d", argCount+2)
+	switch c.Sort {
+	case SortCreatedDesc, SortUpdatedDesc:
+		return fmt.Sprintf("(%s < %s OR (%s = %s AND id > %s))", column, k, column, k, id), []interface{}{c.ID, key}
+	case SortDueAsc, SortPriorityAsc:
+		// Tasks without a key sort last
+		return fmt.Sprintf("(%s > %s OR %s IS NULL OR (%s = %s AND id > %s))", column, k, column, column, k, id), []interface{}{c.ID, key}
+	default:
+		return fmt.Sprintf("(%s > %s OR (%s = %s AND id > %s))", column, k, column, k, id), []interface{}{c.ID, key}
+	}
+}
+
+// Query returns a single page of tasks matching the query's filter, in the requested order
+func (s *PgTaskStore) Query(ctx context.Context, q TaskQuery) (*TaskPage, error) {
+	order := q.order()
+	cursor, err := decodeCursor(q.Cursor, order)
+	if err != nil {
+		return nil, err
+	}
+
+	f := q.Filter
+	conditions := []string{"deleted_at IS NULL"}
+	if f.IncludeDeleted {
+		conditions = []string{"TRUE"}
+	}
+	var args []interface{}
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if f.UserID != "" {
+		addCondition("user_id = $%d", f.UserID)
+	}
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, len(f.Statuses))
+		for i, status := range f.Statuses {
+			statuses[i] = string(status)
 		}
-		if scheduledDate.Valid {
-			t := scheduledDate.Time.Local()
-			task.ScheduledDate = &t
+		addCondition("status = ANY($%d)", statuses)
+	}
+	if f.ProjectID != "" {
+		addCondition("project_id = $%d", f.ProjectID)
+	}
+	if f.NoProject {
+		conditions = append(conditions, "(project_id IS NULL OR project_id = '')")
+	}
+	if f.Context != "" {
+		contextJSON, _ := json.Marshal([]string{string(f.Context)})
+		addCondition("contexts @> $%d::jsonb", string(contextJSON))
+	}
+	if f.Tag != "" {
+		tagJSON, _ := json.Marshal([]string{f.Tag})
+		addCondition("tags @> $%d::jsonb", string(tagJSON))
+	}
+	if f.DueAfter != nil {
+		addCondition("due_date >= $%d", *f.DueAfter)
+	}
+	if f.DueBefore != nil {
+		addCondition("due_date < $%d", *f.DueBefore)
+	}
+	if f.DatedAfter != nil || f.DatedBefore != nil {
+		var after, before string
+		if f.DatedAfter != nil {
+			args = append(args, *f.DatedAfter)
+			after = fmt.Sprintf("$%d", len(args))
 		}
-		if completedAt.Valid {
-			t := completedAt.Time.Local()
-			task.CompletedAt = &t
+		if f.DatedBefore != nil {
+			args = append(args, *f.DatedBefore)
+			before = fmt.Sprintf("$%d", len(args))
 		}
-		if deletedAt.Valid {
-			t := deletedAt.Time.Local()
-			task.DeletedAt = &t
+		within := func(column string) string {
+			var bounds []string
+			if f.DatedAfter != nil {
+				bounds = append(bounds, column+" >= "+after)
+			}
+			if f.DatedBefore != nil {
+				bounds = append(bounds, column+" < "+before)
+			}
+			return strings.Join(bounds, " AND ")
 		}
+		conditions = append(conditions, fmt.Sprintf("((%s) OR (%s))", within("due_date"), within("scheduled_date")))
+	}
+	if f.Energy != "" {
+		addCondition("LOWER(energy_required) = LOWER($%d)", f.Energy)
+	}
+	if f.Priority != 0 {
+		addCondition("priority = $%d", f.Priority)
+	}
+	if f.Unblocked {
+		// Same rule as hasOpenBlocker: only open blockers in the task's own project count
+		conditions = append(conditions, `NOT EXISTS (
+			SELECT 1 FROM tasks blocker
+			WHERE blocker.id IN (SELECT jsonb_array_elements_text(tasks.blocked_by))
+				AND blocker.project_id = tasks.project_id AND blocker.project_id <> ''
+				AND blocker.status <> 'done' AND blocker.deleted_at IS NULL
+		)`)
+	}
 
-		tasks = append(tasks, &task)
+	if cursor != nil {
+		condition, cursorArgs := keysetCondition(cursor, len(args))
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
 	}
 
-	if err = rows.Err(); err != nil {
+	// Fetch one extra row to find out whether another page follows
+	limit := q.pageLimit()
+	args = append(args, limit+1)
+	query := fmt.Sprintf(`SELECT %s
+		FROM tasks
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d
+	`, taskColumns, strings.Join(conditions, " AND "), taskSortClauses[order], len(args))
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
 		return nil, err
 	}
 
-	return tasks, nil
+	tasks, err := collectTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TaskPage{Tasks: tasks}
+	if len(tasks) > limit {
+		page.Tasks = tasks[:limit]
+		page.NextCursor = encodeCursor(page.Tasks[limit-1], order)
+	}
+	if page.Tasks == nil {
+		page.Tasks = []*Task{}
+	}
+
+	return page, nil
 }
 
-// GetByStatusAndUserID returns all tasks with the specified status for a specific user
-func (s *PgTaskStore) GetByStatusAndUserID(status TaskStatus, userID string) ([]*Task, error) {
-	query := `
-		SELECT 
-			id, title, description, status, user_id, project_id, parent_id, 
-			contexts, tags, due_date, scheduled_date, time_estimate, 
-			energy_required, priority, timeframe, is_recurring, 
-			recurring_rule, created_at, updated_at, completed_at, deleted_at
+// GetChildren returns the direct sub-tasks of a user's task, oldest first
+func (s *PgTaskStore) GetChildren(ctx context.Context, parentID string, userID string) ([]*Task, error) {
+	query := `SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE status = $1 AND user_id = $2 AND deleted_at IS NULL
-		ORDER BY created_at DESC
+		WHERE parent_id = $1 AND user_id = $2 AND deleted_at IS NULL
+		ORDER BY created_at, id
 	`
 
-	rows, err := s.db.Query(context.Background(), query, string(status), userID)
+	rows, err := s.db.Query(ctx, query, parentID, userID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var tasks []*Task
-	for rows.Next() {
-		var task Task
-		var contextsJSON, tagsJSON []byte
-		var projectID, parentID, energyRequired, timeframe sql.NullString
-		var dueDate, scheduledDate, completedAt, deletedAt pgtype.Timestamptz
-		var timeEstimate, priority sql.NullInt32
-		var isRecurring sql.NullBool
-		var recurringRule sql.NullString
-
-		err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.Status, &task.UserID, &projectID, &parentID,
-			&contextsJSON, &tagsJSON, &dueDate, &scheduledDate, &timeEstimate,
-			&energyRequired, &priority, &timeframe, &isRecurring,
-			&recurringRule, &task.CreatedAt, &task.UpdatedAt, &completedAt, &deletedAt,
+	return collectTasks(rows)
+}
+
+// GetSubtree returns every descendant of a user's task, level by level
+func (s *PgTaskStore) GetSubtree(ctx context.Context, rootID string, userID string) ([]*Task, error) {
+	query := fmt.Sprintf(`
+		WITH RECURSIVE subtree AS (
+			SELECT tasks.*, 1 AS depth
+			FROM tasks
+			WHERE parent_id = $1 AND user_id = $2 AND deleted_at IS NULL
+			UNION ALL
+			SELECT child.*, subtree.depth + 1
+			FROM tasks child
+			JOIN subtree ON child.parent_id = subtree.id
+			WHERE child.user_id = $2 AND child.deleted_at IS NULL AND subtree.depth < %d
 		)
-		if err != nil {
-			return nil, err
-		}
-		
-		// Handle nullable string fields
-		if projectID.Valid {
-			task.ProjectID = projectID.String
-		}
-		if parentID.Valid {
-			task.ParentID = parentID.String
-		}
-		if energyRequired.Valid {
-			task.EnergyRequired = energyRequired.String
-		}
-		if timeframe.Valid {
-			task.Timeframe = Timeframe(timeframe.String)
-		}
-		if timeEstimate.Valid {
-			task.TimeEstimate = int(timeEstimate.Int32)
-		}
-		if priority.Valid {
-			task.Priority = int(priority.Int32)
-		}
-		if isRecurring.Valid {
-			task.IsRecurring = isRecurring.Bool
-		}
-		if recurringRule.Valid {
-			task.RecurringRule = recurringRule.String
-		}
+		SELECT %s
+		FROM subtree
+		ORDER BY depth, created_at, id
+	`, maxSubtreeDepth, taskColumns)
 
-		// Convert JSON fields back to Go structures
-		if contextsJSON != nil {
-			var contexts []string
-			if err := json.Unmarshal(contextsJSON, &contexts); err == nil {
-				for _, c := range contexts {
-					task.Contexts = append(task.Contexts, Context(c))
-				}
-			}
-		}
+	rows, err := s.db.Query(ctx, query, rootID, userID)
+	if err != nil {
+		return nil, err
+	}
 
-		if tagsJSON != nil {
-			if err := json.Unmarshal(tagsJSON, &task.Tags); err != nil {
-				fmt.Printf("Error unmarshaling tags: %v\n", err)
-			}
-		}
+	return collectTasks(rows)
+}
 
-		// Handle nullable time.Time fields
-		if dueDate.Valid {
-			t := dueDate.Time.Local()
-			task.DueDate = &t
-		}
-		if scheduledDate.Valid {
-			t := scheduledDate.Time.Local()
-			task.ScheduledDate = &t
-		}
-		if completedAt.Valid {
-			t := completedAt.Time.Local()
-			task.CompletedAt = &t
-		}
-		if deletedAt.Valid {
-			t := deletedAt.Time.Local()
-			task.DeletedAt = &t
-		}
+// Save creates or updates a task
+func (s *PgTaskStore) Save(task *Task) error {
+	return s.save(task, "", false)
+}
 
-		tasks = append(tasks, &task)
+// SaveForUser creates or updates a task on behalf of a user.
+// It refuses to write tasks owned by someone else or to hand a task over to another user.
+func (s *PgTaskStore) SaveForUser(task *Task, userID string) error {
+	if task.UserID != userID {
+		return ErrTaskNotFound
 	}
+	return s.save(task, userID, false)
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+// ReplaceForUser writes a user's task over whatever version is stored
+func (s *PgTaskStore) ReplaceForUser(task *Task, userID string) error {
+	if task.UserID != userID {
+		return ErrTaskNotFound
 	}
-
-	return tasks, nil
+	return s.save(task, userID, true)
 }
 
-// Save creates or updates a task
-func (s *PgTaskStore) Save(task *Task) error {
+// save upserts a task and records what changed in its history; when ownerID is set, an existing row
+// is only updated if it belongs to that user. Unless forced, the row must still be at the task's version.
+// The caller's task only gets its new version and timestamp once the save went through.
+func (s *PgTaskStore) save(task *Task, ownerID string, force bool) error {
 	if err := task.Validate(); err != nil {
 		return err
 	}
@@ -645,17 +575,24 @@ func (s *PgTaskStore) Save(task *Task) error {
 		return err
 	}
 
-	// Ensure task has an updated timestamp
-	task.UpdatedAt = time.Now()
+	// Always store an array, so queries can expand it
+	blockedByJSON, err := json.Marshal(append([]string{}, task.BlockedBy...))
+	if err != nil {
+		return err
+	}
+
+	// The stored row gets a new timestamp
+	updatedAt := time.Now()
 
 	query := `
 		INSERT INTO tasks (
 			id, title, description, status, user_id, project_id, parent_id, 
 			contexts, tags, due_date, scheduled_date, time_estimate, 
 			energy_required, priority, timeframe, is_recurring, 
-			recurring_rule, created_at, updated_at, completed_at, deleted_at
+			recurring_rule, recurrence_from, created_at, updated_at, completed_at, deleted_at, version, blocked_by,
+			auto_complete, next_occurrence_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $24, $25, $26, $27
 		) ON CONFLICT (id) DO UPDATE SET
 			title = EXCLUDED.title,
 			description = EXCLUDED.description,
@@ -673,19 +610,112 @@ func (s *PgTaskStore) Save(task *Task) error {
 			timeframe = EXCLUDED.timeframe,
 			is_recurring = EXCLUDED.is_recurring,
 			recurring_rule = EXCLUDED.recurring_rule,
+			recurrence_from = EXCLUDED.recurrence_from,
 			updated_at = EXCLUDED.updated_at,
 			completed_at = EXCLUDED.completed_at,
-			deleted_at = EXCLUDED.deleted_at
+			deleted_at = EXCLUDED.deleted_at,
+			version = EXCLUDED.version,
+			blocked_by = EXCLUDED.blocked_by,
+			auto_complete = EXCLUDED.auto_complete,
+			next_occurrence_id = EXCLUDED.next_occurrence_id
+		WHERE $23 = '' OR tasks.user_id = $23
 	`
 
-	_, err = s.db.Exec(context.Background(), query,
-		task.ID, task.Title, task.Description, string(task.Status), task.UserID, task.ProjectID, task.ParentID,
-		contextsJSON, tagsJSON, task.DueDate, task.ScheduledDate, task.TimeEstimate,
-		task.EnergyRequired, task.Priority, string(task.Timeframe), task.IsRecurring,
-		task.RecurringRule, task.CreatedAt, task.UpdatedAt, task.CompletedAt, task.DeletedAt,
-	)
+	ctx := context.Background()
+	var version int
+	var events []*TaskEvent
+	err = pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		// Lock the current row so concurrent saves are recorded against the state they replaced
+		previous, err := scanTask(tx.QueryRow(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = $1 FOR UPDATE`, task.ID))
+		if err == pgx.ErrNoRows {
+			previous = nil
+		} else if err != nil {
+			return err
+		}
 
-	return err
+		// Don't tell another user's task apart from a missing one
+		if previous != nil && ownerID != "" && previous.UserID != ownerID {
+			return ErrTaskNotFound
+		}
+
+		version, err = nextVersion(previous, task, force)
+		if err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, query,
+			task.ID, task.Title, task.Description, string(task.Status), task.UserID, task.ProjectID, task.ParentID,
+			contextsJSON, tagsJSON, task.DueDate, task.ScheduledDate, task.TimeEstimate,
+			task.EnergyRequired, task.Priority, string(task.Timeframe), task.IsRecurring,
+			task.RecurringRule, string(task.RecurrenceFrom), task.CreatedAt, updatedAt, task.CompletedAt, task.DeletedAt,
+			ownerID, version, blockedByJSON, task.AutoComplete, task.NextOccurrenceID,
+		)
+		if err != nil {
+			return err
+		}
+
+		// The conflicting row belongs to another user
+		if tag.RowsAffected() == 0 {
+			return ErrTaskNotFound
+		}
+
+		actor := ownerID
+		if actor == "" {
+			actor = task.UserID
+		}
+		events = TaskChanges(previous, task, actor, updatedAt)
+		return insertTaskEvents(ctx, tx, events)
+	})
+	if err != nil {
+		return err
+	}
+
+	task.UpdatedAt = updatedAt
+	task.Version = version
+	task.undo.recordEvents(events)
+	return nil
+}
+
+// taskEventColumns lists the task event columns in the order expected by scanTaskEvent
+const taskEventColumns = `id, task_id, user_id, type, field, before_value, after_value, created_at`
+
+// insertTaskEvents appends events to the task history
+func insertTaskEvents(ctx context.Context, tx pgx.Tx, events []*TaskEvent) error {
+	for _, event := range events {
+		_, err := tx.Exec(ctx, `INSERT INTO task_events (`+taskEventColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			event.ID, event.TaskID, event.UserID, string(event.Type), event.Field,
+			nullableJSON(event.Before), nullableJSON(event.After), event.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nullableJSON stores a missing JSON value as NULL
+func nullableJSON(value []byte) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
+
+// scanTaskEvent reads a single task event row selected with taskEventColumns
+func scanTaskEvent(row pgx.Row) (*TaskEvent, error) {
+	var event TaskEvent
+	var eventType string
+	var before, after []byte
+
+	if err := row.Scan(&event.ID, &event.TaskID, &event.UserID, &eventType, &event.Field, &before, &after, &event.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	event.Type = TaskEventType(eventType)
+	event.Before = before
+	event.After = after
+	return &event, nil
 }
 
 // Delete soft-deletes a task
@@ -701,135 +731,112 @@ func (s *PgTaskStore) Delete(id string) error {
 	return s.Save(task)
 }
 
-// Search finds tasks that match the query in title, description, contexts, or tags
-func (s *PgTaskStore) Search(query string) ([]*Task, error) {
-	// Build a query that searches in multiple columns with case-insensitive matching
-	sqlQuery := `
-		SELECT 
-			id, title, description, status, user_id, project_id, parent_id, 
-			contexts, tags, due_date, scheduled_date, time_estimate, 
-			energy_required, priority, timeframe, is_recurring, 
-			recurring_rule, created_at, updated_at, completed_at, deleted_at
+// ListDeleted returns a user's soft-deleted tasks, most recently deleted first
+func (s *PgTaskStore) ListDeleted(ctx context.Context, userID string) ([]*Task, error) {
+	query := `SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE deleted_at IS NULL AND (
-			LOWER(title) LIKE LOWER($1) OR 
-			LOWER(description) LIKE LOWER($1) OR
-			contexts::text ILIKE $1 OR
-			tags::text ILIKE $1
-		)
-		ORDER BY created_at DESC
+		WHERE deleted_at IS NOT NULL AND user_id = $1
+		ORDER BY deleted_at DESC, id
 	`
 
-	// Add wildcards for partial matching
-	searchPattern := "%" + query + "%"
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := s.db.Query(context.Background(), sqlQuery, searchPattern)
+	tasks, err := collectTasks(rows)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	if tasks == nil {
+		tasks = []*Task{}
+	}
+	return tasks, nil
+}
 
-	var tasks []*Task
-	for rows.Next() {
-		var task Task
-		var contextsJSON, tagsJSON []byte
-		var projectID, parentID, energyRequired, timeframe sql.NullString
-		var dueDate, scheduledDate, completedAt, deletedAt pgtype.Timestamptz
-		var timeEstimate, priority sql.NullInt32
-		var isRecurring sql.NullBool
-		var recurringRule sql.NullString
-
-		err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.Status, &task.UserID, &projectID, &parentID,
-			&contextsJSON, &tagsJSON, &dueDate, &scheduledDate, &timeEstimate,
-			&energyRequired, &priority, &timeframe, &isRecurring,
-			&recurringRule, &task.CreatedAt, &task.UpdatedAt, &completedAt, &deletedAt,
+// Restore brings back a soft-deleted task
+func (s *PgTaskStore) Restore(id string, userID string) error {
+	query := `
+		WITH restored AS (
+			UPDATE tasks SET deleted_at = NULL, updated_at = $3, version = version + 1
+			WHERE ` + taskIDMatch + ` AND user_id = $2 AND deleted_at IS NOT NULL
+			RETURNING id
 		)
-		if err != nil {
-			return nil, err
-		}
-		
-		// Handle nullable string fields
-		if projectID.Valid {
-			task.ProjectID = projectID.String
-		}
-		if parentID.Valid {
-			task.ParentID = parentID.String
-		}
-		if energyRequired.Valid {
-			task.EnergyRequired = energyRequired.String
-		}
-		if timeframe.Valid {
-			task.Timeframe = Timeframe(timeframe.String)
-		}
-		if timeEstimate.Valid {
-			task.TimeEstimate = int(timeEstimate.Int32)
-		}
-		if priority.Valid {
-			task.Priority = int(priority.Int32)
-		}
-		if isRecurring.Valid {
-			task.IsRecurring = isRecurring.Bool
-		}
-		if recurringRule.Valid {
-			task.RecurringRule = recurringRule.String
-		}
+		INSERT INTO task_events (` + taskEventColumns + `)
+		SELECT $4, id, $2, $5, '', NULL, NULL, $3 FROM restored
+	`
 
-		// Convert JSON fields back to Go structures
-		if contextsJSON != nil {
-			var contexts []string
-			if err := json.Unmarshal(contextsJSON, &contexts); err == nil {
-				for _, c := range contexts {
-					task.Contexts = append(task.Contexts, Context(c))
-				}
-			}
-		}
+	tag, err := s.db.Exec(context.Background(), query, id, userID, time.Now(), GenerateID(), string(EventRestored))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTaskNotFound
+	}
 
-		if tagsJSON != nil {
-			if err := json.Unmarshal(tagsJSON, &task.Tags); err != nil {
-				fmt.Printf("Error unmarshaling tags: %v\n", err)
-			}
-		}
+	return nil
+}
 
-		// Handle nullable time.Time fields
-		if dueDate.Valid {
-			t := dueDate.Time.Local()
-			task.DueDate = &t
-		}
-		if scheduledDate.Valid {
-			t := scheduledDate.Time.Local()
-			task.ScheduledDate = &t
-		}
-		if completedAt.Valid {
-			t := completedAt.Time.Local()
-			task.CompletedAt = &t
-		}
-		if deletedAt.Valid {
-			t := deletedAt.Time.Local()
-			task.DeletedAt = &t
-		}
+// Purge permanently removes a soft-deleted task; its history is kept
+func (s *PgTaskStore) Purge(id string, userID string) error {
+	query := `DELETE FROM tasks WHERE ` + taskIDMatch + ` AND user_id = $2 AND deleted_at IS NOT NULL`
+
+	tag, err := s.db.Exec(context.Background(), query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTaskNotFound
+	}
 
-		tasks = append(tasks, &task)
+	return nil
+}
+
+// PurgeDeleted permanently removes all tasks deleted before the cutoff and returns how many were
+// removed; their history is kept
+func (s *PgTaskStore) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	query := `DELETE FROM tasks WHERE deleted_at < $1`
+
+	tag, err := s.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
 	}
 
-	if err = rows.Err(); err != nil {
+	return int(tag.RowsAffected()), nil
+}
+
+// History returns the events recorded for a user's task, oldest first
+func (s *PgTaskStore) History(ctx context.Context, taskID string, userID string) ([]*TaskEvent, error) {
+	query := `SELECT ` + taskEventColumns + `
+		FROM task_events
+		WHERE task_id IN ($1, ` + legacyTaskID + `) AND user_id = $2
+		ORDER BY created_at, id
+	`
+
+	rows, err := s.db.Query(ctx, query, taskID, userID)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return tasks, nil
+	events := []*TaskEvent{}
+	for rows.Next() {
+		event, err := scanTaskEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
 }
 
-// SearchByUserID finds tasks for a specific user that match the query in title, description, contexts, or tags
-func (s *PgTaskStore) SearchByUserID(query string, userID string) ([]*Task, error) {
+// Search finds tasks that match the query in title, description, contexts, or tags
+func (s *PgTaskStore) Search(query string) ([]*Task, error) {
 	// Build a query that searches in multiple columns with case-insensitive matching
-	sqlQuery := `
-		SELECT 
-			id, title, description, status, user_id, project_id, parent_id, 
-			contexts, tags, due_date, scheduled_date, time_estimate, 
-			energy_required, priority, timeframe, is_recurring, 
-			recurring_rule, created_at, updated_at, completed_at, deleted_at
+	sqlQuery := `SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE deleted_at IS NULL AND user_id = $2 AND (
+		WHERE deleted_at IS NULL AND (
 			LOWER(title) LIKE LOWER($1) OR 
 			LOWER(description) LIKE LOWER($1) OR
 			contexts::text ILIKE $1 OR
@@ -841,98 +848,133 @@ func (s *PgTaskStore) SearchByUserID(query string, userID string) ([]*Task, erro
 	// Add wildcards for partial matching
 	searchPattern := "%" + query + "%"
 
-	rows, err := s.db.Query(context.Background(), sqlQuery, searchPattern, userID)
+	rows, err := s.db.Query(context.Background(), sqlQuery, searchPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectTasks(rows)
+}
+
+// SearchByUserID finds a user's tasks matching a search in the syntax of ParseSearchQuery, best matches first
+func (s *PgTaskStore) SearchByUserID(query string, userID string) ([]*Task, error) {
+	return searchTasks(s, query, userID)
+}
+
+// rankedRow scans a task row selected with taskColumns followed by its search rank
+type rankedRow struct {
+	pgx.Row
+	rank *float64
+}
+
+func (r rankedRow) Scan(dest ...interface{}) error {
+	return r.Row.Scan(append(dest, r.rank)...)
+}
+
+// SearchTasks runs a parsed search against the search_vector index, ordered by ts_rank
+func (s *PgTaskStore) SearchTasks(ctx context.Context, userID string, q SearchQuery) ([]*SearchResult, error) {
+	conditions, matches, args := searchConditions(userID, q)
+
+	rank := "0"
+	if len(matches) > 0 {
+		rank = "ts_rank(search_vector, " + strings.Join(matches, " && ") + ")"
+	}
+
+	query := fmt.Sprintf(`SELECT %s, %s::float8 AS search_rank
+		FROM tasks
+		WHERE %s
+		ORDER BY search_rank DESC, updated_at DESC, id
+		LIMIT %d
+	`, taskColumns, rank, strings.Join(conditions, " AND "), q.limit())
+
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tasks []*Task
+	results := []*SearchResult{}
 	for rows.Next() {
-		var task Task
-		var contextsJSON, tagsJSON []byte
-		var projectID, parentID, energyRequired, timeframe sql.NullString
-		var dueDate, scheduledDate, completedAt, deletedAt pgtype.Timestamptz
-		var timeEstimate, priority sql.NullInt32
-		var isRecurring sql.NullBool
-		var recurringRule sql.NullString
-
-		err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.Status, &task.UserID, &projectID, &parentID,
-			&contextsJSON, &tagsJSON, &dueDate, &scheduledDate, &timeEstimate,
-			&energyRequired, &priority, &timeframe, &isRecurring,
-			&recurringRule, &task.CreatedAt, &task.UpdatedAt, &completedAt, &deletedAt,
-		)
+		var rank float64
+		task, err := scanTask(rankedRow{Row: rows, rank: &rank})
 		if err != nil {
 			return nil, err
 		}
-		
-		// Handle nullable string fields
-		if projectID.Valid {
-			task.ProjectID = projectID.String
-		}
-		if parentID.Valid {
-			task.ParentID = parentID.String
-		}
-		if energyRequired.Valid {
-			task.EnergyRequired = energyRequired.String
-		}
-		if timeframe.Valid {
-			task.Timeframe = Timeframe(timeframe.String)
-		}
-		if timeEstimate.Valid {
-			task.TimeEstimate = int(timeEstimate.Int32)
-		}
-		if priority.Valid {
-			task.Priority = int(priority.Int32)
-		}
-		if isRecurring.Valid {
-			task.IsRecurring = isRecurring.Bool
-		}
-		if recurringRule.Valid {
-			task.RecurringRule = recurringRule.String
-		}
+		results = append(results, newSearchResult(task, rank, q))
+	}
 
-		// Convert JSON fields back to Go structures
-		if contextsJSON != nil {
-			var contexts []string
-			if err := json.Unmarshal(contextsJSON, &contexts); err == nil {
-				for _, c := range contexts {
-					task.Contexts = append(task.Contexts, Context(c))
-				}
-			}
-		}
+	return results, rows.Err()
+}
 
-		if tagsJSON != nil {
-			if err := json.Unmarshal(tagsJSON, &task.Tags); err != nil {
-				fmt.Printf("Error unmarshaling tags: %v\n", err)
-			}
-		}
+// CountSearchTasks counts the tasks a parsed search matches without loading them
+func (s *PgTaskStore) CountSearchTasks(ctx context.Context, userID string, q SearchQuery) (int, error) {
+	conditions, _, args := searchConditions(userID, q)
 
-		// Handle nullable time.Time fields
-		if dueDate.Valid {
-			t := dueDate.Time.Local()
-			task.DueDate = &t
-		}
-		if scheduledDate.Valid {
-			t := scheduledDate.Time.Local()
-			task.ScheduledDate = &t
-		}
-		if completedAt.Valid {
-			t := completedAt.Time.Local()
-			task.CompletedAt = &t
-		}
-		if deletedAt.Valid {
-			t := deletedAt.Time.Local()
-			task.DeletedAt = &t
-		}
+	var count int
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM tasks WHERE "+strings.Join(conditions, " AND "), args...).Scan(&count)
+	return count, err
+}
 
-		tasks = append(tasks, &task)
+// searchConditions translates a parsed search into SQL conditions on a user's tasks and their
+// arguments. The tsqueries the task must match are also returned, as they rank the results.
+func searchConditions(userID string, q SearchQuery) ([]string, []string, []interface{}) {
+	conditions := []string{"deleted_at IS NULL", "user_id = $1"}
+	args := []interface{}{userID}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+	// Every term and phrase must match; together they rank the results
+	var matches []string
+	if len(q.Terms) > 0 {
+		// Terms only hold letters and digits, so they are safe in tsquery syntax
+		prefixes := make([]string, len(q.Terms))
+		for i, term := range q.Terms {
+			prefixes[i] = term + ":*"
+		}
+		matches = append(matches, "to_tsquery('simple', "+param(strings.Join(prefixes, " & "))+")")
+	}
+	for _, phrase := range q.Phrases {
+		matches = append(matches, "phraseto_tsquery('simple', "+param(phrase)+")")
+	}
+	for _, match := range matches {
+		conditions = append(conditions, "search_vector @@ "+match)
+	}
+	for _, excluded := range q.Excluded {
+		conditions = append(conditions, "NOT (search_vector @@ phraseto_tsquery('simple', "+param(excluded)+"))")
 	}
 
-	return tasks, nil
+	if len(q.Statuses) > 0 {
+		statuses := make([]string, len(q.Statuses))
+		for i, status := range q.Statuses {
+			statuses[i] = string(status)
+		}
+		conditions = append(conditions, "status = ANY("+param(statuses)+")")
+	}
+	for _, context := range q.Contexts {
+		contextJSON, _ := json.Marshal([]string{string(context)})
+		conditions = append(conditions, "contexts @> "+param(string(contextJSON))+"::jsonb")
+	}
+	for _, tag := range q.Tags {
+		tagJSON, _ := json.Marshal([]string{tag})
+		conditions = append(conditions, "tags @> "+param(string(tagJSON))+"::jsonb")
+	}
+	if q.DueAfter != nil {
+		conditions = append(conditions, "due_date >= "+param(*q.DueAfter))
+	}
+	if q.DueBefore != nil {
+		conditions = append(conditions, "due_date < "+param(*q.DueBefore))
+	}
+	if q.Energy != "" {
+		conditions = append(conditions, "LOWER(energy_required) = LOWER("+param(q.Energy)+")")
+	}
+	if q.MaxTime != 0 {
+		conditions = append(conditions, "time_estimate > 0 AND time_estimate <= "+param(q.MaxTime))
+	}
+	if q.Priority != 0 {
+		conditions = append(conditions, "priority = "+param(q.Priority))
+	}
+
+	return conditions, matches, args
 }
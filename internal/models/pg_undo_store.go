@@ -0,0 +1,133 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PgUndoStore implements UndoStore interface with PostgreSQL storage, so actions can still be
+// undone after a restart
+type PgUndoStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPgUndoStore creates an undo store on an existing connection pool
+func NewPgUndoStore(db *pgxpool.Pool) *PgUndoStore {
+	return &PgUndoStore{
+		db: db,
+	}
+}
+
+// undoActionColumns lists the undo action columns in the order expected by scanUndoAction
+const undoActionColumns = `token, user_id, label, task_ids, created_ids, event_ids, created_at, expires_at`
+
+// scanUndoAction reads a single undo action row selected with undoActionColumns
+func scanUndoAction(row pgx.Row) (*UndoAction, error) {
+	var action UndoAction
+	var taskIDs, createdIDs, eventIDs []byte
+	err := row.Scan(
+		&action.Token, &action.UserID, &action.Label,
+		&taskIDs, &createdIDs, &eventIDs, &action.CreatedAt, &action.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(taskIDs, &action.TaskIDs); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(createdIDs, &action.Created); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(eventIDs, &action.Events); err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+// Push saves an action and drops the user's expired actions and those beyond the newest depth
+func (s *PgUndoStore) Push(action *UndoAction, depth int) error {
+	taskIDs, err := json.Marshal(append([]string{}, action.TaskIDs...))
+	if err != nil {
+		return err
+	}
+	createdIDs, err := json.Marshal(append([]string{}, action.Created...))
+	if err != nil {
+		return err
+	}
+	eventIDs, err := json.Marshal(append([]string{}, action.Events...))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	return pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO undo_actions (`+undoActionColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (token) DO NOTHING
+		`, action.Token, action.UserID, action.Label, taskIDs, createdIDs, eventIDs, action.CreatedAt, action.ExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			DELETE FROM undo_actions
+			WHERE user_id = $1 AND (expires_at <= $2 OR token NOT IN (
+				SELECT token FROM undo_actions
+				WHERE user_id = $1
+				ORDER BY created_at DESC, token DESC
+				LIMIT $3
+			))
+		`, action.UserID, action.CreatedAt, depth)
+		return err
+	})
+}
+
+// List returns the user's actions that haven't expired by now, newest first
+func (s *PgUndoStore) List(userID string, now time.Time) ([]*UndoAction, error) {
+	query := `SELECT ` + undoActionColumns + `
+		FROM undo_actions
+		WHERE user_id = $1 AND expires_at > $2
+		ORDER BY created_at DESC, token DESC
+	`
+
+	rows, err := s.db.Query(context.Background(), query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := []*UndoAction{}
+	for rows.Next() {
+		action, err := scanUndoAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, rows.Err()
+}
+
+// Take removes an action that hasn't expired by now and returns it. Deleting the row claims it,
+// so concurrent requests can't both undo the same action.
+func (s *PgUndoStore) Take(token string, userID string, now time.Time) (*UndoAction, error) {
+	query := `DELETE FROM undo_actions
+		WHERE token = $1 AND user_id = $2 AND expires_at > $3
+		RETURNING ` + undoActionColumns
+
+	action, err := scanUndoAction(s.db.QueryRow(context.Background(), query, token, userID, now))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrUndoNotFound
+		}
+		return nil, err
+	}
+
+	return action, nil
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ProjectState defines the lifecycle states of a project
+type ProjectState string
+
+const (
+	ProjectActive    ProjectState = "active"    // Being worked on
+	ProjectOnHold    ProjectState = "on_hold"   // Paused, still reviewed
+	ProjectCompleted ProjectState = "completed" // Desired outcome reached
+	ProjectArchived  ProjectState = "archived"  // Kept for reference, hidden from reviews
+	ProjectDropped   ProjectState = "dropped"   // Abandoned without completion
+)
+
+// ProjectStates lists every project state in lifecycle order
+var ProjectStates = []ProjectState{
+	ProjectActive,
+	ProjectOnHold,
+	ProjectCompleted,
+	ProjectArchived,
+	ProjectDropped,
+}
+
+// ErrProjectNotFound is returned when a project doesn't exist or isn't visible to the caller
+var ErrProjectNotFound = errors.New("project not found")
+
+// Project represents a GTD project: a desired outcome that takes more than one action
+type Project struct {
+	ID          string       `json:"id"`
+	UserID      string       `json:"userId,omitempty"` // User who owns this project
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Outcome     string       `json:"outcome,omitempty"` // What "done" looks like
+	Notes       string       `json:"notes,omitempty"`   // Free-form supporting material
+	State       ProjectState `json:"state"`
+	Contexts    []Context    `json:"contexts,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	DueDate     *time.Time   `json:"dueDate,omitempty"`
+	ReviewDate  *time.Time   `json:"reviewDate,omitempty"` // When the project should next be reviewed
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+	CompletedAt *time.Time   `json:"completedAt,omitempty"`
+	DeletedAt   *time.Time   `json:"deletedAt,omitempty"` // Soft delete support
+}
+
+// NewProject creates a new active project
+func NewProject(title, description string, userID string) *Project {
+	now := time.Now()
+	return &Project{
+		ID:          GenerateID(),
+		UserID:      userID,
+		Title:       title,
+		Description: description,
+		State:       ProjectActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// ProjectFromTask converts a legacy project-status task into a project, keeping its ID
+// so tasks that reference it through ProjectID stay attached
+func ProjectFromTask(task *Task) *Project {
+	project := &Project{
+		ID:          task.ID,
+		UserID:      task.UserID,
+		Title:       task.Title,
+		Description: task.Description,
+		State:       ProjectActive,
+		Contexts:    append([]Context(nil), task.Contexts...),
+		Tags:        append([]string(nil), task.Tags...),
+		DueDate:     task.DueDate,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   time.Now(),
+	}
+	if task.Status == StatusDone {
+		project.State = ProjectCompleted
+		project.CompletedAt = task.CompletedAt
+	}
+	return project
+}
+
+// ParseProjectState converts a string into a ProjectState, accepting "on-hold" style spellings
+func ParseProjectState(s string) (ProjectState, error) {
+	state := ProjectState(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", "_"))
+	if !state.Valid() {
+		return "", errors.New("invalid project state")
+	}
+	return state, nil
+}
+
+// Valid reports whether the state is one of the defined constants
+func (s ProjectState) Valid() bool {
+	for _, state := range ProjectStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen reports whether projects in this state still need attention
+func (s ProjectState) IsOpen() bool {
+	return s == ProjectActive || s == ProjectOnHold
+}
+
+// Validate checks if the project data is valid
+func (p *Project) Validate() error {
+	if p.Title == "" {
+		return errors.New("project title cannot be empty")
+	}
+
+	if !p.State.Valid() {
+		return errors.New("invalid project state")
+	}
+
+	return nil
+}
+
+// SetState moves the project to a new lifecycle state
+func (p *Project) SetState(state ProjectState) error {
+	if !state.Valid() {
+		return errors.New("invalid project state")
+	}
+
+	now := time.Now()
+	if state == ProjectCompleted {
+		if p.State != ProjectCompleted {
+			p.CompletedAt = &now
+		}
+	} else if state != ProjectArchived {
+		// Archived projects keep their completion date; reopening clears it
+		p.CompletedAt = nil
+	}
+
+	p.State = state
+	p.UpdatedAt = now
+	return nil
+}
+
+// Delete soft-deletes a project
+func (p *Project) Delete() {
+	now := time.Now()
+	p.DeletedAt = &now
+	p.UpdatedAt = now
+}
+
+// IsDeleted checks if a project has been soft-deleted
+func (p *Project) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+// clone returns a deep copy of the project, so later changes to it don't alter the copy
+func (p *Project) clone() *Project {
+	c := *p
+	c.Contexts = append([]Context(nil), p.Contexts...)
+	c.Tags = append([]string(nil), p.Tags...)
+	for _, field := range []**time.Time{&c.DueDate, &c.ReviewDate, &c.CompletedAt, &c.DeletedAt} {
+		if *field != nil {
+			value := **field
+			*field = &value
+		}
+	}
+	return &c
+}
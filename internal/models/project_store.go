@@ -0,0 +1,299 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProjectSort defines the order in which projects are listed
+type ProjectSort string
+
+const (
+	ProjectSortCreatedDesc ProjectSort = "created_desc" // Newest first (default)
+	ProjectSortCreatedAsc  ProjectSort = "created_asc"  // Oldest first
+	ProjectSortUpdatedDesc ProjectSort = "updated_desc" // Most recently updated first
+	ProjectSortTitleAsc    ProjectSort = "title_asc"    // Alphabetical by title
+	ProjectSortDueAsc      ProjectSort = "due_asc"      // Earliest due date first, undated last
+	ProjectSortReviewAsc   ProjectSort = "review_asc"   // Earliest review date first, unscheduled last
+)
+
+// ParseProjectSort converts a string such as "created-asc" into a ProjectSort.
+// The second result is false for unknown orders.
+func ParseProjectSort(s string) (ProjectSort, bool) {
+	order := ProjectSort(strings.ReplaceAll(strings.ToLower(s), "-", "_"))
+	switch order {
+	case ProjectSortCreatedDesc, ProjectSortCreatedAsc, ProjectSortUpdatedDesc,
+		ProjectSortTitleAsc, ProjectSortDueAsc, ProjectSortReviewAsc:
+		return order, true
+	default:
+		return ProjectSortCreatedDesc, false
+	}
+}
+
+// ProjectFilter narrows down the projects returned by List.
+// Zero values mean "don't filter on this field".
+type ProjectFilter struct {
+	UserID       string         // Owner of the projects
+	States       []ProjectState // Match any of these states
+	ReviewBefore *time.Time     // Review date on or before this time
+
+	IncludeDeleted bool // Also match soft-deleted projects
+}
+
+// Matches reports whether a project satisfies the filter
+func (f ProjectFilter) Matches(project *Project) bool {
+	if project.IsDeleted() && !f.IncludeDeleted {
+		return false
+	}
+	if f.UserID != "" && project.UserID != f.UserID {
+		return false
+	}
+	if len(f.States) > 0 {
+		found := false
+		for _, state := range f.States {
+			if project.State == state {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.ReviewBefore != nil && (project.ReviewDate == nil || project.ReviewDate.After(*f.ReviewBefore)) {
+		return false
+	}
+	return true
+}
+
+// ProjectStore defines the interface for project storage
+type ProjectStore interface {
+	Get(id string) (*Project, error)
+	GetForUser(id string, userID string) (*Project, error)
+	List(ctx context.Context, filter ProjectFilter, order ProjectSort) ([]*Project, error)
+	Save(project *Project) error
+	SaveForUser(project *Project, userID string) error
+	Delete(id string) error
+
+	// Trash: soft-deleted projects can be listed, restored or removed for good
+	ListDeleted(ctx context.Context, userID string) ([]*Project, error) // Most recently deleted first
+	Restore(id string, userID string) error
+	Purge(id string, userID string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error) // Every user's projects deleted before the cutoff
+}
+
+// MemoryProjectStore implements ProjectStore interface with in-memory storage
+type MemoryProjectStore struct {
+	projects map[string]*Project
+	mutex    sync.RWMutex
+}
+
+// NewMemoryProjectStore creates a new in-memory project store
+func NewMemoryProjectStore() *MemoryProjectStore {
+	return &MemoryProjectStore{
+		projects: make(map[string]*Project),
+	}
+}
+
+// Get retrieves a project by ID
+func (s *MemoryProjectStore) Get(id string) (*Project, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	project, ok := s.projects[id]
+	if !ok || project.IsDeleted() {
+		return nil, ErrProjectNotFound
+	}
+
+	return project.clone(), nil
+}
+
+// GetForUser retrieves a project by ID, hiding projects owned by other users
+func (s *MemoryProjectStore) GetForUser(id string, userID string) (*Project, error) {
+	project, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.UserID != userID {
+		return nil, ErrProjectNotFound
+	}
+
+	return project, nil
+}
+
+// List returns the projects matching the filter in the requested order
+func (s *MemoryProjectStore) List(ctx context.Context, filter ProjectFilter, order ProjectSort) ([]*Project, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	projects := []*Project{}
+	for _, project := range s.projects {
+		if filter.Matches(project) {
+			projects = append(projects, project.clone())
+		}
+	}
+
+	sortProjects(projects, order)
+	return projects, nil
+}
+
+// Save creates or updates a project
+func (s *MemoryProjectStore) Save(project *Project) error {
+	if err := project.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.projects[project.ID] = project.clone()
+	return nil
+}
+
+// SaveForUser creates or updates a project on behalf of a user.
+// It refuses to write projects owned by someone else or to hand a project over to another user.
+func (s *MemoryProjectStore) SaveForUser(project *Project, userID string) error {
+	if project.UserID != userID {
+		return ErrProjectNotFound
+	}
+
+	if err := project.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if existing, ok := s.projects[project.ID]; ok && existing.UserID != userID {
+		return ErrProjectNotFound
+	}
+
+	s.projects[project.ID] = project.clone()
+	return nil
+}
+
+// Delete soft-deletes a project
+func (s *MemoryProjectStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok {
+		return ErrProjectNotFound
+	}
+
+	project.Delete()
+	return nil
+}
+
+// ListDeleted returns a user's soft-deleted projects, most recently deleted first
+func (s *MemoryProjectStore) ListDeleted(ctx context.Context, userID string) ([]*Project, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	projects := []*Project{}
+	for _, project := range s.projects {
+		if project.IsDeleted() && project.UserID == userID {
+			projects = append(projects, project.clone())
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		if !projects[i].DeletedAt.Equal(*projects[j].DeletedAt) {
+			return projects[i].DeletedAt.After(*projects[j].DeletedAt)
+		}
+		return projects[i].ID < projects[j].ID
+	})
+
+	return projects, nil
+}
+
+// Restore brings back a soft-deleted project
+func (s *MemoryProjectStore) Restore(id string, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok || !project.IsDeleted() || project.UserID != userID {
+		return ErrProjectNotFound
+	}
+
+	project.DeletedAt = nil
+	project.UpdatedAt = time.Now()
+	return nil
+}
+
+// Purge permanently removes a soft-deleted project
+func (s *MemoryProjectStore) Purge(id string, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok || !project.IsDeleted() || project.UserID != userID {
+		return ErrProjectNotFound
+	}
+
+	delete(s.projects, id)
+	return nil
+}
+
+// PurgeDeleted permanently removes all projects deleted before the cutoff and returns how many were removed
+func (s *MemoryProjectStore) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	purged := 0
+	for id, project := range s.projects {
+		if project.IsDeleted() && project.DeletedAt.Before(before) {
+			delete(s.projects, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// sortProjects orders projects in place; ties are broken by ID
+func sortProjects(projects []*Project, order ProjectSort) {
+	sort.SliceStable(projects, func(i, j int) bool {
+		a, b := projects[i], projects[j]
+		switch order {
+		case ProjectSortCreatedAsc:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		case ProjectSortUpdatedDesc:
+			if !a.UpdatedAt.Equal(b.UpdatedAt) {
+				return a.UpdatedAt.After(b.UpdatedAt)
+			}
+		case ProjectSortTitleAsc:
+			at, bt := strings.ToLower(a.Title), strings.ToLower(b.Title)
+			if at != bt {
+				return at < bt
+			}
+		case ProjectSortDueAsc:
+			if (a.DueDate == nil) != (b.DueDate == nil) {
+				return a.DueDate != nil
+			}
+			if a.DueDate != nil && !a.DueDate.Equal(*b.DueDate) {
+				return a.DueDate.Before(*b.DueDate)
+			}
+		case ProjectSortReviewAsc:
+			if (a.ReviewDate == nil) != (b.ReviewDate == nil) {
+				return a.ReviewDate != nil
+			}
+			if a.ReviewDate != nil && !a.ReviewDate.Equal(*b.ReviewDate) {
+				return a.ReviewDate.Before(*b.ReviewDate)
+			}
+		default:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+		}
+		return a.ID < b.ID
+	})
+}
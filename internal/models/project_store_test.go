@@ -0,0 +1,56 @@
+package models
+
+import "testing"
+
+func TestMemoryProjectStoreKeepsItsOwnCopies(t *testing.T) {
+	store := NewMemoryProjectStore()
+	project := NewProject("Plan the trip", "", "alice")
+	project.Tags = []string{"travel"}
+	if err := store.SaveForUser(project, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing the saved value or a loaded copy must not reach the store until it is saved
+	project.Title = "Changed after saving"
+	loaded, err := store.GetForUser(project.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.Title = ""
+	loaded.Tags[0] = "changed"
+	if err := store.SaveForUser(loaded, "alice"); err == nil {
+		t.Fatal("saving a project without a title succeeded")
+	}
+
+	stored, err := store.GetForUser(project.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Title != "Plan the trip" || stored.Tags[0] != "travel" {
+		t.Errorf("stored project = %q %v, want %q [travel]", stored.Title, stored.Tags, "Plan the trip")
+	}
+}
+
+func TestMemoryReviewStoreKeepsItsOwnCopies(t *testing.T) {
+	store := NewMemoryReviewStore()
+	session := NewReviewSession("alice", ReviewSnapshot{})
+	if err := store.Save(session); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.GetForUser(session.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.UpdateStep("collect", true, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := store.GetForUser(session.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Steps[0].Completed {
+		t.Error("an unsaved step change reached the store")
+	}
+}
@@ -0,0 +1,501 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecurrenceFrequency is the base period of a recurrence rule (RFC 5545 FREQ)
+type RecurrenceFrequency string
+
+const (
+	FrequencyDaily   RecurrenceFrequency = "DAILY"
+	FrequencyWeekly  RecurrenceFrequency = "WEEKLY"
+	FrequencyMonthly RecurrenceFrequency = "MONTHLY"
+	FrequencyYearly  RecurrenceFrequency = "YEARLY"
+)
+
+// RecurrenceAnchor decides which date the next occurrence of a recurring task is computed from
+type RecurrenceAnchor string
+
+const (
+	AnchorDueDate    RecurrenceAnchor = "due"        // Keep a fixed schedule based on the previous due/scheduled date
+	AnchorCompletion RecurrenceAnchor = "completion" // Count from the moment the previous occurrence was completed
+)
+
+// maxRecurrenceSteps bounds the search for the next matching date so bad rules can't loop forever
+const maxRecurrenceSteps = 1000
+
+// RecurrenceRule is a parsed subset of an RFC 5545 RRULE
+type RecurrenceRule struct {
+	Freq       RecurrenceFrequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Count      int        // Remaining occurrences including the current one (0 = unlimited)
+	Until      *time.Time // No occurrences after this time
+}
+
+var rruleDays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+	"sunday":    time.Sunday,
+	"mon":       time.Monday,
+	"tue":       time.Tuesday,
+	"wed":       time.Wednesday,
+	"thu":       time.Thursday,
+	"thur":      time.Thursday,
+	"fri":       time.Friday,
+	"sat":       time.Saturday,
+	"sun":       time.Sunday,
+}
+
+var (
+	everyNPattern   = regexp.MustCompile(`^every (\d+) (day|week|month|year)s?(?: on (.+))?$`)
+	periodPattern   = regexp.MustCompile(`^(daily|weekly|biweekly|monthly|yearly|annually|every day|every week|every other week|every month|every year)(?: on (.+))?$`)
+	everyDayPattern = regexp.MustCompile(`^every (.+)$`)
+	monthDayPattern = regexp.MustCompile(`^(?:the )?(?:day )?(\d{1,2})(?:st|nd|rd|th)?$`)
+)
+
+// ParseRecurrenceRule parses an RRULE ("FREQ=WEEKLY;BYDAY=MO", optionally prefixed with "RRULE:")
+// or one of the supported shorthands such as "daily", "weekdays", "weekly on Monday",
+// "every 2 weeks on tue and thu" or "monthly on the 15th"
+func ParseRecurrenceRule(s string) (*RecurrenceRule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("recurrence rule cannot be empty")
+	}
+
+	upper := strings.ToUpper(s)
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"))
+	}
+
+	return parseRecurrenceShorthand(strings.Join(strings.Fields(strings.ToLower(s)), " "))
+}
+
+// parseRRule parses the property list of an RFC 5545 RRULE
+func parseRRule(s string) (*RecurrenceRule, error) {
+	rule := &RecurrenceRule{Interval: 1}
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+
+		switch key {
+		case "FREQ":
+			switch RecurrenceFrequency(value) {
+			case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+				rule.Freq = RecurrenceFrequency(value)
+			default:
+				return nil, fmt.Errorf("unsupported recurrence frequency %q", value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid recurrence interval %q", value)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid recurrence count %q", value)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseRRuleTime(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := rruleDays[day]
+				if !ok {
+					return nil, fmt.Errorf("unsupported recurrence day %q", day)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, err := strconv.Atoi(day)
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, fmt.Errorf("invalid recurrence month day %q", day)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, monthDay)
+			}
+		case "WKST":
+			// Weeks always start on Monday
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("recurrence rule must include FREQ")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("recurrence rule cannot have both COUNT and UNTIL")
+	}
+	if rule.Freq == FrequencyWeekly && len(rule.ByMonthDay) > 0 {
+		// RFC 5545 doesn't allow BYMONTHDAY in weekly rules
+		return nil, errors.New("BYMONTHDAY is not supported for weekly rules")
+	}
+
+	rule.normalize()
+	return rule, nil
+}
+
+// parseRRuleTime parses an RRULE UNTIL value (date or UTC date-time)
+func parseRRuleTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid recurrence UNTIL %q", value)
+}
+
+// parseRecurrenceShorthand parses the human-friendly forms of a recurrence rule
+func parseRecurrenceShorthand(s string) (*RecurrenceRule, error) {
+	rule := &RecurrenceRule{Interval: 1}
+	var on string
+
+	switch {
+	case s == "weekdays" || s == "every weekday":
+		rule.Freq = FrequencyWeekly
+		rule.ByDay = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		return rule, nil
+
+	case s == "weekends" || s == "every weekend":
+		rule.Freq = FrequencyWeekly
+		rule.ByDay = []time.Weekday{time.Saturday, time.Sunday}
+		rule.normalize()
+		return rule, nil
+
+	case everyNPattern.MatchString(s):
+		m := everyNPattern.FindStringSubmatch(s)
+		interval, _ := strconv.Atoi(m[1])
+		if interval < 1 {
+			return nil, fmt.Errorf("invalid recurrence interval in %q", s)
+		}
+		rule.Interval = interval
+		rule.Freq = map[string]RecurrenceFrequency{
+			"day":   FrequencyDaily,
+			"week":  FrequencyWeekly,
+			"month": FrequencyMonthly,
+			"year":  FrequencyYearly,
+		}[m[2]]
+		on = m[3]
+
+	case periodPattern.MatchString(s):
+		m := periodPattern.FindStringSubmatch(s)
+		switch m[1] {
+		case "daily", "every day":
+			rule.Freq = FrequencyDaily
+		case "weekly", "every week":
+			rule.Freq = FrequencyWeekly
+		case "biweekly", "every other week":
+			rule.Freq = FrequencyWeekly
+			rule.Interval = 2
+		case "monthly", "every month":
+			rule.Freq = FrequencyMonthly
+		case "yearly", "annually", "every year":
+			rule.Freq = FrequencyYearly
+		}
+		on = m[2]
+
+	case everyDayPattern.MatchString(s):
+		// "every monday", "every tue and fri"
+		days, err := parseWeekdayList(everyDayPattern.FindStringSubmatch(s)[1])
+		if err != nil {
+			return nil, fmt.Errorf("unrecognized recurrence rule %q", s)
+		}
+		rule.Freq = FrequencyWeekly
+		rule.ByDay = days
+		rule.normalize()
+		return rule, nil
+
+	default:
+		return nil, fmt.Errorf("unrecognized recurrence rule %q", s)
+	}
+
+	if on != "" {
+		switch rule.Freq {
+		case FrequencyWeekly:
+			days, err := parseWeekdayList(on)
+			if err != nil {
+				return nil, err
+			}
+			rule.ByDay = days
+		case FrequencyMonthly:
+			m := monthDayPattern.FindStringSubmatch(on)
+			if m == nil {
+				return nil, fmt.Errorf("unrecognized day of month %q", on)
+			}
+			day, _ := strconv.Atoi(m[1])
+			if day < 1 || day > 31 {
+				return nil, fmt.Errorf("invalid day of month %q", on)
+			}
+			rule.ByMonthDay = []int{day}
+		default:
+			return nil, fmt.Errorf("%q is not supported for %s rules", "on "+on, strings.ToLower(string(rule.Freq)))
+		}
+	}
+
+	rule.normalize()
+	return rule, nil
+}
+
+// parseWeekdayList parses lists like "monday", "mon, wed" or "tuesday and thursday"
+func parseWeekdayList(s string) ([]time.Weekday, error) {
+	s = strings.ReplaceAll(s, " and ", ",")
+	var days []time.Weekday
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSuffix(strings.TrimSpace(name), "s")
+		if name == "" {
+			continue
+		}
+		day, ok := weekdayNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unrecognized weekday %q", name)
+		}
+		days = append(days, day)
+	}
+	if len(days) == 0 {
+		return nil, errors.New("no weekdays given")
+	}
+	return days, nil
+}
+
+// normalize sorts and de-duplicates the rule's day lists
+func (r *RecurrenceRule) normalize() {
+	seenDays := make(map[time.Weekday]bool)
+	days := r.ByDay[:0]
+	for _, day := range r.ByDay {
+		if !seenDays[day] {
+			seenDays[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return mondayIndex(days[i]) < mondayIndex(days[j]) })
+	r.ByDay = days
+
+	sort.Ints(r.ByMonthDay)
+}
+
+// String returns the rule in canonical RRULE syntax
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = strings.ToUpper(day.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence strictly after the given time, keeping its time of day.
+// It returns false when the rule has no further occurrences.
+func (r *RecurrenceRule) Next(after time.Time) (time.Time, bool) {
+	var next time.Time
+
+	switch r.Freq {
+	case FrequencyDaily:
+		next = after.AddDate(0, 0, r.interval())
+		for i := 0; !r.matchesDay(next); i++ {
+			if i >= maxRecurrenceSteps {
+				return time.Time{}, false
+			}
+			next = next.AddDate(0, 0, r.interval())
+		}
+
+	case FrequencyWeekly:
+		if len(r.ByDay) == 0 {
+			next = after.AddDate(0, 0, 7*r.interval())
+			break
+		}
+		// Look for a later matching day in the current week first
+		weekStart := after.AddDate(0, 0, -mondayIndex(after.Weekday()))
+		found := false
+		for _, day := range r.ByDay {
+			if mondayIndex(day) > mondayIndex(after.Weekday()) {
+				next = weekStart.AddDate(0, 0, mondayIndex(day))
+				found = true
+				break
+			}
+		}
+		if !found {
+			next = weekStart.AddDate(0, 0, 7*r.interval()+mondayIndex(r.ByDay[0]))
+		}
+
+	case FrequencyMonthly, FrequencyYearly:
+		months := r.interval()
+		if r.Freq == FrequencyYearly {
+			months *= 12
+		}
+		var ok bool
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			next, ok = addMonthsOnDay(after, months, after.Day())
+		} else {
+			next, ok = r.nextMatchingDay(after, months)
+		}
+		if !ok {
+			return time.Time{}, false
+		}
+
+	default:
+		return time.Time{}, false
+	}
+
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Occurrences returns up to n occurrences following start, honouring COUNT and UNTIL
+func (r *RecurrenceRule) Occurrences(start time.Time, n int) []time.Time {
+	if r.Count > 0 && r.Count-1 < n {
+		// The task at start is the first of Count occurrences
+		n = r.Count - 1
+	}
+
+	var occurrences []time.Time
+	current := start
+	for len(occurrences) < n {
+		next, ok := r.Next(current)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, next)
+		current = next
+	}
+	return occurrences
+}
+
+// nextMatchingDay returns the first day after t that matches BYDAY and BYMONTHDAY, looking through
+// the month (or, for yearly rules, the year) of t and then every period that many months later.
+// BYDAY picks every such weekday of the period, as RFC 5545 expands it for monthly and yearly rules.
+func (r *RecurrenceRule) nextMatchingDay(t time.Time, months int) (time.Time, bool) {
+	periodMonths, month := 1, t.Month()
+	if r.Freq == FrequencyYearly {
+		periodMonths, month = 12, time.January
+	}
+	periodStart := time.Date(t.Year(), month, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+	for i := 0; i < maxRecurrenceSteps; i++ {
+		start := periodStart.AddDate(0, i*months, 0)
+		end := start.AddDate(0, periodMonths, 0)
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			if day.After(t) && r.matchesDay(day) {
+				return day, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// matchesDay reports whether a date is one of the rule's BYDAY weekdays and BYMONTHDAY days.
+// Negative month days count from the end of the month; an empty list matches every day.
+func (r *RecurrenceRule) matchesDay(t time.Time) bool {
+	if len(r.ByDay) > 0 && !r.hasDay(t.Weekday()) {
+		return false
+	}
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+
+	daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for _, day := range r.ByMonthDay {
+		if day < 0 {
+			day = daysInMonth + day + 1
+		}
+		if day == t.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RecurrenceRule) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+func (r *RecurrenceRule) hasDay(day time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// mondayIndex returns the position of a weekday in a Monday-based week
+func mondayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// addMonthsOnDay steps t forward by months at a time until it reaches a month that has the given
+// day. Like BYMONTHDAY, months too short for the day are skipped rather than clamped, so a series
+// on the 31st stays on the 31st instead of drifting to the 28th after February.
+func addMonthsOnDay(t time.Time, months int, day int) (time.Time, bool) {
+	firstOfMonth := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	for i := 1; i <= maxRecurrenceSteps; i++ {
+		target := firstOfMonth.AddDate(0, i*months, 0)
+		daysInMonth := time.Date(target.Year(), target.Month()+1, 0, 0, 0, 0, 0, target.Location()).Day()
+		if day <= daysInMonth {
+			return target.AddDate(0, 0, day-1), true
+		}
+	}
+	return time.Time{}, false
+}
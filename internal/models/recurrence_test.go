@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecurrenceRuleNext(t *testing.T) {
+	// Wednesday, 15 January 2025
+	start := time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 9, 30, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		rule  string
+		after time.Time
+		want  []time.Time // The next occurrences in order; none when the rule has ended
+	}{
+		{rule: "FREQ=DAILY", after: start, want: []time.Time{day(1, 16), day(1, 17)}},
+		{rule: "FREQ=DAILY;INTERVAL=3", after: start, want: []time.Time{day(1, 18), day(1, 21)}},
+		{rule: "FREQ=DAILY;BYDAY=MO,FR", after: start, want: []time.Time{day(1, 17), day(1, 20), day(1, 24)}},
+		{rule: "FREQ=DAILY;BYMONTHDAY=1,20", after: start, want: []time.Time{day(1, 20), day(2, 1), day(2, 20)}},
+		{rule: "FREQ=WEEKLY", after: start, want: []time.Time{day(1, 22), day(1, 29)}},
+		{rule: "FREQ=WEEKLY;BYDAY=MO,WE,FR", after: start, want: []time.Time{day(1, 17), day(1, 20), day(1, 22)}},
+		{rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", after: start, want: []time.Time{day(1, 28), day(2, 11)}},
+		{rule: "FREQ=MONTHLY", after: start, want: []time.Time{day(2, 15), day(3, 15)}},
+		{rule: "FREQ=MONTHLY", after: day(1, 31), want: []time.Time{day(3, 31), day(5, 31)}},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=1,20", after: start, want: []time.Time{day(1, 20), day(2, 1), day(2, 20)}},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=-1", after: start, want: []time.Time{day(1, 31), day(2, 28), day(3, 31)}},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=30", after: day(1, 30), want: []time.Time{day(3, 30), day(4, 30)}},
+		{rule: "FREQ=MONTHLY;BYDAY=MO", after: start, want: []time.Time{day(1, 20), day(1, 27), day(2, 3)}},
+		{rule: "FREQ=MONTHLY;INTERVAL=2;BYDAY=MO", after: day(1, 27), want: []time.Time{day(3, 3), day(3, 10)}},
+		{rule: "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", after: start, want: []time.Time{day(6, 13)}},
+		{rule: "FREQ=YEARLY", after: start, want: []time.Time{time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC)}},
+		{rule: "FREQ=YEARLY;BYMONTHDAY=15", after: start, want: []time.Time{day(2, 15), day(3, 15)}},
+		{rule: "FREQ=YEARLY;BYDAY=SU", after: day(12, 27), want: []time.Time{day(12, 28), time.Date(2026, 1, 4, 9, 30, 0, 0, time.UTC)}},
+		{rule: "FREQ=DAILY;UNTIL=20250116", after: start, want: []time.Time{day(1, 16)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			current := tt.after
+			for i, want := range tt.want {
+				next, ok := rule.Next(current)
+				if !ok || !next.Equal(want) {
+					t.Fatalf("occurrence %d after %s = %s (%v), want %s", i+1, current.Format(time.DateOnly), next.Format(time.DateOnly), ok, want.Format(time.DateOnly))
+				}
+				current = next
+			}
+			if rule.Until != nil {
+				if next, ok := rule.Next(current); ok {
+					t.Errorf("occurrence after UNTIL = %s, want none", next.Format(time.DateOnly))
+				}
+			}
+		})
+	}
+}
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // Canonical form; empty when the rule is rejected
+	}{
+		{input: "RRULE:FREQ=WEEKLY;BYDAY=FR,MO", want: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{input: "FREQ=MONTHLY;BYDAY=MO", want: "FREQ=MONTHLY;BYDAY=MO"},
+		{input: "FREQ=YEARLY;BYMONTHDAY=1", want: "FREQ=YEARLY;BYMONTHDAY=1"},
+		{input: "weekdays", want: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{input: "every 2 weeks on tue and thu", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH"},
+		{input: "monthly on the 15th", want: "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{input: "FREQ=WEEKLY;BYMONTHDAY=15"},
+		{input: "FREQ=MONTHLY;BYDAY=2TU"},
+		{input: "FREQ=MONTHLY;BYMONTHDAY=32"},
+		{input: "FREQ=DAILY;COUNT=2;UNTIL=20250101"},
+		{input: "FREQ=HOURLY"},
+		{input: "INTERVAL=2"},
+		{input: "yearly on monday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.input)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("got %s, want an error", rule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rule.String() != tt.want {
+				t.Errorf("rule = %s, want %s", rule, tt.want)
+			}
+		})
+	}
+}
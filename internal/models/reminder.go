@@ -0,0 +1,271 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrReminderExists is returned when creating a reminder that has already been scheduled
+var ErrReminderExists = errors.New("reminder already exists")
+
+// ErrReminderClaimed is returned when claiming a reminder that is no longer pending, because
+// another run already took it
+var ErrReminderClaimed = errors.New("reminder already claimed")
+
+// ErrNotificationNotFound is returned when a notification doesn't exist or belongs to another user
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// ReminderChannel identifies how a reminder is delivered
+type ReminderChannel string
+
+const (
+	ChannelInApp   ReminderChannel = "in_app"  // Notification inbox inside the app
+	ChannelEmail   ReminderChannel = "email"   // Email sent through the configured SMTP server
+	ChannelWebhook ReminderChannel = "webhook" // JSON POST to a user supplied URL
+)
+
+// ReminderField is the task date a reminder is relative to
+type ReminderField string
+
+const (
+	ReminderDue       ReminderField = "due"
+	ReminderScheduled ReminderField = "scheduled"
+)
+
+// ReminderStatus tracks the delivery of a reminder
+type ReminderStatus string
+
+const (
+	ReminderPending   ReminderStatus = "pending"   // Waiting to be delivered or retried
+	ReminderSending   ReminderStatus = "sending"   // Claimed by a run that is delivering it
+	ReminderSent      ReminderStatus = "sent"      // Delivered
+	ReminderFailed    ReminderStatus = "failed"    // Gave up after too many failed attempts
+	ReminderCancelled ReminderStatus = "cancelled" // Task was completed, deleted or rescheduled before delivery
+)
+
+// ReminderOffset fires a reminder a number of minutes before one of a task's dates
+type ReminderOffset struct {
+	Field         ReminderField `json:"field"`
+	MinutesBefore int           `json:"minutesBefore"`
+}
+
+// ReminderSettings holds a user's reminder preferences
+type ReminderSettings struct {
+	UserID     string            `json:"-"`
+	Offsets    []ReminderOffset  `json:"offsets"`
+	Channels   []ReminderChannel `json:"channels"`
+	Email      string            `json:"email,omitempty"`      // Address for the email channel
+	WebhookURL string            `json:"webhookUrl,omitempty"` // Endpoint for the webhook channel
+}
+
+// DefaultReminderSettings returns the settings used until a user sets their own:
+// an in-app reminder an hour before a task is due and when it is scheduled
+func DefaultReminderSettings(userID string) ReminderSettings {
+	return ReminderSettings{
+		UserID: userID,
+		Offsets: []ReminderOffset{
+			{Field: ReminderDue, MinutesBefore: 60},
+			{Field: ReminderScheduled, MinutesBefore: 0},
+		},
+		Channels: []ReminderChannel{ChannelInApp},
+	}
+}
+
+// Validate checks the offsets and channels and that each channel has a destination
+func (s ReminderSettings) Validate() error {
+	for _, offset := range s.Offsets {
+		if offset.Field != ReminderDue && offset.Field != ReminderScheduled {
+			return fmt.Errorf("invalid reminder field: %s", offset.Field)
+		}
+		if offset.MinutesBefore < 0 {
+			return errors.New("reminder offsets cannot be negative")
+		}
+	}
+
+	for _, channel := range s.Channels {
+		switch channel {
+		case ChannelInApp:
+		case ChannelEmail:
+			if !strings.Contains(s.Email, "@") {
+				return errors.New("the email channel requires an email address")
+			}
+		case ChannelWebhook:
+			u, err := url.Parse(s.WebhookURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return errors.New("the webhook channel requires an http or https URL")
+			}
+			if isInternalHost(u.Hostname()) {
+				return errors.New("the webhook URL must point to a public host")
+			}
+		default:
+			return fmt.Errorf("invalid reminder channel: %s", channel)
+		}
+	}
+
+	return nil
+}
+
+// isInternalHost reports whether a host name is localhost or an address that isn't publicly routable.
+// Names are only checked again when the webhook is dialed, as they may resolve differently by then.
+func isInternalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && IsInternalIP(ip)
+}
+
+// IsInternalIP reports whether an address is loopback, private, link-local or otherwise not
+// publicly routable, so webhooks can't be pointed at the server's own network
+func IsInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
+// Reminder is a single delivery of a task reminder through one channel.
+// The task, date, offset, channel and fire time identify a reminder, so it is only created once.
+type Reminder struct {
+	ID            string          `json:"id"`
+	UserID        string          `json:"userId"`
+	TaskID        string          `json:"taskId"`
+	Field         ReminderField   `json:"field"`
+	MinutesBefore int             `json:"minutesBefore"`
+	Channel       ReminderChannel `json:"channel"`
+	FireAt        time.Time       `json:"fireAt"`
+	Status        ReminderStatus  `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"lastError,omitempty"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	SentAt        *time.Time      `json:"sentAt,omitempty"`
+}
+
+// reminderDate returns the task date a reminder field refers to
+func reminderDate(task *Task, field ReminderField) *time.Time {
+	switch field {
+	case ReminderDue:
+		return task.DueDate
+	case ReminderScheduled:
+		return task.ScheduledDate
+	}
+	return nil
+}
+
+// maxOffset returns how long before a task date the earliest of the settings' reminders fires
+func (s ReminderSettings) maxOffset() time.Duration {
+	var longest time.Duration
+	for _, offset := range s.Offsets {
+		if d := time.Duration(offset.MinutesBefore) * time.Minute; d > longest {
+			longest = d
+		}
+	}
+	return longest
+}
+
+// DueReminders returns the reminders for a task that should fire at now.
+// Reminders whose fire time is more than lookback in the past are skipped, so a server
+// that was down for a short time catches up without reminding about long overdue tasks.
+func (s ReminderSettings) DueReminders(task *Task, now time.Time, lookback time.Duration) []*Reminder {
+	if task.Status == StatusDone || task.IsDeleted() {
+		return nil
+	}
+
+	var reminders []*Reminder
+	for _, offset := range s.Offsets {
+		date := reminderDate(task, offset.Field)
+		if date == nil {
+			continue
+		}
+
+		fireAt := date.Add(-time.Duration(offset.MinutesBefore) * time.Minute)
+		if fireAt.After(now) || now.Sub(fireAt) > lookback {
+			continue
+		}
+
+		for _, channel := range s.Channels {
+			reminders = append(reminders, &Reminder{
+				ID:            GenerateID(),
+				UserID:        task.UserID,
+				TaskID:        task.ID,
+				Field:         offset.Field,
+				MinutesBefore: offset.MinutesBefore,
+				Channel:       channel,
+				FireAt:        fireAt,
+				Status:        ReminderPending,
+				CreatedAt:     now,
+			})
+		}
+	}
+
+	return reminders
+}
+
+// Key identifies the reminder independently of its ID
+func (r *Reminder) Key() string {
+	return fmt.Sprintf("%s|%s|%d|%s|%d", r.TaskID, r.Field, r.MinutesBefore, r.Channel, r.FireAt.Unix())
+}
+
+// IsCurrent reports whether the reminder still matches the task, i.e. the task is open and
+// the date it was created for hasn't moved
+func (r *Reminder) IsCurrent(task *Task) bool {
+	if task.Status == StatusDone || task.IsDeleted() {
+		return false
+	}
+
+	date := reminderDate(task, r.Field)
+	if date == nil {
+		return false
+	}
+	// Compare whole seconds, as the database doesn't keep nanoseconds
+	return date.Add(-time.Duration(r.MinutesBefore)*time.Minute).Unix() == r.FireAt.Unix()
+}
+
+// MarkSent records a successful delivery
+func (r *Reminder) MarkSent(now time.Time) {
+	r.Attempts++
+	r.Status = ReminderSent
+	r.LastError = ""
+	r.SentAt = &now
+}
+
+// MarkFailed records a failed delivery, putting the reminder back for a retry until maxAttempts is reached
+func (r *Reminder) MarkFailed(err error, maxAttempts int) {
+	r.Attempts++
+	r.LastError = err.Error()
+	r.Status = ReminderPending
+	if r.Attempts >= maxAttempts {
+		r.Status = ReminderFailed
+	}
+}
+
+// Notification is an entry in a user's in-app notification inbox
+type Notification struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"userId"`
+	TaskID    string     `json:"taskId,omitempty"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ReadAt    *time.Time `json:"readAt,omitempty"`
+}
+
+// NewNotification creates an unread notification
+func NewNotification(userID, taskID, title, body string) *Notification {
+	return &Notification{
+		ID:        GenerateID(),
+		UserID:    userID,
+		TaskID:    taskID,
+		Title:     title,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}
+}
+
+// IsRead reports whether the notification has been read
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
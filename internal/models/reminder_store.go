@@ -0,0 +1,235 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReminderStore defines the interface for reminder settings and delivery state storage
+type ReminderStore interface {
+	GetSettings(userID string) (ReminderSettings, error) // Falls back to DefaultReminderSettings
+	SaveSettings(settings ReminderSettings) error
+	Create(ctx context.Context, reminder *Reminder) error // ErrReminderExists if it was already scheduled
+	ListPending(ctx context.Context) ([]*Reminder, error)
+	Claim(ctx context.Context, reminder *Reminder) error // Marks a pending reminder as sending; ErrReminderClaimed if it isn't pending
+	Update(ctx context.Context, reminder *Reminder) error
+
+	// MaxOffset returns the longest any user's reminders fire before a task date, the defaults included
+	MaxOffset(ctx context.Context) (time.Duration, error)
+}
+
+// NotificationStore defines the interface for the in-app notification inbox
+type NotificationStore interface {
+	Create(notification *Notification) error
+	ListByUser(ctx context.Context, userID string, unreadOnly bool, limit int) ([]*Notification, error)
+	CountUnread(userID string) (int, error)
+	MarkRead(id string, userID string) error
+	MarkAllRead(userID string) error
+}
+
+// MemoryReminderStore implements ReminderStore interface with in-memory storage
+type MemoryReminderStore struct {
+	settings  map[string]ReminderSettings
+	reminders map[string]*Reminder // Keyed by Reminder.Key
+	mutex     sync.RWMutex
+}
+
+// NewMemoryReminderStore creates a new in-memory reminder store
+func NewMemoryReminderStore() *MemoryReminderStore {
+	return &MemoryReminderStore{
+		settings:  make(map[string]ReminderSettings),
+		reminders: make(map[string]*Reminder),
+	}
+}
+
+// GetSettings returns the user's reminder settings
+func (s *MemoryReminderStore) GetSettings(userID string) (ReminderSettings, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	settings, ok := s.settings[userID]
+	if !ok {
+		return DefaultReminderSettings(userID), nil
+	}
+	return settings, nil
+}
+
+// SaveSettings stores the user's reminder settings
+func (s *MemoryReminderStore) SaveSettings(settings ReminderSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.settings[settings.UserID] = settings
+	return nil
+}
+
+// Create schedules a reminder unless the same reminder was scheduled before
+func (s *MemoryReminderStore) Create(ctx context.Context, reminder *Reminder) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := reminder.Key()
+	if _, ok := s.reminders[key]; ok {
+		return ErrReminderExists
+	}
+
+	stored := *reminder
+	s.reminders[key] = &stored
+	return nil
+}
+
+// ListPending returns the reminders waiting to be delivered, oldest first
+func (s *MemoryReminderStore) ListPending(ctx context.Context) ([]*Reminder, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	pending := []*Reminder{}
+	for _, reminder := range s.reminders {
+		if reminder.Status == ReminderPending {
+			copied := *reminder
+			pending = append(pending, &copied)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].FireAt.Before(pending[j].FireAt)
+	})
+
+	return pending, nil
+}
+
+// Claim takes a pending reminder for delivery, so no other run sends it too
+func (s *MemoryReminderStore) Claim(ctx context.Context, reminder *Reminder) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, ok := s.reminders[reminder.Key()]
+	if !ok || stored.Status != ReminderPending {
+		return ErrReminderClaimed
+	}
+
+	stored.Status = ReminderSending
+	reminder.Status = ReminderSending
+	return nil
+}
+
+// MaxOffset returns the longest any user's reminders fire before a task date
+func (s *MemoryReminderStore) MaxOffset(ctx context.Context) (time.Duration, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	longest := DefaultReminderSettings("").maxOffset()
+	for _, settings := range s.settings {
+		if offset := settings.maxOffset(); offset > longest {
+			longest = offset
+		}
+	}
+	return longest, nil
+}
+
+// Update stores the delivery state of a reminder
+func (s *MemoryReminderStore) Update(ctx context.Context, reminder *Reminder) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := *reminder
+	s.reminders[reminder.Key()] = &stored
+	return nil
+}
+
+// MemoryNotificationStore implements NotificationStore interface with in-memory storage
+type MemoryNotificationStore struct {
+	notifications map[string]*Notification
+	mutex         sync.RWMutex
+}
+
+// NewMemoryNotificationStore creates a new in-memory notification store
+func NewMemoryNotificationStore() *MemoryNotificationStore {
+	return &MemoryNotificationStore{
+		notifications: make(map[string]*Notification),
+	}
+}
+
+// Create adds a notification to the user's inbox
+func (s *MemoryNotificationStore) Create(notification *Notification) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.notifications[notification.ID] = notification
+	return nil
+}
+
+// ListByUser returns the user's notifications, newest first; limit <= 0 returns all of them
+func (s *MemoryNotificationStore) ListByUser(ctx context.Context, userID string, unreadOnly bool, limit int) ([]*Notification, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	notifications := []*Notification{}
+	for _, notification := range s.notifications {
+		if notification.UserID != userID || (unreadOnly && notification.IsRead()) {
+			continue
+		}
+		notifications = append(notifications, notification)
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+
+	if limit > 0 && len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+
+	return notifications, nil
+}
+
+// CountUnread returns the number of unread notifications of the user
+func (s *MemoryNotificationStore) CountUnread(userID string) (int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	count := 0
+	for _, notification := range s.notifications {
+		if notification.UserID == userID && !notification.IsRead() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *MemoryNotificationStore) MarkRead(id string, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	notification, ok := s.notifications[id]
+	if !ok || notification.UserID != userID {
+		return ErrNotificationNotFound
+	}
+
+	if !notification.IsRead() {
+		now := time.Now()
+		notification.ReadAt = &now
+	}
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications as read
+func (s *MemoryNotificationStore) MarkAllRead(userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for _, notification := range s.notifications {
+		if notification.UserID == userID && !notification.IsRead() {
+			notification.ReadAt = &now
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrReviewNotFound is returned when a review session doesn't exist or isn't visible to the caller
+var ErrReviewNotFound = errors.New("review session not found")
+
+// ErrReviewFinished is returned when changing a review session that has already been finished
+var ErrReviewFinished = errors.New("review session is already finished")
+
+// ReviewStep is a single item of the weekly review checklist
+type ReviewStep struct {
+	Key         string     `json:"key"`
+	Title       string     `json:"title"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+}
+
+// ReviewSnapshot captures the state of the user's system at a point of the review
+type ReviewSnapshot struct {
+	Inbox                     int       `json:"inbox"`
+	NextActions               int       `json:"nextActions"`
+	Waiting                   int       `json:"waiting"`
+	StaleWaiting              int       `json:"staleWaiting"` // Waiting-for items past the user's stale threshold
+	Someday                   int       `json:"someday"`
+	ActiveProjects            int       `json:"activeProjects"`
+	ProjectsWithoutNextAction int       `json:"projectsWithoutNextAction"`
+	TakenAt                   time.Time `json:"takenAt"`
+}
+
+// ReviewSession records one pass through the weekly review
+type ReviewSession struct {
+	ID            string          `json:"id"`
+	UserID        string          `json:"userId"`
+	Steps         []ReviewStep    `json:"steps"`
+	Notes         string          `json:"notes,omitempty"`
+	StartSnapshot ReviewSnapshot  `json:"startSnapshot"`
+	EndSnapshot   *ReviewSnapshot `json:"endSnapshot,omitempty"`
+	StartedAt     time.Time       `json:"startedAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+	CompletedAt   *time.Time      `json:"completedAt,omitempty"`
+}
+
+// DefaultReviewSteps returns the weekly review checklist, in order
+func DefaultReviewSteps() []ReviewStep {
+	return []ReviewStep{
+		{Key: "collect", Title: "Collect Loose Papers and Materials"},
+		{Key: "notes", Title: "Process Your Notes"},
+		{Key: "inbox", Title: "Empty Your Inbox"},
+		{Key: "next", Title: "Review Next Actions Lists"},
+		{Key: "waiting", Title: "Review Waiting For List"},
+		{Key: "projects", Title: "Review Projects"},
+		{Key: "someday", Title: "Review Someday/Maybe List"},
+		{Key: "creative", Title: "Get Creative"},
+	}
+}
+
+// NewReviewSession starts a review session with the default checklist
+func NewReviewSession(userID string, snapshot ReviewSnapshot) *ReviewSession {
+	now := time.Now()
+	return &ReviewSession{
+		ID:            GenerateID(),
+		UserID:        userID,
+		Steps:         DefaultReviewSteps(),
+		StartSnapshot: snapshot,
+		StartedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// IsFinished reports whether the review has been finished
+func (s *ReviewSession) IsFinished() bool {
+	return s.CompletedAt != nil
+}
+
+// UpdateStep marks a checklist step as done or not done and optionally replaces its notes
+func (s *ReviewSession) UpdateStep(key string, completed bool, notes *string) error {
+	if s.IsFinished() {
+		return ErrReviewFinished
+	}
+
+	for i := range s.Steps {
+		step := &s.Steps[i]
+		if step.Key != key {
+			continue
+		}
+
+		now := time.Now()
+		if completed && !step.Completed {
+			step.CompletedAt = &now
+		} else if !completed {
+			step.CompletedAt = nil
+		}
+		step.Completed = completed
+		if notes != nil {
+			step.Notes = *notes
+		}
+		s.UpdatedAt = now
+		return nil
+	}
+
+	return errors.New("unknown review step")
+}
+
+// Progress returns the percentage of completed steps
+func (s *ReviewSession) Progress() int {
+	if len(s.Steps) == 0 {
+		return 0
+	}
+
+	completed := 0
+	for _, step := range s.Steps {
+		if step.Completed {
+			completed++
+		}
+	}
+	return (completed * 100) / len(s.Steps)
+}
+
+// Finish closes the review, recording the state of the system at the end
+func (s *ReviewSession) Finish(snapshot ReviewSnapshot, notes string) error {
+	if s.IsFinished() {
+		return ErrReviewFinished
+	}
+
+	now := time.Now()
+	s.EndSnapshot = &snapshot
+	s.Notes = notes
+	s.CompletedAt = &now
+	s.UpdatedAt = now
+	return nil
+}
+
+// clone returns a deep copy of the session, so later changes to it don't alter the copy
+func (s *ReviewSession) clone() *ReviewSession {
+	c := *s
+	c.Steps = append([]ReviewStep(nil), s.Steps...)
+	for i := range c.Steps {
+		if completedAt := c.Steps[i].CompletedAt; completedAt != nil {
+			value := *completedAt
+			c.Steps[i].CompletedAt = &value
+		}
+	}
+	if s.EndSnapshot != nil {
+		snapshot := *s.EndSnapshot
+		c.EndSnapshot = &snapshot
+	}
+	if s.CompletedAt != nil {
+		completedAt := *s.CompletedAt
+		c.CompletedAt = &completedAt
+	}
+	return &c
+}
+
+// TakeReviewSnapshot counts the user's open items for a review
+func TakeReviewSnapshot(ctx context.Context, tasks TaskStore, projects ProjectStore, userID string, thresholds StaleThresholds) (ReviewSnapshot, error) {
+	now := time.Now()
+	snapshot := ReviewSnapshot{TakenAt: now}
+
+	open, err := tasks.GetAllByUserID(userID)
+	if err != nil {
+		return snapshot, err
+	}
+
+	projectsWithNextAction := make(map[string]bool)
+	for _, task := range open {
+		switch task.Status {
+		case StatusInbox:
+			snapshot.Inbox++
+		case StatusNext:
+			snapshot.NextActions++
+			if task.ProjectID != "" {
+				projectsWithNextAction[task.ProjectID] = true
+			}
+		case StatusWaiting:
+			snapshot.Waiting++
+			if thresholds.IsStale(StaleWaiting, task.UpdatedAt, now) {
+				snapshot.StaleWaiting++
+			}
+		case StatusSomeday:
+			snapshot.Someday++
+		}
+	}
+
+	active, err := projects.List(ctx, ProjectFilter{
+		UserID: userID,
+		States: []ProjectState{ProjectActive},
+	}, ProjectSortCreatedDesc)
+	if err != nil {
+		return snapshot, err
+	}
+
+	snapshot.ActiveProjects = len(active)
+	for _, project := range active {
+		if !projectsWithNextAction[project.ID] {
+			snapshot.ProjectsWithoutNextAction++
+		}
+	}
+
+	return snapshot, nil
+}
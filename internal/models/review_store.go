@@ -0,0 +1,100 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// ReviewStore defines the interface for weekly review session storage
+type ReviewStore interface {
+	GetForUser(id string, userID string) (*ReviewSession, error)
+	GetActive(userID string) (*ReviewSession, error) // Most recent unfinished session
+	ListByUser(ctx context.Context, userID string, limit int) ([]*ReviewSession, error)
+	Save(session *ReviewSession) error
+}
+
+// MemoryReviewStore implements ReviewStore interface with in-memory storage
+type MemoryReviewStore struct {
+	sessions map[string]*ReviewSession
+	mutex    sync.RWMutex
+}
+
+// NewMemoryReviewStore creates a new in-memory review store
+func NewMemoryReviewStore() *MemoryReviewStore {
+	return &MemoryReviewStore{
+		sessions: make(map[string]*ReviewSession),
+	}
+}
+
+// GetForUser retrieves a review session by ID, hiding other users' sessions
+func (s *MemoryReviewStore) GetForUser(id string, userID string) (*ReviewSession, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	session, ok := s.sessions[id]
+	if !ok || session.UserID != userID {
+		return nil, ErrReviewNotFound
+	}
+
+	return session.clone(), nil
+}
+
+// GetActive returns the user's most recent unfinished review session
+func (s *MemoryReviewStore) GetActive(userID string) (*ReviewSession, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var active *ReviewSession
+	for _, session := range s.sessions {
+		if session.UserID != userID || session.IsFinished() {
+			continue
+		}
+		if active == nil || session.StartedAt.After(active.StartedAt) {
+			active = session
+		}
+	}
+
+	if active == nil {
+		return nil, ErrReviewNotFound
+	}
+
+	return active.clone(), nil
+}
+
+// ListByUser returns the user's review sessions, newest first; limit <= 0 returns all of them
+func (s *MemoryReviewStore) ListByUser(ctx context.Context, userID string, limit int) ([]*ReviewSession, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sessions := []*ReviewSession{}
+	for _, session := range s.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session.clone())
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.After(sessions[j].StartedAt)
+	})
+
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+
+	return sessions, nil
+}
+
+// Save creates or updates a review session
+func (s *MemoryReviewStore) Save(session *ReviewSession) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Refuse to overwrite another user's session
+	if existing, ok := s.sessions[session.ID]; ok && existing.UserID != session.UserID {
+		return ErrReviewNotFound
+	}
+
+	s.sessions[session.ID] = session.clone()
+	return nil
+}
@@ -0,0 +1,520 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxSearchResults caps how many tasks a search returns
+const MaxSearchResults = 100
+
+// snippetWords is how many words of a description a search snippet shows
+const snippetWords = 24
+
+// SearchQuery is a parsed search. Free words have to appear in a task's title, tags or description,
+// where each also matches the longer words it starts; the operators narrow the results down:
+//
+//	"exact phrase"                words that appear next to each other
+//	-word -"some phrase"          words that must not appear
+//	status:next status:next,waiting
+//	@phone                        context
+//	#work                         tag
+//	due:today due:tomorrow due:overdue
+//	due:<7d due:>2w               due within, or after, a number of days or weeks
+//	due:2025-01-31 due:<2025-01-31 due:>2025-01-31
+//	energy:low                    energy required
+//	time:<15m time:<1h            estimated to take at most this long (a bare number means minutes)
+//	priority:1
+type SearchQuery struct {
+	Terms     []string     // Words that must all appear
+	Phrases   []string     // Space-separated words that must appear in this order
+	Excluded  []string     // Words or phrases that must not appear
+	Statuses  []TaskStatus // Match any of these statuses
+	Contexts  []Context    // Match tasks with all of these contexts
+	Tags      []string     // Match tasks with all of these tags
+	DueAfter  *time.Time   // Due on or after this time
+	DueBefore *time.Time   // Due before this time
+	Energy    string       // Required energy level
+	MaxTime   int          // Estimated at most this many minutes; tasks without an estimate don't match
+	Priority  int          // Exact priority level
+
+	Limit int // Most results to return; MaxSearchResults when 0
+}
+
+// searchToken is a piece of a search as the user typed it
+type searchToken struct {
+	text    string
+	quoted  bool
+	negated bool
+}
+
+// splitSearch splits a search into whitespace-separated tokens, keeping quoted phrases together.
+// Every pass of the loop moves past at least one rune, whatever the input holds.
+func splitSearch(input string) []searchToken {
+	var tokens []searchToken
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+
+		token := searchToken{}
+		if r == '-' && i+1 < len(input) {
+			// A dash on its own is a word rather than an empty exclusion
+			if next, _ := utf8.DecodeRuneInString(input[i+1:]); !unicode.IsSpace(next) {
+				token.negated = true
+				i++
+			}
+		}
+
+		if input[i] == '"' {
+			end := strings.IndexByte(input[i+1:], '"')
+			if end < 0 {
+				end = len(input) - i - 1
+			}
+			token.text, token.quoted = input[i+1:i+1+end], true
+			tokens = append(tokens, token)
+			i += end + 2
+			continue
+		}
+
+		end := strings.IndexFunc(input[i:], unicode.IsSpace)
+		if end < 0 {
+			end = len(input) - i
+		}
+		if end == 0 {
+			_, end = utf8.DecodeRuneInString(input[i:])
+		}
+		token.text = input[i : i+end]
+		tokens = append(tokens, token)
+		i += end
+	}
+	return tokens
+}
+
+// searchWords lowercases text and splits it into words of letters and digits, the way both
+// stores index tasks
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ParseSearchQuery parses the search syntax described on SearchQuery.
+// Relative due dates are resolved against now, in now's location.
+func ParseSearchQuery(input string, now time.Time) (SearchQuery, error) {
+	var q SearchQuery
+	for _, token := range splitSearch(input) {
+		if !token.quoted && !token.negated {
+			handled, err := q.parseOperator(token.text, now)
+			if err != nil {
+				return SearchQuery{}, err
+			}
+			if handled {
+				continue
+			}
+		}
+
+		words := searchWords(token.text)
+		switch {
+		case len(words) == 0:
+		case token.negated:
+			q.Excluded = append(q.Excluded, strings.Join(words, " "))
+		case token.quoted || len(words) > 1:
+			q.Phrases = append(q.Phrases, strings.Join(words, " "))
+		default:
+			q.Terms = append(q.Terms, words[0])
+		}
+	}
+	return q, nil
+}
+
+// parseOperator applies a status:, due:, @context or #tag token and reports whether it was one
+func (q *SearchQuery) parseOperator(token string, now time.Time) (bool, error) {
+	lower := strings.ToLower(token)
+	switch {
+	case strings.HasPrefix(lower, "status:"):
+		for _, value := range strings.Split(strings.TrimPrefix(lower, "status:"), ",") {
+			status := TaskStatus(value)
+			switch status {
+			case StatusInbox, StatusNext, StatusWaiting, StatusScheduled, StatusSomeday, StatusDone, StatusProject, StatusReference:
+				q.Statuses = append(q.Statuses, status)
+			default:
+				return false, fmt.Errorf("unknown status %q in search", value)
+			}
+		}
+		return true, nil
+	case strings.HasPrefix(lower, "due:"):
+		return true, q.parseDue(strings.TrimPrefix(lower, "due:"), now)
+	case strings.HasPrefix(lower, "energy:"):
+		q.Energy = strings.TrimPrefix(lower, "energy:")
+		return true, nil
+	case strings.HasPrefix(lower, "time:"):
+		value := strings.TrimLeft(strings.TrimPrefix(lower, "time:"), "<=")
+		minutes, err := strconv.Atoi(value)
+		if err != nil {
+			d, parseErr := time.ParseDuration(value)
+			if parseErr != nil || d < time.Minute {
+				return false, fmt.Errorf("invalid time estimate %q in search", value)
+			}
+			minutes = int(d / time.Minute)
+		}
+		q.MaxTime = minutes
+		return true, nil
+	case strings.HasPrefix(lower, "priority:"):
+		priority, err := strconv.Atoi(strings.TrimPrefix(lower, "priority:"))
+		if err != nil || priority < 1 || priority > 3 {
+			return false, fmt.Errorf("invalid priority %q in search", strings.TrimPrefix(lower, "priority:"))
+		}
+		q.Priority = priority
+		return true, nil
+	case len(lower) > 1 && lower[0] == '@':
+		q.Contexts = append(q.Contexts, Context(lower[1:]))
+		return true, nil
+	case len(lower) > 1 && lower[0] == '#':
+		q.Tags = append(q.Tags, lower[1:])
+		return true, nil
+	}
+	return false, nil
+}
+
+// parseDue sets the due date range of a due: operator
+func (q *SearchQuery) parseDue(value string, now time.Time) error {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := func(offset int) *time.Time {
+		t := today.AddDate(0, 0, offset)
+		return &t
+	}
+
+	switch value {
+	case "today":
+		q.DueAfter, q.DueBefore = day(0), day(1)
+		return nil
+	case "tomorrow":
+		q.DueAfter, q.DueBefore = day(1), day(2)
+		return nil
+	case "overdue":
+		q.DueBefore = &now
+		return nil
+	}
+
+	comparison := byte(0)
+	if value != "" && (value[0] == '<' || value[0] == '>') {
+		comparison, value = value[0], value[1:]
+	}
+
+	// A number of days or weeks from today, or a date
+	var date time.Time
+	if len(value) > 1 && (strings.HasSuffix(value, "d") || strings.HasSuffix(value, "w")) {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid due date %q in search", value)
+		}
+		if strings.HasSuffix(value, "w") {
+			n *= 7
+		}
+		date = *day(n)
+	} else {
+		var err error
+		if date, err = time.ParseInLocation("2006-01-02", value, now.Location()); err != nil {
+			return fmt.Errorf("invalid due date %q in search", value)
+		}
+	}
+
+	next := date.AddDate(0, 0, 1)
+	switch comparison {
+	case '<':
+		q.DueBefore = &next
+	case '>':
+		q.DueAfter = &next
+	default:
+		q.DueAfter, q.DueBefore = &date, &next
+	}
+	return nil
+}
+
+// limit returns how many results the query may return
+func (q SearchQuery) limit() int {
+	if q.Limit <= 0 {
+		return MaxSearchResults
+	}
+	return q.Limit
+}
+
+// Empty reports whether the query has nothing to search for
+func (q SearchQuery) Empty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0 && len(q.Excluded) == 0 && len(q.Statuses) == 0 &&
+		len(q.Contexts) == 0 && len(q.Tags) == 0 && q.DueAfter == nil && q.DueBefore == nil &&
+		q.Energy == "" && q.MaxTime == 0 && q.Priority == 0
+}
+
+// searchDocument holds the indexed words of a task, field by field, with the weight of each field.
+// The weights follow ts_rank's defaults for the A, B and C labels of the tsvector.
+type searchDocument struct {
+	fields  [3][]string
+	weights [3]float64
+}
+
+func newSearchDocument(task *Task) searchDocument {
+	return searchDocument{
+		fields:  [3][]string{searchWords(task.Title), searchWords(strings.Join(task.Tags, " ")), searchWords(task.Description)},
+		weights: [3]float64{1.0, 0.4, 0.2},
+	}
+}
+
+// weight returns the weight of the best field containing the words in order, or 0 if none does.
+// With prefix set, the last word also matches longer words it starts.
+func (d searchDocument) weight(words []string, prefix bool) float64 {
+	for i, field := range d.fields {
+		for start := 0; start+len(words) <= len(field); start++ {
+			matched := true
+			for j, word := range words {
+				candidate := field[start+j]
+				if candidate != word && !(prefix && j == len(words)-1 && strings.HasPrefix(candidate, word)) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				return d.weights[i]
+			}
+		}
+	}
+	return 0
+}
+
+// Match evaluates the query against a task the same way PgTaskStore does. It reports whether
+// the task matches and ranks it by where the words were found.
+func (q SearchQuery) Match(task *Task) (float64, bool) {
+	if len(q.Statuses) > 0 {
+		found := false
+		for _, status := range q.Statuses {
+			if task.Status == status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	for _, context := range q.Contexts {
+		if !containsContext(task.Contexts, context) {
+			return 0, false
+		}
+	}
+	for _, tag := range q.Tags {
+		if !containsFold(task.Tags, tag) {
+			return 0, false
+		}
+	}
+	if q.DueAfter != nil && (task.DueDate == nil || task.DueDate.Before(*q.DueAfter)) {
+		return 0, false
+	}
+	if q.DueBefore != nil && (task.DueDate == nil || !task.DueDate.Before(*q.DueBefore)) {
+		return 0, false
+	}
+	if q.Energy != "" && !strings.EqualFold(task.EnergyRequired, q.Energy) {
+		return 0, false
+	}
+	if q.MaxTime != 0 && (task.TimeEstimate == 0 || task.TimeEstimate > q.MaxTime) {
+		return 0, false
+	}
+	if q.Priority != 0 && task.Priority != q.Priority {
+		return 0, false
+	}
+
+	doc := newSearchDocument(task)
+	rank := 0.0
+	for _, term := range q.Terms {
+		weight := doc.weight([]string{term}, true)
+		if weight == 0 {
+			return 0, false
+		}
+		rank += weight
+	}
+	for _, phrase := range q.Phrases {
+		weight := doc.weight(strings.Fields(phrase), false)
+		if weight == 0 {
+			return 0, false
+		}
+		rank += weight
+	}
+	for _, excluded := range q.Excluded {
+		if doc.weight(strings.Fields(excluded), false) > 0 {
+			return 0, false
+		}
+	}
+	return rank, true
+}
+
+// containsContext reports whether contexts include context, ignoring case
+func containsContext(contexts []Context, context Context) bool {
+	for _, c := range contexts {
+		if strings.EqualFold(string(c), string(context)) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether values include value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchTasks parses a search typed by a user and returns the matching tasks without their highlights
+func searchTasks(store TaskStore, query string, userID string) ([]*Task, error) {
+	q, err := ParseSearchQuery(query, time.Now())
+	if err != nil || q.Empty() {
+		return nil, err
+	}
+
+	results, err := store.SearchTasks(context.Background(), userID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*Task, len(results))
+	for i, result := range results {
+		tasks[i] = result.Task
+	}
+	return tasks, nil
+}
+
+// SnippetPart is a piece of highlighted text; Match marks the words the search matched
+type SnippetPart struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+// SearchResult is a task found by a search
+type SearchResult struct {
+	Task    *Task         `json:"task"`
+	Rank    float64       `json:"rank"`
+	Title   []SnippetPart `json:"title"`             // The title with the matched words marked
+	Snippet []SnippetPart `json:"snippet,omitempty"` // The part of the description around the first match
+}
+
+// newSearchResult builds a result with the matched words of the task highlighted
+func newSearchResult(task *Task, rank float64, q SearchQuery) *SearchResult {
+	result := &SearchResult{Task: task, Rank: rank, Title: q.highlight(task.Title, 0)}
+	if task.Description != "" {
+		result.Snippet = q.highlight(task.Description, snippetWords)
+	}
+	return result
+}
+
+// sortSearchResults orders results best first, then by most recent update
+func sortSearchResults(results []*SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		if !a.Task.UpdatedAt.Equal(b.Task.UpdatedAt) {
+			return a.Task.UpdatedAt.After(b.Task.UpdatedAt)
+		}
+		return a.Task.ID < b.Task.ID
+	})
+}
+
+// highlights reports whether a word of a task matches one of the query's terms or phrases
+func (q SearchQuery) highlights(word string) bool {
+	for _, term := range q.Terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	for _, phrase := range q.Phrases {
+		for _, w := range strings.Fields(phrase) {
+			if word == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// highlight splits text into parts with the matched words marked. A positive limit keeps only
+// that many words around the first match and marks the cut with an ellipsis.
+func (q SearchQuery) highlight(text string, limit int) []SnippetPart {
+	type span struct{ start, end int }
+	var words []span
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			words = append(words, span{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, span{start, len(text)})
+	}
+
+	first, last := 0, len(words)
+	if limit > 0 && len(words) > limit {
+		match := 0
+		for i, w := range words {
+			if q.highlights(strings.ToLower(text[w.start:w.end])) {
+				match = i
+				break
+			}
+		}
+		first = match - limit/4
+		if first < 0 {
+			first = 0
+		}
+		last = first + limit
+		if last > len(words) {
+			last, first = len(words), len(words)-limit
+		}
+	}
+
+	var parts []SnippetPart
+	add := func(s string, match bool) {
+		if s == "" {
+			return
+		}
+		if n := len(parts); n > 0 && parts[n-1].Match == match {
+			parts[n-1].Text += s
+			return
+		}
+		parts = append(parts, SnippetPart{Text: s, Match: match})
+	}
+
+	from, to := 0, len(text)
+	if first > 0 {
+		add("…", false)
+		from = words[first].start
+	}
+	if last < len(words) {
+		to = words[last-1].end
+	}
+	pos := from
+	for _, w := range words[first:last] {
+		add(text[pos:w.start], false)
+		add(text[w.start:w.end], q.highlights(strings.ToLower(text[w.start:w.end])))
+		pos = w.end
+	}
+	add(text[pos:to], false)
+	if to < len(text) {
+		add("…", false)
+	}
+	return parts
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []searchToken
+	}{
+		{
+			name:  "plain words",
+			input: "call  mom",
+			want:  []searchToken{{text: "call"}, {text: "mom"}},
+		},
+		{
+			name:  "non-breaking space",
+			input: "call\u00a0mom",
+			want:  []searchToken{{text: "call"}, {text: "mom"}},
+		},
+		{
+			name:  "leading non-breaking space",
+			input: "\u00a0call",
+			want:  []searchToken{{text: "call"}},
+		},
+		{
+			name:  "ideographic and em spaces",
+			input: "call\u3000mom\u2003dad",
+			want:  []searchToken{{text: "call"}, {text: "mom"}, {text: "dad"}},
+		},
+		{
+			name:  "multi-byte letters",
+			input: "café über",
+			want:  []searchToken{{text: "café"}, {text: "über"}},
+		},
+		{
+			name:  "quoted phrase",
+			input: `"call mom" -"call dad" -phone`,
+			want: []searchToken{
+				{text: "call mom", quoted: true},
+				{text: "call dad", quoted: true, negated: true},
+				{text: "phone", negated: true},
+			},
+		},
+		{
+			name:  "trailing dash",
+			input: "call -",
+			want:  []searchToken{{text: "call"}, {text: "-"}},
+		},
+		{
+			name:  "dash before a space",
+			input: "call -\u00a0mom",
+			want:  []searchToken{{text: "call"}, {text: "-"}, {text: "mom"}},
+		},
+		{
+			name:  "unterminated quote",
+			input: `call "mom`,
+			want:  []searchToken{{text: "call"}, {text: "mom", quoted: true}},
+		},
+		{
+			name:  "lone quote",
+			input: `"`,
+			want:  []searchToken{{text: "", quoted: true}},
+		},
+		{
+			name:  "only spaces",
+			input: " \u00a0\t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitSearch(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitSearch(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSearchQueryNonBreakingSpace(t *testing.T) {
+	q, err := ParseSearchQuery("call\u00a0mom\u00a0status:next", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(q.Terms, []string{"call", "mom"}) {
+		t.Errorf("terms = %v, want [call mom]", q.Terms)
+	}
+	if !reflect.DeepEqual(q.Statuses, []TaskStatus{StatusNext}) {
+		t.Errorf("statuses = %v, want [next]", q.Statuses)
+	}
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StaleKind identifies the rule that flagged an item as stale
+type StaleKind string
+
+const (
+	StaleInbox   StaleKind = "inbox"   // Inbox item waiting too long to be processed
+	StaleWaiting StaleKind = "waiting" // Waiting-for item without a recent follow-up
+	StaleProject StaleKind = "project" // Active project without a next action
+	StaleSomeday StaleKind = "someday" // Someday/maybe item nobody has looked at in a long time
+)
+
+// StaleKinds lists the stale kinds in the order they are reported
+var StaleKinds = []StaleKind{StaleInbox, StaleWaiting, StaleProject, StaleSomeday}
+
+// StaleThresholds configures how many days an item may sit untouched before it is flagged
+type StaleThresholds struct {
+	InboxDays   int `json:"inboxDays"`
+	WaitingDays int `json:"waitingDays"`
+	SomedayDays int `json:"somedayDays"`
+}
+
+// DefaultStaleThresholds returns the thresholds used until a user sets their own
+func DefaultStaleThresholds() StaleThresholds {
+	return StaleThresholds{
+		InboxDays:   2,
+		WaitingDays: 7,
+		SomedayDays: 90,
+	}
+}
+
+// Validate checks that every threshold is at least one day
+func (t StaleThresholds) Validate() error {
+	if t.InboxDays < 1 || t.WaitingDays < 1 || t.SomedayDays < 1 {
+		return errors.New("stale thresholds must be at least 1 day")
+	}
+	return nil
+}
+
+// IsStale reports whether an item of the given kind last touched at lastActivity is stale at now.
+// Projects are flagged by their lack of a next action rather than by age, so they are never stale by age.
+func (t StaleThresholds) IsStale(kind StaleKind, lastActivity, now time.Time) bool {
+	var days int
+	switch kind {
+	case StaleInbox:
+		days = t.InboxDays
+	case StaleWaiting:
+		days = t.WaitingDays
+	case StaleSomeday:
+		days = t.SomedayDays
+	default:
+		return false
+	}
+	return now.Sub(lastActivity) >= time.Duration(days)*24*time.Hour
+}
+
+// StaleItem is a task or project that needs attention
+type StaleItem struct {
+	Kind         StaleKind `json:"kind"`
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	LastActivity time.Time `json:"lastActivity"`
+	DaysIdle     int       `json:"daysIdle"`
+	Reason       string    `json:"reason"`
+}
+
+// StaleReport is the result of analyzing a user's system for stale items
+type StaleReport struct {
+	Items       []StaleItem       `json:"items"`
+	Counts      map[StaleKind]int `json:"counts"`
+	Thresholds  StaleThresholds   `json:"thresholds"`
+	GeneratedAt time.Time         `json:"generatedAt"`
+}
+
+// Total returns the number of stale items in the report
+func (r *StaleReport) Total() int {
+	return len(r.Items)
+}
+
+// daysBetween returns the number of whole days from since to now
+func daysBetween(since, now time.Time) int {
+	return int(now.Sub(since).Hours() / 24)
+}
+
+// FindStaleItems runs the stale item rules over the user's tasks and projects.
+// Items are grouped by kind and ordered from the longest idle within each kind.
+func FindStaleItems(ctx context.Context, tasks TaskStore, projects ProjectStore, userID string, thresholds StaleThresholds, now time.Time) (*StaleReport, error) {
+	report := &StaleReport{
+		Items:       []StaleItem{},
+		Counts:      make(map[StaleKind]int),
+		Thresholds:  thresholds,
+		GeneratedAt: now,
+	}
+
+	all, err := tasks.GetAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	projectsWithNextAction := make(map[string]bool)
+	for _, task := range all {
+		item := StaleItem{ID: task.ID, Title: task.Title}
+
+		switch task.Status {
+		case StatusInbox:
+			// Inbox items age from capture; editing one doesn't process it
+			item.Kind = StaleInbox
+			item.LastActivity = task.CreatedAt
+		case StatusWaiting:
+			item.Kind = StaleWaiting
+			item.LastActivity = task.UpdatedAt
+		case StatusSomeday:
+			item.Kind = StaleSomeday
+			item.LastActivity = task.UpdatedAt
+		case StatusNext:
+			if task.ProjectID != "" {
+				projectsWithNextAction[task.ProjectID] = true
+			}
+			continue
+		default:
+			continue
+		}
+
+		if !thresholds.IsStale(item.Kind, item.LastActivity, now) {
+			continue
+		}
+
+		item.DaysIdle = daysBetween(item.LastActivity, now)
+		switch item.Kind {
+		case StaleInbox:
+			item.Reason = fmt.Sprintf("In the inbox for %d days", item.DaysIdle)
+		case StaleWaiting:
+			item.Reason = fmt.Sprintf("No follow-up for %d days", item.DaysIdle)
+		case StaleSomeday:
+			item.Reason = fmt.Sprintf("Not reviewed for %d days", item.DaysIdle)
+		}
+		report.Items = append(report.Items, item)
+	}
+
+	active, err := projects.List(ctx, ProjectFilter{
+		UserID: userID,
+		States: []ProjectState{ProjectActive},
+	}, ProjectSortCreatedDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, project := range active {
+		if projectsWithNextAction[project.ID] {
+			continue
+		}
+		report.Items = append(report.Items, StaleItem{
+			Kind:         StaleProject,
+			ID:           project.ID,
+			Title:        project.Title,
+			LastActivity: project.UpdatedAt,
+			DaysIdle:     daysBetween(project.UpdatedAt, now),
+			Reason:       "No next action",
+		})
+	}
+
+	order := make(map[StaleKind]int, len(StaleKinds))
+	for i, kind := range StaleKinds {
+		order[kind] = i
+	}
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if a.Kind != b.Kind {
+			return order[a.Kind] < order[b.Kind]
+		}
+		return a.LastActivity.Before(b.LastActivity)
+	})
+
+	for _, item := range report.Items {
+		report.Counts[item.Kind]++
+	}
+
+	return report, nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"sync"
+)
+
+// StaleThresholdStore defines the interface for per-user stale item threshold storage
+type StaleThresholdStore interface {
+	Get(userID string) (StaleThresholds, error) // Falls back to DefaultStaleThresholds
+	Save(userID string, thresholds StaleThresholds) error
+}
+
+// MemoryStaleThresholdStore implements StaleThresholdStore interface with in-memory storage
+type MemoryStaleThresholdStore struct {
+	thresholds map[string]StaleThresholds
+	mutex      sync.RWMutex
+}
+
+// NewMemoryStaleThresholdStore creates a new in-memory stale threshold store
+func NewMemoryStaleThresholdStore() *MemoryStaleThresholdStore {
+	return &MemoryStaleThresholdStore{
+		thresholds: make(map[string]StaleThresholds),
+	}
+}
+
+// Get returns the user's stale thresholds
+func (s *MemoryStaleThresholdStore) Get(userID string) (StaleThresholds, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	thresholds, ok := s.thresholds[userID]
+	if !ok {
+		return DefaultStaleThresholds(), nil
+	}
+	return thresholds, nil
+}
+
+// Save stores the user's stale thresholds
+func (s *MemoryStaleThresholdStore) Save(userID string, thresholds StaleThresholds) error {
+	if err := thresholds.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.thresholds[userID] = thresholds
+	return nil
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"context"
+	"errors"
+)
+
+// maxSubtreeDepth bounds how deep sub-task lookups follow the tree, so a broken chain of parents
+// can't loop forever
+const maxSubtreeDepth = 32
+
+// ErrSubtaskCycle is returned when a task would become a sub-task of itself or of one of its sub-tasks
+var ErrSubtaskCycle = errors.New("a task cannot be moved under itself or one of its sub-tasks")
+
+// NewSubtask creates a sub-task of parent. It belongs to the parent's project and starts as a next action.
+func NewSubtask(parent *Task, title, description string) *Task {
+	task := NewTask(title, description, parent.UserID)
+	task.ParentID = parent.ID
+	task.ProjectID = parent.ProjectID
+	task.Status = StatusNext
+	return task
+}
+
+// SubtaskProgress counts how many of a task's sub-tasks, at any depth, are done
+type SubtaskProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// Progress rolls the completion of a subtree up into a single count
+func Progress(subtree []*Task) SubtaskProgress {
+	progress := SubtaskProgress{Total: len(subtree)}
+	for _, task := range subtree {
+		if task.Status == StatusDone {
+			progress.Done++
+		}
+	}
+	return progress
+}
+
+// Percentage returns the share of done sub-tasks, from 0 to 100
+func (p SubtaskProgress) Percentage() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Done * 100 / p.Total
+}
+
+// CheckParent verifies that a task can become a sub-task of parentID: the parent must be the
+// user's and must not be the task itself or one of its descendants. An empty parentID is always fine.
+func CheckParent(ctx context.Context, tasks TaskStore, task *Task, parentID string, userID string) (*Task, error) {
+	if parentID == "" {
+		return nil, nil
+	}
+	if parentID == task.ID {
+		return nil, ErrSubtaskCycle
+	}
+
+	parent, err := tasks.GetForUser(parentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	subtree, err := tasks.GetSubtree(ctx, task.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, descendant := range subtree {
+		if descendant.ID == parentID {
+			return nil, ErrSubtaskCycle
+		}
+	}
+
+	return parent, nil
+}
+
+// AllChildrenDone reports whether a task has sub-tasks and all of its direct sub-tasks are done
+func AllChildrenDone(ctx context.Context, tasks TaskStore, parentID string, userID string) (bool, error) {
+	children, err := tasks.GetChildren(ctx, parentID, userID)
+	if err != nil {
+		return false, err
+	}
+	if len(children) == 0 {
+		return false, nil
+	}
+
+	for _, child := range children {
+		if child.Status != StatusDone {
+			return false, nil
+		}
+	}
+	return true, nil
+}
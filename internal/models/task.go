@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -35,27 +36,34 @@ const (
 
 // Task represents a GTD task
 type Task struct {
-	ID             string     `json:"id"`
-	Title          string     `json:"title"`
-	Description    string     `json:"description"`
-	Status         TaskStatus `json:"status"`
-	UserID         string     `json:"userId,omitempty"`         // User who owns this task
-	ProjectID      string     `json:"projectId,omitempty"`      // For tasks that are part of a project
-	ParentID       string     `json:"parentId,omitempty"`       // For hierarchical tasks
-	Contexts       []Context  `json:"contexts,omitempty"`       // Where this can be done (home, work, phone, etc.)
-	Tags           []string   `json:"tags,omitempty"`           // Custom tags for organization
-	DueDate        *time.Time `json:"dueDate,omitempty"`        // When this must be completed by
-	ScheduledDate  *time.Time `json:"scheduledDate,omitempty"`  // When this is scheduled to be done
-	TimeEstimate   int        `json:"timeEstimate,omitempty"`   // Estimated minutes to complete
-	EnergyRequired string     `json:"energyRequired,omitempty"` // High, medium, low
-	Priority       int        `json:"priority,omitempty"`       // 1-3 priority level (1 highest)
-	Timeframe      Timeframe  `json:"timeframe,omitempty"`      // When this should be addressed
-	IsRecurring    bool       `json:"isRecurring,omitempty"`    // Whether this task recurs
-	RecurringRule  string     `json:"recurringRule,omitempty"`  // Rule for recurrence (e.g., "daily", "weekly on Monday")
-	CreatedAt      time.Time  `json:"createdAt"`
-	UpdatedAt      time.Time  `json:"updatedAt"`
-	CompletedAt    *time.Time `json:"completedAt,omitempty"`
-	DeletedAt      *time.Time `json:"deletedAt,omitempty"` // Soft delete support
+	ID               string           `json:"id"`
+	Title            string           `json:"title"`
+	Description      string           `json:"description"`
+	Status           TaskStatus       `json:"status"`
+	UserID           string           `json:"userId,omitempty"`           // User who owns this task
+	ProjectID        string           `json:"projectId,omitempty"`        // For tasks that are part of a project
+	ParentID         string           `json:"parentId,omitempty"`         // For hierarchical tasks
+	BlockedBy        []string         `json:"blockedBy,omitempty"`        // Tasks in the same project that must be done first
+	AutoComplete     bool             `json:"autoComplete,omitempty"`     // Complete this task once all of its sub-tasks are done
+	Contexts         []Context        `json:"contexts,omitempty"`         // Where this can be done (home, work, phone, etc.)
+	Tags             []string         `json:"tags,omitempty"`             // Custom tags for organization
+	DueDate          *time.Time       `json:"dueDate,omitempty"`          // When this must be completed by
+	ScheduledDate    *time.Time       `json:"scheduledDate,omitempty"`    // When this is scheduled to be done
+	TimeEstimate     int              `json:"timeEstimate,omitempty"`     // Estimated minutes to complete
+	EnergyRequired   string           `json:"energyRequired,omitempty"`   // High, medium, low
+	Priority         int              `json:"priority,omitempty"`         // 1-3 priority level (1 highest)
+	Timeframe        Timeframe        `json:"timeframe,omitempty"`        // When this should be addressed
+	IsRecurring      bool             `json:"isRecurring,omitempty"`      // Whether this task recurs
+	RecurringRule    string           `json:"recurringRule,omitempty"`    // Rule for recurrence (e.g., "daily", "weekly on Monday")
+	RecurrenceFrom   RecurrenceAnchor `json:"recurrenceFrom,omitempty"`   // Whether the next occurrence counts from the due date (default) or completion
+	NextOccurrenceID string           `json:"nextOccurrenceId,omitempty"` // Occurrence created when this task was completed, so completing it again doesn't create another
+	CreatedAt        time.Time        `json:"createdAt"`
+	UpdatedAt        time.Time        `json:"updatedAt"`
+	CompletedAt      *time.Time       `json:"completedAt,omitempty"`
+	DeletedAt        *time.Time       `json:"deletedAt,omitempty"` // Soft delete support
+	Version          int              `json:"version"`             // Bumped on every save; a save from an older version is rejected
+
+	undo *UndoAction // Action that the history events of this copy's saves are recorded on
 }
 
 // NewTask creates a new task with default values (in inbox)
@@ -94,18 +102,46 @@ func (t *Task) Validate() error {
 		return errors.New("invalid task status")
 	}
 
+	if t.IsRecurring && t.RecurringRule == "" {
+		return errors.New("recurring task must have a recurrence rule")
+	}
+
+	if t.RecurringRule != "" {
+		if _, err := ParseRecurrenceRule(t.RecurringRule); err != nil {
+			return fmt.Errorf("invalid recurrence rule: %v", err)
+		}
+	}
+
+	switch t.RecurrenceFrom {
+	case "", AnchorDueDate, AnchorCompletion:
+	default:
+		return errors.New("invalid recurrence anchor")
+	}
+
+	for _, id := range t.BlockedBy {
+		if id == t.ID {
+			return ErrDependencySelf
+		}
+	}
+
+	if t.ParentID != "" && t.ParentID == t.ID {
+		return ErrSubtaskCycle
+	}
+
 	return nil
 }
 
 // MarkAsNext moves a task to the Next Actions list
 func (t *Task) MarkAsNext() {
 	t.Status = StatusNext
+	t.CompletedAt = nil
 	t.UpdatedAt = time.Now()
 }
 
 // MarkAsWaiting marks a task as waiting for someone else
 func (t *Task) MarkAsWaiting() {
 	t.Status = StatusWaiting
+	t.CompletedAt = nil
 	t.UpdatedAt = time.Now()
 }
 
@@ -113,12 +149,14 @@ func (t *Task) MarkAsWaiting() {
 func (t *Task) MarkAsScheduled(scheduledDate time.Time) {
 	t.Status = StatusScheduled
 	t.ScheduledDate = &scheduledDate
+	t.CompletedAt = nil
 	t.UpdatedAt = time.Now()
 }
 
 // MarkAsSomeday moves a task to the Someday/Maybe list
 func (t *Task) MarkAsSomeday() {
 	t.Status = StatusSomeday
+	t.CompletedAt = nil
 	t.UpdatedAt = time.Now()
 }
 
@@ -130,7 +168,92 @@ func (t *Task) MarkAsDone() {
 	t.UpdatedAt = now
 }
 
+// NextOccurrence creates the next instance of a completed recurring task.
+// It returns nil when the task doesn't recur or its rule has no further occurrences.
+func (t *Task) NextOccurrence() (*Task, error) {
+	if !t.IsRecurring || t.RecurringRule == "" {
+		return nil, nil
+	}
+
+	rule, err := ParseRecurrenceRule(t.RecurringRule)
+	if err != nil {
+		return nil, err
+	}
+
+	// COUNT includes the occurrence that was just completed
+	if rule.Count == 1 {
+		return nil, nil
+	}
+
+	completedAt := time.Now()
+	if t.CompletedAt != nil {
+		completedAt = *t.CompletedAt
+	}
+
+	// The primary date is the one the schedule is based on; the other keeps its offset from it
+	primary := t.DueDate
+	if primary == nil {
+		primary = t.ScheduledDate
+	}
+
+	base := completedAt
+	if t.RecurrenceFrom != AnchorCompletion && primary != nil {
+		base = *primary
+	}
+
+	next, ok := rule.Next(base)
+	if !ok {
+		return nil, nil
+	}
+
+	if rule.Count > 1 {
+		rule.Count--
+	}
+
+	occurrence := NewTask(t.Title, t.Description, t.UserID)
+	occurrence.ProjectID = t.ProjectID
+	occurrence.ParentID = t.ParentID
+	occurrence.Contexts = append([]Context(nil), t.Contexts...)
+	occurrence.Tags = append([]string(nil), t.Tags...)
+	occurrence.TimeEstimate = t.TimeEstimate
+	occurrence.EnergyRequired = t.EnergyRequired
+	occurrence.Priority = t.Priority
+	occurrence.Timeframe = t.Timeframe
+	occurrence.IsRecurring = true
+	occurrence.RecurringRule = t.RecurringRule
+	occurrence.RecurrenceFrom = t.RecurrenceFrom
+	if rule.Count > 0 {
+		occurrence.RecurringRule = rule.String()
+	}
+
+	switch {
+	case t.DueDate != nil:
+		due := next
+		occurrence.DueDate = &due
+		if t.ScheduledDate != nil {
+			scheduled := next.Add(t.ScheduledDate.Sub(*t.DueDate))
+			occurrence.ScheduledDate = &scheduled
+		}
+	case t.ScheduledDate != nil:
+		scheduled := next
+		occurrence.ScheduledDate = &scheduled
+	default:
+		due := next
+		occurrence.DueDate = &due
+	}
+
+	if occurrence.ScheduledDate != nil {
+		occurrence.Status = StatusScheduled
+	} else {
+		occurrence.Status = StatusNext
+	}
+
+	return occurrence, nil
+}
+
 // MarkAsProject marks a task as a project (container for other tasks)
+//
+// Deprecated: projects are stored as Project records; use ProjectFromTask to convert a task.
 func (t *Task) MarkAsProject() {
 	t.Status = StatusProject
 	t.UpdatedAt = time.Now()
@@ -147,9 +270,3 @@ func (t *Task) Delete() {
 func (t *Task) IsDeleted() bool {
 	return t.DeletedAt != nil
 }
-
-// GenerateID generates a random ID for a task
-// Note: In a real application, you might want to use a more sophisticated ID generation method
-func GenerateID() string {
-	return time.Now().Format("20060102150405")
-}
@@ -0,0 +1,358 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrContextNotFound is returned when a context doesn't exist or belongs to another user
+var ErrContextNotFound = errors.New("context not found")
+
+// ErrContextExists is returned when a user already has a context with the same name
+var ErrContextExists = errors.New("a context with this name already exists")
+
+// contextColorPattern matches the hex colors a context can be shown in
+var contextColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// clockPattern matches a time of day such as "09:00"
+var clockPattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// ContextHours is a window of the day in which a context is available, such as an office's opening hours
+type ContextHours struct {
+	Days  []time.Weekday `json:"days,omitempty"` // Every day when empty
+	Start string         `json:"start"`          // "09:00"
+	End   string         `json:"end"`            // "17:30"; before Start for a window that runs past midnight
+}
+
+// contains reports whether the window is open at t, in t's time zone
+func (h ContextHours) contains(t time.Time) bool {
+	if len(h.Days) > 0 {
+		found := false
+		for _, day := range h.Days {
+			if day == t.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	clock := t.Format("15:04")
+	if h.Start <= h.End {
+		return clock >= h.Start && clock < h.End
+	}
+	return clock >= h.Start || clock < h.End
+}
+
+// ContextDefinition is a context a user has set up, such as "@phone" or "@office".
+// Tasks refer to it by name through Task.Contexts.
+type ContextDefinition struct {
+	ID        string         `json:"id"`
+	UserID    string         `json:"userId,omitempty"`
+	Name      Context        `json:"name"`               // Without the leading "@"
+	Icon      string         `json:"icon,omitempty"`     // An emoji or short symbol
+	Color     string         `json:"color,omitempty"`    // Hex color such as "#3b82f6"
+	Location  string         `json:"location,omitempty"` // Where the context applies, such as an address
+	Hours     []ContextHours `json:"hours,omitempty"`    // When the context is available; always when empty
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// NewContextDefinition creates a new context
+func NewContextDefinition(name Context, userID string) *ContextDefinition {
+	now := time.Now()
+	return &ContextDefinition{
+		ID:        GenerateID(),
+		UserID:    userID,
+		Name:      NormalizeContext(name),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate checks if the context data is valid
+func (c *ContextDefinition) Validate() error {
+	if c.Name == "" {
+		return errors.New("context name cannot be empty")
+	}
+	if strings.ContainsAny(string(c.Name), " \t\n,") {
+		return errors.New("context name cannot contain spaces or commas")
+	}
+	if c.Color != "" && !contextColorPattern.MatchString(c.Color) {
+		return errors.New("context color must be a hex color such as #3b82f6")
+	}
+
+	for _, hours := range c.Hours {
+		if !clockPattern.MatchString(hours.Start) || !clockPattern.MatchString(hours.End) {
+			return errors.New("context hours must be times such as 09:00")
+		}
+		if hours.Start == hours.End {
+			return errors.New("context hours cannot start and end at the same time")
+		}
+		for _, day := range hours.Days {
+			if day < time.Sunday || day > time.Saturday {
+				return errors.New("context days must be 0 (Sunday) to 6 (Saturday)")
+			}
+		}
+	}
+
+	return nil
+}
+
+// AvailableAt reports whether the context is available at t
+func (c *ContextDefinition) AvailableAt(t time.Time) bool {
+	if len(c.Hours) == 0 {
+		return true
+	}
+	for _, hours := range c.Hours {
+		if hours.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeContext trims whitespace and the leading "@" from a context name
+func NormalizeContext(name Context) Context {
+	return Context(strings.TrimPrefix(strings.TrimSpace(string(name)), "@"))
+}
+
+// contextKey is the form in which context names are compared, so "@Phone" and "phone" are the same context
+func contextKey(name Context) string {
+	return strings.ToLower(string(NormalizeContext(name)))
+}
+
+// ResolveContexts maps the context names given for a task onto the user's contexts, returning
+// them spelled as defined. Names that match no context are returned as unknown.
+func ResolveContexts(ctx context.Context, contexts ContextStore, userID string, names []Context) ([]Context, []Context, error) {
+	if len(names) == 0 {
+		return names, nil, nil
+	}
+
+	defined, err := contexts.List(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	byKey := make(map[string]Context, len(defined))
+	for _, def := range defined {
+		byKey[contextKey(def.Name)] = def.Name
+	}
+
+	var resolved, unknown []Context
+	seen := make(map[string]bool)
+	for _, name := range names {
+		key := contextKey(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if def, ok := byKey[key]; ok {
+			resolved = append(resolved, def)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	return resolved, unknown, nil
+}
+
+// ContextsToTitle resolves the contexts of a captured task and moves the names that match no context
+// back to the end of its title as "@name", so nothing that was captured gets lost
+func ContextsToTitle(ctx context.Context, contexts ContextStore, userID string, task *Task) error {
+	resolved, unknown, err := ResolveContexts(ctx, contexts, userID, task.Contexts)
+	if err != nil {
+		return err
+	}
+	for _, name := range unknown {
+		if name := NormalizeContext(name); name != "" {
+			task.Title += " @" + string(name)
+		}
+	}
+	task.Contexts = resolved
+	return nil
+}
+
+// ContextsToTags resolves the contexts of a task synced or imported from elsewhere and keeps the
+// names that match no context as tags, as such input can't be sent back for correction
+func ContextsToTags(ctx context.Context, contexts ContextStore, userID string, task *Task) error {
+	resolved, unknown, err := ResolveContexts(ctx, contexts, userID, task.Contexts)
+	if err != nil {
+		return err
+	}
+	for _, name := range unknown {
+		tag := strings.ToLower(string(NormalizeContext(name)))
+		if tag != "" && !containsFold(task.Tags, tag) {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+	task.Contexts = resolved
+	return nil
+}
+
+// openStatuses are the statuses of tasks still to be done
+var openStatuses = []TaskStatus{StatusInbox, StatusNext, StatusWaiting, StatusScheduled, StatusSomeday}
+
+// TasksInContext returns a user's open tasks in a context, oldest first
+func TasksInContext(ctx context.Context, tasks TaskStore, userID string, name Context) ([]*Task, error) {
+	key := contextKey(name)
+	matched := []*Task{}
+	err := eachTask(ctx, tasks, TaskFilter{UserID: userID, Statuses: openStatuses}, func(task *Task) error {
+		for _, c := range task.Contexts {
+			if contextKey(c) == key {
+				matched = append(matched, task)
+				break
+			}
+		}
+		return nil
+	})
+	return matched, err
+}
+
+// CountTasksByContext counts a user's open tasks in each context, keyed by the lower-case context name
+func CountTasksByContext(ctx context.Context, tasks TaskStore, userID string) (map[string]int, error) {
+	counts := make(map[string]int)
+	err := eachTask(ctx, tasks, TaskFilter{UserID: userID, Statuses: openStatuses}, func(task *Task) error {
+		seen := make(map[string]bool)
+		for _, name := range task.Contexts {
+			if key := contextKey(name); !seen[key] {
+				seen[key] = true
+				counts[key]++
+			}
+		}
+		return nil
+	})
+	return counts, err
+}
+
+// SaveContext creates or updates a user's context. A changed name is rewritten on all of the
+// user's tasks and projects, including those in the trash. It returns how many tasks were changed.
+func SaveContext(ctx context.Context, contexts ContextStore, tasks TaskStore, projects ProjectStore, def *ContextDefinition, oldName Context, userID string) (int, error) {
+	def.Name = NormalizeContext(def.Name)
+	if err := def.Validate(); err != nil {
+		return 0, err
+	}
+	if err := contexts.SaveForUser(def, userID); err != nil {
+		return 0, err
+	}
+
+	if oldName == "" || oldName == def.Name {
+		return 0, nil
+	}
+	return rewriteContext(ctx, tasks, projects, userID, oldName, def.Name)
+}
+
+// MergeContexts folds source into target: every task and project in source moves to target and
+// source is deleted. It returns how many tasks were changed.
+func MergeContexts(ctx context.Context, contexts ContextStore, tasks TaskStore, projects ProjectStore, source, target *ContextDefinition, userID string) (int, error) {
+	if source.ID == target.ID {
+		return 0, errors.New("a context cannot be merged into itself")
+	}
+
+	changed, err := rewriteContext(ctx, tasks, projects, userID, source.Name, target.Name)
+	if err != nil {
+		return changed, err
+	}
+	return changed, contexts.Delete(source.ID, userID)
+}
+
+// DeleteContext deletes a user's context and removes it from their tasks and projects.
+// It returns how many tasks were changed.
+func DeleteContext(ctx context.Context, contexts ContextStore, tasks TaskStore, projects ProjectStore, def *ContextDefinition, userID string) (int, error) {
+	changed, err := rewriteContext(ctx, tasks, projects, userID, def.Name, "")
+	if err != nil {
+		return changed, err
+	}
+	return changed, contexts.Delete(def.ID, userID)
+}
+
+// rewriteContext replaces the context from with to on a user's tasks and projects, or removes it
+// when to is empty. Names are matched like ResolveContexts does. It returns how many tasks were changed.
+func rewriteContext(ctx context.Context, tasks TaskStore, projects ProjectStore, userID string, from, to Context) (int, error) {
+	changed := 0
+	err := eachTask(ctx, tasks, TaskFilter{UserID: userID, IncludeDeleted: true}, func(task *Task) error {
+		rewritten, ok := replaceContext(task.Contexts, from, to)
+		if !ok {
+			return nil
+		}
+		task.Contexts = rewritten
+		task.UpdatedAt = time.Now()
+		if err := tasks.SaveForUser(task, userID); err != nil {
+			return fmt.Errorf("failed to update task %s: %v", task.ID, err)
+		}
+		changed++
+		return nil
+	})
+	if err != nil {
+		return changed, err
+	}
+
+	all, err := projects.List(ctx, ProjectFilter{UserID: userID, IncludeDeleted: true}, ProjectSortCreatedAsc)
+	if err != nil {
+		return changed, err
+	}
+	for _, project := range all {
+		rewritten, ok := replaceContext(project.Contexts, from, to)
+		if !ok {
+			continue
+		}
+		project.Contexts = rewritten
+		project.UpdatedAt = time.Now()
+		if err := projects.SaveForUser(project, userID); err != nil {
+			return changed, fmt.Errorf("failed to update project %s: %v", project.ID, err)
+		}
+	}
+
+	return changed, nil
+}
+
+// replaceContext returns names with from replaced by to, or dropped when to is empty, without
+// listing any context twice. It reports whether from was found.
+func replaceContext(names []Context, from, to Context) ([]Context, bool) {
+	fromKey := contextKey(from)
+	found := false
+	for _, name := range names {
+		if contextKey(name) == fromKey {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return names, false
+	}
+
+	var rewritten []Context
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if contextKey(name) == fromKey {
+			if to == "" {
+				continue
+			}
+			name = to
+		}
+		if key := contextKey(name); !seen[key] {
+			seen[key] = true
+			rewritten = append(rewritten, name)
+		}
+	}
+	return rewritten, true
+}
+
+// eachTask calls fn for every task matching filter
+func eachTask(ctx context.Context, tasks TaskStore, filter TaskFilter, fn func(task *Task) error) error {
+	all, err := QueryAll(ctx, tasks, TaskQuery{Filter: filter, Sort: SortCreatedAsc})
+	if err != nil {
+		return err
+	}
+	for _, task := range all {
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TaskEventType describes what kind of change a task event records
+type TaskEventType string
+
+const (
+	EventCreated       TaskEventType = "created"        // The task was first saved
+	EventUpdated       TaskEventType = "updated"        // A field other than status or project changed
+	EventStatusChanged TaskEventType = "status_changed" // The task moved to another GTD list
+	EventProjectMoved  TaskEventType = "project_moved"  // The task was added to, moved between or removed from projects
+	EventDeleted       TaskEventType = "deleted"        // The task was moved to the trash
+	EventRestored      TaskEventType = "restored"       // The task was brought back from the trash
+)
+
+// TaskEvent is a single entry in a task's append-only history.
+// Before and After hold the JSON values of the changed field; either is empty when it doesn't apply.
+type TaskEvent struct {
+	ID        string          `json:"id"`
+	TaskID    string          `json:"taskId"`
+	UserID    string          `json:"userId"` // Who made the change
+	Type      TaskEventType   `json:"type"`
+	Field     string          `json:"field,omitempty"` // JSON name of the changed task field
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// taskEventField is a task field whose changes are recorded in the history
+type taskEventField struct {
+	name  string // JSON name
+	label string // How the timeline refers to it
+	value func(t *Task) interface{}
+}
+
+// taskEventFields lists the recorded fields besides status, project and deletion, which have their
+// own event types. Timestamps maintained by the store are left out.
+var taskEventFields = []taskEventField{
+	{"title", "title", func(t *Task) interface{} { return t.Title }},
+	{"description", "description", func(t *Task) interface{} { return t.Description }},
+	{"parentId", "parent task", func(t *Task) interface{} { return t.ParentID }},
+	{"blockedBy", "blockers", func(t *Task) interface{} { return t.BlockedBy }},
+	{"autoComplete", "auto-complete", func(t *Task) interface{} { return t.AutoComplete }},
+	{"contexts", "contexts", func(t *Task) interface{} { return t.Contexts }},
+	{"tags", "tags", func(t *Task) interface{} { return t.Tags }},
+	{"dueDate", "due date", func(t *Task) interface{} { return eventTime(t.DueDate) }},
+	{"scheduledDate", "scheduled date", func(t *Task) interface{} { return eventTime(t.ScheduledDate) }},
+	{"timeEstimate", "time estimate", func(t *Task) interface{} { return t.TimeEstimate }},
+	{"energyRequired", "energy", func(t *Task) interface{} { return t.EnergyRequired }},
+	{"priority", "priority", func(t *Task) interface{} { return t.Priority }},
+	{"timeframe", "timeframe", func(t *Task) interface{} { return t.Timeframe }},
+	{"isRecurring", "recurring", func(t *Task) interface{} { return t.IsRecurring }},
+	{"recurringRule", "recurrence rule", func(t *Task) interface{} { return t.RecurringRule }},
+	{"recurrenceFrom", "recurrence anchor", func(t *Task) interface{} { return t.RecurrenceFrom }},
+}
+
+// eventTime normalizes a date so a value that went through the database compares equal to the original
+func eventTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	normalized := t.UTC().Truncate(time.Microsecond)
+	return &normalized
+}
+
+// eventValue encodes a field value for the history; empty values are recorded as null
+func eventValue(value interface{}) json.RawMessage {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	switch string(data) {
+	case `""`, "0", "false", "[]":
+		return json.RawMessage("null")
+	}
+	return data
+}
+
+// TaskChanges returns the events that describe how a task changed from before to after.
+// A nil before means the task is new.
+func TaskChanges(before, after *Task, userID string, at time.Time) []*TaskEvent {
+	var events []*TaskEvent
+	add := func(eventType TaskEventType, field string, from, to interface{}) {
+		event := &TaskEvent{
+			ID:        GenerateID(),
+			TaskID:    after.ID,
+			UserID:    userID,
+			Type:      eventType,
+			Field:     field,
+			CreatedAt: at,
+		}
+		if from != nil {
+			event.Before = eventValue(from)
+		}
+		if to != nil {
+			event.After = eventValue(to)
+		}
+		events = append(events, event)
+	}
+
+	if before == nil {
+		add(EventCreated, "status", nil, after.Status)
+		if after.ProjectID != "" {
+			add(EventProjectMoved, "projectId", nil, after.ProjectID)
+		}
+		if after.IsDeleted() {
+			add(EventDeleted, "", nil, nil)
+		}
+		return events
+	}
+
+	if before.Status != after.Status {
+		add(EventStatusChanged, "status", before.Status, after.Status)
+	}
+	if before.ProjectID != after.ProjectID {
+		add(EventProjectMoved, "projectId", before.ProjectID, after.ProjectID)
+	}
+	for _, field := range taskEventFields {
+		from, to := field.value(before), field.value(after)
+		if string(eventValue(from)) != string(eventValue(to)) {
+			add(EventUpdated, field.name, from, to)
+		}
+	}
+
+	switch {
+	case !before.IsDeleted() && after.IsDeleted():
+		add(EventDeleted, "", nil, nil)
+	case before.IsDeleted() && !after.IsDeleted():
+		add(EventRestored, "", nil, nil)
+	}
+
+	return events
+}
+
+// taskFieldPointer returns the address of the task field recorded in the history under a JSON name,
+// or nil for a field that isn't recorded
+func taskFieldPointer(t *Task, name string) interface{} {
+	switch name {
+	case "status":
+		return &t.Status
+	case "projectId":
+		return &t.ProjectID
+	case "title":
+		return &t.Title
+	case "description":
+		return &t.Description
+	case "parentId":
+		return &t.ParentID
+	case "blockedBy":
+		return &t.BlockedBy
+	case "autoComplete":
+		return &t.AutoComplete
+	case "contexts":
+		return &t.Contexts
+	case "tags":
+		return &t.Tags
+	case "dueDate":
+		return &t.DueDate
+	case "scheduledDate":
+		return &t.ScheduledDate
+	case "timeEstimate":
+		return &t.TimeEstimate
+	case "energyRequired":
+		return &t.EnergyRequired
+	case "priority":
+		return &t.Priority
+	case "timeframe":
+		return &t.Timeframe
+	case "isRecurring":
+		return &t.IsRecurring
+	case "recurringRule":
+		return &t.RecurringRule
+	case "recurrenceFrom":
+		return &t.RecurrenceFrom
+	}
+	return nil
+}
+
+// setEventFieldValue writes a value recorded in the history back into a task field
+func setEventFieldValue(t *Task, name string, value json.RawMessage) error {
+	field := taskFieldPointer(t, name)
+	if field == nil {
+		return fmt.Errorf("task field %q can't be set from the history", name)
+	}
+
+	target := reflect.ValueOf(field).Elem()
+	target.Set(reflect.Zero(target.Type()))
+	if isEmptyEventValue(value) {
+		return nil
+	}
+	return json.Unmarshal(value, field)
+}
+
+// eventFieldValue returns a task field as the history records it
+func eventFieldValue(t *Task, name string) json.RawMessage {
+	switch name {
+	case "status":
+		return eventValue(t.Status)
+	case "projectId":
+		return eventValue(t.ProjectID)
+	}
+	for _, field := range taskEventFields {
+		if field.name == name {
+			return eventValue(field.value(t))
+		}
+	}
+	return nil
+}
+
+// hasEventFieldValue reports whether a task field holds a value recorded in the history. The value is
+// decoded into the field first, so values that went through the database compare equal.
+func hasEventFieldValue(t *Task, name string, value json.RawMessage) (bool, error) {
+	probe := t.clone()
+	if err := setEventFieldValue(probe, name, value); err != nil {
+		return false, err
+	}
+	return string(eventFieldValue(probe, name)) == string(eventFieldValue(t, name)), nil
+}
+
+// Describe summarizes the event for the task timeline
+func (e *TaskEvent) Describe() string {
+	switch e.Type {
+	case EventCreated:
+		return "Created in " + describeEventValue(e.After)
+	case EventStatusChanged:
+		return fmt.Sprintf("Moved from %s to %s", describeEventValue(e.Before), describeEventValue(e.After))
+	case EventProjectMoved:
+		switch {
+		case isEmptyEventValue(e.Before):
+			return "Added to a project"
+		case isEmptyEventValue(e.After):
+			return "Removed from its project"
+		default:
+			return "Moved to another project"
+		}
+	case EventDeleted:
+		return "Moved to the trash"
+	case EventRestored:
+		return "Restored from the trash"
+	}
+
+	label := e.Field
+	for _, field := range taskEventFields {
+		if field.name == e.Field {
+			label = field.label
+		}
+	}
+
+	// Long text doesn't fit on a timeline
+	if e.Field == "description" {
+		return "Changed the description"
+	}
+	// Blockers are recorded by ID, which means nothing on a timeline
+	if e.Field == "blockedBy" {
+		return "Changed the tasks it waits on"
+	}
+	if isEmptyEventValue(e.Before) {
+		return fmt.Sprintf("Set %s to %s", label, describeEventValue(e.After))
+	}
+	if isEmptyEventValue(e.After) {
+		return fmt.Sprintf("Cleared %s (was %s)", label, describeEventValue(e.Before))
+	}
+	return fmt.Sprintf("Changed %s from %s to %s", label, describeEventValue(e.Before), describeEventValue(e.After))
+}
+
+// isEmptyEventValue reports whether a recorded value is missing or null
+func isEmptyEventValue(value json.RawMessage) bool {
+	return len(value) == 0 || string(value) == "null"
+}
+
+// describeEventValue formats a recorded value for display
+func describeEventValue(value json.RawMessage) string {
+	if isEmptyEventValue(value) {
+		return "none"
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(value, &decoded); err != nil {
+		return string(value)
+	}
+
+	switch v := decoded.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t.Local().Format("Jan 02, 2006")
+		}
+		return v
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// clone returns a deep copy of the task, so later changes to it don't alter the copy.
+// The copy isn't recorded on the undo action the task is.
+func (t *Task) clone() *Task {
+	c := *t
+	c.undo = nil
+	c.Contexts = append([]Context(nil), t.Contexts...)
+	c.Tags = append([]string(nil), t.Tags...)
+	c.BlockedBy = append([]string(nil), t.BlockedBy...)
+	for _, field := range []**time.Time{&c.DueDate, &c.ScheduledDate, &c.CompletedAt, &c.DeletedAt} {
+		if *field != nil {
+			value := **field
+			*field = &value
+		}
+	}
+	return &c
+}
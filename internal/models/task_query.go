@@ -0,0 +1,338 @@
+package models
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page size limits for task queries
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// TaskSort defines the order in which a task query returns results
+type TaskSort string
+
+const (
+	SortCreatedDesc TaskSort = "created_desc" // Newest first (default)
+	SortCreatedAsc  TaskSort = "created_asc"  // Oldest first
+	SortUpdatedDesc TaskSort = "updated_desc" // Most recently updated first
+	SortDueAsc      TaskSort = "due_asc"      // Earliest due date first, undated last
+	SortPriorityAsc TaskSort = "priority_asc" // Highest priority (1) first, unprioritized last
+	SortTitleAsc    TaskSort = "title_asc"    // Alphabetical by title
+)
+
+// ErrInvalidCursor is returned when a query cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TaskFilter narrows down the tasks returned by a query.
+// Zero values mean "don't filter on this field".
+type TaskFilter struct {
+	UserID    string       // Owner of the tasks
+	Statuses  []TaskStatus // Match any of these statuses
+	ProjectID string       // Tasks belonging to this project
+	NoProject bool         // Only tasks that aren't assigned to a project
+	Context   Context      // Tasks that can be done in this context
+	Tag       string       // Tasks carrying this tag
+	DueAfter  *time.Time   // Due on or after this time
+	DueBefore *time.Time   // Due before this time
+	// Due or scheduled on or after DatedAfter and before DatedBefore; the same date must fall in both
+	DatedAfter  *time.Time
+	DatedBefore *time.Time
+	Energy      string // Required energy level
+	Priority    int    // Exact priority level
+	Unblocked   bool   // Leave out tasks still waiting on an open blocker
+
+	IncludeDeleted bool // Also match soft-deleted tasks
+}
+
+// TaskQuery describes a filtered, sorted and paginated task lookup
+type TaskQuery struct {
+	Filter TaskFilter
+	Sort   TaskSort
+	Cursor string // Opaque cursor returned as NextCursor by a previous page
+	Limit  int    // Page size, defaults to DefaultPageSize
+}
+
+// TaskPage is a single page of query results
+type TaskPage struct {
+	Tasks      []*Task `json:"tasks"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
+
+// ParseTaskSort converts a string into a TaskSort, falling back to the default order
+func ParseTaskSort(s string) TaskSort {
+	switch TaskSort(s) {
+	case SortCreatedAsc, SortUpdatedDesc, SortDueAsc, SortPriorityAsc, SortTitleAsc:
+		return TaskSort(s)
+	default:
+		return SortCreatedDesc
+	}
+}
+
+// Matches reports whether a task satisfies the filter
+func (f TaskFilter) Matches(task *Task) bool {
+	if task.IsDeleted() && !f.IncludeDeleted {
+		return false
+	}
+	if f.UserID != "" && task.UserID != f.UserID {
+		return false
+	}
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, status := range f.Statuses {
+			if task.Status == status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.ProjectID != "" && task.ProjectID != f.ProjectID {
+		return false
+	}
+	if f.NoProject && task.ProjectID != "" {
+		return false
+	}
+	if f.Context != "" {
+		found := false
+		for _, c := range task.Contexts {
+			if c == f.Context {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Tag != "" {
+		found := false
+		for _, tag := range task.Tags {
+			if tag == f.Tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.DueAfter != nil && (task.DueDate == nil || task.DueDate.Before(*f.DueAfter)) {
+		return false
+	}
+	if f.DueBefore != nil && (task.DueDate == nil || !task.DueDate.Before(*f.DueBefore)) {
+		return false
+	}
+	if (f.DatedAfter != nil || f.DatedBefore != nil) && !f.datedWithin(task.DueDate) && !f.datedWithin(task.ScheduledDate) {
+		return false
+	}
+	if f.Energy != "" && !strings.EqualFold(task.EnergyRequired, f.Energy) {
+		return false
+	}
+	if f.Priority != 0 && task.Priority != f.Priority {
+		return false
+	}
+	return true
+}
+
+// datedWithin reports whether a task date falls between DatedAfter and DatedBefore
+func (f TaskFilter) datedWithin(date *time.Time) bool {
+	if date == nil {
+		return false
+	}
+	if f.DatedAfter != nil && date.Before(*f.DatedAfter) {
+		return false
+	}
+	if f.DatedBefore != nil && !date.Before(*f.DatedBefore) {
+		return false
+	}
+	return true
+}
+
+// pageLimit returns the effective page size for a query
+func (q TaskQuery) pageLimit() int {
+	if q.Limit <= 0 {
+		return DefaultPageSize
+	}
+	if q.Limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return q.Limit
+}
+
+// QueryAll follows a query's cursors until every matching task has been loaded, ignoring its
+// cursor and page size
+func QueryAll(ctx context.Context, tasks TaskStore, q TaskQuery) ([]*Task, error) {
+	q.Cursor = ""
+	q.Limit = MaxPageSize
+
+	var all []*Task
+	for {
+		page, err := tasks.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Tasks...)
+		if page.NextCursor == "" {
+			return all, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// order returns the query's sort order, falling back to the default for an unknown one
+func (q TaskQuery) order() TaskSort {
+	return ParseTaskSort(string(q.Sort))
+}
+
+// taskCursor points just past the last task of a page: the next page starts after the task with this
+// sort key and ID, so tasks added or removed in between don't shift the pages
+type taskCursor struct {
+	Sort TaskSort `json:"s"`
+	Key  *string  `json:"k,omitempty"` // Sort key of the last task; nil when it has no due date or priority
+	ID   string   `json:"id"`
+}
+
+// taskSortKey returns a task's key for a sort order, or nil when the task sorts last for lacking one
+func taskSortKey(task *Task, order TaskSort) *string {
+	var key string
+	switch order {
+	case SortCreatedAsc, SortCreatedDesc:
+		key = task.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case SortUpdatedDesc:
+		key = task.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case SortDueAsc:
+		if task.DueDate == nil {
+			return nil
+		}
+		key = task.DueDate.UTC().Format(time.RFC3339Nano)
+	case SortPriorityAsc:
+		if task.Priority == 0 {
+			return nil
+		}
+		key = strconv.Itoa(task.Priority)
+	case SortTitleAsc:
+		key = strings.ToLower(task.Title)
+	}
+	return &key
+}
+
+// encodeCursor creates an opaque cursor pointing just past the given task
+func encodeCursor(task *Task, order TaskSort) string {
+	data, _ := json.Marshal(taskCursor{Sort: order, Key: taskSortKey(task, order), ID: task.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the position stored in a cursor, or nil for an empty cursor.
+// A cursor handed out for another sort order is rejected.
+func decodeCursor(cursor string, order TaskSort) (*taskCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c taskCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" || c.Sort != order {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := c.probe(); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// probe returns a task holding only the cursor's sort key and ID, so it sorts where the last task
+// of the previous page did
+func (c *taskCursor) probe() (*Task, error) {
+	task := &Task{ID: c.ID}
+	if c.Key == nil {
+		if c.Sort != SortDueAsc && c.Sort != SortPriorityAsc {
+			return nil, ErrInvalidCursor
+		}
+		return task, nil
+	}
+
+	key := *c.Key
+	switch c.Sort {
+	case SortCreatedAsc, SortCreatedDesc, SortUpdatedDesc, SortDueAsc:
+		t, err := time.Parse(time.RFC3339Nano, key)
+		if err != nil {
+			return nil, err
+		}
+		switch c.Sort {
+		case SortUpdatedDesc:
+			task.UpdatedAt = t
+		case SortDueAsc:
+			task.DueDate = &t
+		default:
+			task.CreatedAt = t
+		}
+	case SortPriorityAsc:
+		priority, err := strconv.Atoi(key)
+		if err != nil || priority == 0 {
+			return nil, ErrInvalidCursor
+		}
+		task.Priority = priority
+	case SortTitleAsc:
+		task.Title = key
+	}
+	return task, nil
+}
+
+// sortTasks orders tasks in place; ties are broken by ID so pages are stable
+func sortTasks(tasks []*Task, order TaskSort) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return taskLess(tasks[i], tasks[j], order)
+	})
+}
+
+// taskLess reports whether task a sorts before task b
+func taskLess(a, b *Task, order TaskSort) bool {
+	switch order {
+	case SortCreatedAsc:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+	case SortUpdatedDesc:
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.After(b.UpdatedAt)
+		}
+	case SortDueAsc:
+		if (a.DueDate == nil) != (b.DueDate == nil) {
+			return a.DueDate != nil
+		}
+		if a.DueDate != nil && !a.DueDate.Equal(*b.DueDate) {
+			return a.DueDate.Before(*b.DueDate)
+		}
+	case SortPriorityAsc:
+		if (a.Priority == 0) != (b.Priority == 0) {
+			return a.Priority != 0
+		}
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+	case SortTitleAsc:
+		at, bt := strings.ToLower(a.Title), strings.ToLower(b.Title)
+		if at != bt {
+			return at < bt
+		}
+	default:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+	}
+	return a.ID < b.ID
+}
@@ -1,38 +1,107 @@
 package models
 
 import (
+	"context"
 	"errors"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
-// TaskStore defines the interface for task storage operations
+// ErrTaskNotFound is returned when a task doesn't exist, was deleted, or belongs to another user
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrTaskConflict is returned when a task is saved from a copy that is older than the stored version
+var ErrTaskConflict = errors.New("task was changed since it was loaded")
+
+// nextVersion checks that a task is saved over the version it was loaded at and returns the version
+// it is stored with. With force the stored version is replaced whatever it is.
+func nextVersion(stored, task *Task, force bool) (int, error) {
+	if stored == nil {
+		return task.Version + 1, nil
+	}
+	if task.Version != stored.Version && !force {
+		return 0, ErrTaskConflict
+	}
+	return stored.Version + 1, nil
+}
+
+// TaskStore defines the interface for task storage operations.
+// Save and SaveForUser reject a task whose Version no longer matches the stored one with ErrTaskConflict,
+// and bump the version of every task they write.
 type TaskStore interface {
 	Get(id string) (*Task, error)
+	GetForUser(id string, userID string) (*Task, error)
 	GetAll() ([]*Task, error)
 	GetAllByUserID(userID string) ([]*Task, error)
 	GetByStatus(status TaskStatus) ([]*Task, error)
 	GetByStatusAndUserID(status TaskStatus, userID string) ([]*Task, error)
 	Search(query string) ([]*Task, error)
 	SearchByUserID(query string, userID string) ([]*Task, error)
+	// SearchTasks runs a parsed search over a user's tasks, best matches first
+	SearchTasks(ctx context.Context, userID string, q SearchQuery) ([]*SearchResult, error)
+	// CountSearchTasks counts the tasks a parsed search matches, ignoring its limit
+	CountSearchTasks(ctx context.Context, userID string, q SearchQuery) (int, error)
+	Query(ctx context.Context, q TaskQuery) (*TaskPage, error)
+
+	// Sub-tasks: GetChildren returns the direct children of a user's task, GetSubtree every
+	// descendant ordered by depth. Both are oldest first within a level and skip deleted tasks.
+	GetChildren(ctx context.Context, parentID string, userID string) ([]*Task, error)
+	GetSubtree(ctx context.Context, rootID string, userID string) ([]*Task, error)
+
 	Save(task *Task) error
+	SaveForUser(task *Task, userID string) error
+	// ReplaceForUser writes a user's task over whatever version is stored. It is meant for restoring
+	// backups, where the archived copy wins; everything else saves at the loaded version.
+	ReplaceForUser(task *Task, userID string) error
 	Delete(id string) error
+
+	// Trash: soft-deleted tasks can be listed, restored or removed for good. The history is
+	// append-only, so a purged task's events stay behind as its audit trail.
+	ListDeleted(ctx context.Context, userID string) ([]*Task, error) // Most recently deleted first
+	Restore(id string, userID string) error
+	Purge(id string, userID string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error) // Every user's tasks deleted before the cutoff
+
+	// History returns the events recorded for a user's task, oldest first
+	History(ctx context.Context, taskID string, userID string) ([]*TaskEvent, error)
 }
 
 // MemoryTaskStore implements TaskStore interface with in-memory storage
-// This is a simple implementation for development - in production you'd use a database
+// This is a simple implementation for development - in production you'd use a database.
+// Like a database it hands out copies, so a task only changes when it is saved.
 type MemoryTaskStore struct {
-	tasks map[string]*Task
-	mutex sync.RWMutex
+	tasks  map[string]*Task
+	events map[string][]*TaskEvent // History by task ID
+	mutex  sync.RWMutex
 }
 
 // NewMemoryTaskStore creates a new in-memory task store
 func NewMemoryTaskStore() *MemoryTaskStore {
 	return &MemoryTaskStore{
-		tasks: make(map[string]*Task),
+		tasks:  make(map[string]*Task),
+		events: make(map[string][]*TaskEvent),
 	}
 }
 
+// write stores a task in place of its previous version, appends what changed to its history and
+// returns those events. Callers must hold the write lock.
+func (s *MemoryTaskStore) write(previous, task *Task, userID string) []*TaskEvent {
+	changes := TaskChanges(previous, task, userID, time.Now())
+	s.events[task.ID] = append(s.events[task.ID], changes...)
+	s.tasks[task.ID] = task
+	return changes
+}
+
+// copies returns a copy of each task, so callers can't change the stored ones
+func copies(tasks []*Task) []*Task {
+	for i, task := range tasks {
+		tasks[i] = task.clone()
+	}
+	return tasks
+}
+
 // Get retrieves a task by ID
 func (s *MemoryTaskStore) Get(id string) (*Task, error) {
 	s.mutex.RLock()
@@ -40,12 +109,26 @@ func (s *MemoryTaskStore) Get(id string) (*Task, error) {
 
 	task, ok := s.tasks[id]
 	if !ok {
-		return nil, errors.New("task not found")
+		return nil, ErrTaskNotFound
 	}
 
 	// Don't return soft-deleted tasks
 	if task.IsDeleted() {
-		return nil, errors.New("task not found")
+		return nil, ErrTaskNotFound
+	}
+
+	return task.clone(), nil
+}
+
+// GetForUser retrieves a task by ID, hiding tasks owned by other users
+func (s *MemoryTaskStore) GetForUser(id string, userID string) (*Task, error) {
+	task, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.UserID != userID {
+		return nil, ErrTaskNotFound
 	}
 
 	return task, nil
@@ -63,7 +146,7 @@ func (s *MemoryTaskStore) GetAll() ([]*Task, error) {
 		}
 	}
 
-	return result, nil
+	return copies(result), nil
 }
 
 // GetAllByUserID returns all non-deleted tasks for a specific user
@@ -78,7 +161,7 @@ func (s *MemoryTaskStore) GetAllByUserID(userID string) ([]*Task, error) {
 		}
 	}
 
-	return result, nil
+	return copies(result), nil
 }
 
 // GetByStatus returns all tasks with the specified status
@@ -93,7 +176,7 @@ func (s *MemoryTaskStore) GetByStatus(status TaskStatus) ([]*Task, error) {
 		}
 	}
 
-	return result, nil
+	return copies(result), nil
 }
 
 // GetByStatusAndUserID returns all tasks with the specified status for a specific user
@@ -108,7 +191,96 @@ func (s *MemoryTaskStore) GetByStatusAndUserID(status TaskStatus, userID string)
 		}
 	}
 
-	return result, nil
+	return copies(result), nil
+}
+
+// Query returns a single page of tasks matching the query's filter, in the requested order
+func (s *MemoryTaskStore) Query(ctx context.Context, q TaskQuery) (*TaskPage, error) {
+	order := q.order()
+	cursor, err := decodeCursor(q.Cursor, order)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	lookup := func(id string) *Task { return s.tasks[id] }
+	var matched []*Task
+	for _, task := range s.tasks {
+		if q.Filter.Matches(task) && !(q.Filter.Unblocked && hasOpenBlocker(task, lookup)) {
+			matched = append(matched, task)
+		}
+	}
+	s.mutex.RUnlock()
+
+	sortTasks(matched, order)
+
+	start := 0
+	if cursor != nil {
+		last, _ := cursor.probe()
+		start = sort.Search(len(matched), func(i int) bool {
+			return taskLess(last, matched[i], order)
+		})
+	}
+
+	page := &TaskPage{Tasks: []*Task{}}
+	if start >= len(matched) {
+		return page, nil
+	}
+
+	end := start + q.pageLimit()
+	if end < len(matched) {
+		page.NextCursor = encodeCursor(matched[end-1], order)
+	} else {
+		end = len(matched)
+	}
+	page.Tasks = copies(matched[start:end])
+
+	return page, nil
+}
+
+// GetChildren returns the direct sub-tasks of a user's task, oldest first
+func (s *MemoryTaskStore) GetChildren(ctx context.Context, parentID string, userID string) ([]*Task, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return copies(s.children(parentID, userID)), nil
+}
+
+// GetSubtree returns every descendant of a user's task, level by level
+func (s *MemoryTaskStore) GetSubtree(ctx context.Context, rootID string, userID string) ([]*Task, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	subtree := []*Task{}
+	seen := map[string]bool{rootID: true}
+	level := []string{rootID}
+	for depth := 0; len(level) > 0 && depth < maxSubtreeDepth; depth++ {
+		var next []string
+		for _, parentID := range level {
+			for _, child := range s.children(parentID, userID) {
+				if !seen[child.ID] {
+					seen[child.ID] = true
+					subtree = append(subtree, child)
+					next = append(next, child.ID)
+				}
+			}
+		}
+		level = next
+	}
+
+	return copies(subtree), nil
+}
+
+// children returns the stored sub-tasks of a task, oldest first. Callers must hold the lock.
+func (s *MemoryTaskStore) children(parentID string, userID string) []*Task {
+	children := []*Task{}
+	for _, task := range s.tasks {
+		if task.ParentID == parentID && task.UserID == userID && !task.IsDeleted() {
+			children = append(children, task)
+		}
+	}
+	sortTasks(children, SortCreatedAsc)
+	return children
 }
 
 // Save creates or updates a task
@@ -120,7 +292,51 @@ func (s *MemoryTaskStore) Save(task *Task) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	s.tasks[task.ID] = task
+	return s.save(task, task.UserID, false)
+}
+
+// SaveForUser creates or updates a task on behalf of a user.
+// It refuses to write tasks owned by someone else or to hand a task over to another user.
+func (s *MemoryTaskStore) SaveForUser(task *Task, userID string) error {
+	return s.saveForUser(task, userID, false)
+}
+
+// ReplaceForUser writes a user's task over whatever version is stored
+func (s *MemoryTaskStore) ReplaceForUser(task *Task, userID string) error {
+	return s.saveForUser(task, userID, true)
+}
+
+// saveForUser validates a task and saves it if it belongs to the user
+func (s *MemoryTaskStore) saveForUser(task *Task, userID string, force bool) error {
+	if task.UserID != userID {
+		return ErrTaskNotFound
+	}
+
+	if err := task.Validate(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if existing, ok := s.tasks[task.ID]; ok && existing.UserID != userID {
+		return ErrTaskNotFound
+	}
+
+	return s.save(task, userID, force)
+}
+
+// save writes a copy of the task if it is based on the stored version, or with force over any version,
+// and gives the caller's task the new version. Callers must hold the write lock.
+func (s *MemoryTaskStore) save(task *Task, userID string, force bool) error {
+	previous := s.tasks[task.ID]
+	version, err := nextVersion(previous, task, force)
+	if err != nil {
+		return err
+	}
+
+	task.Version = version
+	task.undo.recordEvents(s.write(previous, task.clone(), userID))
 	return nil
 }
 
@@ -129,59 +345,106 @@ func (s *MemoryTaskStore) Delete(id string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	task, ok := s.tasks[id]
+	previous, ok := s.tasks[id]
 	if !ok {
-		return errors.New("task not found")
+		return ErrTaskNotFound
 	}
 
+	task := previous.clone()
 	task.Delete()
+	task.Version++
+	s.write(previous, task, task.UserID)
 	return nil
 }
 
-// Search finds tasks that match the given query in title or description
-func (s *MemoryTaskStore) Search(query string) ([]*Task, error) {
+// ListDeleted returns a user's soft-deleted tasks, most recently deleted first
+func (s *MemoryTaskStore) ListDeleted(ctx context.Context, userID string) ([]*Task, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	var result []*Task
-
-	// Convert query to lowercase for case-insensitive search
-	lowerQuery := strings.ToLower(query)
-
+	tasks := []*Task{}
 	for _, task := range s.tasks {
-		// Skip deleted tasks
-		if task.IsDeleted() {
-			continue
+		if task.IsDeleted() && task.UserID == userID {
+			tasks = append(tasks, task)
 		}
+	}
 
-		// Check if query is in title or description (case insensitive)
-		if strings.Contains(strings.ToLower(task.Title), lowerQuery) ||
-			strings.Contains(strings.ToLower(task.Description), lowerQuery) {
-			result = append(result, task)
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DeletedAt.Equal(*tasks[j].DeletedAt) {
+			return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
 		}
+		return tasks[i].ID < tasks[j].ID
+	})
 
-		// Also search in contexts and tags
-		for _, context := range task.Contexts {
-			if strings.Contains(strings.ToLower(string(context)), lowerQuery) {
-				result = append(result, task)
-				break // No need to check other contexts
-			}
+	return copies(tasks), nil
+}
+
+// Restore brings back a soft-deleted task
+func (s *MemoryTaskStore) Restore(id string, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, ok := s.tasks[id]
+	if !ok || !previous.IsDeleted() || previous.UserID != userID {
+		return ErrTaskNotFound
+	}
+
+	task := previous.clone()
+	task.DeletedAt = nil
+	task.UpdatedAt = time.Now()
+	task.Version++
+	s.write(previous, task, userID)
+	return nil
+}
+
+// Purge permanently removes a soft-deleted task; its history is kept
+func (s *MemoryTaskStore) Purge(id string, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok || !task.IsDeleted() || task.UserID != userID {
+		return ErrTaskNotFound
+	}
+
+	delete(s.tasks, id)
+	return nil
+}
+
+// PurgeDeleted permanently removes all tasks deleted before the cutoff and returns how many were removed;
+// their history is kept
+func (s *MemoryTaskStore) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	purged := 0
+	for id, task := range s.tasks {
+		if task.IsDeleted() && task.DeletedAt.Before(before) {
+			delete(s.tasks, id)
+			purged++
 		}
+	}
 
-		// Check tags
-		for _, tag := range task.Tags {
-			if strings.Contains(strings.ToLower(tag), lowerQuery) {
-				result = append(result, task)
-				break // No need to check other tags
-			}
+	return purged, nil
+}
+
+// History returns the events recorded for a user's task, oldest first
+func (s *MemoryTaskStore) History(ctx context.Context, taskID string, userID string) ([]*TaskEvent, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	events := []*TaskEvent{}
+	for _, event := range s.events[taskID] {
+		if event.UserID == userID {
+			events = append(events, event)
 		}
 	}
 
-	return result, nil
+	return events, nil
 }
 
-// SearchByUserID finds tasks for a specific user that match the query in title, description, contexts, or tags
-func (s *MemoryTaskStore) SearchByUserID(query string, userID string) ([]*Task, error) {
+// Search finds tasks that match the given query in title, description, contexts or tags
+func (s *MemoryTaskStore) Search(query string) ([]*Task, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
@@ -191,33 +454,71 @@ func (s *MemoryTaskStore) SearchByUserID(query string, userID string) ([]*Task,
 	lowerQuery := strings.ToLower(query)
 
 	for _, task := range s.tasks {
-		// Skip deleted tasks and tasks not belonging to the user
-		if task.IsDeleted() || task.UserID != userID {
+		// Skip deleted tasks
+		if task.IsDeleted() {
 			continue
 		}
 
-		// Check if query is in title or description (case insensitive)
-		if strings.Contains(strings.ToLower(task.Title), lowerQuery) ||
-			strings.Contains(strings.ToLower(task.Description), lowerQuery) {
+		// Check title and description (case insensitive), then contexts and tags; each task is added once
+		matched := strings.Contains(strings.ToLower(task.Title), lowerQuery) ||
+			strings.Contains(strings.ToLower(task.Description), lowerQuery)
+		for _, context := range task.Contexts {
+			matched = matched || strings.Contains(strings.ToLower(string(context)), lowerQuery)
+		}
+		for _, tag := range task.Tags {
+			matched = matched || strings.Contains(strings.ToLower(tag), lowerQuery)
+		}
+		if matched {
 			result = append(result, task)
 		}
+	}
 
-		// Also search in contexts and tags
-		for _, context := range task.Contexts {
-			if strings.Contains(strings.ToLower(string(context)), lowerQuery) {
-				result = append(result, task)
-				break // No need to check other contexts
-			}
-		}
+	return copies(result), nil
+}
 
-		// Check tags
-		for _, tag := range task.Tags {
-			if strings.Contains(strings.ToLower(tag), lowerQuery) {
-				result = append(result, task)
-				break // No need to check other tags
-			}
+// SearchByUserID finds a user's tasks matching a search in the syntax of ParseSearchQuery, best matches first
+func (s *MemoryTaskStore) SearchByUserID(query string, userID string) ([]*Task, error) {
+	return searchTasks(s, query, userID)
+}
+
+// SearchTasks evaluates a parsed search against a user's tasks, best matches first
+func (s *MemoryTaskStore) SearchTasks(ctx context.Context, userID string, q SearchQuery) ([]*SearchResult, error) {
+	s.mutex.RLock()
+	var results []*SearchResult
+	for _, task := range s.tasks {
+		if task.IsDeleted() || task.UserID != userID {
+			continue
 		}
+		if rank, ok := q.Match(task); ok {
+			results = append(results, &SearchResult{Task: task, Rank: rank})
+		}
+	}
+	s.mutex.RUnlock()
+
+	sortSearchResults(results)
+	if len(results) > q.limit() {
+		results = results[:q.limit()]
 	}
+	for i, result := range results {
+		results[i] = newSearchResult(result.Task.clone(), result.Rank, q)
+	}
+
+	return results, nil
+}
 
-	return result, nil
+// CountSearchTasks counts the user's tasks matching a parsed search
+func (s *MemoryTaskStore) CountSearchTasks(ctx context.Context, userID string, q SearchQuery) (int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	count := 0
+	for _, task := range s.tasks {
+		if task.IsDeleted() || task.UserID != userID {
+			continue
+		}
+		if _, ok := q.Match(task); ok {
+			count++
+		}
+	}
+	return count, nil
 }
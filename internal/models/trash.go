@@ -0,0 +1,166 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// DeleteProject soft-deletes a project. With cascade, the project's tasks are deleted along with
+// it and stamped with the same deletion time, which is how RestoreProject and PurgeProject find
+// them again. Without it, the tasks stay and no longer belong to any project. It returns the number
+// of tasks deleted.
+func DeleteProject(ctx context.Context, tasks TaskStore, projects ProjectStore, project *Project, userID string, cascade bool) (int, error) {
+	// Postgres keeps microseconds, so the shared stamp still matches after a round trip
+	now := time.Now().Truncate(time.Microsecond)
+	project.DeletedAt = &now
+	project.UpdatedAt = now
+	if err := projects.SaveForUser(project, userID); err != nil {
+		return 0, err
+	}
+
+	// Collect every task first, so the query isn't paging over tasks as they are changed
+	projectTasks, err := QueryAll(ctx, tasks, TaskQuery{
+		Filter: TaskFilter{UserID: userID, ProjectID: project.ID},
+		Sort:   SortCreatedAsc,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, task := range projectTasks {
+		task.UpdatedAt = now
+		if cascade {
+			deletedAt := now
+			task.DeletedAt = &deletedAt
+		} else {
+			task.ProjectID = ""
+		}
+		if err := tasks.SaveForUser(task, userID); err != nil {
+			return deleted, err
+		}
+		if cascade {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// deletedProject finds a project in the user's trash
+func deletedProject(ctx context.Context, projects ProjectStore, id string, userID string) (*Project, error) {
+	deleted, err := projects.ListDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range deleted {
+		if project.ID == id {
+			return project, nil
+		}
+	}
+	return nil, ErrProjectNotFound
+}
+
+// tasksDeletedWith returns the trashed tasks that were deleted together with a project
+func tasksDeletedWith(ctx context.Context, tasks TaskStore, project *Project, userID string) ([]*Task, error) {
+	deleted, err := tasks.ListDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*Task
+	for _, task := range deleted {
+		if task.ProjectID == project.ID && task.DeletedAt.Equal(*project.DeletedAt) {
+			matched = append(matched, task)
+		}
+	}
+	return matched, nil
+}
+
+// RestoreProject brings back a deleted project. With cascade, the tasks that were deleted together
+// with it are restored as well. It returns the number of tasks restored.
+func RestoreProject(ctx context.Context, tasks TaskStore, projects ProjectStore, id string, userID string, cascade bool) (int, error) {
+	project, err := deletedProject(ctx, projects, id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Find the tasks before restoring, which clears the project's deletion time
+	var projectTasks []*Task
+	if cascade {
+		if projectTasks, err = tasksDeletedWith(ctx, tasks, project, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := projects.Restore(id, userID); err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, task := range projectTasks {
+		if err := tasks.Restore(task.ID, userID); err != nil {
+			return restored, err
+		}
+		restored++
+	}
+	return restored, nil
+}
+
+// PurgeProject permanently removes a deleted project. With cascade, the tasks that were deleted
+// together with it are removed as well. It returns the number of tasks removed.
+func PurgeProject(ctx context.Context, tasks TaskStore, projects ProjectStore, id string, userID string, cascade bool) (int, error) {
+	project, err := deletedProject(ctx, projects, id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var projectTasks []*Task
+	if cascade {
+		if projectTasks, err = tasksDeletedWith(ctx, tasks, project, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := projects.Purge(id, userID); err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, task := range projectTasks {
+		if err := tasks.Purge(task.ID, userID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// EmptyTrash permanently removes all of a user's deleted tasks and projects and returns how many
+// of each were removed
+func EmptyTrash(ctx context.Context, tasks TaskStore, projects ProjectStore, userID string) (int, int, error) {
+	deletedTasks, err := tasks.ListDeleted(ctx, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	purgedTasks := 0
+	for _, task := range deletedTasks {
+		if err := tasks.Purge(task.ID, userID); err != nil {
+			return purgedTasks, 0, err
+		}
+		purgedTasks++
+	}
+
+	deletedProjects, err := projects.ListDeleted(ctx, userID)
+	if err != nil {
+		return purgedTasks, 0, err
+	}
+	purgedProjects := 0
+	for _, project := range deletedProjects {
+		if err := projects.Purge(project.ID, userID); err != nil {
+			return purgedTasks, purgedProjects, err
+		}
+		purgedProjects++
+	}
+
+	return purgedTasks, purgedProjects, nil
+}
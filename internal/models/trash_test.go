@@ -0,0 +1,38 @@
+package models
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDeleteProjectWithoutCascadeKeepsTasks(t *testing.T) {
+	ctx := context.Background()
+	tasks := NewMemoryTaskStore()
+	projects := NewMemoryProjectStore()
+
+	project := NewProject("Plan the trip", "", "alice")
+	if err := projects.SaveForUser(project, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	task := NewTask("Book flights", "", "alice")
+	task.ProjectID = project.ID
+	if err := tasks.SaveForUser(task, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := DeleteProject(ctx, tasks, projects, project, "alice", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 0 {
+		t.Errorf("deleted %d tasks, want 0", deleted)
+	}
+
+	stored, err := tasks.GetForUser(task.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ProjectID != "" {
+		t.Errorf("project = %q, want the task out of the deleted project", stored.ProjectID)
+	}
+}
@@ -0,0 +1,321 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUndoNotFound is returned when an undo token is unknown, expired, already used or belongs to another user
+var ErrUndoNotFound = errors.New("nothing to undo")
+
+// ErrUndoUnavailable is returned when a task changed by the action has since been removed for good
+var ErrUndoUnavailable = errors.New("the change can no longer be undone")
+
+// UndoAction is a single user action that can be reverted. It doesn't copy the tasks it changed:
+// the history events its own saves wrote tell what the action did, so edits made meanwhile by
+// another tab or client aren't reverted with it.
+type UndoAction struct {
+	Token     string    `json:"token"`
+	UserID    string    `json:"-"`
+	Label     string    `json:"label"` // What the action did, e.g. "Task marked as Done"
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	TaskIDs []string `json:"-"` // Tasks the action changed
+	Created []string `json:"-"` // Tasks the action created
+	Events  []string `json:"-"` // History events written by saving the recorded tasks
+}
+
+// Record notes that the action changes a task. Call it before saving the task: saving this copy
+// records the history events it writes on the action. A nil action records nothing.
+func (a *UndoAction) Record(task *Task) {
+	if a == nil {
+		return
+	}
+	task.undo = a
+	for _, id := range a.TaskIDs {
+		if id == task.ID {
+			return
+		}
+	}
+	a.TaskIDs = append(a.TaskIDs, task.ID)
+}
+
+// recordEvents notes history events written for the action; a nil action ignores them
+func (a *UndoAction) recordEvents(events []*TaskEvent) {
+	if a == nil {
+		return
+	}
+	for _, event := range events {
+		a.Events = append(a.Events, event.ID)
+	}
+}
+
+// RecordCreated remembers a task the action created, so undoing the action deletes it
+func (a *UndoAction) RecordCreated(task *Task) {
+	a.Created = append(a.Created, task.ID)
+}
+
+// Empty reports whether the action recorded no mutations
+func (a *UndoAction) Empty() bool {
+	return len(a.TaskIDs) == 0 && len(a.Created) == 0
+}
+
+// TaskCount returns how many tasks the action changed or created
+func (a *UndoAction) TaskCount() int {
+	return len(a.TaskIDs) + len(a.Created)
+}
+
+// UndoStack keeps each user's recent actions so they can be reverted within a time window.
+// Only the newest actions are kept; older ones fall off the bottom of the stack.
+type UndoStack struct {
+	store  UndoStore
+	window time.Duration
+	depth  int
+	now    func() time.Time
+}
+
+// NewUndoStack creates a stack that keeps up to depth actions per user in store for the given window
+func NewUndoStack(store UndoStore, window time.Duration, depth int) *UndoStack {
+	return &UndoStack{
+		store:  store,
+		window: window,
+		depth:  depth,
+		now:    time.Now,
+	}
+}
+
+// Begin starts recording a user action; hand it to Push once the action succeeded
+func (s *UndoStack) Begin(userID string, label string) *UndoAction {
+	return &UndoAction{
+		Token:  GenerateID(),
+		UserID: userID,
+		Label:  label,
+	}
+}
+
+// Push makes a recorded action undoable and returns its token, or "" if the action changed nothing
+func (s *UndoStack) Push(action *UndoAction) (string, error) {
+	if action.Empty() {
+		return "", nil
+	}
+
+	action.CreatedAt = s.now()
+	action.ExpiresAt = action.CreatedAt.Add(s.window)
+	if err := s.store.Push(action, s.depth); err != nil {
+		return "", err
+	}
+
+	return action.Token, nil
+}
+
+// List returns the user's actions that can still be undone, newest first
+func (s *UndoStack) List(userID string) ([]*UndoAction, error) {
+	return s.store.List(userID, s.now())
+}
+
+// Undo reverts the action with the given token: created tasks are deleted, deleted ones come back
+// from the trash and the fields the action changed are set back. A field edited again since then
+// makes the undo fail with ErrTaskConflict. The token can be used once; when reverting fails it stays
+// on the stack so the undo can be retried.
+func (s *UndoStack) Undo(ctx context.Context, tasks TaskStore, token string, userID string) (*UndoAction, error) {
+	action, err := s.store.Take(token, userID, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := revertAction(ctx, tasks, action); err != nil {
+		if err == ErrUndoUnavailable {
+			return nil, err
+		}
+		if pushErr := s.store.Push(action, s.depth); pushErr != nil {
+			return nil, fmt.Errorf("%v (the undo could not be kept for a retry: %v)", err, pushErr)
+		}
+		return nil, err
+	}
+
+	return action, nil
+}
+
+// undoStep is how one task changed by an action is put back
+type undoStep struct {
+	taskID  string
+	restore bool                  // The action moved the task to the trash
+	fields  map[string]*TaskEvent // The action's first event on each field, holding its old value
+	last    map[string]*TaskEvent // The action's last event on each field, holding its new value
+	order   []string              // Fields in the order they were first changed
+}
+
+// revertAction checks every task the action changed before writing any of them, so a task edited
+// since the action doesn't leave the undo half done. Reverting is safe to repeat after a failure:
+// work already undone is skipped.
+func revertAction(ctx context.Context, tasks TaskStore, action *UndoAction) error {
+	steps := make([]*undoStep, 0, len(action.TaskIDs))
+	for _, id := range action.TaskIDs {
+		step, err := planUndoStep(ctx, tasks, action, id)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, step)
+	}
+
+	for _, id := range action.Created {
+		if _, err := tasks.GetForUser(id, action.UserID); err != nil {
+			if err == ErrTaskNotFound {
+				continue // Already deleted
+			}
+			return err
+		}
+		if err := tasks.Delete(id); err != nil {
+			return err
+		}
+	}
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		if err := applyUndoStep(ctx, tasks, action.UserID, steps[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// planUndoStep reads the events an action wrote on a task and checks that none of the changed
+// fields were edited since
+func planUndoStep(ctx context.Context, tasks TaskStore, action *UndoAction, taskID string) (*undoStep, error) {
+	history, err := tasks.History(ctx, taskID, action.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	written := make(map[string]bool, len(action.Events))
+	for _, id := range action.Events {
+		written[id] = true
+	}
+
+	step := &undoStep{taskID: taskID, fields: make(map[string]*TaskEvent), last: make(map[string]*TaskEvent)}
+	for _, event := range history {
+		if !written[event.ID] {
+			continue
+		}
+		switch event.Type {
+		case EventDeleted:
+			step.restore = true
+		case EventRestored:
+			step.restore = false
+		case EventStatusChanged, EventProjectMoved, EventUpdated:
+			if _, ok := step.fields[event.Field]; !ok {
+				step.fields[event.Field] = event
+				step.order = append(step.order, event.Field)
+			}
+			step.last[event.Field] = event
+		}
+	}
+
+	current, err := loadTaskForUndo(ctx, tasks, taskID, action.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range step.order {
+		done, err := fieldUndone(current, field, step.fields[field].Before, step.last[field].After)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			delete(step.fields, field)
+		}
+	}
+
+	return step, nil
+}
+
+// fieldUndone reports whether a field already holds its value from before the action. It fails with
+// ErrTaskConflict when the field holds neither that value nor the one the action left.
+func fieldUndone(task *Task, field string, before, after []byte) (bool, error) {
+	matchesBefore, err := hasEventFieldValue(task, field, before)
+	if err != nil {
+		return false, err
+	}
+	if matchesBefore {
+		return true, nil
+	}
+
+	matchesAfter, err := hasEventFieldValue(task, field, after)
+	if err != nil {
+		return false, err
+	}
+	if !matchesAfter {
+		return false, ErrTaskConflict
+	}
+	return false, nil
+}
+
+// applyUndoStep brings a task back from the trash if the action deleted it and sets back the fields
+// the action changed, saving over the version just loaded
+func applyUndoStep(ctx context.Context, tasks TaskStore, userID string, step *undoStep) error {
+	task, err := loadTaskForUndo(ctx, tasks, step.taskID, userID)
+	if err != nil {
+		return err
+	}
+
+	if step.restore && task.IsDeleted() {
+		if err := tasks.Restore(task.ID, userID); err != nil {
+			if err == ErrTaskNotFound {
+				return ErrUndoUnavailable
+			}
+			return err
+		}
+		if task, err = tasks.GetForUser(task.ID, userID); err != nil {
+			return err
+		}
+	}
+
+	if len(step.fields) == 0 {
+		return nil
+	}
+
+	for _, field := range step.order {
+		if event, ok := step.fields[field]; ok {
+			if err := setEventFieldValue(task, field, event.Before); err != nil {
+				return err
+			}
+		}
+	}
+	if _, ok := step.fields["status"]; ok {
+		// The completion time isn't part of the history; it follows the status
+		if task.Status != StatusDone {
+			task.CompletedAt = nil
+		} else if task.CompletedAt == nil {
+			now := time.Now()
+			task.CompletedAt = &now
+		}
+	}
+	task.UpdatedAt = time.Now()
+
+	return tasks.SaveForUser(task, userID)
+}
+
+// loadTaskForUndo loads a user's task whether or not it is in the trash. Tasks removed for good
+// can't be undone.
+func loadTaskForUndo(ctx context.Context, tasks TaskStore, taskID string, userID string) (*Task, error) {
+	task, err := tasks.GetForUser(taskID, userID)
+	if err == nil {
+		return task, nil
+	}
+	if err != ErrTaskNotFound {
+		return nil, err
+	}
+
+	deleted, err := tasks.ListDeleted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range deleted {
+		if task.ID == taskID {
+			return task, nil
+		}
+	}
+	return nil, ErrUndoUnavailable
+}
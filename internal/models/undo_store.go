@@ -0,0 +1,101 @@
+package models
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// UndoStore keeps the actions users can undo
+type UndoStore interface {
+	// Push saves an action and drops the user's expired actions and those beyond the newest depth
+	Push(action *UndoAction, depth int) error
+	// List returns the user's actions that haven't expired by now, newest first
+	List(userID string, now time.Time) ([]*UndoAction, error)
+	// Take removes an action that hasn't expired by now and returns it, so each token is used once
+	Take(token string, userID string, now time.Time) (*UndoAction, error)
+}
+
+// MemoryUndoStore implements UndoStore with in-memory storage
+type MemoryUndoStore struct {
+	actions map[string][]*UndoAction // By user, oldest first
+	mutex   sync.Mutex
+}
+
+// NewMemoryUndoStore creates a new in-memory undo store
+func NewMemoryUndoStore() *MemoryUndoStore {
+	return &MemoryUndoStore{
+		actions: make(map[string][]*UndoAction),
+	}
+}
+
+// copyUndoAction returns a copy of an action, so callers can't change the stored one
+func copyUndoAction(action *UndoAction) *UndoAction {
+	c := *action
+	c.TaskIDs = append([]string(nil), action.TaskIDs...)
+	c.Created = append([]string(nil), action.Created...)
+	c.Events = append([]string(nil), action.Events...)
+	return &c
+}
+
+// Push saves an action and drops the user's expired actions and those beyond the newest depth
+func (s *MemoryUndoStore) Push(action *UndoAction, depth int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	actions := append(s.live(action.UserID, action.CreatedAt), copyUndoAction(action))
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].CreatedAt.Before(actions[j].CreatedAt)
+	})
+	if len(actions) > depth {
+		actions = actions[len(actions)-depth:]
+	}
+	s.actions[action.UserID] = actions
+
+	return nil
+}
+
+// List returns the user's actions that haven't expired by now, newest first
+func (s *MemoryUndoStore) List(userID string, now time.Time) ([]*UndoAction, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	live := s.live(userID, now)
+	actions := make([]*UndoAction, len(live))
+	for i, action := range live {
+		actions[len(live)-1-i] = copyUndoAction(action)
+	}
+	return actions, nil
+}
+
+// Take removes an action that hasn't expired by now and returns it
+func (s *MemoryUndoStore) Take(token string, userID string, now time.Time) (*UndoAction, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	live := s.live(userID, now)
+	for i, action := range live {
+		if action.Token == token {
+			s.actions[userID] = append(live[:i:i], live[i+1:]...)
+			return action, nil
+		}
+	}
+	return nil, ErrUndoNotFound
+}
+
+// live drops the user's actions that expired by now and returns the others, oldest first.
+// Callers must hold the lock.
+func (s *MemoryUndoStore) live(userID string, now time.Time) []*UndoAction {
+	var live []*UndoAction
+	for _, action := range s.actions[userID] {
+		if now.Before(action.ExpiresAt) {
+			live = append(live, action)
+		}
+	}
+	if len(live) == 0 {
+		delete(s.actions, userID)
+	} else {
+		s.actions[userID] = live
+	}
+	return live
+}
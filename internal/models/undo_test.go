@@ -0,0 +1,88 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestUndoKeepsEditsMadeDuringTheAction(t *testing.T) {
+	ctx := context.Background()
+	tasks := NewMemoryTaskStore()
+	stack := NewUndoStack(NewMemoryUndoStore(), time.Minute, 10)
+
+	task := NewTask("Call mom", "", "alice")
+	if err := tasks.SaveForUser(task, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	action := stack.Begin("alice", "Task updated")
+	loaded, err := tasks.GetForUser(task.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	action.Record(loaded)
+	loaded.Title = "Call mom back"
+	if err := tasks.SaveForUser(loaded, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another tab edits the same task before the action is pushed
+	other, err := tasks.GetForUser(task.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Description = "About the weekend"
+	if err := tasks.SaveForUser(other, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := stack.Push(action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stack.Undo(ctx, tasks, token, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := tasks.GetForUser(task.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Title != "Call mom" {
+		t.Errorf("title = %q, want %q", stored.Title, "Call mom")
+	}
+	if stored.Description != "About the weekend" {
+		t.Errorf("description = %q, want the other tab's edit kept", stored.Description)
+	}
+}
+
+func TestUndoRestoresDeletedTasks(t *testing.T) {
+	ctx := context.Background()
+	tasks := NewMemoryTaskStore()
+	stack := NewUndoStack(NewMemoryUndoStore(), time.Minute, 10)
+
+	task := NewTask("Call mom", "", "alice")
+	if err := tasks.SaveForUser(task, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	action := stack.Begin("alice", "Task deleted")
+	action.Record(task)
+	task.Delete()
+	if err := tasks.SaveForUser(task, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := stack.Push(action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stack.Undo(ctx, tasks, token, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tasks.GetForUser(task.ID, "alice"); err != nil {
+		t.Errorf("task after undo: %v, want it restored", err)
+	}
+}
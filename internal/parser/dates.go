@@ -0,0 +1,305 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+	"sunday":    time.Sunday,
+}
+
+var months = map[string]time.Month{
+	"jan": time.January, "january": time.January,
+	"feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"may": time.May,
+	"jun": time.June, "june": time.June,
+	"jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+// clockPattern matches "3pm", "3:30pm", "15:00" and "9am"
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+
+// trailingMarks is the punctuation that may follow a word without being part of it
+const trailingMarks = ",.;!?"
+
+// normalize lowercases a word and drops trailing punctuation so "Friday," matches
+func normalize(word string) string {
+	return strings.ToLower(strings.TrimRight(word, trailingMarks))
+}
+
+// parseDate recognizes a date phrase starting at words[i], optionally preceded by a keyword
+// choosing between the due and scheduled date, and returns how many words it used.
+// Only the first due date and the first scheduled date are taken; later ones stay in the title.
+// Weekdays are often part of the title, as in "Email Sunday school teacher", so a weekday
+// without a keyword only counts at the end of the text, where only markers may follow it.
+func (r *Result) parseDate(words []string, i int, now time.Time) int {
+	start := i
+	kind := DetectDue
+
+	switch normalize(words[i]) {
+	case "due", "by", "on":
+		i++
+	case "start", "starting", "scheduled":
+		kind = DetectScheduled
+		i++
+	}
+	if i >= len(words) {
+		return 0
+	}
+
+	when, n := parseWhen(words[i:], now)
+	if n == 0 {
+		return 0
+	}
+	if i == start && hasBareWeekday(words[i:i+n]) {
+		for _, word := range words[i+n:] {
+			if !isMarker(word) {
+				return 0
+			}
+		}
+	}
+
+	target := &r.DueDate
+	if kind == DetectScheduled {
+		target = &r.ScheduledDate
+	}
+	if *target != nil {
+		return 0
+	}
+
+	*target = &when
+	phrase := strings.Join(words[start:i+n], " ")
+	r.detect(kind, strings.TrimSuffix(phrase, trailingPunctuation(phrase)))
+	return i - start + n
+}
+
+// hasBareWeekday reports whether a date phrase names a weekday on its own, not as "next friday"
+func hasBareWeekday(phrase []string) bool {
+	for j, word := range phrase {
+		if _, ok := weekdays[normalize(word)]; ok && (j == 0 || normalize(phrase[j-1]) != "next") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWhen recognizes a day, a time of day, or both in either order.
+// A day without a time is midnight; a time without a day is its next occurrence.
+func parseWhen(words []string, now time.Time) (time.Time, int) {
+	if day, n := parseDay(words, now); n > 0 {
+		if hour, minute, tn := parseClock(words[n:]); tn > 0 {
+			return atClock(day, hour, minute), n + tn
+		}
+		return day, n
+	}
+
+	hour, minute, n := parseClock(words)
+	if n == 0 {
+		return time.Time{}, 0
+	}
+	if day, dn := parseDay(words[n:], now); dn > 0 {
+		return atClock(day, hour, minute), n + dn
+	}
+
+	when := atClock(startOfDay(now), hour, minute)
+	if !when.After(now) {
+		when = when.AddDate(0, 0, 1)
+	}
+	return when, n
+}
+
+// parseDay recognizes today, tomorrow, weekdays, "next week|month|<weekday>",
+// "in N days|weeks|months", YYYY-MM-DD and month-day dates such as "Mar 14" or "14th March 2026"
+func parseDay(words []string, now time.Time) (time.Time, int) {
+	if len(words) == 0 {
+		return time.Time{}, 0
+	}
+	today := startOfDay(now)
+	word := normalize(words[0])
+
+	switch word {
+	case "today":
+		return today, 1
+	case "tomorrow", "tmrw", "tmr":
+		return today.AddDate(0, 0, 1), 1
+	case "next":
+		if len(words) < 2 {
+			return time.Time{}, 0
+		}
+		next := normalize(words[1])
+		// Weeks start on Monday
+		monday := today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7)
+		switch next {
+		case "week":
+			return monday, 2
+		case "month":
+			return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), 2
+		}
+		if weekday, ok := weekdays[next]; ok {
+			return monday.AddDate(0, 0, (int(weekday)+6)%7), 2
+		}
+		return time.Time{}, 0
+	case "in":
+		if len(words) < 3 {
+			return time.Time{}, 0
+		}
+		count, err := strconv.Atoi(words[1])
+		if words[1] == "a" || words[1] == "an" || words[1] == "one" {
+			count, err = 1, nil
+		}
+		if err != nil || count < 1 {
+			return time.Time{}, 0
+		}
+		switch strings.TrimSuffix(normalize(words[2]), "s") {
+		case "day":
+			return today.AddDate(0, 0, count), 3
+		case "week":
+			return today.AddDate(0, 0, 7*count), 3
+		case "month":
+			return today.AddDate(0, count, 0), 3
+		}
+		return time.Time{}, 0
+	}
+
+	if weekday, ok := weekdays[word]; ok {
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, days), 1
+	}
+
+	if date, err := time.ParseInLocation("2006-01-02", word, now.Location()); err == nil {
+		return date, 1
+	}
+
+	return parseMonthDay(words, today)
+}
+
+// parseMonthDay recognizes "Mar 14", "March 14th" and "14 March", each with an optional year.
+// Without a year the next such date on or after today is used.
+func parseMonthDay(words []string, today time.Time) (time.Time, int) {
+	if len(words) < 2 {
+		return time.Time{}, 0
+	}
+
+	month, ok := months[normalize(words[0])]
+	day, dayOK := parseDayOfMonth(words[1])
+	if !ok || !dayOK {
+		day, dayOK = parseDayOfMonth(words[0])
+		month, ok = months[normalize(words[1])]
+		if !ok || !dayOK {
+			return time.Time{}, 0
+		}
+	}
+
+	n := 2
+	year := today.Year()
+	if len(words) > 2 {
+		if y, err := strconv.Atoi(normalize(words[2])); err == nil && y >= 1970 && y <= 9999 {
+			year = y
+			n = 3
+		}
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	if date.Day() != day {
+		return time.Time{}, 0 // e.g. February 30
+	}
+	if n == 2 && date.Before(today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return date, n
+}
+
+// parseDayOfMonth converts "14", "14th" or "1st," into a day of the month
+func parseDayOfMonth(word string) (int, bool) {
+	word = normalize(word)
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		word = strings.TrimSuffix(word, suffix)
+	}
+	day, err := strconv.Atoi(word)
+	return day, err == nil && day >= 1 && day <= 31
+}
+
+// parseClock recognizes a time of day such as "3pm", "3 pm", "15:30" or "noon", optionally after "at"
+func parseClock(words []string) (hour, minute, n int) {
+	if len(words) > 1 && normalize(words[0]) == "at" {
+		if hour, minute, n = parseClock(words[1:]); n > 0 {
+			return hour, minute, n + 1
+		}
+		return 0, 0, 0
+	}
+	if len(words) == 0 {
+		return 0, 0, 0
+	}
+
+	word := normalize(words[0])
+	if word == "noon" {
+		return 12, 0, 1
+	}
+
+	n = 1
+	if len(words) > 1 && !strings.HasSuffix(word, "m") {
+		if suffix := normalize(words[1]); suffix == "am" || suffix == "pm" {
+			word += suffix
+			n = 2
+		}
+	}
+
+	match := clockPattern.FindStringSubmatch(word)
+	if match == nil || (match[2] == "" && match[3] == "") {
+		return 0, 0, 0 // A bare number isn't a time
+	}
+
+	hour, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+	if minute > 59 {
+		return 0, 0, 0
+	}
+
+	switch match[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, 0
+		}
+		hour %= 12
+		if match[3] == "pm" {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return 0, 0, 0
+		}
+	}
+
+	return hour, minute, n
+}
+
+// startOfDay returns midnight of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// atClock sets the time of day of a midnight date
+func atClock(day time.Time, hour, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
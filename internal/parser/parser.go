@@ -0,0 +1,273 @@
+// Package parser extracts task structure from a single line of quick-capture text.
+//
+// A line such as "Call dentist @phone #health tomorrow 3pm !1 ~15m +Website" yields
+// the title "Call dentist" together with:
+//
+//	@word          context
+//	#word          tag
+//	+Name          project, matched by title ("+\"Two words\"" for titles with spaces)
+//	!1 .. !3       priority
+//	~15m ~1h30m    time estimate (a bare number means minutes)
+//	dates          due date; "start", "starting" or "scheduled" before a date sets the scheduled date instead
+//
+// Contexts and tags may also carry a leading plus ("+@phone", "+#errand") as in email capture.
+// A weekday on its own is only taken as a date after a keyword such as "on", "by" or "start", or at
+// the end of the text.
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// DetectionKind names the kind of structure found in captured text
+type DetectionKind string
+
+const (
+	DetectContext   DetectionKind = "context"
+	DetectTag       DetectionKind = "tag"
+	DetectProject   DetectionKind = "project"
+	DetectPriority  DetectionKind = "priority"
+	DetectEstimate  DetectionKind = "estimate"
+	DetectDue       DetectionKind = "due"
+	DetectScheduled DetectionKind = "scheduled"
+)
+
+// Detection is a piece of captured text that was turned into a task field
+type Detection struct {
+	Kind DetectionKind `json:"kind"`
+	Text string        `json:"text"` // The words as they were written
+}
+
+// Result is what Parse found in a line of text
+type Result struct {
+	Title         string           `json:"title"`
+	Contexts      []models.Context `json:"contexts,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Project       string           `json:"project,omitempty"` // Project name as written; see MatchProject
+	DueDate       *time.Time       `json:"dueDate,omitempty"`
+	ScheduledDate *time.Time       `json:"scheduledDate,omitempty"`
+	Priority      int              `json:"priority,omitempty"`
+	TimeEstimate  int              `json:"timeEstimate,omitempty"` // Minutes
+	Detected      []Detection      `json:"detected"`
+}
+
+// Parse extracts contexts, tags, project, dates, priority and time estimate from text.
+// Relative dates such as "tomorrow" are resolved against now, in now's location.
+// Words that aren't recognized stay in the title in their original order, and punctuation
+// after a recognized phrase stays with them, so "Ship on monday, then review" keeps its comma.
+func Parse(text string, now time.Time) *Result {
+	result := &Result{Detected: []Detection{}}
+	words := splitWords(text)
+	var title []string
+
+	// keep moves punctuation that followed a recognized phrase onto the title
+	keep := func(word string) {
+		punctuation := trailingPunctuation(word)
+		if punctuation == "" || len(title) == 0 || trailingPunctuation(title[len(title)-1]) != "" {
+			return
+		}
+		title[len(title)-1] += punctuation
+	}
+
+	for i := 0; i < len(words); {
+		if n := result.parseDate(words, i, now); n > 0 {
+			keep(words[i+n-1])
+			i += n
+			continue
+		}
+		if result.parseMarker(strings.TrimSuffix(words[i], trailingPunctuation(words[i]))) {
+			keep(words[i])
+		} else {
+			title = append(title, words[i])
+		}
+		i++
+	}
+
+	result.Title = strings.Join(title, " ")
+	return result
+}
+
+// Apply copies the parsed fields onto a task. The project is applied separately, see MatchProject.
+// An empty title, as in text made only of tags, leaves the task's title alone.
+func (r *Result) Apply(task *models.Task) {
+	if r.Title != "" {
+		task.Title = r.Title
+	}
+	task.Contexts = append(task.Contexts, r.Contexts...)
+	task.Tags = append(task.Tags, r.Tags...)
+	if r.DueDate != nil {
+		task.DueDate = r.DueDate
+	}
+	if r.ScheduledDate != nil {
+		task.ScheduledDate = r.ScheduledDate
+	}
+	if r.Priority != 0 {
+		task.Priority = r.Priority
+	}
+	if r.TimeEstimate != 0 {
+		task.TimeEstimate = r.TimeEstimate
+	}
+}
+
+// MatchProject finds the project a parsed project name refers to. An exact title match
+// (ignoring case) wins; otherwise the name must appear in exactly one project title.
+// Dashes and underscores in the name match spaces, so "+personal-website" works too.
+func MatchProject(name string, projects []*models.Project) *models.Project {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return nil
+	}
+	spaced := strings.NewReplacer("-", " ", "_", " ").Replace(name)
+
+	var partial []*models.Project
+	for _, project := range projects {
+		title := strings.ToLower(project.Title)
+		if title == name || title == spaced {
+			return project
+		}
+		if strings.Contains(title, name) || strings.Contains(title, spaced) {
+			partial = append(partial, project)
+		}
+	}
+
+	if len(partial) == 1 {
+		return partial[0]
+	}
+	return nil
+}
+
+// splitWords splits text on whitespace, keeping a quoted project name such as +"Two words" together
+func splitWords(text string) []string {
+	fields := strings.Fields(text)
+	words := make([]string, 0, len(fields))
+
+	for i := 0; i < len(fields); i++ {
+		word := fields[i]
+		if strings.HasPrefix(word, `+"`) && (len(word) == 2 || !strings.HasSuffix(word, `"`)) {
+			for j := i + 1; j < len(fields); j++ {
+				if strings.HasSuffix(fields[j], `"`) {
+					word = strings.Join(fields[i:j+1], " ")
+					i = j
+					break
+				}
+			}
+		}
+		words = append(words, word)
+	}
+
+	return words
+}
+
+// parseMarker records a context, tag, project, priority or estimate word and reports whether it was one
+func (r *Result) parseMarker(word string) bool {
+	switch {
+	case len(word) > 2 && strings.HasPrefix(word, `+"`) && strings.HasSuffix(word, `"`):
+		if r.Project != "" {
+			return false
+		}
+		r.Project = word[2 : len(word)-1]
+		r.detect(DetectProject, word)
+		return true
+	case len(word) > 2 && (strings.HasPrefix(word, "+@") || strings.HasPrefix(word, "+#")):
+		return r.parseMarker(word[1:])
+	case len(word) > 1 && word[0] == '@' && isName(word[1:]):
+		context := models.Context(strings.ToLower(word[1:]))
+		for _, c := range r.Contexts {
+			if c == context {
+				r.detect(DetectContext, word)
+				return true
+			}
+		}
+		r.Contexts = append(r.Contexts, context)
+		r.detect(DetectContext, word)
+		return true
+	case len(word) > 1 && word[0] == '#' && isName(word[1:]):
+		tag := strings.ToLower(word[1:])
+		for _, t := range r.Tags {
+			if t == tag {
+				r.detect(DetectTag, word)
+				return true
+			}
+		}
+		r.Tags = append(r.Tags, tag)
+		r.detect(DetectTag, word)
+		return true
+	case len(word) > 1 && word[0] == '+' && isName(word[1:]):
+		if r.Project != "" {
+			return false
+		}
+		r.Project = word[1:]
+		r.detect(DetectProject, word)
+		return true
+	case len(word) == 2 && word[0] == '!' && word[1] >= '1' && word[1] <= '3':
+		if r.Priority != 0 {
+			return false
+		}
+		r.Priority = int(word[1] - '0')
+		r.detect(DetectPriority, word)
+		return true
+	case len(word) > 1 && word[0] == '~':
+		minutes, ok := parseEstimate(word[1:])
+		if !ok || r.TimeEstimate != 0 {
+			return false
+		}
+		r.TimeEstimate = minutes
+		r.detect(DetectEstimate, word)
+		return true
+	}
+	return false
+}
+
+// isMarker reports whether a word is a context, tag, project, priority or estimate
+func isMarker(word string) bool {
+	return (&Result{}).parseMarker(strings.TrimSuffix(word, trailingPunctuation(word)))
+}
+
+// trailingPunctuation returns the punctuation a word ends with, as in "Friday," or "@phone."
+func trailingPunctuation(word string) string {
+	return word[len(strings.TrimRight(word, trailingMarks)):]
+}
+
+// detect records a detection for the preview
+func (r *Result) detect(kind DetectionKind, text string) {
+	r.Detected = append(r.Detected, Detection{Kind: kind, Text: text})
+}
+
+// isName reports whether s can name a context, tag or project: it must start with a letter
+// so that "#1" or "@3pm" stay in the title
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	c := s[0]
+	if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80) {
+		return false
+	}
+	for _, c := range s {
+		if c == '@' || c == '#' || c == '"' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseEstimate converts "15m", "1h30m", "1.5h" or "45" into minutes
+func parseEstimate(s string) (int, bool) {
+	s = strings.ToLower(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, n > 0
+	}
+	if strings.HasSuffix(s, "min") {
+		s = strings.TrimSuffix(s, "in")
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Minute {
+		return 0, false
+	}
+	return int(d.Round(time.Minute) / time.Minute), true
+}
@@ -0,0 +1,69 @@
+// Package reminders watches task dates and delivers reminders through pluggable notifiers.
+package reminders
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// Message is a reminder ready to be delivered
+type Message struct {
+	Reminder *models.Reminder
+	Task     *models.Task
+	Settings models.ReminderSettings // Holds the user's email address and webhook URL
+	Subject  string
+	Body     string
+}
+
+// newMessage describes a reminder for a task
+func newMessage(reminder *models.Reminder, task *models.Task, settings models.ReminderSettings) Message {
+	date := task.DueDate
+	verb := "is due"
+	if reminder.Field == models.ReminderScheduled {
+		date = task.ScheduledDate
+		verb = "is scheduled"
+	}
+
+	body := fmt.Sprintf("%q %s", task.Title, verb)
+	if date != nil {
+		body = fmt.Sprintf("%q %s %s", task.Title, verb, date.Format("Mon, Jan 02 2006 15:04 MST"))
+	}
+
+	return Message{
+		Reminder: reminder,
+		Task:     task,
+		Settings: settings,
+		Subject:  "Reminder: " + task.Title,
+		Body:     body,
+	}
+}
+
+// Notifier delivers reminders through one channel
+type Notifier interface {
+	Channel() models.ReminderChannel
+	Notify(ctx context.Context, msg Message) error
+}
+
+// InAppNotifier delivers reminders to the user's notification inbox
+type InAppNotifier struct {
+	notifications models.NotificationStore
+}
+
+// NewInAppNotifier creates a notifier that writes to the notification inbox
+func NewInAppNotifier(notifications models.NotificationStore) *InAppNotifier {
+	return &InAppNotifier{
+		notifications: notifications,
+	}
+}
+
+// Channel returns the in-app channel
+func (n *InAppNotifier) Channel() models.ReminderChannel {
+	return models.ChannelInApp
+}
+
+// Notify adds the reminder to the user's notification inbox
+func (n *InAppNotifier) Notify(ctx context.Context, msg Message) error {
+	return n.notifications.Create(models.NewNotification(msg.Reminder.UserID, msg.Task.ID, msg.Subject, msg.Body))
+}
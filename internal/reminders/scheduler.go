@@ -0,0 +1,227 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// Scheduler periodically turns task due and scheduled dates into reminders and delivers them.
+//
+// Each reminder is persisted before it is delivered, keyed by task, date, offset, channel and
+// fire time, so a reminder is only created once even across restarts. A run claims a reminder
+// before sending it, so it is never sent twice, even by several servers sharing a database.
+// Delivery state is saved after every attempt; failed deliveries are retried on later runs up
+// to MaxAttempts.
+type Scheduler struct {
+	tasks       models.TaskStore
+	reminders   models.ReminderStore
+	notifiers   map[models.ReminderChannel]Notifier
+	interval    time.Duration
+	lookback    time.Duration
+	maxAttempts int
+	now         func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex // Serializes runs
+}
+
+// NewScheduler creates a scheduler that checks for due reminders every interval.
+// Reminders whose fire time is more than lookback in the past are not created.
+func NewScheduler(tasks models.TaskStore, reminders models.ReminderStore, interval, lookback time.Duration, maxAttempts int, notifiers ...Notifier) *Scheduler {
+	s := &Scheduler{
+		tasks:       tasks,
+		reminders:   reminders,
+		notifiers:   make(map[models.ReminderChannel]Notifier),
+		interval:    interval,
+		lookback:    lookback,
+		maxAttempts: maxAttempts,
+		now:         time.Now,
+	}
+	for _, notifier := range notifiers {
+		s.notifiers[notifier.Channel()] = notifier
+	}
+	return s
+}
+
+// Start runs the scheduler in the background until Shutdown is called
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Reminder scheduler: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the scheduler and waits for a run in progress to finish,
+// or for ctx to expire
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce creates the reminders that have come due and delivers all pending reminders
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	settings := make(map[string]models.ReminderSettings)
+
+	if err := s.schedule(ctx, now, settings); err != nil {
+		return err
+	}
+	return s.deliver(ctx, now, settings)
+}
+
+// userSettings returns a user's reminder settings, loading them once per run
+func (s *Scheduler) userSettings(userID string, cache map[string]models.ReminderSettings) (models.ReminderSettings, error) {
+	if settings, ok := cache[userID]; ok {
+		return settings, nil
+	}
+
+	settings, err := s.reminders.GetSettings(userID)
+	if err != nil {
+		return models.ReminderSettings{}, err
+	}
+	cache[userID] = settings
+	return settings, nil
+}
+
+// schedule persists a reminder for every task date that has come due. Only tasks dated between
+// the lookback and the longest reminder offset ahead can have one, so no other task is loaded.
+func (s *Scheduler) schedule(ctx context.Context, now time.Time, cache map[string]models.ReminderSettings) error {
+	maxOffset, err := s.reminders.MaxOffset(ctx)
+	if err != nil {
+		return err
+	}
+
+	// A second of slack keeps dates the database rounded inside the window
+	from := now.Add(-s.lookback)
+	until := now.Add(maxOffset + time.Second)
+	tasks, err := models.QueryAll(ctx, s.tasks, models.TaskQuery{
+		Filter: models.TaskFilter{DatedAfter: &from, DatedBefore: &until},
+		Sort:   models.SortCreatedAsc,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if task.UserID == "" || (task.DueDate == nil && task.ScheduledDate == nil) {
+			continue
+		}
+
+		settings, err := s.userSettings(task.UserID, cache)
+		if err != nil {
+			return err
+		}
+
+		for _, reminder := range settings.DueReminders(task, now, s.lookback) {
+			if err := s.reminders.Create(ctx, reminder); err != nil && !errors.Is(err, models.ErrReminderExists) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// deliver sends the pending reminders and records the outcome of each attempt
+func (s *Scheduler) deliver(ctx context.Context, now time.Time, cache map[string]models.ReminderSettings) error {
+	pending, err := s.reminders.ListPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, reminder := range pending {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Claim the reminder first, so a run that fails to save the outcome can't send it again
+		if err := s.reminders.Claim(ctx, reminder); err != nil {
+			if errors.Is(err, models.ErrReminderClaimed) {
+				continue
+			}
+			return err
+		}
+
+		// A claimed reminder is finished even when shutdown starts meanwhile: left claimed, it would
+		// never be listed as pending again
+		claimed := context.WithoutCancel(ctx)
+		if err := s.deliverOne(claimed, reminder, now, cache); err != nil {
+			reminder.MarkFailed(err, s.maxAttempts)
+			log.Printf("Reminder %s for task %s via %s failed (attempt %d): %v",
+				reminder.ID, reminder.TaskID, reminder.Channel, reminder.Attempts, err)
+		}
+
+		if err := s.reminders.Update(claimed, reminder); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliverOne sends a single reminder, marking it sent, or cancelled when the task was
+// completed, deleted or rescheduled. A returned error means the attempt failed and may be retried.
+func (s *Scheduler) deliverOne(ctx context.Context, reminder *models.Reminder, now time.Time, cache map[string]models.ReminderSettings) error {
+	task, err := s.tasks.GetForUser(reminder.TaskID, reminder.UserID)
+	if errors.Is(err, models.ErrTaskNotFound) || (err == nil && !reminder.IsCurrent(task)) {
+		reminder.Status = models.ReminderCancelled
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	notifier, ok := s.notifiers[reminder.Channel]
+	if !ok {
+		return fmt.Errorf("no notifier configured for channel %s", reminder.Channel)
+	}
+
+	settings, err := s.userSettings(reminder.UserID, cache)
+	if err != nil {
+		return err
+	}
+
+	if err := notifier.Notify(ctx, newMessage(reminder, task, settings)); err != nil {
+		return err
+	}
+
+	reminder.MarkSent(now)
+	return nil
+}
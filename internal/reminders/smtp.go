@@ -0,0 +1,52 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/melihkorkmaz/gtd/internal/config"
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// SMTPNotifier delivers reminders by email
+type SMTPNotifier struct {
+	config config.SMTPConfig
+}
+
+// NewSMTPNotifier creates a notifier that sends email through the configured SMTP server
+func NewSMTPNotifier(cfg config.SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{
+		config: cfg,
+	}
+}
+
+// Channel returns the email channel
+func (n *SMTPNotifier) Channel() models.ReminderChannel {
+	return models.ChannelEmail
+}
+
+// Notify emails the reminder to the address in the user's reminder settings
+func (n *SMTPNotifier) Notify(ctx context.Context, msg Message) error {
+	to := msg.Settings.Email
+	if to == "" {
+		return errors.New("no email address configured")
+	}
+
+	// Header values must not contain line breaks
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(msg.Subject)
+
+	body := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		n.config.From, to, subject, msg.Body,
+	)
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	return smtp.SendMail(n.config.Addr(), auth, n.config.From, []string{to}, []byte(body))
+}
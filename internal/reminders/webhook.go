@@ -0,0 +1,110 @@
+package reminders
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// WebhookPayload is the JSON body posted to webhook endpoints
+type WebhookPayload struct {
+	Event    string           `json:"event"`
+	Subject  string           `json:"subject"`
+	Body     string           `json:"body"`
+	Reminder *models.Reminder `json:"reminder"`
+	Task     *models.Task     `json:"task"`
+}
+
+// WebhookNotifier delivers reminders as JSON POST requests
+type WebhookNotifier struct {
+	client *http.Client
+	secret string
+}
+
+// errInternalAddress is returned when a webhook host resolves to an address that isn't public
+var errInternalAddress = errors.New("webhook host resolves to an internal address")
+
+// NewWebhookNotifier creates a webhook notifier. When secret is set, each request carries an
+// X-GTD-Signature header with the hex HMAC-SHA256 of the body.
+// Requests, redirects included, are only sent to public addresses.
+func NewWebhookNotifier(secret string) *WebhookNotifier {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		// Checked on the resolved address, so a name can't be pointed at an internal one later
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || models.IsInternalIP(ip) {
+				return errInternalAddress
+			}
+			return nil
+		},
+	}
+
+	return &WebhookNotifier{
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+		},
+		secret: secret,
+	}
+}
+
+// Channel returns the webhook channel
+func (n *WebhookNotifier) Channel() models.ReminderChannel {
+	return models.ChannelWebhook
+}
+
+// Notify posts the reminder to the URL in the user's reminder settings
+func (n *WebhookNotifier) Notify(ctx context.Context, msg Message) error {
+	if msg.Settings.WebhookURL == "" {
+		return errors.New("no webhook URL configured")
+	}
+
+	payload, err := json.Marshal(WebhookPayload{
+		Event:    "task.reminder",
+		Subject:  msg.Subject,
+		Body:     msg.Body,
+		Reminder: msg.Reminder,
+		Task:     msg.Task,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.Settings.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(payload)
+		req.Header.Set("X-GTD-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
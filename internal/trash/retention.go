@@ -0,0 +1,102 @@
+// Package trash enforces the retention policy of deleted tasks and projects
+package trash
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/melihkorkmaz/gtd/internal/models"
+)
+
+// Retention periodically and permanently removes tasks and projects that have been deleted for
+// longer than the retention period
+type Retention struct {
+	tasks    models.TaskStore
+	projects models.ProjectStore
+	period   time.Duration
+	interval time.Duration
+	now      func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex // Serializes runs
+}
+
+// NewRetention creates a job that purges items deleted more than period ago, checking every interval
+func NewRetention(tasks models.TaskStore, projects models.ProjectStore, period, interval time.Duration) *Retention {
+	return &Retention{
+		tasks:    tasks,
+		projects: projects,
+		period:   period,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the job in the background until Shutdown is called
+func (r *Retention) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			tasks, projects, err := r.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Trash retention: %v", err)
+			}
+			if tasks > 0 || projects > 0 {
+				log.Printf("Trash retention: purged %d tasks and %d projects", tasks, projects)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the job and waits for a run in progress to finish, or for ctx to expire
+func (r *Retention) Shutdown(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce purges every task and project deleted before the retention period and returns how many
+// of each were removed
+func (r *Retention) RunOnce(ctx context.Context) (int, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.now().Add(-r.period)
+
+	tasks, err := r.tasks.PurgeDeleted(ctx, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	projects, err := r.projects.PurgeDeleted(ctx, cutoff)
+	if err != nil {
+		return tasks, 0, err
+	}
+
+	return tasks, projects, nil
+}
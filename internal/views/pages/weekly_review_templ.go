@@ -8,9 +8,60 @@ package pages
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-import "github.com/melihkorkmaz/gtd/internal/views/layouts"
+import (
+	"fmt"
+	"github.com/melihkorkmaz/gtd/internal/views/layouts"
+	"time"
+)
 
-func WeeklyReviewPage() templ.Component {
+type ReviewStepInfo struct {
+	Key       string
+	Title     string
+	Completed bool
+	Notes     string
+}
+
+type ReviewSnapshotInfo struct {
+	Inbox                     int
+	NextActions               int
+	Waiting                   int
+	StaleWaiting              int
+	Someday                   int
+	ActiveProjects            int
+	ProjectsWithoutNextAction int
+}
+
+type ReviewSessionInfo struct {
+	ID          string
+	StartedAt   time.Time
+	CompletedAt *time.Time
+	Progress    int
+	Notes       string
+	Steps       []ReviewStepInfo
+	Start       ReviewSnapshotInfo
+	End         *ReviewSnapshotInfo
+}
+
+type reviewStepDetail struct {
+	Description string
+	Link        string
+	LinkLabel   string
+	Checklist   string
+}
+
+// reviewStepDetails holds the guidance shown for each weekly review step
+var reviewStepDetails = map[string]reviewStepDetail{
+	"collect":  {Description: "Gather all physical materials - notes, receipts, documents, business cards, etc. - into your inbox for processing.", Checklist: "Collected all physical materials"},
+	"notes":    {Description: "Go through any paper or digital notes you've taken during the week and transfer them to the appropriate system.", Checklist: "Processed all notes"},
+	"inbox":    {Description: "Process all items in your inbox to zero. Decide what each item is and what needs to be done with it.", Link: "/tasks?status=inbox", LinkLabel: "Go to Inbox", Checklist: "Inbox is empty"},
+	"next":     {Description: "Review your Next Actions list. Mark completed items as done and update any that have changed.", Link: "/tasks?status=next", LinkLabel: "Review Next Actions", Checklist: "Next Actions list is current and complete"},
+	"waiting":  {Description: "Review items you're waiting on from others. Record any necessary follow-ups.", Link: "/tasks?status=waiting", LinkLabel: "Review Waiting Items", Checklist: "Waiting For list is up to date"},
+	"projects": {Description: "Review the status of all current projects. Ensure each has at least one next action.", Link: "/projects", LinkLabel: "Review Projects", Checklist: "All projects have clear next actions"},
+	"someday":  {Description: "Review your Someday/Maybe items. Move any to active projects if you're ready to start them.", Link: "/tasks?status=someday", LinkLabel: "Review Someday/Maybe", Checklist: "Someday/Maybe list is reviewed"},
+	"creative": {Description: "Consider new ideas, possibilities, or projects you might want to pursue.", Checklist: "Considered new ideas and possibilities"},
+}
+
+func WeeklyReviewPage(session *ReviewSessionInfo) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -43,7 +94,225 @@ func WeeklyReviewPage() templ.Component {
 				}()
 			}
 			ctx = templ.InitializeContext(ctx)
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"grid gap-6\"><div class=\"card bg-base-100 shadow-lg\"><div class=\"card-body\"><h2 class=\"card-title text-2xl mb-4\">Weekly Review</h2><p class=\"mb-4\">The weekly review is a time to get clear, get current, and get creative. Use this checklist to guide your weekly review process.</p><div class=\"flex justify-end mb-4\"><button class=\"btn btn-primary\" id=\"start-review\">Start Weekly Review</button> <button class=\"btn btn-success ml-2\" id=\"reset-review\" style=\"display: none;\">Reset Review</button></div><div id=\"review-progress\" class=\"mb-4\" style=\"display: none;\"><progress class=\"progress progress-primary w-full\" id=\"review-progress-bar\" value=\"0\" max=\"100\"></progress><p class=\"text-center mt-2\"><span id=\"review-progress-text\">0%</span> complete</p></div><div id=\"review-steps\" style=\"display: none;\"><div x-data=\"{ open: false, completed: false }\" class=\"collapse collapse-arrow bg-base-200 mb-4\"><input type=\"checkbox\" x-bind:checked=\"open\" @click=\"open = !open\"><div class=\"collapse-title text-xl font-medium flex items-center\"><input type=\"checkbox\" class=\"checkbox mr-3\" x-model=\"completed\" @change=\"updateProgress()\"> <span>1. Collect Loose Papers and Materials</span></div><div class=\"collapse-content\"><p>Gather all physical materials - notes, receipts, documents, business cards, etc. - into your inbox for processing.</p><div class=\"form-control mt-2\"><label class=\"cursor-pointer label\"><span class=\"label-text\">Collected all physical materials</span> <input type=\"checkbox\" class=\"checkbox checkbox-primary\"></label></div></div></div><div x-data=\"{ open: false, completed: false }\" class=\"collapse collapse-arrow bg-base-200 mb-4\"><input type=\"checkbox\" x-bind:checked=\"open\" @click=\"open = !open\"><div class=\"collapse-title text-xl font-medium flex items-center\"><input type=\"checkbox\" class=\"checkbox mr-3\" x-model=\"completed\" @change=\"updateProgress()\"> <span>2. Process Your Notes</span></div><div class=\"collapse-content\"><p>Go through any paper or digital notes you've taken during the week and transfer them to the appropriate system.</p><div class=\"form-control mt-2\"><label class=\"cursor-pointer label\"><span class=\"label-text\">Processed all notes</span> <input type=\"checkbox\" class=\"checkbox checkbox-primary\"></label></div></div></div><div x-data=\"{ open: false, completed: false }\" class=\"collapse collapse-arrow bg-base-200 mb-4\"><input type=\"checkbox\" x-bind:checked=\"open\" @click=\"open = !open\"><div class=\"collapse-title text-xl font-medium flex items-center\"><input type=\"checkbox\" class=\"checkbox mr-3\" x-model=\"completed\" @change=\"updateProgress()\"> <span>3. Empty Your Inbox</span></div><div class=\"collapse-content\"><p>Process all items in your inbox to zero. Decide what each item is and what needs to be done with it.</p><a href=\"/tasks?status=inbox\" target=\"_blank\" class=\"btn btn-outline btn-sm mt-2\">Go to Inbox</a><div class=\"form-control mt-2\"><label class=\"cursor-pointer label\"><span class=\"label-text\">Inbox is empty</span> <input type=\"checkbox\" class=\"checkbox checkbox-primary\"></label></div></div></div><div x-data=\"{ open: false, completed: false }\" class=\"collapse collapse-arrow bg-base-200 mb-4\"><input type=\"checkbox\" x-bind:checked=\"open\" @click=\"open = !open\"><div class=\"collapse-title text-xl font-medium flex items-center\"><input type=\"checkbox\" class=\"checkbox mr-3\" x-model=\"completed\" @change=\"updateProgress()\"> <span>4. Review Next Actions Lists</span></div><div class=\"collapse-content\"><p>Review your Next Actions list. Mark completed items as done and update any that have changed.</p><a href=\"/tasks?status=next\" target=\"_blank\" class=\"btn btn-outline btn-sm mt-2\">Review Next Actions</a><div class=\"form-control mt-2\"><label class=\"cursor-pointer label\"><span class=\"label-text\">Next Actions list is current and complete</span> <input type=\"checkbox\" class=\"checkbox checkbox-primary\"></label></div></div></div><div x-data=\"{ open: false, completed: false }\" class=\"collapse collapse-arrow bg-base-200 mb-4\"><input type=\"checkbox\" x-bind:checked=\"open\" @click=\"open = !open\"><div class=\"collapse-title text-xl font-medium flex items-center\"><input type=\"checkbox\" class=\"checkbox mr-3\" x-model=\"completed\" @change=\"updateProgress()\"> <span>5. Review Waiting For List</span></div><div class=\"collapse-content\"><p>Review items you're waiting on from others. Record any necessary follow-ups.</p><a href=\"/tasks?status=waiting\" target=\"_blank\" class=\"btn btn-outline btn-sm mt-2\">Review Waiting Items</a><div class=\"form-control mt-2\"><label class=\"cursor-pointer label\"><span class=\"label-text\">Waiting For list is up to date</span> <input type=\"checkbox\" class=\"checkbox checkbox-primary\"></label></div></div></div><div x-data=\"{ open: false, completed: false }\" class=\"collapse collapse-arrow bg-base-200 mb-4\"><input type=\"checkbox\" x-bind:checked=\"open\" @click=\"open = !open\"><div class=\"collapse-title text-xl font-medium flex items-center\"><input type=\"checkbox\" class=\"checkbox mr-3\" x-model=\"completed\" @change=\"updateProgress()\"> <span>6. Review Projects</span></div><div class=\"collapse-content\"><p>Review the status of all current projects. Ensure each has at least one next action.</p><a href=\"/projects\" target=\"_blank\" class=\"btn btn-outline btn-sm mt-2\">Review Projects</a><div class=\"form-control mt-2\"><label class=\"cursor-pointer label\"><span class=\"label-text\">All projects have clear next actions</span> <input type=\"checkbox\" class=\"checkbox checkbox-primary\"></label></div></div></div><div x-data=\"{ open: false, completed: false }\" class=\"collapse collapse-arrow bg-base-200 mb-4\"><input type=\"checkbox\" x-bind:checked=\"open\" @click=\"open = !open\"><div class=\"collapse-title text-xl font-medium flex items-center\"><input type=\"checkbox\" class=\"checkbox mr-3\" x-model=\"completed\" @change=\"updateProgress()\"> <span>7. Review Someday/Maybe List</span></div><div class=\"collapse-content\"><p>Review your Someday/Maybe items. Move any to active projects if you're ready to start them.</p><a href=\"/tasks?status=someday\" target=\"_blank\" class=\"btn btn-outline btn-sm mt-2\">Review Someday/Maybe</a><div class=\"form-control mt-2\"><label class=\"cursor-pointer label\"><span class=\"label-text\">Someday/Maybe list is reviewed</span> <input type=\"checkbox\" class=\"checkbox checkbox-primary\"></label></div></div></div><div x-data=\"{ open: false, completed: false }\" class=\"collapse collapse-arrow bg-base-200 mb-4\"><input type=\"checkbox\" x-bind:checked=\"open\" @click=\"open = !open\"><div class=\"collapse-title text-xl font-medium flex items-center\"><input type=\"checkbox\" class=\"checkbox mr-3\" x-model=\"completed\" @change=\"updateProgress()\"> <span>8. Get Creative</span></div><div class=\"collapse-content\"><p>Consider new ideas, possibilities, or projects you might want to pursue.</p><div class=\"form-control mt-2\"><label class=\"cursor-pointer label\"><span class=\"label-text\">Considered new ideas and possibilities</span> <input type=\"checkbox\" class=\"checkbox checkbox-primary\"></label></div></div></div></div><div id=\"review-complete\" class=\"alert alert-success shadow-lg\" style=\"display: none;\"><div><svg xmlns=\"http://www.w3.org/2000/svg\" class=\"stroke-current flex-shrink-0 h-6 w-6\" fill=\"none\" viewBox=\"0 0 24 24\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M9 12l2 2 4-4m6 2a9 9 0 11-18 0 9 9 0 0118 0z\"></path></svg><div><span class=\"font-bold\">Weekly review completed!</span><p class=\"text-sm\">Your GTD system is now current and up to date.</p></div></div></div></div></div></div><script>\n\t\t\t// Weekly review functionality\n\t\t\tdocument.addEventListener('DOMContentLoaded', function() {\n\t\t\t\tconst startButton = document.getElementById('start-review');\n\t\t\t\tconst resetButton = document.getElementById('reset-review');\n\t\t\t\tconst reviewSteps = document.getElementById('review-steps');\n\t\t\t\tconst reviewProgress = document.getElementById('review-progress');\n\t\t\t\tconst reviewComplete = document.getElementById('review-complete');\n\t\t\t\tconst progressBar = document.getElementById('review-progress-bar');\n\t\t\t\tconst progressText = document.getElementById('review-progress-text');\n\t\t\t\t\n\t\t\t\tstartButton.addEventListener('click', function() {\n\t\t\t\t\treviewSteps.style.display = 'block';\n\t\t\t\t\treviewProgress.style.display = 'block';\n\t\t\t\t\tstartButton.style.display = 'none';\n\t\t\t\t\tresetButton.style.display = 'inline-flex';\n\t\t\t\t});\n\t\t\t\t\n\t\t\t\tresetButton.addEventListener('click', function() {\n\t\t\t\t\tlocation.reload();\n\t\t\t\t});\n\t\t\t\t\n\t\t\t\t// Function to update progress\n\t\t\t\twindow.updateProgress = function() {\n\t\t\t\t\tconst steps = document.querySelectorAll('#review-steps > div');\n\t\t\t\t\tlet completed = 0;\n\t\t\t\t\t\n\t\t\t\t\tsteps.forEach(step => {\n\t\t\t\t\t\tif (step.__x && step.__x.$data.completed) {\n\t\t\t\t\t\t\tcompleted++;\n\t\t\t\t\t\t}\n\t\t\t\t\t});\n\t\t\t\t\t\n\t\t\t\t\tconst percentage = Math.round((completed / steps.length) * 100);\n\t\t\t\t\tprogressBar.value = percentage;\n\t\t\t\t\tprogressText.textContent = percentage + '%';\n\t\t\t\t\t\n\t\t\t\t\tif (percentage === 100) {\n\t\t\t\t\t\treviewComplete.style.display = 'block';\n\t\t\t\t\t} else {\n\t\t\t\t\t\treviewComplete.style.display = 'none';\n\t\t\t\t\t}\n\t\t\t\t};\n\t\t\t});\n\t\t</script>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"grid gap-6\"><div class=\"card bg-base-100 shadow-lg\"><div class=\"card-body\"><div class=\"flex justify-between items-center mb-4\"><h2 class=\"card-title text-2xl\">Weekly Review</h2><a href=\"/weekly-review/history\" class=\"btn btn-ghost btn-sm\">Review History</a></div><p class=\"mb-4\">The weekly review is a time to get clear, get current, and get creative. Use this checklist to guide your weekly review process.</p>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if session == nil {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div class=\"flex justify-end mb-4\"><button class=\"btn btn-primary\" id=\"start-review\">Start Weekly Review</button></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			} else {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<div class=\"text-sm text-gray-500 mb-2\">Started ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var3 string
+				templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(session.StartedAt.Format("Mon, Jan 02 2006 15:04"))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 73, Col: 67}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = ReviewSnapshotStats(session.Start).Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, " <div id=\"review-progress\" class=\"my-4\"><progress class=\"progress progress-primary w-full\" id=\"review-progress-bar\" value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var4 string
+				templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprint(session.Progress))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 79, Col: 119}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "\" max=\"100\"></progress><p class=\"text-center mt-2\"><span id=\"review-progress-text\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var5 string
+				templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%d%%", session.Progress))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 80, Col: 106}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "</span> complete</p></div><div id=\"review-steps\" data-review-id=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var6 string
+				templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(session.ID)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 83, Col: 56}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				for i, step := range session.Steps {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "<div x-data=\"{ open: false }\" class=\"collapse collapse-arrow bg-base-200 mb-4\"><input type=\"checkbox\" x-bind:checked=\"open\" @click=\"open = !open\"><div class=\"collapse-title text-xl font-medium flex items-center\"><input type=\"checkbox\" class=\"checkbox mr-3 review-step-checkbox\" data-step=\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var7 string
+					templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(step.Key)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 88, Col: 96}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					if step.Completed {
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, " checked")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "> <span>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var8 string
+					templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%d. %s", i+1, step.Title))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 89, Col: 56}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "</span></div><div class=\"collapse-content\"><p>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var9 string
+					templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(reviewStepDetails[step.Key].Description)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 92, Col: 54}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "</p>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					if reviewStepDetails[step.Key].Link != "" {
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<a href=\"")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						var templ_7745c5c3_Var10 templ.SafeURL = templ.SafeURL(reviewStepDetails[step.Key].Link)
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var10)))
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "\" target=\"_blank\" class=\"btn btn-outline btn-sm mt-2\">")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						var templ_7745c5c3_Var11 string
+						templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(reviewStepDetails[step.Key].LinkLabel)
+						if templ_7745c5c3_Err != nil {
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 94, Col: 162}
+						}
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "</a>")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "<div class=\"form-control mt-2\"><label class=\"label\"><span class=\"label-text\">")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var12 string
+					templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(reviewStepDetails[step.Key].Checklist)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 98, Col: 76}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "</span></label> <textarea class=\"textarea textarea-bordered review-step-notes\" data-step=\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var13 string
+					templ_7745c5c3_Var13, templ_7745c5c3_Err = templ.JoinStringErrs(step.Key)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 100, Col: 94}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var13))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "\" rows=\"2\" placeholder=\"Notes (optional)\">")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var14 string
+					templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs(step.Notes)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 100, Col: 149}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "</textarea></div></div></div>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "</div><div class=\"form-control mb-4\"><label class=\"label\"><span class=\"label-text\">Review notes</span></label> <textarea id=\"review-notes\" class=\"textarea textarea-bordered\" rows=\"3\" placeholder=\"Anything worth remembering about this week?\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var15 string
+				templ_7745c5c3_Var15, templ_7745c5c3_Err = templ.JoinStringErrs(session.Notes)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 111, Col: 152}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var15))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "</textarea></div><div class=\"flex justify-end\"><button class=\"btn btn-success\" id=\"finish-review\">Finish Review</button></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "</div></div></div><script>\n\t\t\t// Weekly review functionality; progress is saved to the server so a review survives a refresh\n\t\t\tdocument.addEventListener('DOMContentLoaded', function() {\n\t\t\t\tconst startButton = document.getElementById('start-review');\n\t\t\t\tconst finishButton = document.getElementById('finish-review');\n\t\t\t\tconst reviewSteps = document.getElementById('review-steps');\n\n\t\t\t\tfunction request(method, url, body) {\n\t\t\t\t\treturn fetch(url, {\n\t\t\t\t\t\tmethod: method,\n\t\t\t\t\t\theaders: { 'Content-Type': 'application/json' },\n\t\t\t\t\t\tbody: body ? JSON.stringify(body) : undefined\n\t\t\t\t\t}).then(response => {\n\t\t\t\t\t\tif (!response.ok) {\n\t\t\t\t\t\t\tthrow new Error('Request failed with status ' + response.status);\n\t\t\t\t\t\t}\n\t\t\t\t\t\treturn response.json();\n\t\t\t\t\t});\n\t\t\t\t}\n\n\t\t\t\tif (startButton) {\n\t\t\t\t\tstartButton.addEventListener('click', function() {\n\t\t\t\t\t\trequest('POST', '/api/reviews')\n\t\t\t\t\t\t\t.then(() => window.location.reload())\n\t\t\t\t\t\t\t.catch(error => alert('Failed to start review: ' + error.message));\n\t\t\t\t\t});\n\t\t\t\t}\n\n\t\t\t\tif (!reviewSteps) {\n\t\t\t\t\treturn;\n\t\t\t\t}\n\n\t\t\t\tconst reviewId = reviewSteps.dataset.reviewId;\n\t\t\t\tconst progressBar = document.getElementById('review-progress-bar');\n\t\t\t\tconst progressText = document.getElementById('review-progress-text');\n\n\t\t\t\tfunction saveStep(key) {\n\t\t\t\t\tconst checkbox = reviewSteps.querySelector('.review-step-checkbox[data-step=\"' + key + '\"]');\n\t\t\t\t\tconst notes = reviewSteps.querySelector('.review-step-notes[data-step=\"' + key + '\"]');\n\t\t\t\t\trequest('PUT', '/api/reviews/' + reviewId + '/steps/' + key, {\n\t\t\t\t\t\tcompleted: checkbox.checked,\n\t\t\t\t\t\tnotes: notes.value\n\t\t\t\t\t})\n\t\t\t\t\t\t.then(session => {\n\t\t\t\t\t\t\tprogressBar.value = session.progress;\n\t\t\t\t\t\t\tprogressText.textContent = session.progress + '%';\n\t\t\t\t\t\t})\n\t\t\t\t\t\t.catch(error => console.error('Error saving review step:', error));\n\t\t\t\t}\n\n\t\t\t\treviewSteps.querySelectorAll('.review-step-checkbox').forEach(checkbox => {\n\t\t\t\t\tcheckbox.addEventListener('click', event => event.stopPropagation());\n\t\t\t\t\tcheckbox.addEventListener('change', () => saveStep(checkbox.dataset.step));\n\t\t\t\t});\n\t\t\t\treviewSteps.querySelectorAll('.review-step-notes').forEach(notes => {\n\t\t\t\t\tnotes.addEventListener('change', () => saveStep(notes.dataset.step));\n\t\t\t\t});\n\n\t\t\t\tfinishButton.addEventListener('click', function() {\n\t\t\t\t\trequest('PUT', '/api/reviews/' + reviewId + '/finish', {\n\t\t\t\t\t\tnotes: document.getElementById('review-notes').value\n\t\t\t\t\t})\n\t\t\t\t\t\t.then(() => window.location.href = '/weekly-review/history')\n\t\t\t\t\t\t.catch(error => alert('Failed to finish review: ' + error.message));\n\t\t\t\t});\n\t\t\t});\n\t\t</script>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -57,4 +326,250 @@ func WeeklyReviewPage() templ.Component {
 	})
 }
 
+func ReviewSnapshotStats(snapshot ReviewSnapshotInfo) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var16 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var16 == nil {
+			templ_7745c5c3_Var16 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "<div class=\"stats stats-vertical lg:stats-horizontal shadow w-full\"><div class=\"stat\"><div class=\"stat-title\">Inbox</div><div class=\"stat-value text-lg\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var17 string
+		templ_7745c5c3_Var17, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprint(snapshot.Inbox))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 196, Col: 63}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var17))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "</div></div><div class=\"stat\"><div class=\"stat-title\">Stale Waiting For</div><div class=\"stat-value text-lg\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var18 string
+		templ_7745c5c3_Var18, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%d / %d", snapshot.StaleWaiting, snapshot.Waiting))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 200, Col: 100}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var18))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "</div></div><div class=\"stat\"><div class=\"stat-title\">Projects Without Next Action</div><div class=\"stat-value text-lg\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var19 string
+		templ_7745c5c3_Var19, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%d / %d", snapshot.ProjectsWithoutNextAction, snapshot.ActiveProjects))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 204, Col: 120}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var19))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "</div></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func ReviewHistoryPage(sessions []ReviewSessionInfo) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var20 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var20 == nil {
+			templ_7745c5c3_Var20 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Var21 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+			if !templ_7745c5c3_IsBuffer {
+				defer func() {
+					templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+					if templ_7745c5c3_Err == nil {
+						templ_7745c5c3_Err = templ_7745c5c3_BufErr
+					}
+				}()
+			}
+			ctx = templ.InitializeContext(ctx)
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, "<div class=\"card bg-base-100 shadow-lg\"><div class=\"card-body\"><div class=\"flex justify-between items-center mb-4\"><h2 class=\"card-title text-2xl\">Review History</h2><a href=\"/weekly-review\" class=\"btn btn-primary btn-sm\">Weekly Review</a></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if len(sessions) == 0 {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 30, "<div class=\"alert\"><span>No weekly reviews yet. Start one from the Weekly Review page.</span></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			} else {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 31, "<div class=\"overflow-x-auto\"><table class=\"table w-full\"><thead><tr><th>Started</th><th>Finished</th><th>Progress</th><th>Inbox</th><th>Stale Waiting For</th><th>Projects Without Next Action</th><th>Notes</th></tr></thead> <tbody>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				for _, session := range sessions {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 32, "<tr><td>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var22 string
+					templ_7745c5c3_Var22, templ_7745c5c3_Err = templ.JoinStringErrs(session.StartedAt.Format("Jan 02, 2006"))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 239, Col: 56}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var22))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 33, "</td><td>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					if session.CompletedAt != nil {
+						var templ_7745c5c3_Var23 string
+						templ_7745c5c3_Var23, templ_7745c5c3_Err = templ.JoinStringErrs(session.CompletedAt.Format("Jan 02, 2006 15:04"))
+						if templ_7745c5c3_Err != nil {
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 242, Col: 62}
+						}
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var23))
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+					} else {
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 34, "<a href=\"/weekly-review\" class=\"link link-primary\">In progress</a>")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 35, "</td><td>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var24 string
+					templ_7745c5c3_Var24, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%d%%", session.Progress))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 247, Col: 53}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var24))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 36, "</td><td>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var25 string
+					templ_7745c5c3_Var25, templ_7745c5c3_Err = templ.JoinStringErrs(reviewSnapshotChange(session, func(s ReviewSnapshotInfo) int { return s.Inbox }))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 248, Col: 96}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var25))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 37, "</td><td>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var26 string
+					templ_7745c5c3_Var26, templ_7745c5c3_Err = templ.JoinStringErrs(reviewSnapshotChange(session, func(s ReviewSnapshotInfo) int { return s.StaleWaiting }))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 249, Col: 103}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var26))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 38, "</td><td>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var27 string
+					templ_7745c5c3_Var27, templ_7745c5c3_Err = templ.JoinStringErrs(reviewSnapshotChange(session, func(s ReviewSnapshotInfo) int { return s.ProjectsWithoutNextAction }))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 250, Col: 116}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var27))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 39, "</td><td class=\"max-w-xs truncate\">")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var28 string
+					templ_7745c5c3_Var28, templ_7745c5c3_Err = templ.JoinStringErrs(session.Notes)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/weekly_review.templ`, Line: 251, Col: 55}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var28))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 40, "</td></tr>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 41, "</tbody></table></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 42, "</div></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			return nil
+		})
+		templ_7745c5c3_Err = layouts.Base("Review History - GTD App").Render(templ.WithChildren(ctx, templ_7745c5c3_Var21), templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// reviewSnapshotChange formats a count at the start of a review and, once finished, at its end
+func reviewSnapshotChange(session ReviewSessionInfo, count func(ReviewSnapshotInfo) int) string {
+	if session.End == nil {
+		return fmt.Sprint(count(session.Start))
+	}
+	return fmt.Sprintf("%d → %d", count(session.Start), count(*session.End))
+}
+
 var _ = templruntime.GeneratedTemplate
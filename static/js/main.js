@@ -22,6 +22,10 @@ document.body.addEventListener('htmx:afterRequest', function(event) {
         if (form) {
             setTimeout(() => {
                 form.reset();
+                const preview = document.getElementById('quick-capture-preview');
+                if (preview) {
+                    preview.innerHTML = '';
+                }
                 document.querySelector('#quick-capture-form input[name="title"]').focus();
             }, 100);
         }